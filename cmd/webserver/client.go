@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"webserver/internal/tui"
+)
+
+// serverURLs collects the -server flag, which may be repeated or comma-separated
+type serverURLs []string
+
+func (s *serverURLs) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *serverURLs) Set(value string) error {
+	for _, url := range strings.Split(value, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			*s = append(*s, url)
+		}
+	}
+	return nil
+}
+
+// runClient runs the terminal UI against servers, or over a saved snapshot
+func runClient(args []string) error {
+	flags := newFlagSet("client", "client [flags]",
+		"Monitor one or more servers with the terminal UI, or browse a saved test run\noffline. Defaults come from the client config file.",
+		"webserver client",
+		"webserver client -server ws://example.com:8080/ws",
+		"webserver client -server ws://localhost:8080/ws -server ws://localhost:8081/ws",
+		"WEBSERVER_TOKEN=change-me webserver client",
+		"webserver client -from-file webserver-snapshot-20250101-120000.json",
+		"webserver client -theme light",
+	)
+	var servers serverURLs
+	flags.Var(&servers, "server", "WebSocket server URL; repeat or comma-separate to monitor several servers, switched with [ and ]\n(default: the client config's servers, or ws://localhost:8080/ws)")
+	themeName := flags.String("theme", "", "Color theme: dark, light or high-contrast (default: dark)")
+	configPath := flags.String("client-config", "", "Client config file, YAML or JSON, with default servers, refresh interval, theme,\nkey bindings, layout, filters and columns (default: webserver-tui/config.yaml in the user config dir)")
+	snapshotPath := flags.String("from-file", "", "Browse a saved test run offline instead of connecting: a snapshot saved with W\non the Overview tab, or a stats or request log JSON/NDJSON export")
+	token := flags.String("token", "", "Management API token sent on HTTP and WebSocket requests when the server has\nmanagement_auth tokens (default: $"+tui.TokenEnvVar+")")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	config, err := tui.LoadClientConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load TUI client config: %w", err)
+	}
+	theme, err := config.ResolveTheme(*themeName)
+	if err != nil {
+		return fmt.Errorf("failed to load TUI theme: %w", err)
+	}
+	keys, err := config.KeyMap()
+	if err != nil {
+		return fmt.Errorf("failed to load TUI key bindings: %w", err)
+	}
+
+	// -server replaces the client config's servers
+	if len(servers) == 0 {
+		servers = config.Servers
+	}
+	if len(servers) == 0 {
+		servers = []string{"ws://localhost:8080/ws"}
+	}
+
+	if *token == "" {
+		*token = os.Getenv(tui.TokenEnvVar)
+	}
+
+	var snapshot *tui.Snapshot
+	if *snapshotPath != "" {
+		snapshot, err = tui.LoadSnapshot(*snapshotPath)
+		if err != nil {
+			return fmt.Errorf("failed to load snapshot: %w", err)
+		}
+		log.Printf("Starting webserver client, browsing snapshot: %s", *snapshotPath)
+	} else {
+		log.Printf("Starting webserver client, connecting to: %s", strings.Join(servers, ", "))
+	}
+
+	if err := tui.RunTUI(tui.Options{Servers: servers, Theme: theme, Keys: &keys, Layout: config.Layout, Token: *token, Snapshot: snapshot, Config: config}); err != nil {
+		return fmt.Errorf("failed to start TUI: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"webserver/internal/config"
+)
+
+// runHealthcheck exits zero when a local server answers its health check,
+// for container HEALTHCHECK directives that have no curl or wget
+func runHealthcheck(args []string) error {
+	defaultPort := 8080
+	if port, err := strconv.Atoi(os.Getenv(config.EnvPort)); err == nil {
+		defaultPort = port
+	}
+
+	flags := newFlagSet("healthcheck", "healthcheck [flags]",
+		"Request GET /healthz of a running server and exit non-zero unless it answers\n200 within the timeout. Meant for container health checks:\n\n  HEALTHCHECK CMD [\"./webserver\", \"healthcheck\"]",
+		"webserver healthcheck",
+		"webserver healthcheck -port 9090 -timeout 2s",
+	)
+	port := flags.Int("port", defaultPort, "Port of the server (default: $"+config.EnvPort+", or 8080)")
+	host := flags.String("host", "127.0.0.1", "Host of the server")
+	timeout := flags.Duration("timeout", 5*time.Second, "How long to wait for the answer")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("http://%s/healthz", net.JoinHostPort(*host, strconv.Itoa(*port)))
+	client := &http.Client{Timeout: *timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("unhealthy: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unhealthy: %s answered %d", url, resp.StatusCode)
+	}
+	fmt.Println("healthy")
+	return nil
+}
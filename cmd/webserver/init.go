@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"webserver/internal/config"
+	"webserver/pkg/types"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// runInit writes a starter configuration and static directory
+func runInit(args []string) error {
+	flags := newFlagSet("init", "init [flags]",
+		"Create a starter configuration with an example endpoint of each type, and a\nstatic directory with an index page listing them. On a terminal the settings\nare asked for, with the flags as defaults; -yes takes the flags as they are.",
+		"webserver init",
+		"webserver init -yes -config mock.json -port 9090",
+	)
+	configPath := flags.String("config", "configs/default.json", "Path to write the configuration file to")
+	host := flags.String("host", "0.0.0.0", "Address the server listens on")
+	port := flags.Int("port", 8080, "Port the server listens on")
+	staticDir := flags.String("static-dir", "./static", "Directory to serve static files from")
+	examples := flags.Bool("examples", true, "Include the example endpoints")
+	yes := flags.Bool("yes", false, "Do not ask, use the flags")
+	force := flags.Bool("force", false, "Overwrite an existing configuration file")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	if !*yes && term.IsTerminal(os.Stdin.Fd()) {
+		in := bufio.NewReader(os.Stdin)
+		*configPath = ask(in, "Configuration file", *configPath)
+		*host = ask(in, "Listen address", *host)
+		for {
+			answer := ask(in, "Port", strconv.Itoa(*port))
+			if parsed, err := strconv.Atoi(answer); err == nil {
+				*port = parsed
+				break
+			}
+			fmt.Printf("  %q is not a port number\n", answer)
+		}
+		*staticDir = ask(in, "Static directory", *staticDir)
+		*examples = askYesNo(in, "Include example endpoints", *examples)
+	}
+
+	if _, err := os.Stat(*configPath); err == nil && !*force {
+		return fmt.Errorf("%s already exists (use -force to overwrite it)", *configPath)
+	}
+
+	cfg := config.StarterConfig(types.ServerConfig{Host: *host, Port: *port, StaticDir: *staticDir})
+	if !*examples {
+		cfg.Endpoints = map[string]types.EndpointConfig{}
+	}
+	if err := config.NewManager(*configPath).ValidateConfig(cfg); err != nil {
+		return err
+	}
+
+	if err := writeConfigFile(*configPath, cfg); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s with %d endpoints\n", *configPath, len(cfg.Endpoints))
+
+	// Static paths are relative to where the server is started, as in the file
+	created, err := writeStaticSkeleton(*staticDir, cfg)
+	if err != nil {
+		return err
+	}
+	for _, path := range created {
+		fmt.Printf("Wrote %s\n", path)
+	}
+
+	fmt.Println()
+	fmt.Printf("Start the server with:\n  webserver serve -config %s\n", *configPath)
+	return nil
+}
+
+// writeConfigFile writes a configuration as indented JSON, creating its
+// directory
+func writeConfigFile(path string, cfg *types.Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// ask prompts for a value, returning the default for an empty answer
+func ask(in *bufio.Reader, question, defaultValue string) string {
+	fmt.Printf("%s [%s]: ", question, defaultValue)
+	answer, err := in.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer == "" || (err != nil && err != io.EOF) {
+		return defaultValue
+	}
+	return answer
+}
+
+// askYesNo prompts for a yes or no answer
+func askYesNo(in *bufio.Reader, question string, defaultValue bool) bool {
+	choices := "Y/n"
+	if !defaultValue {
+		choices = "y/N"
+	}
+	switch strings.ToLower(ask(in, question, choices)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return defaultValue
+}
+
+// writeStaticSkeleton creates the static directory with an index page
+// linking to the configured endpoints and a stylesheet, leaving files that
+// already exist alone. It returns the files written.
+func writeStaticSkeleton(dir string, cfg *types.Config) ([]string, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "css"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create static directory: %w", err)
+	}
+
+	paths := make([]string, 0, len(cfg.Endpoints))
+	for path := range cfg.Endpoints {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var endpoints strings.Builder
+	for _, path := range paths {
+		endpoint := cfg.Endpoints[path]
+		fmt.Fprintf(&endpoints, "      <li><a href=\"%s\">%s</a> - %s</li>\n", html.EscapeString(types.EndpointPath(path)), html.EscapeString(path), html.EscapeString(describeEndpoint(endpoint)))
+	}
+	if len(paths) == 0 {
+		endpoints.WriteString("      <li>No endpoints yet: add them to the configuration file or with POST /config</li>\n")
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"index.html", `<!DOCTYPE html>
+<html>
+<head>
+  <title>WebServer</title>
+  <link rel="stylesheet" href="/css/style.css">
+</head>
+<body>
+  <main>
+    <h1>WebServer</h1>
+    <p>Files in this directory are served as they are. Configured endpoints:</p>
+    <ul>
+` + endpoints.String() + `    </ul>
+    <p>Statistics are at <a href="/stats">/stats</a> and the configuration at <a href="/config">/config</a>.</p>
+  </main>
+</body>
+</html>
+`},
+		{filepath.Join("css", "style.css"), `body { font-family: sans-serif; margin: 40px; color: #333; }
+main { max-width: 800px; margin: 0 auto; }
+li { margin: 6px 0; }
+`},
+	}
+
+	var created []string
+	for _, file := range files {
+		path := filepath.Join(dir, file.name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(file.content), 0644); err != nil {
+			return created, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		created = append(created, path)
+	}
+	return created, nil
+}
+
+// describeEndpoint says what an endpoint does, for the index page
+func describeEndpoint(endpoint types.EndpointConfig) string {
+	switch endpoint.Type {
+	case "error":
+		return fmt.Sprintf("always fails with %d", endpoint.StatusCode)
+	case "delay":
+		if profile := endpoint.DelayProfile; profile != nil {
+			return fmt.Sprintf("responds after %dms to %dms (%s over %s)", profile.FromMs, profile.ToMs, profile.Shape, profile.Duration)
+		}
+		if endpoint.DelayMs == 0 {
+			return "responds at once"
+		}
+		return fmt.Sprintf("responds after %dms", endpoint.DelayMs)
+	case "conditional_error":
+		return fmt.Sprintf("fails with %d every %d requests", endpoint.StatusCode, endpoint.ErrorEveryN)
+	case "fuzz":
+		return "returns malformed JSON"
+	case "proxy":
+		return "forwards to " + endpoint.TargetURL
+	case "redirect":
+		return "redirects to " + endpoint.Location
+	}
+	return endpoint.Type
+}
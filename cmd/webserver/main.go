@@ -1,110 +1,260 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"log"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 
-	"webserver/internal/server"
-	"webserver/internal/tui"
+	"webserver/internal/buildinfo"
+	"webserver/internal/logging"
 )
 
+// command is a webserver subcommand, run with the arguments after its name
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands are listed in the help in this order
+var commands []command
+
+func init() {
+	commands = []command{
+		{"serve", "Run the configurable web server (the default)", runServe},
+		{"client", "Monitor servers with the terminal UI, or browse a saved snapshot", runClient},
+		{"init", "Create a starter configuration and static directory", runInit},
+		{"validate", "Check a configuration file without starting the server", runValidate},
+		{"selftest", "Check that every configured endpoint behaves as configured", runSelftest},
+		{"record", "Proxy to an API and record its responses as endpoints", runRecord},
+		{"healthcheck", "Exit non-zero unless a local server is healthy", runHealthcheck},
+		{"help", "Show help for webserver or one of its commands", runHelp},
+		{"version", "Show version information", runVersion},
+	}
+}
+
 func main() {
-	var (
-		configPath = flag.String("config", "configs/default.json", "Path to configuration file")
-		client     = flag.Bool("client", false, "Run in client mode (TUI)")
-		serverURL  = flag.String("server", "ws://localhost:8080/ws", "WebSocket server URL (client mode only)")
-		help       = flag.Bool("help", false, "Show help message")
-		version    = flag.Bool("version", false, "Show version information")
-	)
-	flag.Parse()
-
-	if *help {
-		showHelp()
-		return
+	name, args := commandLine(os.Args[1:])
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "webserver: unknown command %q\n\nRun 'webserver help' for the list of commands.\n", name)
+		os.Exit(2)
 	}
 
-	if *version {
-		showVersion()
-		return
+	if err := cmd.run(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		var usage usageError
+		if errors.As(err, &usage) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "webserver %s: %v\n", cmd.name, err)
+		os.Exit(1)
 	}
+}
 
-	if *client {
-		runClient(*serverURL)
-	} else {
-		runServer(*configPath)
+// commandLine splits the arguments into a command name and its arguments.
+// Without a command name the flags from before subcommands existed keep
+// working: -client (or -from-file) runs the client, -help and -version show
+// the help and version, and anything else runs the server.
+func commandLine(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0], args[1:]
 	}
+
+	name := "serve"
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			rest = append(rest, arg)
+			continue
+		}
+		switch strings.TrimLeft(arg, "-") {
+		case "client", "client=true":
+			name = "client"
+			continue
+		case "help", "h":
+			return "help", nil
+		case "version":
+			return "version", nil
+		}
+		if flagName(arg) == "from-file" {
+			name = "client"
+		}
+		rest = append(rest, arg)
+	}
+	return name, rest
 }
 
-func runServer(configPath string) {
-	log.Println("Starting webserver...")
+// flagName returns the name of a -flag or --flag=value argument
+func flagName(arg string) string {
+	name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+	return name
+}
 
-	// Create and start server
-	srv, err := server.NewServer(configPath)
-	if err != nil {
-		log.Fatalf("Failed to create server: %v", err)
+// findCommand returns the command with the given name
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// usageError reports a command line the flag package already explained
+type usageError struct{ err error }
+
+func (e usageError) Error() string { return e.err.Error() }
+
+// newFlagSet creates the flags of a command. Its usage shows the command's
+// synopsis and description, the flags and the examples.
+func newFlagSet(name, synopsis, description string, examples ...string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintf(out, "Usage: webserver %s\n\n%s\n", synopsis, description)
+		hasFlags := false
+		flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(out, "\nFlags:")
+			flags.PrintDefaults()
+		}
+		if len(examples) > 0 {
+			fmt.Fprintln(out, "\nExamples:")
+			for _, example := range examples {
+				fmt.Fprintf(out, "  %s\n", example)
+			}
+		}
 	}
+	return flags
+}
 
-	// Start server
-	if err := srv.Start(); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+// parseFlags parses a command's arguments, wrapping errors the flag package
+// has already printed with the usage
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{err}
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(flags.Output(), "unexpected argument: %s\n", flags.Arg(0))
+		flags.Usage()
+		return usageError{fmt.Errorf("unexpected argument: %s", flags.Arg(0))}
 	}
+	return nil
+}
 
-	// Wait for interrupt signal
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+// logFlags are the logging flags of the commands that run a server
+type logFlags struct {
+	level string
+	quiet bool
+}
 
-	log.Println("Server is running. Press Ctrl+C to stop.")
-	<-sigChan
+// addLogFlags adds -log-level and -quiet to a command's flags
+func addLogFlags(flags *flag.FlagSet) *logFlags {
+	lf := &logFlags{}
+	flags.StringVar(&lf.level, "log-level", "info", "Lowest level logged: debug, info (one line per request), warn or error")
+	flags.BoolVar(&lf.quiet, "quiet", false, "Only log errors, the same as -log-level error")
+	return lf
+}
 
-	log.Println("Shutting down server...")
-	if err := srv.Stop(); err != nil {
-		log.Printf("Error during shutdown: %v", err)
+// apply sets the logging level from the flags
+func (lf *logFlags) apply() error {
+	if lf.quiet {
+		logging.SetLevel(logging.LevelError)
+		return nil
+	}
+	level, err := logging.ParseLevel(lf.level)
+	if err != nil {
+		return err
 	}
-	log.Println("Server stopped.")
+	logging.SetLevel(level)
+	return nil
 }
 
-func runClient(serverURL string) {
-	log.Printf("Starting webserver client, connecting to: %s", serverURL)
+// runHelp shows the overall help, or a command's usage
+func runHelp(args []string) error {
+	if len(args) == 0 {
+		showHelp()
+		return nil
+	}
+	cmd, ok := findCommand(args[0])
+	if !ok || cmd.name == "help" {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return cmd.run([]string{"-help"})
+}
 
-	if err := tui.RunTUI(serverURL); err != nil {
-		log.Fatalf("Failed to start TUI: %v", err)
+// runVersion shows version information
+func runVersion(args []string) error {
+	flags := newFlagSet("version", "version", "Show version information.")
+	if err := parseFlags(flags, args); err != nil {
+		return err
 	}
+	showVersion()
+	return nil
 }
 
 func showHelp() {
 	fmt.Println("WebServer - Configurable Web Server")
 	fmt.Println()
 	fmt.Println("USAGE:")
-	fmt.Println("  webserver [OPTIONS]")
-	fmt.Println()
-	fmt.Println("OPTIONS:")
-	fmt.Println("  -config string")
-	fmt.Println("        Path to configuration file (default: configs/default.json)")
-	fmt.Println("  -client")
-	fmt.Println("        Run in client mode (TUI)")
-	fmt.Println("  -server string")
-	fmt.Println("        WebSocket server URL for client mode (default: ws://localhost:8080/ws)")
-	fmt.Println("  -help")
-	fmt.Println("        Show this help message")
-	fmt.Println("  -version")
-	fmt.Println("        Show version information")
+	fmt.Println("  webserver <command> [flags]")
+	fmt.Println()
+	fmt.Println("COMMANDS:")
+	for _, cmd := range commands {
+		fmt.Printf("  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Println()
+	fmt.Println("  Run 'webserver help <command>' or 'webserver <command> -help' for a command's flags.")
+	fmt.Println("  Without a command, webserver runs the server; -client still runs the client.")
 	fmt.Println()
 	fmt.Println("EXAMPLES:")
+	fmt.Println("  # Create a starter configuration and static directory")
+	fmt.Println("  webserver init")
+	fmt.Println()
 	fmt.Println("  # Start server with default configuration")
-	fmt.Println("  webserver")
+	fmt.Println("  webserver serve")
 	fmt.Println()
 	fmt.Println("  # Start server with custom configuration")
-	fmt.Println("  webserver -config /path/to/config.json")
+	fmt.Println("  webserver serve -config /path/to/config.json")
+	fmt.Println()
+	fmt.Println("  # Check a configuration before deploying it")
+	fmt.Println("  webserver validate -config /path/to/config.json")
+	fmt.Println()
+	fmt.Println("  # Smoke test a configuration: request every endpoint once")
+	fmt.Println("  webserver selftest -config /path/to/config.json")
+	fmt.Println()
+	fmt.Println("  # Record a real API through a proxy, then serve the recording")
+	fmt.Println("  webserver record -upstream https://api.example.com -out recorded.json")
+	fmt.Println("  webserver serve -config recorded.json")
+	fmt.Println()
+	fmt.Println("  # Run in a container: JSON logs on stdout, listen on 0.0.0.0, /healthz")
+	fmt.Println("  webserver serve -container -config /app/configs/default.json")
 	fmt.Println()
 	fmt.Println("  # Run client (TUI) to connect to local server")
-	fmt.Println("  webserver --client")
+	fmt.Println("  webserver client")
 	fmt.Println()
 	fmt.Println("  # Run client (TUI) to connect to remote server")
-	fmt.Println("  webserver --client -server ws://example.com:8080/ws")
+	fmt.Println("  webserver client -server ws://example.com:8080/ws")
+	fmt.Println()
+	fmt.Println("  # Run client (TUI) monitoring two local servers")
+	fmt.Println("  webserver client -server ws://localhost:8080/ws -server ws://localhost:8081/ws")
+	fmt.Println()
+	fmt.Println("  # Run client (TUI) against a server with management auth")
+	fmt.Println("  WEBSERVER_TOKEN=change-me webserver client")
+	fmt.Println()
+	fmt.Println("  # Browse a past test run without a server")
+	fmt.Println("  webserver client -from-file webserver-snapshot-20250101-120000.json")
+	fmt.Println()
+	fmt.Println("  # Run client (TUI) on a light terminal background")
+	fmt.Println("  webserver client -theme light")
 	fmt.Println()
 	fmt.Println("SERVER FEATURES:")
 	fmt.Println("  - Configurable static file serving")
@@ -143,20 +293,63 @@ func showHelp() {
 	fmt.Println("  PUT    /config      - Update entire configuration")
 	fmt.Println("  POST   /config      - Add/update endpoint")
 	fmt.Println("  DELETE /config      - Remove endpoint")
-	fmt.Println("  GET    /stats       - Get server statistics")
-	fmt.Println("  GET    /ws          - WebSocket connection for TUI")
+	fmt.Println("  POST   /config/validate - Validate a full configuration without applying it")
+	fmt.Println("  GET    /config/status - Show whether the latest configuration reload failed")
+	fmt.Println("  GET    /config/overrides - List endpoint overrides that still apply")
+	fmt.Println("  POST   /config/overrides - Override an endpoint for a count of requests or a TTL")
+	fmt.Println("  DELETE /config/overrides - Remove an override (id=3) or all of them")
+	fmt.Println("  GET    /scenarios   - List scenarios and the endpoints they changed")
+	fmt.Println("  POST   /scenarios   - Start a scenario (name=outage)")
+	fmt.Println("  DELETE /scenarios   - Stop the running scenario and restore its endpoints")
+	fmt.Println("  GET    /sessions    - List sessions with their calls and counters")
+	fmt.Println("  DELETE /sessions    - Forget sessions (session=alice)")
+	fmt.Println("  GET    /groups      - List endpoint groups with their chaos profiles")
+	fmt.Println("  POST   /groups      - Enable, disable or break a group (group=payments, action=enable|disable|chaos, profile=flaky)")
+	fmt.Println("  DELETE /groups      - Clear the chaos profile of a group (group=payments) or all")
+	fmt.Println("  GET    /stats       - Get server statistics (changed_since=RFC3339 for changes only)")
+	fmt.Println("  DELETE /stats       - Reset server statistics")
+	fmt.Println("  GET    /stats/top   - Get top N endpoints (by=requests|errors|latency, n=10)")
+	fmt.Println("  GET    /stats/timeseries - Per-second traffic and latency (window=60, step=1, path)")
+	fmt.Println("  GET    /stats/export - Export statistics (format=csv|tsv)")
+	fmt.Println("  POST   /stats/snapshot - Take a named statistics snapshot (name=A)")
+	fmt.Println("  GET    /stats/diff  - Diff two snapshots (from=A&to=B)")
+	fmt.Println("  GET    /requestlog  - Get request log (since=N for entries logged after position N)")
+	fmt.Println("  DELETE /requestlog  - Clear request log")
+	fmt.Println("  GET    /requestlog/export - Stream request log as NDJSON (follow=true)")
+	fmt.Println("  GET    /requestlog/groups - Request log grouped by normalized route")
+	fmt.Println("  GET    /requestlog/diff - Diff two logged requests (from, to)")
+	fmt.Println("  GET    /requestlog/{id} - Get a request log entry by X-Request-ID")
+	fmt.Println("  POST   /requestlog/{id}/replay - Replay a logged request (target=URL)")
+	fmt.Println("  GET    /requestlog/{id}/curl - Render a logged request as a curl command")
+	fmt.Println("  GET    /version     - Get the server's version, commit, build date and protocol")
+	fmt.Println("  GET    /ws          - WebSocket push API (subscribe to stats, config, request_log)")
+	fmt.Println("  GET    /ws/clients  - List connected WebSocket clients")
 	fmt.Println()
 	fmt.Println("CLIENT KEYBOARD SHORTCUTS:")
 	fmt.Println("  Tab/Shift+Tab    - Switch between tabs")
 	fmt.Println("  R                - Refresh data")
+	fmt.Println("  < / >            - Poll more / less often")
+	fmt.Println("  B                - Toggle low-bandwidth polling")
 	fmt.Println("  Q/Ctrl+C         - Quit")
 	fmt.Println()
 }
 
 func showVersion() {
+	info := buildinfo.Get()
 	fmt.Println("WebServer Configurable Web Server")
-	fmt.Println("Version: 1.0.0")
-	fmt.Println("Built with Go")
+	fmt.Printf("Version:  %s\n", info.Version)
+	if info.Commit != "" {
+		commit := info.Commit
+		if info.Modified {
+			commit += " (modified)"
+		}
+		fmt.Printf("Commit:   %s\n", commit)
+	}
+	if info.Date != "" {
+		fmt.Printf("Built:    %s\n", info.Date)
+	}
+	fmt.Printf("Go:       %s\n", info.GoVersion)
+	fmt.Printf("Protocol: %d\n", info.Protocol)
 	fmt.Println()
 	fmt.Println("Server Features:")
 	fmt.Println("  ✓ Static file serving")
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"webserver/internal/config"
+	"webserver/internal/logging"
+	"webserver/internal/record"
+	"webserver/pkg/types"
+)
+
+// runRecord proxies to an upstream API until interrupted, then writes an
+// endpoint for every route it saw
+func runRecord(args []string) error {
+	flags := newFlagSet("record", "record -upstream URL [flags]",
+		"Run a proxy to an upstream API and record its responses. On Ctrl+C every\nroute seen is written as an endpoint: routes that always succeeded replay the\nlatest response after the average latency, routes that always failed replay\nthe error, and routes that did both fail as often as upstream did. Serve the\nrecording with 'webserver serve -config <out>'.",
+		"webserver record -upstream https://api.example.com -out recorded.json",
+		"webserver record -upstream http://localhost:3000 -port 9090 -latency=false",
+	)
+	upstream := flags.String("upstream", "", "URL of the API to record (required)")
+	out := flags.String("out", "recorded.json", "Path to write the recorded configuration to")
+	host := flags.String("host", "0.0.0.0", "Address the proxy listens on, also written to the configuration")
+	port := flags.Int("port", 8080, "Port the proxy listens on, also written to the configuration")
+	staticDir := flags.String("static-dir", "./static", "Static directory written to the configuration")
+	latency := flags.Bool("latency", true, "Replay the average upstream latency as a delay")
+	force := flags.Bool("force", false, "Overwrite an existing output file")
+	logs := addLogFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := logs.apply(); err != nil {
+		return err
+	}
+
+	if *upstream == "" {
+		return fmt.Errorf("-upstream is required")
+	}
+	upstreamURL, err := url.Parse(*upstream)
+	if err != nil || (upstreamURL.Scheme != "http" && upstreamURL.Scheme != "https") || upstreamURL.Host == "" {
+		return fmt.Errorf("-upstream must be an http(s) URL: %q", *upstream)
+	}
+	if *port < 1 || *port > 65535 {
+		return fmt.Errorf("invalid -port: %d", *port)
+	}
+	if _, err := os.Stat(*out); err == nil && !*force {
+		return fmt.Errorf("%s already exists (use -force to overwrite it)", *out)
+	}
+
+	recorder := record.New(upstreamURL)
+	httpServer := &http.Server{
+		Addr:    net.JoinHostPort(*host, strconv.Itoa(*port)),
+		Handler: recorder,
+	}
+	listener, err := net.Listen("tcp", httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- httpServer.Serve(listener) }()
+	logging.Infof("Recording %s on %s. Press Ctrl+C to stop and write %s.", upstreamURL, httpServer.Addr, *out)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case <-sigChan:
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("proxy failed: %w", err)
+		}
+	}
+	signal.Stop(sigChan)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil {
+		logging.Errorf("Error during shutdown: %v", err)
+	}
+
+	cfg, notes := recorder.Config(types.ServerConfig{Host: *host, Port: *port, StaticDir: *staticDir}, *latency)
+	if err := config.NewManager(*out).ValidateConfig(cfg); err != nil {
+		return err
+	}
+	if err := writeConfigFile(*out, cfg); err != nil {
+		return err
+	}
+	for _, note := range notes {
+		fmt.Printf("note: %s\n", note)
+	}
+	fmt.Printf("Wrote %s with %d endpoints\n", *out, len(cfg.Endpoints))
+	return nil
+}
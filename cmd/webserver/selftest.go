@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"webserver/internal/config"
+	"webserver/internal/logging"
+	"webserver/internal/protobuf"
+	"webserver/internal/server"
+	"webserver/pkg/types"
+)
+
+// selftestResult is the outcome of requesting one configured endpoint
+type selftestResult struct {
+	path    string
+	status  string // PASS, FAIL or SKIP
+	details string
+}
+
+// runSelftest serves a configuration on an ephemeral port and checks that
+// every endpoint behaves as configured
+func runSelftest(args []string) error {
+	flags := newFlagSet("selftest", "selftest [flags]",
+		"Start the server on an ephemeral local port, request every configured endpoint\nonce and check the response against the configuration: the status code, the\nbody, and for delay endpoints that the response took between delay_ms and\ndelay_ms plus -slack. Exits non-zero when an endpoint does not behave as\nconfigured, as a smoke test for mock configurations.",
+		"webserver selftest -config configs/default.json",
+		"webserver selftest -config base.json -config payments.json -slack 500ms",
+	)
+	var configPaths configFiles
+	flags.Var(&configPaths, "config", "Path to configuration file; repeat to merge files as 'serve' does\n(default \"configs/default.json\")")
+	slack := flags.Duration("slack", 250*time.Millisecond, "How much longer than delay_ms a delay endpoint may take")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if len(configPaths) == 0 {
+		configPaths = configFiles{"configs/default.json"}
+	}
+
+	// The server would create a missing file with defaults, which would
+	// then pass for the wrong reason
+	for _, path := range configPaths {
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+	}
+
+	// Per-request lines would interleave with the report
+	previousLevel := logging.GetLevel()
+	logging.SetLevel(max(previousLevel, logging.LevelWarn))
+	defer logging.SetLevel(previousLevel)
+
+	srv, err := server.NewServerWithOverrides(configPaths, config.Overrides{})
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	if err := srv.StartListener(listener); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+	defer srv.Stop()
+
+	cfg := srv.GetConfig()
+	paths := make([]string, 0, len(cfg.Endpoints))
+	for path := range cfg.Endpoints {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// Endpoints are requested at once so delays do not add up
+	baseURL := "http://" + listener.Addr().String()
+	results := make([]selftestResult, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = checkEndpoint(baseURL, path, cfg.Endpoints[path], *slack)
+		}()
+	}
+	wg.Wait()
+
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.status]++
+		fmt.Printf("%s  %s  %s\n", result.status, result.path, result.details)
+	}
+	fmt.Printf("\n%d passed, %d failed, %d skipped\n", counts["PASS"], counts["FAIL"], counts["SKIP"])
+
+	if counts["FAIL"] > 0 {
+		return fmt.Errorf("%d of %d endpoints do not behave as configured", counts["FAIL"], len(paths))
+	}
+	return nil
+}
+
+// checkEndpoint requests an endpoint once and compares the response with
+// what its configuration promises for a first request
+func checkEndpoint(baseURL, path string, endpoint types.EndpointConfig, slack time.Duration) selftestResult {
+	result := selftestResult{path: path, status: "FAIL"}
+
+	var wantStatus int
+	var wantBody interface{}
+	switch {
+	case endpoint.Disabled:
+		result.status, result.details = "SKIP", "disabled"
+		return result
+	case endpoint.Type == "error":
+		wantStatus, wantBody = endpoint.StatusCode, map[string]string{"error": endpoint.Message}
+	case endpoint.Type == "delay":
+		wantStatus, wantBody = http.StatusOK, endpoint.Response
+	case endpoint.Type == "conditional_error" && endpoint.ErrorEveryN == 1:
+		wantStatus, wantBody = endpoint.StatusCode, map[string]string{"error": "Conditional error triggered"}
+	case endpoint.Type == "conditional_error":
+		wantStatus, wantBody = http.StatusOK, endpoint.SuccessResponse
+	default:
+		result.status, result.details = "SKIP", fmt.Sprintf("%s endpoints are not checked", endpoint.Type)
+		return result
+	}
+
+	// Profiles may delay anywhere between their two delays
+	minDelay := time.Duration(endpoint.DelayMs) * time.Millisecond
+	maxDelay := minDelay
+	if profile := endpoint.DelayProfile; profile != nil {
+		minDelay = time.Duration(min(profile.FromMs, profile.ToMs)) * time.Millisecond
+		maxDelay = time.Duration(max(profile.FromMs, profile.ToMs)) * time.Millisecond
+	}
+	// Endpoints limited to other methods are requested with the first one
+	method := http.MethodGet
+	if !endpoint.AcceptsMethod(method) {
+		method = endpoint.Methods[0]
+	}
+	req, err := http.NewRequest(method, baseURL+types.EndpointPath(path), nil)
+	if err != nil {
+		result.details = err.Error()
+		return result
+	}
+	client := &http.Client{Timeout: maxDelay + slack + 5*time.Second}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		result.details = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	elapsed := time.Since(start)
+	if err != nil {
+		result.details = fmt.Sprintf("failed to read the body: %v", err)
+		return result
+	}
+
+	if resp.StatusCode != wantStatus {
+		result.details = fmt.Sprintf("status %d, want %d", resp.StatusCode, wantStatus)
+		return result
+	}
+	if endpoint.Type == "delay" && (elapsed < minDelay || elapsed > maxDelay+slack) {
+		result.details = fmt.Sprintf("took %dms, want %dms to %dms", elapsed.Milliseconds(), minDelay.Milliseconds(), (maxDelay + slack).Milliseconds())
+		return result
+	}
+	if endpoint.Protobuf != nil && resp.StatusCode < 400 {
+		// Validation already encoded the response with the descriptor set
+		if contentType := resp.Header.Get("Content-Type"); contentType != protobuf.ContentType {
+			result.details = fmt.Sprintf("content type %q, want %q", contentType, protobuf.ContentType)
+			return result
+		}
+	} else if !sameJSON(body, wantBody) {
+		result.details = fmt.Sprintf("unexpected body %s", truncate(string(body), 80))
+		return result
+	}
+
+	result.status = "PASS"
+	result.details = fmt.Sprintf("%d in %dms", resp.StatusCode, elapsed.Milliseconds())
+	return result
+}
+
+// sameJSON reports whether a body is the JSON encoding of a value
+func sameJSON(body []byte, want interface{}) bool {
+	encoded, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+	var got, expected interface{}
+	if json.Unmarshal(body, &got) != nil || json.Unmarshal(encoded, &expected) != nil {
+		return false
+	}
+	return reflect.DeepEqual(got, expected)
+}
+
+// truncate shortens text to at most n characters
+func truncate(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	return text[:n] + "..."
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"webserver/internal/config"
+	"webserver/internal/logging"
+	"webserver/internal/server"
+)
+
+// configFiles collects the -config flag, which may be repeated
+type configFiles []string
+
+func (c *configFiles) String() string {
+	return strings.Join(*c, ",")
+}
+
+func (c *configFiles) Set(value string) error {
+	*c = append(*c, value)
+	return nil
+}
+
+// envContainer turns on container mode like -container
+const envContainer = "WEBSERVER_CONTAINER"
+
+// runServe runs the server until it is interrupted
+func runServe(args []string) error {
+	flags := newFlagSet("serve", "serve [flags]",
+		"Run the configurable web server. The configuration file is created with\ndefaults if it does not exist, and reloaded when it changes. -host, -port and\n-static-dir replace the file's settings on every load without changing the file.\n\nWith several -config files, each later file replaces the server settings it\nsets, adds or replaces endpoints by path, and replaces alerts if it has them.\nChanges made through the API are then applied but not saved.\n\nWith -env-config no file is used: the configuration starts from the default\nserver settings without endpoints, then "+config.EnvConfigJSON+" (a whole\nconfiguration), "+config.EnvEndpoints+" (endpoints by path),\n"+config.EnvHost+", "+config.EnvPort+" and "+config.EnvStaticDir+" are applied in turn.\n\nSIGHUP reloads the configuration at once; SIGUSR1 writes the statistics and\nrequest log to JSON files in -dump-dir, as the TUI exports them.\n\nContainer mode (-container, or "+envContainer+"=true) logs JSON lines to stdout,\nlistens on 0.0.0.0 unless -host is given, and exits non-zero instead of\ncreating a missing configuration file. GET /healthz answers health checks, and\n'webserver healthcheck' requests it for HEALTHCHECK directives.\n\nContract mode (-contract URL, or server.contract) proxies every request to a\nreal backend instead of mocking it, and compares the responses of configured\nendpoints with theirs, reporting mismatches in the statistics and request log.",
+		"webserver serve",
+		"webserver serve -config /path/to/config.json",
+		"webserver serve -config base.json -config payments.json",
+		"webserver serve -port 9090 -static-dir ./public",
+		"webserver serve -log-level warn",
+		"webserver serve -container -config /etc/webserver/config.json",
+		"webserver serve -config recorded.json -contract https://staging.example.com",
+		`WEBSERVER_ENDPOINTS='{"/api/down": {"type": "error", "status_code": 503}}' webserver serve -env-config`,
+	)
+	var configPaths configFiles
+	flags.Var(&configPaths, "config", "Path to configuration file; repeat to merge files, each over the ones before it\n(default \"configs/default.json\")")
+	var overrides config.Overrides
+	flags.StringVar(&overrides.Host, "host", "", "Address to listen on, replacing the config file's server.host")
+	flags.IntVar(&overrides.Port, "port", 0, "Port to listen on, replacing the config file's server.port")
+	flags.StringVar(&overrides.StaticDir, "static-dir", "", "Directory to serve static files from, replacing the config file's server.static_dir")
+	flags.StringVar(&overrides.Contract, "contract", "", "Check a real backend against the endpoints: proxy to this URL and compare its\nresponses, replacing the config file's server.contract.upstream")
+	envConfig := flags.Bool("env-config", false, "Read the configuration from WEBSERVER_* environment variables instead of a file")
+	container := flags.Bool("container", false, "Run in container mode (also enabled by "+envContainer+"=true)")
+	dumpDir := flags.String("dump-dir", ".", "Directory SIGUSR1 writes the statistics and request log to")
+	logs := addLogFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := logs.apply(); err != nil {
+		return err
+	}
+	if overrides.Port < 0 || overrides.Port > 65535 {
+		return fmt.Errorf("invalid -port: %d", overrides.Port)
+	}
+
+	if !*container {
+		if enabled, err := strconv.ParseBool(os.Getenv(envContainer)); err == nil {
+			*container = enabled
+		}
+	}
+	if *container {
+		logging.SetJSON(os.Stdout)
+		if overrides.Host == "" {
+			overrides.Host = "0.0.0.0"
+		}
+	}
+
+	logging.Infof("Starting webserver...")
+
+	// Create and start server
+	var srv *server.Server
+	var err error
+	if *envConfig {
+		if len(configPaths) > 0 {
+			return fmt.Errorf("-config and -env-config cannot be combined")
+		}
+		manager := config.NewEnvManager(os.Getenv)
+		manager.SetOverrides(overrides)
+		srv, err = server.NewServerWithManager(manager)
+	} else {
+		if len(configPaths) == 0 {
+			configPaths = configFiles{"configs/default.json"}
+		}
+		// A missing file in a container is a deployment mistake, not a
+		// first run
+		if *container {
+			for _, path := range configPaths {
+				if _, err := os.Stat(path); err != nil {
+					return err
+				}
+			}
+		}
+		srv, err = server.NewServerWithOverrides(configPaths, overrides)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
+	}
+
+	// Start server
+	if err := srv.Start(); err != nil {
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+
+	// Wait for interrupt signal, reloading and dumping on the control signals
+	sigChan := make(chan os.Signal, 1)
+	signals := []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	for _, sig := range []os.Signal{reloadSignal, dumpSignal} {
+		if sig != nil {
+			signals = append(signals, sig)
+		}
+	}
+	signal.Notify(sigChan, signals...)
+
+	logging.Infof("Server is running. Press Ctrl+C to stop.")
+	for sig := range sigChan {
+		if sig == reloadSignal {
+			logging.Infof("Received %v, reloading configuration", sig)
+			if err := srv.ReloadConfig(); err != nil {
+				logging.Errorf("Failed to reload configuration: %v", err)
+			}
+			continue
+		}
+		if sig == dumpSignal {
+			paths, err := srv.DumpState(*dumpDir)
+			if err != nil {
+				logging.Errorf("Failed to dump state: %v", err)
+			}
+			for _, path := range paths {
+				logging.Infof("Received %v, wrote %s", sig, path)
+			}
+			continue
+		}
+		break
+	}
+
+	logging.Infof("Shutting down server...")
+	if err := srv.Stop(); err != nil {
+		logging.Errorf("Error during shutdown: %v", err)
+	}
+	logging.Infof("Server stopped.")
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// Signals that reload the configuration and dump the statistics and request
+// log while serving
+var (
+	reloadSignal os.Signal = syscall.SIGHUP
+	dumpSignal   os.Signal = syscall.SIGUSR1
+)
@@ -0,0 +1,12 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// Windows has no SIGHUP or SIGUSR1; the configuration is still reloaded when
+// its file changes
+var (
+	reloadSignal os.Signal
+	dumpSignal   os.Signal
+)
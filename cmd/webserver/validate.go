@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"webserver/internal/config"
+	"webserver/pkg/types"
+)
+
+// runValidate checks configuration files the way the server would load them,
+// without creating them when they are missing
+func runValidate(args []string) error {
+	flags := newFlagSet("validate", "validate [flags] [file...]",
+		"Check configuration files without starting the server. Every problem in a\nfile is reported, with the line and column of syntax errors. Unknown keys are\nerrors, since the server would silently ignore them; a static directory that\ndoes not exist yet is only a warning, since the server creates it. Exits\nnon-zero when any file is invalid, for pre-commit hooks and CI.",
+		"webserver validate -config configs/default.json",
+		"webserver validate configs/*.json",
+		"webserver validate -config base.json -config payments.json",
+	)
+	var configPaths configFiles
+	flags.Var(&configPaths, "config", "Path to configuration file, when no files are given; repeat to check files merged\nas 'serve' merges them (default \"configs/default.json\")")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{err}
+	}
+
+	// Files given as arguments are checked one by one, repeated -config
+	// files together
+	checks := [][]string{configPaths}
+	if flags.NArg() > 0 {
+		checks = checks[:0]
+		for _, path := range flags.Args() {
+			checks = append(checks, []string{path})
+		}
+	} else if len(configPaths) == 0 {
+		checks = [][]string{{"configs/default.json"}}
+	}
+
+	invalid := 0
+	for _, paths := range checks {
+		if !validateFiles(os.Stdout, paths) {
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d configurations invalid", invalid, len(checks))
+	}
+	return nil
+}
+
+// validateFiles reports the problems in a configuration merged from one or
+// more files, returning whether it is valid
+func validateFiles(out io.Writer, paths []string) bool {
+	var cfg types.Config
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(out, "%s: %v\n", path, err)
+			return false
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&cfg); err != nil {
+			fmt.Fprintf(out, "%s: %s\n", path, describeDecodeError(data, err))
+			return false
+		}
+	}
+	path := strings.Join(paths, " + ")
+
+	problems := config.NewManager(path).ValidationErrors(&cfg)
+	for _, problem := range problems {
+		fmt.Fprintf(out, "%s: %v\n", path, problem)
+	}
+
+	// The static directory is the only file the configuration refers to
+	if dir := cfg.Server.StaticDir; dir != "" {
+		if info, err := os.Stat(dir); os.IsNotExist(err) {
+			fmt.Fprintf(out, "%s: warning: static_dir %s does not exist and will be created at startup\n", path, dir)
+		} else if err != nil {
+			fmt.Fprintf(out, "%s: static_dir %s: %v\n", path, dir, err)
+			problems = append(problems, err)
+		} else if !info.IsDir() {
+			fmt.Fprintf(out, "%s: static_dir %s is not a directory\n", path, dir)
+			problems = append(problems, fmt.Errorf("static_dir %s is not a directory", dir))
+		}
+	}
+
+	if len(problems) > 0 {
+		return false
+	}
+	fmt.Fprintf(out, "%s: valid (%d endpoints)\n", path, len(cfg.Endpoints))
+	return true
+}
+
+// describeDecodeError explains a JSON decoding error with the position of
+// the offending input where the error has one
+func describeDecodeError(data []byte, err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, column := position(data, syntaxErr.Offset)
+		return fmt.Sprintf("%d:%d: %v", line, column, syntaxErr)
+	case errors.As(err, &typeErr):
+		line, column := position(data, typeErr.Offset)
+		return fmt.Sprintf("%d:%d: %s must be %s, not %s", line, column, typeErr.Field, typeErr.Type, typeErr.Value)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return "unexpected end of file"
+	}
+	// Unknown fields are reported as `json: unknown field "name"`
+	return strings.TrimPrefix(err.Error(), "json: ")
+}
+
+// position returns the 1-based line and column of a byte offset
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := 1 + bytes.Count(before, []byte("\n"))
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,61 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"webserver/pkg/types"
+)
+
+// ProtocolVersion is the version of the HTTP and WebSocket API shared by the
+// server and the TUI. It changes when one of them can no longer understand
+// the other.
+const ProtocolVersion = 1
+
+// Set at build time with, for example:
+//
+//	go build -ldflags "-X webserver/internal/buildinfo.Version=1.2.0 -X webserver/internal/buildinfo.Commit=$(git rev-parse HEAD) -X webserver/internal/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Values left empty are taken from the build information Go embeds.
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// Get returns the version information of this binary
+func Get() types.VersionInfo {
+	info := types.VersionInfo{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Protocol:  ProtocolVersion,
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			case "vcs.modified":
+				// Only describes the commit when that came from version control too
+				info.Modified = Commit == "" && setting.Value == "true"
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
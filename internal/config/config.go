@@ -2,20 +2,59 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	"webserver/internal/fuzz"
+	"webserver/internal/jsonschema"
+	"webserver/internal/logging"
+	"webserver/internal/protobuf"
 	"webserver/pkg/types"
 )
 
+var (
+	// ErrInvalidConfig is wrapped by the errors of changes that would leave
+	// the configuration invalid
+	ErrInvalidConfig = errors.New("invalid configuration")
+
+	// ErrGroupNotFound is wrapped by the errors of group changes naming a
+	// group without endpoints
+	ErrGroupNotFound = errors.New("no endpoints in group")
+)
+
 // Manager handles configuration loading, validation, and hot reloading
 type Manager struct {
 	configPath string
 	config     *types.Config
 	mutex      sync.RWMutex
 	watchers   []func(*types.Config)
+	reloaded   []func(types.ConfigStatus) // Told the outcome of every reload
+	layers     []string                   // Files merged over the configuration file, in order
+	getenv     func(key string) string    // Set when the configuration comes from the environment instead
+	overrides  Overrides
+	fileServer types.ServerConfig // Server settings as last read from or written to the file
+	loadErr    error              // Error of the latest load, nil once one succeeds
+	loadedAt   time.Time          // When the configuration in use was loaded
+	failedAt   time.Time          // When the latest load failed
+}
+
+// Overrides replace server settings from the configuration file, e.g. with
+// command-line flags. They apply to every load and reload but are never
+// written back to the file; zero values keep the file's setting.
+type Overrides struct {
+	Host      string
+	Port      int
+	StaticDir string
+	Contract  string // Upstream checked in contract mode, keeping the file's other contract settings
 }
 
 // NewManager creates a new configuration manager
@@ -26,39 +65,164 @@ func NewManager(configPath string) *Manager {
 	}
 }
 
-// LoadConfig loads the configuration from file
+// SetOverrides sets the server settings that replace the file's from the
+// next load on
+func (m *Manager) SetOverrides(overrides Overrides) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.overrides = overrides
+}
+
+// SetLayers sets files to merge over the configuration file, in order, from
+// the next load on. Runtime changes to a merged configuration are not saved,
+// since no single file holds it.
+func (m *Manager) SetLayers(paths []string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.layers = append([]string(nil), paths...)
+}
+
+// applyOverrides replaces the overridden server settings of a configuration
+func (m *Manager) applyOverrides(config *types.Config) {
+	if m.overrides.Host != "" {
+		config.Server.Host = m.overrides.Host
+	}
+	if m.overrides.Port != 0 {
+		config.Server.Port = m.overrides.Port
+	}
+	if m.overrides.StaticDir != "" {
+		config.Server.StaticDir = m.overrides.StaticDir
+	}
+	if m.overrides.Contract != "" {
+		contract := types.ContractConfig{}
+		if config.Server.Contract != nil {
+			contract = *config.Server.Contract
+		}
+		contract.Upstream = m.overrides.Contract
+		config.Server.Contract = &contract
+	}
+}
+
+// LoadConfig loads the configuration from file, merging the layers over it,
+// or from the environment
 func (m *Manager) LoadConfig() error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	m.loadErr = m.loadConfig()
+	if m.loadErr != nil {
+		m.failedAt = time.Now()
+	} else {
+		m.loadedAt = time.Now()
+	}
+	return m.loadErr
+}
+
+// Reload loads the configuration files again, as when they change. They
+// are validated before anything is applied: on success the watchers get the
+// new configuration, and on failure the previous one stays in use, with the
+// error reported by Status. Either way the reload watchers get the status.
+func (m *Manager) Reload() error {
+	err := m.LoadConfig()
+	if err == nil {
+		m.notifyWatchers(m.GetConfig())
+	}
+
+	status := m.Status()
+	m.mutex.RLock()
+	reloaded := append([]func(types.ConfigStatus){}, m.reloaded...)
+	m.mutex.RUnlock()
+	for _, watcher := range reloaded {
+		watcher(status)
+	}
+	return err
+}
+
+// Status reports which files the configuration comes from, when the one in
+// use was loaded, and why the latest reload failed if it did
+func (m *Manager) Status() types.ConfigStatus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.status()
+}
+
+// status does the work of Status with the lock held
+func (m *Manager) status() types.ConfigStatus {
+	status := types.ConfigStatus{LoadedAt: m.loadedAt}
+	if m.getenv == nil {
+		status.Files = append([]string{m.configPath}, m.layers...)
+	}
+	if m.loadErr != nil {
+		failedAt := m.failedAt
+		status.Error = m.loadErr.Error()
+		status.FailedAt = &failedAt
+	}
+	return status
+}
+
+// LoadError returns the error of the latest load or reload, which keeps the
+// previous configuration in use, or nil once a load succeeds
+func (m *Manager) LoadError() error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.loadErr
+}
+
+// loadConfig does the work of LoadConfig with the lock held
+func (m *Manager) loadConfig() error {
+	var config *types.Config
+
 	// Check if config file exists
-	if _, err := os.Stat(m.configPath); os.IsNotExist(err) {
+	if m.getenv != nil {
+		envConfig, err := ConfigFromEnv(m.getenv)
+		if err != nil {
+			return err
+		}
+		config = envConfig
+	} else if _, err := os.Stat(m.configPath); os.IsNotExist(err) {
 		// Create default configuration if file doesn't exist
-		defaultConfig := m.createDefaultConfig()
-		if err := m.saveConfigToFile(defaultConfig); err != nil {
+		config = m.createDefaultConfig()
+		m.fileServer = config.Server
+		if err := m.saveConfigToFile(config); err != nil {
 			return fmt.Errorf("failed to create default config: %w", err)
 		}
-		m.config = defaultConfig
-		return nil
-	}
-
-	// Load existing configuration
-	data, err := os.ReadFile(m.configPath)
-	if err != nil {
-		return fmt.Errorf("failed to read config file: %w", err)
+	} else {
+		// Load existing configuration
+		config = &types.Config{}
+		if err := readConfigFile(m.configPath, config); err != nil {
+			return err
+		}
 	}
 
-	var config types.Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return fmt.Errorf("failed to parse config file: %w", err)
+	for _, layer := range m.layers {
+		if err := readConfigFile(layer, config); err != nil {
+			return err
+		}
 	}
+	fileServer := config.Server
+	m.applyOverrides(config)
 
 	// Validate configuration
-	if err := m.validateConfig(&config); err != nil {
+	if err := m.validateConfig(config); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	m.config = &config
+	m.fileServer = fileServer
+	m.config = config
+	return nil
+}
+
+// readConfigFile decodes a configuration file over a configuration. Only
+// the keys present in the file are replaced: server settings one by one,
+// endpoints path by path, and lists such as alerts as a whole.
+func readConfigFile(path string, config *types.Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
 	return nil
 }
 
@@ -72,11 +236,23 @@ func (m *Manager) GetConfig() *types.Config {
 	}
 
 	// Create a deep copy to avoid race conditions
-	configCopy := *m.config
-	configCopy.Endpoints = make(map[string]types.EndpointConfig)
-	for k, v := range m.config.Endpoints {
+	return cloneConfig(m.config)
+}
+
+// cloneConfig copies a configuration deeply enough that its endpoints and
+// chaos profiles can be changed without touching the original
+func cloneConfig(config *types.Config) *types.Config {
+	configCopy := *config
+	configCopy.Endpoints = make(map[string]types.EndpointConfig, len(config.Endpoints))
+	for k, v := range config.Endpoints {
 		configCopy.Endpoints[k] = v
 	}
+	if config.ChaosProfiles != nil {
+		configCopy.ChaosProfiles = make(map[string]types.ChaosProfile, len(config.ChaosProfiles))
+		for k, v := range config.ChaosProfiles {
+			configCopy.ChaosProfiles[k] = v
+		}
+	}
 
 	return &configCopy
 }
@@ -87,21 +263,25 @@ func (m *Manager) UpdateConfig(newConfig *types.Config) error {
 	defer m.mutex.Unlock()
 
 	// Validate new configuration
+	m.applyOverrides(newConfig)
 	if err := m.validateConfig(newConfig); err != nil {
-		return fmt.Errorf("invalid configuration: %w", err)
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
 	}
 
 	// Save to file
-	if err := m.saveConfigToFile(newConfig); err != nil {
+	if err := m.saveChanges(newConfig); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	// Update in-memory configuration
-	m.config = newConfig
-
-	// Notify watchers
-	go m.notifyWatchers(newConfig)
+	m.applyConfig(newConfig)
+	return nil
+}
 
+// ValidateConfig checks a configuration without applying it
+func (m *Manager) ValidateConfig(config *types.Config) error {
+	if err := m.validateConfig(config); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
 	return nil
 }
 
@@ -119,20 +299,20 @@ func (m *Manager) UpdateEndpoint(path string, endpointConfig types.EndpointConfi
 		return fmt.Errorf("invalid endpoint configuration: %w", err)
 	}
 
-	// Update endpoint
-	if m.config.Endpoints == nil {
-		m.config.Endpoints = make(map[string]types.EndpointConfig)
+	// Validate the whole configuration with the endpoint in place, as it
+	// may clash with the others
+	newConfig := cloneConfig(m.config)
+	newConfig.Endpoints[path] = endpointConfig
+	if err := m.validateConfig(newConfig); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
 	}
-	m.config.Endpoints[path] = endpointConfig
 
 	// Save to file
-	if err := m.saveConfigToFile(m.config); err != nil {
+	if err := m.saveChanges(newConfig); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	// Notify watchers
-	go m.notifyWatchers(m.config)
-
+	m.applyConfig(newConfig)
 	return nil
 }
 
@@ -149,19 +329,76 @@ func (m *Manager) RemoveEndpoint(path string) error {
 		return fmt.Errorf("endpoint not found")
 	}
 
-	delete(m.config.Endpoints, path)
+	newConfig := cloneConfig(m.config)
+	delete(newConfig.Endpoints, path)
 
 	// Save to file
-	if err := m.saveConfigToFile(m.config); err != nil {
+	if err := m.saveChanges(newConfig); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	// Notify watchers
-	go m.notifyWatchers(m.config)
-
+	m.applyConfig(newConfig)
 	return nil
 }
 
+// SetGroupDisabled disables or enables every endpoint of a group at once,
+// returning how many endpoints the group has
+func (m *Manager) SetGroupDisabled(group string, disabled bool) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.config == nil {
+		return 0, fmt.Errorf("configuration not loaded")
+	}
+
+	newConfig := cloneConfig(m.config)
+	count := 0
+	for path, endpointConfig := range newConfig.Endpoints {
+		if endpointConfig.Group == group {
+			endpointConfig.Disabled = disabled
+			newConfig.Endpoints[path] = endpointConfig
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("%w %s", ErrGroupNotFound, group)
+	}
+
+	// Enabling endpoints may make them clash with others
+	if err := m.validateConfig(newConfig); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	// Save to file
+	if err := m.saveChanges(newConfig); err != nil {
+		return 0, fmt.Errorf("failed to save config: %w", err)
+	}
+
+	m.applyConfig(newConfig)
+	return count, nil
+}
+
+// applyConfig puts a validated configuration in use and notifies the
+// watchers. A valid configuration in use clears the error of a failed
+// reload, and the reload watchers are told. The caller holds the lock.
+func (m *Manager) applyConfig(newConfig *types.Config) {
+	m.config = newConfig
+	m.loadedAt = time.Now()
+	go m.notifyWatchers(newConfig)
+
+	if m.loadErr == nil {
+		return
+	}
+	m.loadErr = nil
+	status := m.status()
+	reloaded := append([]func(types.ConfigStatus){}, m.reloaded...)
+	go func() {
+		for _, watcher := range reloaded {
+			watcher(status)
+		}
+	}()
+}
+
 // AddWatcher adds a configuration change watcher
 func (m *Manager) AddWatcher(watcher func(*types.Config)) {
 	m.mutex.Lock()
@@ -169,6 +406,14 @@ func (m *Manager) AddWatcher(watcher func(*types.Config)) {
 	m.watchers = append(m.watchers, watcher)
 }
 
+// AddReloadWatcher adds a watcher told the status after every reload,
+// whether it succeeded or not
+func (m *Manager) AddReloadWatcher(watcher func(types.ConfigStatus)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.reloaded = append(m.reloaded, watcher)
+}
+
 // createDefaultConfig creates a default configuration
 func (m *Manager) createDefaultConfig() *types.Config {
 	return &types.Config{
@@ -202,37 +447,280 @@ func (m *Manager) createDefaultConfig() *types.Config {
 	}
 }
 
-// validateConfig validates the entire configuration
+// validateConfig validates the entire configuration, returning the first
+// problem found
 func (m *Manager) validateConfig(config *types.Config) error {
+	if errs := m.ValidationErrors(config); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// ValidationErrors returns every problem with a configuration, server
+// settings first, then endpoints in path order and then scenarios
+func (m *Manager) ValidationErrors(config *types.Config) []error {
+	var errs []error
+
 	// Validate server configuration
 	if config.Server.Port < 1 || config.Server.Port > 65535 {
-		return fmt.Errorf("invalid port: %d", config.Server.Port)
+		errs = append(errs, fmt.Errorf("invalid port: %d", config.Server.Port))
 	}
 
 	if config.Server.Host == "" {
-		return fmt.Errorf("host cannot be empty")
+		errs = append(errs, fmt.Errorf("host cannot be empty"))
 	}
 
 	if config.Server.StaticDir == "" {
-		return fmt.Errorf("static directory cannot be empty")
+		errs = append(errs, fmt.Errorf("static directory cannot be empty"))
+	}
+
+	if config.Server.SampleEvery < 0 {
+		errs = append(errs, fmt.Errorf("sample_every cannot be negative: %d", config.Server.SampleEvery))
+	}
+
+	if config.Server.RequestLogSize < 0 {
+		errs = append(errs, fmt.Errorf("request_log_size cannot be negative: %d", config.Server.RequestLogSize))
+	}
+
+	if config.Server.RequestLogMemoryMB < 0 {
+		errs = append(errs, fmt.Errorf("request_log_memory_mb cannot be negative: %d", config.Server.RequestLogMemoryMB))
+	}
+
+	if config.Server.RequestLogSampleEvery < 0 {
+		errs = append(errs, fmt.Errorf("request_log_sample_every cannot be negative: %d", config.Server.RequestLogSampleEvery))
+	}
+
+	if config.Server.RequestLogSampleAboveRPS < 0 {
+		errs = append(errs, fmt.Errorf("request_log_sample_above_rps cannot be negative: %d", config.Server.RequestLogSampleAboveRPS))
+	}
+
+	if config.Server.WSHeartbeatSeconds < 0 {
+		errs = append(errs, fmt.Errorf("ws_heartbeat_seconds cannot be negative: %d", config.Server.WSHeartbeatSeconds))
+	}
+
+	if config.Server.WSSendQueueSize < 0 {
+		errs = append(errs, fmt.Errorf("ws_send_queue_size cannot be negative: %d", config.Server.WSSendQueueSize))
+	}
+
+	if config.Server.ShutdownDrainMs < 0 {
+		errs = append(errs, fmt.Errorf("shutdown_drain_ms cannot be negative: %d", config.Server.ShutdownDrainMs))
+	}
+
+	switch config.Server.WSSlowClientPolicy {
+	case "", types.SlowClientDrop, types.SlowClientDisconnect:
+	default:
+		errs = append(errs, fmt.Errorf("unknown ws_slow_client_policy: %s (use drop or disconnect)", config.Server.WSSlowClientPolicy))
+	}
+
+	for _, pattern := range config.Server.RequestLogExclude {
+		if err := ValidatePathPattern(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid request_log_exclude pattern '%s': %w", pattern, err))
+		}
+	}
+
+	if _, err := types.NewRedactor(config.Server.RequestLogRedact); err != nil {
+		errs = append(errs, fmt.Errorf("invalid request_log_redact: %w", err))
+	}
+
+	if err := validateManagementAuth(config.Server.ManagementAuth); err != nil {
+		errs = append(errs, fmt.Errorf("invalid management_auth: %w", err))
+	}
+
+	if err := validateContract(config.Server.Contract); err != nil {
+		errs = append(errs, fmt.Errorf("invalid contract: %w", err))
+	}
+
+	if err := validateSessions(config.Server.Sessions); err != nil {
+		errs = append(errs, fmt.Errorf("invalid sessions: %w", err))
+	}
+
+	for _, target := range config.Server.ReplayTargets {
+		if targetURL, err := url.Parse(target); err != nil || (targetURL.Scheme != "http" && targetURL.Scheme != "https") || targetURL.Host == "" {
+			errs = append(errs, fmt.Errorf("replay target must be an http(s) URL: %q", target))
+		} else if targetURL.User != nil {
+			errs = append(errs, fmt.Errorf("replay target cannot carry credentials: %q", targetURL.Redacted()))
+		}
+	}
+
+	names := make(map[string]bool)
+	for i := range config.Alerts {
+		rule := &config.Alerts[i]
+		if rule.Name == "" {
+			errs = append(errs, fmt.Errorf("alert rule name cannot be empty"))
+			continue
+		}
+		if names[rule.Name] {
+			errs = append(errs, fmt.Errorf("duplicate alert rule name: %s", rule.Name))
+			continue
+		}
+		names[rule.Name] = true
+
+		if err := validateAlertRule(rule); err != nil {
+			errs = append(errs, fmt.Errorf("invalid alert rule '%s': %w", rule.Name, err))
+		}
 	}
 
 	// Validate endpoint configurations
-	for path, endpointConfig := range config.Endpoints {
-		if path == "" {
-			return fmt.Errorf("endpoint path cannot be empty")
+	paths := make([]string, 0, len(config.Endpoints))
+	for path := range config.Endpoints {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		endpointConfig := config.Endpoints[path]
+		if types.EndpointPath(path) == "" {
+			errs = append(errs, fmt.Errorf("endpoint path cannot be empty"))
+		} else if strings.HasSuffix(path, "#") {
+			errs = append(errs, fmt.Errorf("endpoint '%s' needs a label after #", path))
 		}
 
 		if err := m.validateEndpointConfig(&endpointConfig); err != nil {
-			return fmt.Errorf("invalid endpoint '%s': %w", path, err)
+			errs = append(errs, fmt.Errorf("invalid endpoint '%s': %w", path, err))
+		}
+	}
+
+	errs = append(errs, validateEndpointMethods(config.Endpoints, paths)...)
+
+	profileNames := make([]string, 0, len(config.ChaosProfiles))
+	for name := range config.ChaosProfiles {
+		profileNames = append(profileNames, name)
+	}
+	sort.Strings(profileNames)
+	for _, name := range profileNames {
+		profile := config.ChaosProfiles[name]
+		if err := validateChaosProfile(name, &profile); err != nil {
+			errs = append(errs, fmt.Errorf("invalid chaos profile '%s': %w", name, err))
+		}
+	}
+
+	// Validate scenario files
+	if len(config.Scenarios) > 0 {
+		files, err := LoadScenarios(config.Scenarios)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, file := range files {
+			if err := m.validateScenario(file.Scenario); err != nil {
+				errs = append(errs, fmt.Errorf("invalid scenario '%s' in %s: %w", file.Scenario.Name, file.Path, err))
+			}
 		}
 	}
 
+	return errs
+}
+
+// validateChaosProfile checks the name, latency, error rate and status of a
+// chaos profile
+func validateChaosProfile(name string, profile *types.ChaosProfile) error {
+	if !isToken(name) {
+		return fmt.Errorf("name must be a single word")
+	}
+	if profile.LatencyMs < 0 {
+		return fmt.Errorf("latency_ms cannot be negative: %d", profile.LatencyMs)
+	}
+	if profile.ErrorRate < 0 || profile.ErrorRate > 1 {
+		return fmt.Errorf("error_rate must be between 0 and 1: %g", profile.ErrorRate)
+	}
+	if profile.StatusCode != 0 && (profile.StatusCode < 400 || profile.StatusCode > 599) {
+		return fmt.Errorf("status_code must be an error status: %d", profile.StatusCode)
+	}
+	if profile.LatencyMs == 0 && profile.ErrorRate == 0 {
+		return fmt.Errorf("set latency_ms or error_rate")
+	}
+	return nil
+}
+
+// validateEndpointMethods checks that the enabled endpoints serving the same
+// path do not answer the same method, given the endpoint keys in order
+func validateEndpointMethods(endpoints map[string]types.EndpointConfig, keys []string) []error {
+	var errs []error
+	answeredBy := make(map[string]string) // Path and method, or path alone for every method
+	for _, key := range keys {
+		endpoint := endpoints[key]
+		if endpoint.Disabled {
+			continue
+		}
+		path := types.EndpointPath(key)
+		methods := endpoint.Methods
+		if len(methods) == 0 {
+			methods = []string{""}
+		}
+		for _, method := range methods {
+			answered := path + " " + method
+			if other, exists := answeredBy[answered]; exists {
+				if method == "" {
+					errs = append(errs, fmt.Errorf("endpoints '%s' and '%s' both answer every method; list methods to tell them apart", other, key))
+				} else {
+					errs = append(errs, fmt.Errorf("endpoints '%s' and '%s' both answer %s", other, key, method))
+				}
+				continue
+			}
+			answeredBy[answered] = key
+		}
+	}
+	return errs
+}
+
+// validateDelayProfile checks the shape, delays and duration of a profile
+func validateDelayProfile(profile *types.DelayProfile) error {
+	switch profile.Shape {
+	case types.DelayProfileLinear, types.DelayProfileSine:
+	default:
+		return fmt.Errorf("unknown shape: %q (use linear or sine)", profile.Shape)
+	}
+	if profile.FromMs < 0 || profile.ToMs < 0 {
+		return fmt.Errorf("delays cannot be negative: from_ms %d, to_ms %d", profile.FromMs, profile.ToMs)
+	}
+	if duration, err := time.ParseDuration(profile.Duration); err != nil || duration <= 0 {
+		return fmt.Errorf("duration must be a positive duration such as 10m: %q", profile.Duration)
+	}
+	if profile.Repeat && profile.Shape != types.DelayProfileLinear {
+		return fmt.Errorf("repeat only applies to linear profiles; sine profiles always repeat")
+	}
+	return nil
+}
+
+// ValidateOverride checks a runtime endpoint override before it applies
+func (m *Manager) ValidateOverride(override *types.EndpointOverride) error {
+	if err := ValidatePathPattern(override.Path); err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	if override.Count < 0 {
+		return fmt.Errorf("count cannot be negative: %d", override.Count)
+	}
+	if override.TTL != "" {
+		if ttl, err := time.ParseDuration(override.TTL); err != nil || ttl <= 0 {
+			return fmt.Errorf("ttl must be a positive duration such as 30s: %q", override.TTL)
+		}
+	}
+	if override.Count == 0 && override.TTL == "" {
+		return fmt.Errorf("count or ttl is required, so the override expires")
+	}
+	if err := m.validateEndpointConfig(&override.Endpoint); err != nil {
+		return fmt.Errorf("invalid endpoint: %w", err)
+	}
 	return nil
 }
 
 // validateEndpointConfig validates a single endpoint configuration
 func (m *Manager) validateEndpointConfig(config *types.EndpointConfig) error {
+	if config.ApdexThresholdMs < 0 {
+		return fmt.Errorf("apdex_threshold_ms cannot be negative: %d", config.ApdexThresholdMs)
+	}
+
+	if config.SlowThresholdMs < 0 {
+		return fmt.Errorf("slow_threshold_ms cannot be negative: %d", config.SlowThresholdMs)
+	}
+
+	if config.SLO != nil {
+		if config.SLO.Target <= 0 || config.SLO.Target >= 100 {
+			return fmt.Errorf("slo target must be between 0 and 100 (exclusive): %g", config.SLO.Target)
+		}
+		if _, err := types.ParseSLOWindows(config.SLO.Windows); err != nil {
+			return err
+		}
+	}
+
 	switch config.Type {
 	case "error":
 		if config.StatusCode < 400 || config.StatusCode > 599 {
@@ -242,6 +730,14 @@ func (m *Manager) validateEndpointConfig(config *types.EndpointConfig) error {
 		if config.DelayMs < 0 {
 			return fmt.Errorf("delay cannot be negative: %d", config.DelayMs)
 		}
+		if config.DelayProfile != nil {
+			if err := validateDelayProfile(config.DelayProfile); err != nil {
+				return fmt.Errorf("invalid delay_profile: %w", err)
+			}
+			if config.DelayMs != 0 {
+				return fmt.Errorf("delay_profile replaces delay_ms; set only one")
+			}
+		}
 	case "conditional_error":
 		if config.ErrorEveryN < 1 {
 			return fmt.Errorf("error_every_n must be at least 1: %d", config.ErrorEveryN)
@@ -249,17 +745,313 @@ func (m *Manager) validateEndpointConfig(config *types.EndpointConfig) error {
 		if config.StatusCode < 400 || config.StatusCode > 599 {
 			return fmt.Errorf("invalid error status code: %d", config.StatusCode)
 		}
+	case "fuzz":
+		if config.StatusCode != 0 && (config.StatusCode < 200 || config.StatusCode > 599) {
+			return fmt.Errorf("invalid fuzz status code: %d", config.StatusCode)
+		}
+	case "proxy":
+		target, err := url.Parse(config.TargetURL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("proxy target_url must be an http(s) URL: %q", config.TargetURL)
+		}
+	case "redirect":
+		if config.Location == "" {
+			return fmt.Errorf("redirect endpoints need a location")
+		}
+		if _, err := url.Parse(config.Location); err != nil {
+			return fmt.Errorf("invalid redirect location %q: %w", config.Location, err)
+		}
+		switch config.StatusCode {
+		case 0, http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		default:
+			return fmt.Errorf("invalid redirect status code: %d (use 301, 302, 307 or 308)", config.StatusCode)
+		}
 	case "static":
 		// Static endpoints are handled differently
 	default:
 		return fmt.Errorf("unknown endpoint type: %s", config.Type)
 	}
 
+	if config.Fuzz != nil {
+		if config.Type != "fuzz" {
+			return fmt.Errorf("fuzz settings need a fuzz endpoint, not %s", config.Type)
+		}
+		for _, mutation := range config.Fuzz.Mutations {
+			if !fuzz.IsMutation(mutation) {
+				return fmt.Errorf("unknown fuzz mutation: %s (use %s)", mutation, strings.Join(fuzz.Mutations, ", "))
+			}
+		}
+		if config.Fuzz.HugeStringBytes < 0 {
+			return fmt.Errorf("huge_string_bytes cannot be negative: %d", config.Fuzz.HugeStringBytes)
+		}
+	}
+
+	if config.DelayProfile != nil && config.Type != "delay" {
+		return fmt.Errorf("delay_profile needs a delay endpoint, not %s", config.Type)
+	}
+
+	if config.TargetURL != "" && config.Type != "proxy" {
+		return fmt.Errorf("target_url needs a proxy endpoint, not %s", config.Type)
+	}
+
+	if config.Location != "" && config.Type != "redirect" {
+		return fmt.Errorf("location needs a redirect endpoint, not %s", config.Type)
+	}
+
+	if config.PerSession && config.Type != "conditional_error" && config.Type != "fuzz" {
+		return fmt.Errorf("per_session needs a conditional_error or fuzz endpoint, not %s", config.Type)
+	}
+
+	if config.Requires != nil {
+		if !strings.HasPrefix(config.Requires.Path, "/") {
+			return fmt.Errorf("requires.path must be an endpoint path: %q", config.Requires.Path)
+		}
+		if config.Requires.StatusCode != 0 && (config.Requires.StatusCode < 400 || config.Requires.StatusCode > 599) {
+			return fmt.Errorf("requires.status_code must be an error status: %d", config.Requires.StatusCode)
+		}
+	}
+
+	if config.Group != "" && !isToken(config.Group) {
+		return fmt.Errorf("group must be a single word: %q", config.Group)
+	}
+
+	seen := make(map[string]bool, len(config.Methods))
+	for _, method := range config.Methods {
+		if !isToken(method) || strings.ToUpper(method) != method {
+			return fmt.Errorf("invalid method %q (use an uppercase HTTP method such as POST)", method)
+		}
+		if seen[method] {
+			return fmt.Errorf("method %s listed twice", method)
+		}
+		seen[method] = true
+	}
+
+	if config.RequestSchema != nil {
+		if _, err := jsonschema.Compile(config.RequestSchema); err != nil {
+			return fmt.Errorf("invalid request_schema: %w", err)
+		}
+	}
+
+	if config.Protobuf != nil {
+		return validateProtobuf(config)
+	}
+
+	return nil
+}
+
+// validateProtobuf checks that an endpoint's response encodes as its
+// protobuf message, so mistakes show at load time instead of as 500s
+func validateProtobuf(config *types.EndpointConfig) error {
+	var response map[string]interface{}
+	switch config.Type {
+	case "delay":
+		response = config.Response
+	case "conditional_error":
+		response = config.SuccessResponse
+	default:
+		return fmt.Errorf("protobuf responses need a delay or conditional_error endpoint, not %s", config.Type)
+	}
+
+	if config.Protobuf.DescriptorSet == "" || config.Protobuf.Message == "" {
+		return fmt.Errorf("protobuf needs descriptor_set and message")
+	}
+	set, err := protobuf.Load(config.Protobuf.DescriptorSet)
+	if err != nil {
+		return err
+	}
+	if !set.HasMessage(config.Protobuf.Message) {
+		return fmt.Errorf("descriptor set %s has no message %s", config.Protobuf.DescriptorSet, config.Protobuf.Message)
+	}
+	if _, err := set.Marshal(config.Protobuf.Message, response); err != nil {
+		return fmt.Errorf("response does not encode as %s: %w", config.Protobuf.Message, err)
+	}
 	return nil
 }
 
-// saveConfigToFile saves the configuration to file
+// validateAlertRule validates a single alert rule
+func validateAlertRule(rule *types.AlertRule) error {
+	webhookURL, err := url.Parse(rule.WebhookURL)
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+		return fmt.Errorf("webhook_url must be an http(s) URL: %q", rule.WebhookURL)
+	}
+
+	if rule.Path != "" {
+		if err := ValidatePathPattern(rule.Path); err != nil {
+			return err
+		}
+	}
+
+	if rule.MinStatus < 0 || rule.MinStatus > 599 || rule.MaxStatus < 0 || rule.MaxStatus > 599 {
+		return fmt.Errorf("status bounds must be between 0 and 599")
+	}
+	if rule.MaxStatus > 0 && rule.MaxStatus < rule.MinStatus {
+		return fmt.Errorf("max_status %d is below min_status %d", rule.MaxStatus, rule.MinStatus)
+	}
+
+	if rule.MinDurationMs < 0 {
+		return fmt.Errorf("min_duration_ms cannot be negative: %d", rule.MinDurationMs)
+	}
+	if rule.CooldownSeconds < 0 {
+		return fmt.Errorf("cooldown_seconds cannot be negative: %d", rule.CooldownSeconds)
+	}
+
+	switch rule.Format {
+	case "", types.AlertFormatJSON, types.AlertFormatSlack:
+	default:
+		return fmt.Errorf("unknown format: %s (use json or slack)", rule.Format)
+	}
+
+	return nil
+}
+
+// validateContract checks the upstream and matching settings of contract
+// mode; a nil configuration disables it
+func validateContract(contract *types.ContractConfig) error {
+	if contract == nil {
+		return nil
+	}
+
+	upstream, err := url.Parse(contract.Upstream)
+	if err != nil || (upstream.Scheme != "http" && upstream.Scheme != "https") || upstream.Host == "" {
+		return fmt.Errorf("upstream must be an http(s) URL: %q", contract.Upstream)
+	}
+
+	switch contract.Match {
+	case "", types.ContractMatchShape, types.ContractMatchExact:
+	default:
+		return fmt.Errorf("unknown match: %s (use shape or exact)", contract.Match)
+	}
+
+	for _, field := range contract.IgnoreFields {
+		if field == "" {
+			return fmt.Errorf("ignore_fields cannot contain an empty field")
+		}
+	}
+
+	if contract.TimeoutMs < 0 {
+		return fmt.Errorf("timeout_ms cannot be negative: %d", contract.TimeoutMs)
+	}
+	return nil
+}
+
+// validateSessions checks that sessions come from a valid header or cookie
+// name; a nil configuration uses the default header
+func validateSessions(sessions *types.SessionConfig) error {
+	if sessions == nil {
+		return nil
+	}
+	if sessions.Header != "" && !isToken(sessions.Header) {
+		return fmt.Errorf("invalid header name: %q", sessions.Header)
+	}
+	if sessions.Cookie != "" && !isToken(sessions.Cookie) {
+		return fmt.Errorf("invalid cookie name: %q", sessions.Cookie)
+	}
+	return nil
+}
+
+// isToken reports whether a name is an HTTP token, as header and cookie
+// names must be
+func isToken(name string) bool {
+	for _, c := range name {
+		if c <= ' ' || c >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?={}`, c) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// validateManagementAuth checks that auth tokens are named, unique and grant known permissions
+func validateManagementAuth(auth *types.ManagementAuthConfig) error {
+	if auth == nil {
+		return nil
+	}
+
+	names := make(map[string]bool)
+	secrets := make(map[string]bool)
+	for _, token := range auth.Tokens {
+		if token.Name == "" {
+			return fmt.Errorf("token name cannot be empty")
+		}
+		if names[token.Name] {
+			return fmt.Errorf("duplicate token name: %s", token.Name)
+		}
+		names[token.Name] = true
+
+		if token.Token == "" || token.Token == types.RedactedValue {
+			return fmt.Errorf("token '%s' has no secret", token.Name)
+		}
+		if secrets[token.Token] {
+			return fmt.Errorf("token '%s' reuses another token's secret", token.Name)
+		}
+		secrets[token.Token] = true
+
+		if len(token.Permissions) == 0 {
+			return fmt.Errorf("token '%s' has no permissions", token.Name)
+		}
+		for _, permission := range token.Permissions {
+			switch strings.ToLower(permission) {
+			case types.PermissionRead, types.PermissionWrite:
+			default:
+				return fmt.Errorf("token '%s' has unknown permission: %s (use read or write)", token.Name, permission)
+			}
+		}
+	}
+
+	return nil
+}
+
+// MatchPathPattern reports whether a request path matches a pattern. Patterns
+// use path.Match glob syntax; a trailing "*" additionally matches any suffix,
+// so "/stats*" covers "/stats" and "/stats/top".
+func MatchPathPattern(pattern, requestPath string) bool {
+	if strings.HasSuffix(pattern, "*") && strings.HasPrefix(requestPath, strings.TrimSuffix(pattern, "*")) {
+		return true
+	}
+	matched, err := path.Match(pattern, requestPath)
+	return err == nil && matched
+}
+
+// ValidatePathPattern checks that a path pattern is well formed
+func ValidatePathPattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("pattern cannot be empty")
+	}
+	_, err := path.Match(pattern, "/")
+	return err
+}
+
+// saveChanges saves a configuration changed at runtime, unless it is merged
+// from several files or comes from the environment
+func (m *Manager) saveChanges(config *types.Config) error {
+	if m.getenv != nil {
+		logging.Warnf("Configuration change applied but not saved: it comes from the environment")
+		return nil
+	}
+	if len(m.layers) > 0 {
+		logging.Warnf("Configuration change applied but not saved: it is merged from %d files", len(m.layers)+1)
+		return nil
+	}
+	return m.saveConfigToFile(config)
+}
+
+// saveConfigToFile saves the configuration to file, keeping the file's
+// values of overridden server settings
 func (m *Manager) saveConfigToFile(config *types.Config) error {
+	fileConfig := *config
+	if m.overrides.Host != "" {
+		fileConfig.Server.Host = m.fileServer.Host
+	}
+	if m.overrides.Port != 0 {
+		fileConfig.Server.Port = m.fileServer.Port
+	}
+	if m.overrides.StaticDir != "" {
+		fileConfig.Server.StaticDir = m.fileServer.StaticDir
+	}
+	if m.overrides.Contract != "" {
+		fileConfig.Server.Contract = m.fileServer.Contract
+	}
+	config = &fileConfig
+
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(m.configPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -277,6 +1069,7 @@ func (m *Manager) saveConfigToFile(config *types.Config) error {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
+	m.fileServer = config.Server
 	return nil
 }
 
@@ -291,3 +1084,14 @@ func (m *Manager) notifyWatchers(config *types.Config) {
 func (m *Manager) GetConfigPath() string {
 	return m.configPath
 }
+
+// GetConfigPaths returns the configuration file followed by its layers;
+// none when the configuration comes from the environment
+func (m *Manager) GetConfigPaths() []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if m.getenv != nil {
+		return nil
+	}
+	return append([]string{m.configPath}, m.layers...)
+}
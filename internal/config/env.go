@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"webserver/pkg/types"
+)
+
+// Environment variables read by a manager created with NewEnvManager
+const (
+	EnvConfigJSON = "WEBSERVER_CONFIG_JSON" // A whole configuration, as in a file
+	EnvEndpoints  = "WEBSERVER_ENDPOINTS"   // JSON object of endpoints by path, merged over the configuration's
+	EnvHost       = "WEBSERVER_HOST"
+	EnvPort       = "WEBSERVER_PORT"
+	EnvStaticDir  = "WEBSERVER_STATIC_DIR"
+)
+
+// NewEnvManager creates a manager that reads the configuration from
+// environment variables instead of a file. Runtime changes are kept in
+// memory only.
+func NewEnvManager(getenv func(string) string) *Manager {
+	return &Manager{
+		getenv:   getenv,
+		watchers: make([]func(*types.Config), 0),
+	}
+}
+
+// ConfigFromEnv builds a configuration from environment variables: the
+// default server settings without endpoints, then WEBSERVER_CONFIG_JSON,
+// WEBSERVER_ENDPOINTS and the single server settings, each over the ones
+// before it
+func ConfigFromEnv(getenv func(string) string) (*types.Config, error) {
+	config := &types.Config{
+		Server:    (&Manager{}).createDefaultConfig().Server,
+		Endpoints: make(map[string]types.EndpointConfig),
+	}
+
+	if value := getenv(EnvConfigJSON); value != "" {
+		if err := json.Unmarshal([]byte(value), config); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", EnvConfigJSON, err)
+		}
+	}
+
+	if value := getenv(EnvEndpoints); value != "" {
+		var endpoints map[string]types.EndpointConfig
+		if err := json.Unmarshal([]byte(value), &endpoints); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", EnvEndpoints, err)
+		}
+		if config.Endpoints == nil {
+			config.Endpoints = make(map[string]types.EndpointConfig)
+		}
+		for path, endpoint := range endpoints {
+			config.Endpoints[path] = endpoint
+		}
+	}
+
+	if value := getenv(EnvHost); value != "" {
+		config.Server.Host = value
+	}
+	if value := getenv(EnvPort); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %q", EnvPort, value)
+		}
+		config.Server.Port = port
+	}
+	if value := getenv(EnvStaticDir); value != "" {
+		config.Server.StaticDir = value
+	}
+
+	return config, nil
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"webserver/pkg/types"
+)
+
+// ScenarioFile is a scenario and the file it was loaded from
+type ScenarioFile struct {
+	Path     string
+	Scenario *types.Scenario
+}
+
+// LoadScenario reads a scenario from a YAML file. Keys are those of the JSON
+// configuration, so endpoints are written as in the endpoints section.
+func LoadScenario(path string) (*types.Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file %s: %w", path, err)
+	}
+	scenario, err := ParseScenario(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse scenario file %s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+// ParseScenario decodes a YAML scenario, rejecting unknown keys
+func ParseScenario(data []byte) (*types.Scenario, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	// Going through JSON applies the configuration's field names and types
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("scenario must be a mapping with string keys: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+	var scenario types.Scenario
+	if err := decoder.Decode(&scenario); err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+// LoadScenarios loads the scenario files of a configuration in name order.
+// Patterns are expanded as globs; a pattern without matches is an error.
+func LoadScenarios(patterns []string) ([]ScenarioFile, error) {
+	var files []ScenarioFile
+	names := make(map[string]string)
+	for _, pattern := range patterns {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scenarios pattern '%s': %w", pattern, err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no scenario files match '%s'", pattern)
+		}
+		for _, path := range paths {
+			scenario, err := LoadScenario(path)
+			if err != nil {
+				return nil, err
+			}
+			if scenario.Name == "" {
+				return nil, fmt.Errorf("scenario in %s has no name", path)
+			}
+			if other, exists := names[scenario.Name]; exists {
+				if other == path {
+					continue
+				}
+				return nil, fmt.Errorf("duplicate scenario name '%s' in %s and %s", scenario.Name, other, path)
+			}
+			names[scenario.Name] = path
+			files = append(files, ScenarioFile{Path: path, Scenario: scenario})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Scenario.Name < files[j].Scenario.Name })
+	return files, nil
+}
+
+// validateScenario checks a scenario's steps and the endpoints they set
+func (m *Manager) validateScenario(scenario *types.Scenario) error {
+	if len(scenario.Steps) == 0 {
+		return fmt.Errorf("scenario has no steps")
+	}
+
+	var total time.Duration
+	for i, step := range scenario.Steps {
+		label := fmt.Sprintf("step %d", i+1)
+		if step.Name != "" {
+			label = fmt.Sprintf("step '%s'", step.Name)
+		}
+
+		delay, err := ScenarioStepDelay(step)
+		if err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		total += delay
+
+		if len(step.Set) == 0 && len(step.Remove) == 0 && len(step.Restore) == 0 {
+			return fmt.Errorf("%s changes nothing (use set, remove or restore)", label)
+		}
+		paths := make([]string, 0, len(step.Set))
+		for path := range step.Set {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			endpointConfig := step.Set[path]
+			if path == "" {
+				return fmt.Errorf("%s: endpoint path cannot be empty", label)
+			}
+			if err := m.validateEndpointConfig(&endpointConfig); err != nil {
+				return fmt.Errorf("%s: invalid endpoint '%s': %w", label, path, err)
+			}
+		}
+		for _, path := range append(append([]string{}, step.Remove...), step.Restore...) {
+			if path == "" {
+				return fmt.Errorf("%s: endpoint path cannot be empty", label)
+			}
+		}
+	}
+
+	// A loop without delays would apply its steps as fast as it can
+	if scenario.Loop && total == 0 {
+		return fmt.Errorf("a looping scenario needs at least one step with a delay")
+	}
+	return nil
+}
+
+// ScenarioStepDelay parses the delay before a step; an empty delay is none
+func ScenarioStepDelay(step types.ScenarioStep) (time.Duration, error) {
+	if step.After == "" {
+		return 0, nil
+	}
+	delay, err := time.ParseDuration(step.After)
+	if err != nil {
+		return 0, fmt.Errorf("invalid after %q: use a duration such as 30s or 2m", step.After)
+	}
+	if delay < 0 {
+		return 0, fmt.Errorf("after cannot be negative: %s", step.After)
+	}
+	return delay, nil
+}
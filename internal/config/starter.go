@@ -0,0 +1,45 @@
+package config
+
+import "webserver/pkg/types"
+
+// StarterConfig returns a configuration for new users with an example
+// endpoint of each type, served with the given server settings
+func StarterConfig(server types.ServerConfig) *types.Config {
+	return &types.Config{
+		Server: server,
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/hello": {
+				Type: "delay",
+				Response: map[string]interface{}{
+					"message": "Hello from webserver",
+				},
+			},
+			"/api/slow": {
+				Type:            "delay",
+				DelayMs:         1500,
+				SlowThresholdMs: 1000,
+				Response: map[string]interface{}{
+					"message": "Slow response",
+				},
+			},
+			"/api/error": {
+				Type:       "error",
+				StatusCode: 500,
+				Message:    "Internal Server Error",
+			},
+			"/api/not-found": {
+				Type:       "error",
+				StatusCode: 404,
+				Message:    "Not Found",
+			},
+			"/api/flaky": {
+				Type:        "conditional_error",
+				ErrorEveryN: 3,
+				StatusCode:  503,
+				SuccessResponse: map[string]interface{}{
+					"status": "ok",
+				},
+			},
+		},
+	}
+}
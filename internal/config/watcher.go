@@ -1,11 +1,13 @@
 package config
 
 import (
-	"log"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"webserver/internal/logging"
+
 	"github.com/fsnotify/fsnotify"
 )
 
@@ -35,6 +37,12 @@ func (w *Watcher) Start() error {
 		return nil
 	}
 
+	// A configuration from the environment has no file to watch
+	configPaths := w.manager.GetConfigPaths()
+	if len(configPaths) == 0 {
+		return nil
+	}
+
 	// Create file watcher
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -44,21 +52,20 @@ func (w *Watcher) Start() error {
 	w.watcher = watcher
 	w.isRunning = true
 
-	// Watch the configuration file and its directory
-	configPath := w.manager.GetConfigPath()
-	configDir := filepath.Dir(configPath)
-
-	// Add directory to watcher (needed for file creation/deletion)
-	if err := w.watcher.Add(configDir); err != nil {
-		w.watcher.Close()
-		w.isRunning = false
-		return err
+	// Watch the configuration files' directories (needed for file
+	// creation/deletion)
+	for _, configPath := range configPaths {
+		if err := w.watcher.Add(filepath.Dir(configPath)); err != nil {
+			w.watcher.Close()
+			w.isRunning = false
+			return err
+		}
 	}
 
 	// Start the watching goroutine
-	go w.watch()
+	go w.watch(configPaths)
 
-	log.Printf("Started configuration file watcher for: %s", configPath)
+	logging.Debugf("Started configuration file watcher for: %s", strings.Join(configPaths, ", "))
 	return nil
 }
 
@@ -74,7 +81,7 @@ func (w *Watcher) Stop() {
 	close(w.stopChan)
 	w.watcher.Close()
 	w.isRunning = false
-	log.Println("Stopped configuration file watcher")
+	logging.Debugf("Stopped configuration file watcher")
 }
 
 // IsRunning returns whether the watcher is currently running
@@ -85,9 +92,11 @@ func (w *Watcher) IsRunning() bool {
 }
 
 // watch is the main watching loop
-func (w *Watcher) watch() {
-	configPath := w.manager.GetConfigPath()
-	configFileName := filepath.Base(configPath)
+func (w *Watcher) watch(configPaths []string) {
+	watched := make(map[string]bool, len(configPaths))
+	for _, configPath := range configPaths {
+		watched[filepath.Clean(configPath)] = true
+	}
 
 	// Debounce file changes to avoid multiple reloads
 	var lastReload time.Time
@@ -102,8 +111,8 @@ func (w *Watcher) watch() {
 				return
 			}
 
-			// Check if the event is for our configuration file
-			if filepath.Base(event.Name) != configFileName {
+			// Check if the event is for one of our configuration files
+			if !watched[filepath.Clean(event.Name)] {
 				continue
 			}
 
@@ -115,25 +124,25 @@ func (w *Watcher) watch() {
 			// Handle different event types
 			switch {
 			case event.Op&fsnotify.Write == fsnotify.Write:
-				log.Printf("Configuration file modified: %s", event.Name)
+				logging.Debugf("Configuration file modified: %s", event.Name)
 				w.reloadConfig()
 				lastReload = time.Now()
 			case event.Op&fsnotify.Create == fsnotify.Create:
-				log.Printf("Configuration file created: %s", event.Name)
+				logging.Debugf("Configuration file created: %s", event.Name)
 				w.reloadConfig()
 				lastReload = time.Now()
 			case event.Op&fsnotify.Remove == fsnotify.Remove:
-				log.Printf("Configuration file removed: %s", event.Name)
+				logging.Debugf("Configuration file removed: %s", event.Name)
 				// Could handle this by creating a default config
 			case event.Op&fsnotify.Rename == fsnotify.Rename:
-				log.Printf("Configuration file renamed: %s", event.Name)
+				logging.Debugf("Configuration file renamed: %s", event.Name)
 				// Could handle this by re-adding the watcher
 			}
 		case err, ok := <-w.watcher.Errors:
 			if !ok {
 				return
 			}
-			log.Printf("File watcher error: %v", err)
+			logging.Errorf("File watcher error: %v", err)
 		}
 	}
 }
@@ -143,9 +152,9 @@ func (w *Watcher) reloadConfig() {
 	// Add a small delay to ensure file write is complete
 	time.Sleep(100 * time.Millisecond)
 
-	if err := w.manager.LoadConfig(); err != nil {
-		log.Printf("Failed to reload configuration: %v", err)
+	if err := w.manager.Reload(); err != nil {
+		logging.Errorf("Failed to reload configuration, keeping the previous one: %v", err)
 	} else {
-		log.Println("Configuration reloaded successfully")
+		logging.Infof("Configuration reloaded successfully")
 	}
 }
@@ -0,0 +1,207 @@
+// Package fuzz breaks valid JSON responses in reproducible ways, to harden
+// the parsers of clients: the same seed and case number always give the
+// same payload.
+package fuzz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Mutations applied to a response
+const (
+	WrongType    = "wrong_type"    // A field holds a value of another JSON type
+	MissingField = "missing_field" // A field is left out
+	NullField    = "null_field"    // A field is null
+	HugeString   = "huge_string"   // A field holds a very long string
+	HugeNumber   = "huge_number"   // A field holds a number no 64-bit type can represent
+	InvalidUTF8  = "invalid_utf8"  // A field holds a string that is not valid UTF-8
+	Truncated    = "truncated"     // The body ends in the middle of the JSON
+	DeepNesting  = "deep_nesting"  // A field holds arrays nested beyond common parser limits
+	EmptyBody    = "empty_body"    // The body is empty
+)
+
+// Mutations lists every mutation, the default set of fuzz endpoints
+var Mutations = []string{WrongType, MissingField, NullField, HugeString, HugeNumber, InvalidUTF8, Truncated, DeepNesting, EmptyBody}
+
+// DefaultHugeStringBytes is the length of huge strings when none is configured
+const DefaultHugeStringBytes = 1 << 20
+
+// nestingDepth exceeds the depth limits of common JSON parsers, e.g. 10000
+// for Go's encoding/json
+const nestingDepth = 100000
+
+// rawMarker stands in for bytes json.Marshal cannot produce, spliced into
+// the encoded response afterwards
+const rawMarker = "__webserver_fuzz_raw__"
+
+// DefaultTemplate is broken when an endpoint has no response of its own
+var DefaultTemplate = map[string]interface{}{
+	"id":     1,
+	"name":   "fuzz",
+	"active": true,
+	"tags":   []interface{}{"a", "b"},
+	"meta":   map[string]interface{}{"count": 2, "next": nil},
+}
+
+// Payload is a broken response
+type Payload struct {
+	Body     []byte
+	Mutation string
+	Field    string // Dotted path of the mutated field; empty for the whole body
+}
+
+// IsMutation reports whether a name is a known mutation
+func IsMutation(name string) bool {
+	for _, mutation := range Mutations {
+		if mutation == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Generate breaks a response with one of the mutations, chosen along with
+// the field from the seed and the case number. An empty mutation list uses
+// them all, and a hugeStringBytes of 0 the default length.
+func Generate(template map[string]interface{}, seed, caseNumber int64, mutations []string, hugeStringBytes int) Payload {
+	if template == nil {
+		template = DefaultTemplate
+	}
+	if len(mutations) == 0 {
+		mutations = Mutations
+	}
+	if hugeStringBytes <= 0 {
+		hugeStringBytes = DefaultHugeStringBytes
+	}
+	random := rand.New(rand.NewSource(seed*1000003 + caseNumber))
+
+	// Work on a copy in its JSON form, so the template stays untouched
+	var response interface{}
+	data, _ := json.Marshal(template)
+	json.Unmarshal(data, &response)
+
+	payload := Payload{Mutation: mutations[random.Intn(len(mutations))]}
+	switch payload.Mutation {
+	case Truncated:
+		data, _ := json.Marshal(response)
+		payload.Body = data[:random.Intn(len(data)-1)+1]
+		return payload
+	case EmptyBody:
+		payload.Body = []byte{}
+		return payload
+	}
+
+	// The remaining mutations change one field, or the whole body of a
+	// response without fields
+	target := field{
+		get:    func() interface{} { return response },
+		set:    func(value interface{}) { response = value },
+		remove: func() { response = map[string]interface{}{} },
+	}
+	if fields := collectFields(response, "", nil); len(fields) > 0 {
+		target = fields[random.Intn(len(fields))]
+	}
+	payload.Field = target.path
+
+	var raw []byte
+	switch payload.Mutation {
+	case WrongType:
+		target.set(otherType(target.get()))
+	case MissingField:
+		target.remove()
+	case NullField:
+		target.set(nil)
+	case HugeString:
+		target.set(strings.Repeat("x", hugeStringBytes))
+	case HugeNumber:
+		numbers := []string{"1e999", "-1e999", "18446744073709551616", "123456789012345678901234567890.5"}
+		raw = []byte(numbers[random.Intn(len(numbers))])
+		target.set(rawMarker)
+	case InvalidUTF8:
+		raw = []byte("\"caf\xc3\x28 \xff\xfe \xed\xa0\x80\"")
+		target.set(rawMarker)
+	case DeepNesting:
+		raw = append(bytes.Repeat([]byte("["), nestingDepth), bytes.Repeat([]byte("]"), nestingDepth)...)
+		target.set(rawMarker)
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		body = []byte(fmt.Sprintf("%q", err.Error()))
+	}
+	if raw != nil {
+		marker, _ := json.Marshal(rawMarker)
+		body = bytes.Replace(body, marker, raw, 1)
+	}
+	payload.Body = body
+	return payload
+}
+
+// field is a value inside a response that a mutation can change
+type field struct {
+	path   string
+	get    func() interface{}
+	set    func(value interface{})
+	remove func()
+}
+
+// collectFields lists the fields of a JSON value depth first, object keys
+// in sorted order so the choice of field is reproducible
+func collectFields(value interface{}, path string, fields []field) []field {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			key, childPath := key, key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			fields = append(fields, field{
+				path:   childPath,
+				get:    func() interface{} { return v[key] },
+				set:    func(value interface{}) { v[key] = value },
+				remove: func() { delete(v, key) },
+			})
+			fields = collectFields(v[key], childPath, fields)
+		}
+	case []interface{}:
+		for i := range v {
+			i, childPath := i, fmt.Sprintf("%s[%d]", path, i)
+			fields = append(fields, field{
+				path: childPath,
+				get:  func() interface{} { return v[i] },
+				set:  func(value interface{}) { v[i] = value },
+				// Array elements cannot be left out in place; null them instead
+				remove: func() { v[i] = nil },
+			})
+			fields = collectFields(v[i], childPath, fields)
+		}
+	}
+	return fields
+}
+
+// otherType returns a value of a different JSON type than a value
+func otherType(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return 42
+	case float64:
+		return fmt.Sprint(v)
+	case bool:
+		return fmt.Sprint(v)
+	case map[string]interface{}:
+		return []interface{}{}
+	case []interface{}:
+		return map[string]interface{}{}
+	}
+	return 0
+}
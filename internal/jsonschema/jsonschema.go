@@ -0,0 +1,477 @@
+// Package jsonschema validates JSON values against a JSON Schema, so mock
+// endpoints can reject request bodies clients serialized wrongly. It covers
+// the validation keywords of draft 2020-12 that describe a value's structure;
+// annotations such as format and title are accepted and ignored.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema
+type Schema struct {
+	root     interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// Compile checks a schema and prepares it for validation
+func Compile(schema map[string]interface{}) (*Schema, error) {
+	// Work on the schema in its JSON form, with JSON numbers
+	var root interface{}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal(data, &root)
+
+	s := &Schema{root: root, patterns: make(map[string]*regexp.Regexp)}
+	if err := s.check(root, "#"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// check verifies the keywords of a schema and its subschemas
+func (s *Schema) check(node interface{}, at string) error {
+	if _, ok := node.(bool); ok {
+		return nil
+	}
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: a schema must be an object or a boolean", at)
+	}
+
+	if value, exists := schema["type"]; exists {
+		var names []interface{}
+		switch v := value.(type) {
+		case string:
+			names = []interface{}{v}
+		case []interface{}:
+			names = v
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("%s/type: must be a type name or a list of them", at)
+		}
+		for _, name := range names {
+			if !knownType(name) {
+				return fmt.Errorf("%s/type: unknown type %v", at, name)
+			}
+		}
+	}
+
+	for _, keyword := range []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf",
+		"minLength", "maxLength", "minItems", "maxItems", "minProperties", "maxProperties"} {
+		if value, exists := schema[keyword]; exists {
+			if _, ok := value.(float64); !ok {
+				return fmt.Errorf("%s/%s: must be a number", at, keyword)
+			}
+		}
+	}
+	if multipleOf, ok := schema["multipleOf"].(float64); ok && multipleOf <= 0 {
+		return fmt.Errorf("%s/multipleOf: must be greater than 0", at)
+	}
+
+	if value, exists := schema["pattern"]; exists {
+		pattern, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s/pattern: must be a string", at)
+		}
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("%s/pattern: %v", at, err)
+		}
+		s.patterns[pattern] = compiled
+	}
+
+	if value, exists := schema["required"]; exists {
+		names, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s/required: must be a list of property names", at)
+		}
+		for _, name := range names {
+			if _, ok := name.(string); !ok {
+				return fmt.Errorf("%s/required: must be a list of property names", at)
+			}
+		}
+	}
+	if value, exists := schema["enum"]; exists {
+		if _, ok := value.([]interface{}); !ok {
+			return fmt.Errorf("%s/enum: must be a list", at)
+		}
+	}
+
+	if value, exists := schema["properties"]; exists {
+		properties, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s/properties: must be an object", at)
+		}
+		for name, property := range properties {
+			if err := s.check(property, at+"/properties/"+name); err != nil {
+				return err
+			}
+		}
+	}
+	for _, keyword := range []string{"additionalProperties", "items", "not"} {
+		if value, exists := schema[keyword]; exists {
+			if err := s.check(value, at+"/"+keyword); err != nil {
+				return err
+			}
+		}
+	}
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		if value, exists := schema[keyword]; exists {
+			subschemas, ok := value.([]interface{})
+			if !ok || len(subschemas) == 0 {
+				return fmt.Errorf("%s/%s: must be a non-empty list of schemas", at, keyword)
+			}
+			for i, subschema := range subschemas {
+				if err := s.check(subschema, fmt.Sprintf("%s/%s/%d", at, keyword, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, keyword := range []string{"$defs", "definitions"} {
+		if value, exists := schema[keyword]; exists {
+			definitions, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s/%s: must be an object", at, keyword)
+			}
+			for name, definition := range definitions {
+				if err := s.check(definition, at+"/"+keyword+"/"+name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if value, exists := schema["$ref"]; exists {
+		ref, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s/$ref: must be a string", at)
+		}
+		if _, err := s.resolve(ref); err != nil {
+			return fmt.Errorf("%s/$ref: %v", at, err)
+		}
+	}
+	return nil
+}
+
+// resolve finds the schema a local reference such as #/$defs/user points to
+func (s *Schema) resolve(ref string) (interface{}, error) {
+	if ref != "#" && !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("only references within the schema are supported: %s", ref)
+	}
+
+	node := s.root
+	for _, token := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolved reference: %s", ref)
+		}
+		if node, ok = object[token]; !ok {
+			return nil, fmt.Errorf("unresolved reference: %s", ref)
+		}
+	}
+	return node, nil
+}
+
+// Validate checks a decoded JSON value against the schema, returning one
+// message per violation, each starting with the path of the offending value
+func (s *Schema) Validate(value interface{}) []string {
+	// Validate the value in its JSON form, with JSON numbers
+	var decoded interface{}
+	if data, err := json.Marshal(value); err == nil {
+		json.Unmarshal(data, &decoded)
+	}
+
+	v := validation{schema: s}
+	v.validate(s.root, decoded, "", 0)
+	return v.violations
+}
+
+// maxRefDepth stops references that refer back to themselves
+const maxRefDepth = 64
+
+// validation collects the violations of one value
+type validation struct {
+	schema     *Schema
+	violations []string
+}
+
+// failf records a violation at a path
+func (v *validation) failf(path, format string, args ...interface{}) {
+	if path == "" {
+		path = "body"
+	}
+	v.violations = append(v.violations, path+": "+fmt.Sprintf(format, args...))
+}
+
+// validate checks a value against a schema node
+func (v *validation) validate(node, value interface{}, path string, depth int) {
+	if allowed, ok := node.(bool); ok {
+		if !allowed {
+			v.failf(path, "not allowed")
+		}
+		return
+	}
+	schema := node.(map[string]interface{}) // Checked by Compile
+
+	if ref, ok := schema["$ref"].(string); ok {
+		if depth >= maxRefDepth {
+			v.failf(path, "schema references nest too deeply")
+			return
+		}
+		target, _ := v.schema.resolve(ref) // Checked by Compile
+		v.validate(target, value, path, depth+1)
+	}
+
+	if types, exists := schema["type"]; exists && !matchesType(types, value) {
+		v.failf(path, "got %s, want %s", kind(value), describeTypes(types))
+		return
+	}
+	if allowed, ok := schema["enum"].([]interface{}); ok && !contains(allowed, value) {
+		v.failf(path, "%s is not one of %s", encode(value), encode(allowed))
+	}
+	if constant, exists := schema["const"]; exists && !reflect.DeepEqual(constant, value) {
+		v.failf(path, "got %s, want %s", encode(value), encode(constant))
+	}
+
+	switch value := value.(type) {
+	case float64:
+		v.validateNumber(schema, value, path)
+	case string:
+		v.validateString(schema, value, path)
+	case []interface{}:
+		v.validateArray(schema, value, path, depth)
+	case map[string]interface{}:
+		v.validateObject(schema, value, path, depth)
+	}
+
+	if subschemas, ok := schema["allOf"].([]interface{}); ok {
+		for _, subschema := range subschemas {
+			v.validate(subschema, value, path, depth)
+		}
+	}
+	if subschemas, ok := schema["anyOf"].([]interface{}); ok {
+		if v.countMatches(subschemas, value, path, depth) == 0 {
+			v.failf(path, "matches none of the anyOf schemas")
+		}
+	}
+	if subschemas, ok := schema["oneOf"].([]interface{}); ok {
+		if matches := v.countMatches(subschemas, value, path, depth); matches != 1 {
+			v.failf(path, "matches %d of the oneOf schemas, want exactly 1", matches)
+		}
+	}
+	if subschema, exists := schema["not"]; exists && v.matches(subschema, value, path, depth) {
+		v.failf(path, "matches the schema it must not")
+	}
+}
+
+func (v *validation) validateNumber(schema map[string]interface{}, value float64, path string) {
+	if minimum, ok := schema["minimum"].(float64); ok && value < minimum {
+		v.failf(path, "%v is less than the minimum %v", value, minimum)
+	}
+	if maximum, ok := schema["maximum"].(float64); ok && value > maximum {
+		v.failf(path, "%v is greater than the maximum %v", value, maximum)
+	}
+	if minimum, ok := schema["exclusiveMinimum"].(float64); ok && value <= minimum {
+		v.failf(path, "%v must be greater than %v", value, minimum)
+	}
+	if maximum, ok := schema["exclusiveMaximum"].(float64); ok && value >= maximum {
+		v.failf(path, "%v must be less than %v", value, maximum)
+	}
+	if multipleOf, ok := schema["multipleOf"].(float64); ok {
+		if quotient := value / multipleOf; math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			v.failf(path, "%v is not a multiple of %v", value, multipleOf)
+		}
+	}
+}
+
+func (v *validation) validateString(schema map[string]interface{}, value, path string) {
+	length := float64(utf8.RuneCountInString(value))
+	if minLength, ok := schema["minLength"].(float64); ok && length < minLength {
+		v.failf(path, "%d characters, want at least %v", int(length), minLength)
+	}
+	if maxLength, ok := schema["maxLength"].(float64); ok && length > maxLength {
+		v.failf(path, "%d characters, want at most %v", int(length), maxLength)
+	}
+	if pattern, ok := schema["pattern"].(string); ok && !v.schema.patterns[pattern].MatchString(value) {
+		v.failf(path, "%q does not match %s", value, pattern)
+	}
+}
+
+func (v *validation) validateArray(schema map[string]interface{}, value []interface{}, path string, depth int) {
+	count := float64(len(value))
+	if minItems, ok := schema["minItems"].(float64); ok && count < minItems {
+		v.failf(path, "%d items, want at least %v", len(value), minItems)
+	}
+	if maxItems, ok := schema["maxItems"].(float64); ok && count > maxItems {
+		v.failf(path, "%d items, want at most %v", len(value), maxItems)
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := range value {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(value[i], value[j]) {
+					v.failf(path, "items %d and %d are equal", j, i)
+				}
+			}
+		}
+	}
+	if items, exists := schema["items"]; exists {
+		for i, item := range value {
+			v.validate(items, item, fmt.Sprintf("%s[%d]", path, i), depth)
+		}
+	}
+}
+
+func (v *validation) validateObject(schema map[string]interface{}, value map[string]interface{}, path string, depth int) {
+	count := float64(len(value))
+	if minProperties, ok := schema["minProperties"].(float64); ok && count < minProperties {
+		v.failf(path, "%d properties, want at least %v", len(value), minProperties)
+	}
+	if maxProperties, ok := schema["maxProperties"].(float64); ok && count > maxProperties {
+		v.failf(path, "%d properties, want at most %v", len(value), maxProperties)
+	}
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, exists := value[name.(string)]; !exists {
+				v.failf(joinPath(path, name.(string)), "required property is missing")
+			}
+		}
+	}
+
+	// Report properties in a stable order
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	additional, restricted := schema["additionalProperties"]
+	for _, name := range names {
+		if property, exists := properties[name]; exists {
+			v.validate(property, value[name], joinPath(path, name), depth)
+		} else if restricted {
+			if allowed, ok := additional.(bool); ok && !allowed {
+				v.failf(joinPath(path, name), "unexpected property")
+				continue
+			}
+			v.validate(additional, value[name], joinPath(path, name), depth)
+		}
+	}
+}
+
+// matches reports whether a value satisfies a schema, without recording
+// its violations
+func (v *validation) matches(node, value interface{}, path string, depth int) bool {
+	trial := validation{schema: v.schema}
+	trial.validate(node, value, path, depth)
+	return len(trial.violations) == 0
+}
+
+// countMatches counts the schemas a value satisfies
+func (v *validation) countMatches(subschemas []interface{}, value interface{}, path string, depth int) int {
+	matches := 0
+	for _, subschema := range subschemas {
+		if v.matches(subschema, value, path, depth) {
+			matches++
+		}
+	}
+	return matches
+}
+
+// joinPath appends a property name to a dotted path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// knownType reports whether a name is a JSON Schema type
+func knownType(name interface{}) bool {
+	switch name {
+	case "null", "boolean", "object", "array", "number", "integer", "string":
+		return true
+	}
+	return false
+}
+
+// matchesType reports whether a value is of one of a schema's types
+func matchesType(types, value interface{}) bool {
+	names, ok := types.([]interface{})
+	if !ok {
+		names = []interface{}{types}
+	}
+	for _, name := range names {
+		if name == kind(value) || (name == "number" && kind(value) == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// describeTypes names a schema's types for violation messages
+func describeTypes(types interface{}) string {
+	names, ok := types.([]interface{})
+	if !ok {
+		return fmt.Sprint(types)
+	}
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprint(name)
+	}
+	return strings.Join(parts, " or ")
+}
+
+// kind names the JSON Schema type of a decoded value
+func kind(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// contains reports whether a list holds a value
+func contains(list []interface{}, value interface{}) bool {
+	for _, element := range list {
+		if reflect.DeepEqual(element, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// encode renders a value as JSON in violation messages
+func encode(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level is the verbosity of a log line; lines below the configured level are
+// dropped
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo        // The default: lifecycle events and one line per request
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("Level(%d)", int32(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses a level name: debug, info, warn or error
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warning" {
+		return LevelWarn, nil
+	}
+	for i, levelName := range levelNames {
+		if name == levelName {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q (want debug, info, warn or error)", name)
+}
+
+var current atomic.Int32
+
+func init() {
+	current.Store(int32(LevelInfo))
+}
+
+// SetLevel sets the lowest level that is logged
+func SetLevel(l Level) {
+	current.Store(int32(l))
+}
+
+// GetLevel returns the lowest level that is logged
+func GetLevel() Level {
+	return Level(current.Load())
+}
+
+// Enabled reports whether lines of a level are logged, for callers that
+// would otherwise do work to build the line
+func Enabled(l Level) bool {
+	return l >= GetLevel()
+}
+
+// jsonOutput is where lines go as JSON objects; nil logs plain text with
+// the standard logger
+var (
+	jsonMu     sync.Mutex
+	jsonOutput io.Writer
+)
+
+// SetJSON logs one JSON object per line to w, with the time, level and
+// message, e.g. for log collectors reading a container's stdout; nil goes
+// back to plain text
+func SetJSON(w io.Writer) {
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+	jsonOutput = w
+}
+
+// jsonLine is a line logged as JSON
+type jsonLine struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"msg"`
+}
+
+func logf(l Level, format string, args ...interface{}) {
+	if !Enabled(l) {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+	if jsonOutput == nil {
+		log.Output(3, message)
+		return
+	}
+	line, err := json.Marshal(jsonLine{Time: time.Now().UTC(), Level: l.String(), Message: message})
+	if err != nil {
+		return
+	}
+	jsonOutput.Write(append(line, '\n'))
+}
+
+// Debugf logs details only useful when investigating a problem
+func Debugf(format string, args ...interface{}) { logf(LevelDebug, format, args...) }
+
+// Infof logs normal operation
+func Infof(format string, args ...interface{}) { logf(LevelInfo, format, args...) }
+
+// Warnf logs problems the server recovers from
+func Warnf(format string, args ...interface{}) { logf(LevelWarn, format, args...) }
+
+// Errorf logs failures
+func Errorf(format string, args ...interface{}) { logf(LevelError, format, args...) }
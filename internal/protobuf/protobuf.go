@@ -0,0 +1,776 @@
+// Package protobuf encodes JSON values as protobuf messages described by a
+// descriptor set, as written by protoc --descriptor_set_out --include_imports,
+// so mock endpoints can answer clients of binary APIs without generated code.
+package protobuf
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the content type of protobuf-encoded bodies
+const ContentType = "application/x-protobuf"
+
+// Field types of FieldDescriptorProto.Type
+const (
+	typeDouble   = 1
+	typeFloat    = 2
+	typeInt64    = 3
+	typeUint64   = 4
+	typeInt32    = 5
+	typeFixed64  = 6
+	typeFixed32  = 7
+	typeBool     = 8
+	typeString   = 9
+	typeGroup    = 10
+	typeMessage  = 11
+	typeBytes    = 12
+	typeUint32   = 13
+	typeEnum     = 14
+	typeSfixed32 = 15
+	typeSfixed64 = 16
+	typeSint32   = 17
+	typeSint64   = 18
+)
+
+// Wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// Set holds the messages and enums of a descriptor set by fully qualified
+// name, e.g. "shop.v1.Order"
+type Set struct {
+	messages map[string]*message
+	enums    map[string]map[string]int32 // Value numbers by name
+}
+
+// message describes a message type
+type message struct {
+	name     string
+	fields   []*field // In field number order, which is the order they are encoded in
+	mapEntry bool     // Synthesized entry of a map field: key = 1, value = 2
+}
+
+// field describes a field of a message type
+type field struct {
+	name     string
+	jsonName string
+	number   int
+	kind     int
+	repeated bool
+	packed   bool
+	typeName string // Message or enum type, fully qualified without the leading dot
+}
+
+// Load reads a descriptor set file
+func Load(path string) (*Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+	set, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return set, nil
+}
+
+// Parse decodes a serialized FileDescriptorSet
+func Parse(data []byte) (*Set, error) {
+	set := &Set{messages: make(map[string]*message), enums: make(map[string]map[string]int32)}
+	err := readFields(data, func(number, wireType int, value uint64, bytes []byte) error {
+		if number == 1 && wireType == wireBytes {
+			return set.addFile(bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	if len(set.messages) == 0 {
+		return nil, fmt.Errorf("descriptor set has no messages")
+	}
+	return set, nil
+}
+
+// HasMessage reports whether the set describes a message type
+func (s *Set) HasMessage(name string) bool {
+	_, exists := s.messages[strings.TrimPrefix(name, ".")]
+	return exists
+}
+
+// Messages returns the names of the message types in the set, sorted
+func (s *Set) Messages() []string {
+	names := make([]string, 0, len(s.messages))
+	for name, msg := range s.messages {
+		if !msg.mapEntry {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Marshal encodes values as a message. Keys are field names or their JSON
+// names, and values follow the protobuf JSON mapping: numbers or numeric
+// strings for integers, enum value names or numbers, base64 strings for
+// bytes, objects for messages and maps, and arrays for repeated fields.
+// Unknown fields are an error.
+func (s *Set) Marshal(messageName string, values map[string]interface{}) ([]byte, error) {
+	msg, exists := s.messages[strings.TrimPrefix(messageName, ".")]
+	if !exists {
+		return nil, fmt.Errorf("unknown message type %s", messageName)
+	}
+	return s.encodeMessage(nil, msg, values, "")
+}
+
+// addFile registers the types of a FileDescriptorProto
+func (s *Set) addFile(data []byte) error {
+	var pkg, syntax string
+	var messages, enums [][]byte
+	err := readFields(data, func(number, wireType int, value uint64, bytes []byte) error {
+		switch {
+		case number == 2 && wireType == wireBytes:
+			pkg = string(bytes)
+		case number == 4 && wireType == wireBytes:
+			messages = append(messages, bytes)
+		case number == 5 && wireType == wireBytes:
+			enums = append(enums, bytes)
+		case number == 12 && wireType == wireBytes:
+			syntax = string(bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// A file without a syntax statement is proto2
+	proto2 := syntax == "" || syntax == "proto2"
+
+	for _, data := range enums {
+		if err := s.addEnum(pkg, data); err != nil {
+			return err
+		}
+	}
+	for _, data := range messages {
+		if err := s.addMessage(pkg, data, proto2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addMessage registers a DescriptorProto and the types nested in it
+func (s *Set) addMessage(scope string, data []byte, proto2 bool) error {
+	msg := &message{}
+	var fields, nested, enums [][]byte
+	err := readFields(data, func(number, wireType int, value uint64, bytes []byte) error {
+		switch {
+		case number == 1 && wireType == wireBytes:
+			msg.name = qualify(scope, string(bytes))
+		case number == 2 && wireType == wireBytes:
+			fields = append(fields, bytes)
+		case number == 3 && wireType == wireBytes:
+			nested = append(nested, bytes)
+		case number == 4 && wireType == wireBytes:
+			enums = append(enums, bytes)
+		case number == 7 && wireType == wireBytes:
+			return readFields(bytes, func(number, wireType int, value uint64, _ []byte) error {
+				if number == 7 && wireType == wireVarint {
+					msg.mapEntry = value != 0
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, data := range fields {
+		f, err := parseField(data, proto2)
+		if err != nil {
+			return fmt.Errorf("%s: %w", msg.name, err)
+		}
+		msg.fields = append(msg.fields, f)
+	}
+	sort.Slice(msg.fields, func(i, j int) bool { return msg.fields[i].number < msg.fields[j].number })
+	s.messages[msg.name] = msg
+
+	for _, data := range enums {
+		if err := s.addEnum(msg.name, data); err != nil {
+			return err
+		}
+	}
+	for _, data := range nested {
+		if err := s.addMessage(msg.name, data, proto2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseField decodes a FieldDescriptorProto
+func parseField(data []byte, proto2 bool) (*field, error) {
+	f := &field{}
+	packed, packedSet := false, false
+	err := readFields(data, func(number, wireType int, value uint64, bytes []byte) error {
+		switch {
+		case number == 1 && wireType == wireBytes:
+			f.name = string(bytes)
+		case number == 3 && wireType == wireVarint:
+			f.number = int(value)
+		case number == 4 && wireType == wireVarint:
+			f.repeated = value == 3
+		case number == 5 && wireType == wireVarint:
+			f.kind = int(value)
+		case number == 6 && wireType == wireBytes:
+			f.typeName = strings.TrimPrefix(string(bytes), ".")
+		case number == 10 && wireType == wireBytes:
+			f.jsonName = string(bytes)
+		case number == 8 && wireType == wireBytes:
+			return readFields(bytes, func(number, wireType int, value uint64, _ []byte) error {
+				if number == 2 && wireType == wireVarint {
+					packed, packedSet = value != 0, true
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if f.jsonName == "" {
+		f.jsonName = jsonName(f.name)
+	}
+
+	// Repeated scalars are packed by default since proto3
+	if f.repeated && f.kind != typeString && f.kind != typeBytes && f.kind != typeMessage && f.kind != typeGroup {
+		f.packed = !proto2
+		if packedSet {
+			f.packed = packed
+		}
+	}
+	return f, nil
+}
+
+// addEnum registers an EnumDescriptorProto
+func (s *Set) addEnum(scope string, data []byte) error {
+	var name string
+	values := make(map[string]int32)
+	err := readFields(data, func(number, wireType int, value uint64, bytes []byte) error {
+		switch {
+		case number == 1 && wireType == wireBytes:
+			name = qualify(scope, string(bytes))
+		case number == 2 && wireType == wireBytes:
+			var valueName string
+			var valueNumber int32
+			err := readFields(bytes, func(number, wireType int, value uint64, bytes []byte) error {
+				switch {
+				case number == 1 && wireType == wireBytes:
+					valueName = string(bytes)
+				case number == 2 && wireType == wireVarint:
+					valueNumber = int32(value)
+				}
+				return nil
+			})
+			values[valueName] = valueNumber
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.enums[name] = values
+	return nil
+}
+
+// qualify joins a scope and a name into a fully qualified name
+func qualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// jsonName converts a field name to lowerCamelCase as protoc does
+func jsonName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// readFields calls fn for every field of a serialized message; bytes is set
+// for length-delimited fields and value for the others
+func readFields(data []byte, fn func(number, wireType int, value uint64, bytes []byte) error) error {
+	for pos := 0; pos < len(data); {
+		key, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			return fmt.Errorf("truncated field key at byte %d", pos)
+		}
+		pos += n
+		number, wireType := int(key>>3), int(key&7)
+
+		var value uint64
+		var bytes []byte
+		switch wireType {
+		case wireVarint:
+			value, n = binary.Uvarint(data[pos:])
+			if n <= 0 {
+				return fmt.Errorf("truncated varint at byte %d", pos)
+			}
+			pos += n
+		case wireFixed64:
+			if pos+8 > len(data) {
+				return fmt.Errorf("truncated fixed64 at byte %d", pos)
+			}
+			value = binary.LittleEndian.Uint64(data[pos:])
+			pos += 8
+		case wireFixed32:
+			if pos+4 > len(data) {
+				return fmt.Errorf("truncated fixed32 at byte %d", pos)
+			}
+			value = uint64(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+		case wireBytes:
+			length, n := binary.Uvarint(data[pos:])
+			if n <= 0 || length > uint64(len(data)-pos-n) {
+				return fmt.Errorf("truncated length-delimited field at byte %d", pos)
+			}
+			pos += n
+			bytes = data[pos : pos+int(length)]
+			pos += int(length)
+		default:
+			return fmt.Errorf("unsupported wire type %d at byte %d", wireType, pos)
+		}
+
+		if err := fn(number, wireType, value, bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeMessage appends the fields of a message set from values
+func (s *Set) encodeMessage(buf []byte, msg *message, values map[string]interface{}, path string) ([]byte, error) {
+	used := 0
+	for _, f := range msg.fields {
+		value, exists := values[f.name]
+		if !exists {
+			value, exists = values[f.jsonName]
+		}
+		if !exists {
+			continue
+		}
+		used++
+		if value == nil {
+			continue
+		}
+
+		fieldPath := path + f.name
+		var err error
+		switch {
+		case f.repeated && f.kind == typeMessage && s.messages[f.typeName] != nil && s.messages[f.typeName].mapEntry:
+			buf, err = s.encodeMap(buf, f, value, fieldPath)
+		case f.repeated:
+			buf, err = s.encodeRepeated(buf, f, value, fieldPath)
+		default:
+			buf, err = s.encodeField(buf, f, value, fieldPath)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if used < len(values) {
+		for key := range values {
+			if msg.field(key) == nil {
+				return nil, fmt.Errorf("%s: %s has no field %q", strings.TrimSuffix(path, "."), msg.name, key)
+			}
+		}
+	}
+	return buf, nil
+}
+
+// field looks up a field by name or JSON name
+func (m *message) field(key string) *field {
+	for _, f := range m.fields {
+		if f.name == key || f.jsonName == key {
+			return f
+		}
+	}
+	return nil
+}
+
+// encodeRepeated appends the elements of a repeated field, packed into one
+// length-delimited field when the field is packed
+func (s *Set) encodeRepeated(buf []byte, f *field, value interface{}, path string) ([]byte, error) {
+	elements, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: want an array, got %s", path, describe(value))
+	}
+
+	if f.packed {
+		var packed []byte
+		for i, element := range elements {
+			var err error
+			if packed, err = s.encodeScalar(packed, f, element, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return nil, err
+			}
+		}
+		buf = appendKey(buf, f.number, wireBytes)
+		buf = binary.AppendUvarint(buf, uint64(len(packed)))
+		return append(buf, packed...), nil
+	}
+
+	for i, element := range elements {
+		var err error
+		if buf, err = s.encodeField(buf, f, element, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// encodeMap appends the entries of a map field, sorted by key so the same
+// values always encode to the same bytes
+func (s *Set) encodeMap(buf []byte, f *field, value interface{}, path string) ([]byte, error) {
+	entries, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: want an object, got %s", path, describe(value))
+	}
+	entry := s.messages[f.typeName]
+	keyField, valueField := entry.field("key"), entry.field("value")
+	if keyField == nil || valueField == nil {
+		return nil, fmt.Errorf("%s: malformed map entry %s", path, entry.name)
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		entryPath := fmt.Sprintf("%s[%q]", path, key)
+		var keyValue interface{} = key
+		if keyField.kind == typeBool {
+			parsed, err := strconv.ParseBool(key)
+			if err != nil {
+				return nil, fmt.Errorf("%s: want a boolean key", entryPath)
+			}
+			keyValue = parsed
+		}
+
+		encoded, err := s.encodeField(nil, keyField, keyValue, entryPath)
+		if err != nil {
+			return nil, err
+		}
+		if entries[key] != nil {
+			if encoded, err = s.encodeField(encoded, valueField, entries[key], entryPath); err != nil {
+				return nil, err
+			}
+		}
+		buf = appendKey(buf, f.number, wireBytes)
+		buf = binary.AppendUvarint(buf, uint64(len(encoded)))
+		buf = append(buf, encoded...)
+	}
+	return buf, nil
+}
+
+// encodeField appends one value of a field with its key
+func (s *Set) encodeField(buf []byte, f *field, value interface{}, path string) ([]byte, error) {
+	switch f.kind {
+	case typeString:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: want a string, got %s", path, describe(value))
+		}
+		buf = appendKey(buf, f.number, wireBytes)
+		buf = binary.AppendUvarint(buf, uint64(len(text)))
+		return append(buf, text...), nil
+
+	case typeBytes:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: want a base64 string, got %s", path, describe(value))
+		}
+		data, err := decodeBase64(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid base64: %w", path, err)
+		}
+		buf = appendKey(buf, f.number, wireBytes)
+		buf = binary.AppendUvarint(buf, uint64(len(data)))
+		return append(buf, data...), nil
+
+	case typeMessage:
+		nested, exists := s.messages[f.typeName]
+		if !exists {
+			return nil, fmt.Errorf("%s: unknown message type %s (build the descriptor set with --include_imports)", path, f.typeName)
+		}
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: want an object, got %s", path, describe(value))
+		}
+		encoded, err := s.encodeMessage(nil, nested, fields, path+".")
+		if err != nil {
+			return nil, err
+		}
+		buf = appendKey(buf, f.number, wireBytes)
+		buf = binary.AppendUvarint(buf, uint64(len(encoded)))
+		return append(buf, encoded...), nil
+
+	case typeGroup:
+		return nil, fmt.Errorf("%s: groups are not supported", path)
+	}
+
+	buf = appendKey(buf, f.number, wireType(f.kind))
+	return s.encodeScalar(buf, f, value, path)
+}
+
+// encodeScalar appends a numeric, boolean or enum value without a key
+func (s *Set) encodeScalar(buf []byte, f *field, value interface{}, path string) ([]byte, error) {
+	switch f.kind {
+	case typeDouble, typeFloat:
+		number, err := toFloat(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if f.kind == typeFloat {
+			return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(number))), nil
+		}
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(number)), nil
+
+	case typeBool:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s: want a boolean, got %s", path, describe(value))
+		}
+		if b {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+
+	case typeEnum:
+		number, err := s.enumValue(f.typeName, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return binary.AppendUvarint(buf, uint64(int64(number))), nil
+
+	case typeUint32, typeUint64, typeFixed32, typeFixed64:
+		bits := 64
+		if f.kind == typeUint32 || f.kind == typeFixed32 {
+			bits = 32
+		}
+		number, err := toUint(value, bits)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		switch f.kind {
+		case typeFixed32:
+			return binary.LittleEndian.AppendUint32(buf, uint32(number)), nil
+		case typeFixed64:
+			return binary.LittleEndian.AppendUint64(buf, number), nil
+		}
+		return binary.AppendUvarint(buf, number), nil
+
+	case typeInt32, typeInt64, typeSint32, typeSint64, typeSfixed32, typeSfixed64:
+		bits := 64
+		if f.kind == typeInt32 || f.kind == typeSint32 || f.kind == typeSfixed32 {
+			bits = 32
+		}
+		number, err := toInt(value, bits)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		switch f.kind {
+		case typeSint32, typeSint64:
+			return binary.AppendUvarint(buf, uint64(number<<1)^uint64(number>>63)), nil
+		case typeSfixed32:
+			return binary.LittleEndian.AppendUint32(buf, uint32(number)), nil
+		case typeSfixed64:
+			return binary.LittleEndian.AppendUint64(buf, uint64(number)), nil
+		}
+		return binary.AppendUvarint(buf, uint64(number)), nil
+	}
+
+	return nil, fmt.Errorf("%s: unsupported field type %d", path, f.kind)
+}
+
+// enumValue resolves an enum value name or number
+func (s *Set) enumValue(enumName string, value interface{}) (int32, error) {
+	values, exists := s.enums[enumName]
+	if !exists {
+		return 0, fmt.Errorf("unknown enum type %s", enumName)
+	}
+	if name, ok := value.(string); ok {
+		if number, exists := values[name]; exists {
+			return number, nil
+		}
+		return 0, fmt.Errorf("%s has no value %q", enumName, name)
+	}
+	number, err := toInt(value, 32)
+	if err != nil {
+		return 0, fmt.Errorf("want an enum value name or number: %w", err)
+	}
+	return int32(number), nil
+}
+
+// wireType returns the wire type of a scalar field type
+func wireType(kind int) int {
+	switch kind {
+	case typeDouble, typeFixed64, typeSfixed64:
+		return wireFixed64
+	case typeFloat, typeFixed32, typeSfixed32:
+		return wireFixed32
+	}
+	return wireVarint
+}
+
+// appendKey appends a field key
+func appendKey(buf []byte, number, wireType int) []byte {
+	return binary.AppendUvarint(buf, uint64(number)<<3|uint64(wireType))
+}
+
+// toInt converts a JSON number or numeric string to an integer that fits in
+// the given number of bits
+func toInt(value interface{}, bits int) (int64, error) {
+	var number int64
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, fmt.Errorf("want an integer, got %v", v)
+		}
+		number = int64(v)
+	case json.Number:
+		return toInt(string(v), bits)
+	case string:
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("want an integer, got %q", v)
+		}
+		number = parsed
+	case int:
+		number = int64(v)
+	case int64:
+		number = v
+	default:
+		return 0, fmt.Errorf("want an integer, got %s", describe(value))
+	}
+	if bits == 32 && (number < math.MinInt32 || number > math.MaxInt32) {
+		return 0, fmt.Errorf("%d does not fit in 32 bits", number)
+	}
+	return number, nil
+}
+
+// toUint converts a JSON number or numeric string to a non-negative integer
+// that fits in the given number of bits
+func toUint(value interface{}, bits int) (uint64, error) {
+	var number uint64
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) || v < 0 || v >= math.MaxUint64 {
+			return 0, fmt.Errorf("want a non-negative integer, got %v", v)
+		}
+		number = uint64(v)
+	case json.Number:
+		return toUint(string(v), bits)
+	case string:
+		parsed, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("want a non-negative integer, got %q", v)
+		}
+		number = parsed
+	case int:
+		if v < 0 {
+			return 0, fmt.Errorf("want a non-negative integer, got %d", v)
+		}
+		number = uint64(v)
+	default:
+		return 0, fmt.Errorf("want a non-negative integer, got %s", describe(value))
+	}
+	if bits == 32 && number > math.MaxUint32 {
+		return 0, fmt.Errorf("%d does not fit in 32 bits", number)
+	}
+	return number, nil
+}
+
+// toFloat converts a JSON number, numeric string or "NaN", "Infinity" or
+// "-Infinity" to a float
+func toFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case json.Number:
+		return toFloat(string(v))
+	case int:
+		return float64(v), nil
+	case string:
+		switch v {
+		case "NaN":
+			return math.NaN(), nil
+		case "Infinity":
+			return math.Inf(1), nil
+		case "-Infinity":
+			return math.Inf(-1), nil
+		}
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("want a number, got %q", v)
+		}
+		return parsed, nil
+	}
+	return 0, fmt.Errorf("want a number, got %s", describe(value))
+}
+
+// decodeBase64 accepts standard and URL-safe base64, padded or not
+func decodeBase64(text string) ([]byte, error) {
+	text = strings.TrimRight(text, "=")
+	if strings.ContainsAny(text, "-_") {
+		return base64.RawURLEncoding.DecodeString(text)
+	}
+	return base64.RawStdEncoding.DecodeString(text)
+}
+
+// describe names the JSON type of a value for error messages
+func describe(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case float64, json.Number, int, int64:
+		return "a number"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,220 @@
+package record
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"webserver/internal/logging"
+	"webserver/pkg/types"
+)
+
+// maxMessageLength limits error messages taken from plain text bodies
+const maxMessageLength = 200
+
+// Recorder is a reverse proxy that remembers the responses of every route
+// it forwards, to turn them into endpoint configurations
+type Recorder struct {
+	proxy *httputil.ReverseProxy
+
+	mu     sync.Mutex
+	routes map[string]*route
+}
+
+// route is what was observed for one path
+type route struct {
+	requests     int
+	totalTimeMs  int64
+	errorCodes   map[int]int // Status code -> count, for responses of 400 and above
+	errorMessage string      // From the latest error response
+	body         interface{} // Decoded JSON of the latest successful response
+	hasBody      bool
+	nonJSON      bool // The latest successful response was not JSON
+	redirects    int  // Successful responses that were not 2xx
+}
+
+// startKey carries the time a request reached the recorder
+type startKey struct{}
+
+// New creates a recorder forwarding to the upstream URL
+func New(upstream *url.URL) *Recorder {
+	rec := &Recorder{routes: make(map[string]*route)}
+	rec.proxy = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(upstream)
+			r.SetXForwarded()
+		},
+		ModifyResponse: rec.capture,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logging.Warnf("%s %s: upstream error: %v", r.Method, r.URL.Path, err)
+			http.Error(w, "upstream error: "+err.Error(), http.StatusBadGateway)
+		},
+	}
+	return rec
+}
+
+// ServeHTTP forwards a request upstream and records its response
+func (rec *Recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithValue(r.Context(), startKey{}, time.Now())
+	rec.proxy.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// capture reads the upstream response so it can be recorded, leaving it
+// intact for the client
+func (rec *Recorder) capture(resp *http.Response) error {
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	var elapsedMs int64
+	if start, ok := resp.Request.Context().Value(startKey{}).(time.Time); ok {
+		elapsedMs = time.Since(start).Milliseconds()
+	}
+
+	body := data
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		if reader, err := gzip.NewReader(bytes.NewReader(data)); err == nil {
+			if decoded, err := io.ReadAll(reader); err == nil {
+				body = decoded
+			}
+		}
+	}
+
+	path := resp.Request.URL.Path
+	logging.Infof("%s %s -> %d (%dms)", resp.Request.Method, path, resp.StatusCode, elapsedMs)
+	rec.observe(path, resp.StatusCode, elapsedMs, body)
+	return nil
+}
+
+// observe records one response for a path
+func (rec *Recorder) observe(path string, statusCode int, elapsedMs int64, body []byte) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rt, exists := rec.routes[path]
+	if !exists {
+		rt = &route{errorCodes: make(map[int]int)}
+		rec.routes[path] = rt
+	}
+	rt.requests++
+	rt.totalTimeMs += elapsedMs
+
+	var decoded interface{}
+	isJSON := len(bytes.TrimSpace(body)) > 0 && json.Unmarshal(body, &decoded) == nil
+
+	if statusCode >= 400 {
+		rt.errorCodes[statusCode]++
+		rt.errorMessage = errorMessage(statusCode, body, decoded, isJSON)
+		return
+	}
+
+	if statusCode >= 300 {
+		rt.redirects++
+	}
+	rt.body, rt.hasBody, rt.nonJSON = decoded, isJSON, !isJSON && len(bytes.TrimSpace(body)) > 0
+}
+
+// errorMessage picks the message of an error response: an "error" or
+// "message" field of a JSON body, the text of a short plain body, or the
+// status text
+func errorMessage(statusCode int, body []byte, decoded interface{}, isJSON bool) string {
+	if isJSON {
+		if fields, ok := decoded.(map[string]interface{}); ok {
+			for _, key := range []string{"error", "message"} {
+				if message, ok := fields[key].(string); ok && message != "" {
+					return message
+				}
+			}
+		}
+	} else if text := strings.TrimSpace(string(body)); text != "" && len(text) <= maxMessageLength && !strings.ContainsAny(text, "<\n") {
+		return text
+	}
+	return http.StatusText(statusCode)
+}
+
+// Routes returns the number of paths recorded so far
+func (rec *Recorder) Routes() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return len(rec.routes)
+}
+
+// Config turns the recorded routes into a configuration served with the
+// given settings. Paths that only succeeded become delay endpoints replaying
+// the latest response, paths that only failed become error endpoints, and
+// paths that did both become conditional errors failing as often as
+// upstream did. With withLatency the average upstream latency is replayed
+// as the delay. Notes describe what could not be reproduced exactly.
+func (rec *Recorder) Config(server types.ServerConfig, withLatency bool) (*types.Config, []string) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	cfg := &types.Config{Server: server, Endpoints: make(map[string]types.EndpointConfig)}
+	var notes []string
+
+	paths := make([]string, 0, len(rec.routes))
+	for path := range rec.routes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		rt := rec.routes[path]
+
+		errors, statusCode := 0, 0
+		for code, count := range rt.errorCodes {
+			errors += count
+			if count > rt.errorCodes[statusCode] || (count == rt.errorCodes[statusCode] && code < statusCode) {
+				statusCode = code
+			}
+		}
+		if len(rt.errorCodes) > 1 {
+			notes = append(notes, fmt.Sprintf("%s: failed with several status codes, replayed as %d", path, statusCode))
+		}
+
+		response, ok := rt.body.(map[string]interface{})
+		if rt.hasBody && !ok {
+			notes = append(notes, fmt.Sprintf("%s: response is JSON but not an object, replayed without a body", path))
+		}
+		if rt.nonJSON {
+			notes = append(notes, fmt.Sprintf("%s: response is not JSON, replayed without a body", path))
+		}
+		if rt.redirects > 0 {
+			notes = append(notes, fmt.Sprintf("%s: redirects are replayed as 200", path))
+		}
+
+		switch {
+		case errors == 0:
+			endpoint := types.EndpointConfig{Type: "delay", Response: response}
+			if withLatency {
+				endpoint.DelayMs = int(rt.totalTimeMs / int64(rt.requests))
+			}
+			cfg.Endpoints[path] = endpoint
+		case errors == rt.requests:
+			cfg.Endpoints[path] = types.EndpointConfig{Type: "error", StatusCode: statusCode, Message: rt.errorMessage}
+		default:
+			everyN := (rt.requests + errors/2) / errors
+			cfg.Endpoints[path] = types.EndpointConfig{
+				Type:            "conditional_error",
+				StatusCode:      statusCode,
+				ErrorEveryN:     max(everyN, 1),
+				SuccessResponse: response,
+			}
+		}
+	}
+
+	return cfg, notes
+}
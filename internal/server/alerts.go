@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"webserver/internal/logging"
+	"webserver/pkg/types"
+)
+
+// alerter evaluates alert rules against logged requests and notifies their webhooks
+type alerter struct {
+	mu        sync.Mutex
+	rules     []types.AlertRule
+	lastFired map[string]time.Time // Rule name -> last notification time
+	client    *http.Client
+}
+
+// newAlerter creates an alerter without rules
+func newAlerter() *alerter {
+	return &alerter{
+		lastFired: make(map[string]time.Time),
+		client:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// configure replaces the alert rules, keeping cooldowns of rules that remain
+func (a *alerter) configure(rules []types.AlertRule) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rules = append([]types.AlertRule(nil), rules...)
+
+	lastFired := make(map[string]time.Time)
+	for _, rule := range rules {
+		if fired, exists := a.lastFired[rule.Name]; exists {
+			lastFired[rule.Name] = fired
+		}
+	}
+	a.lastFired = lastFired
+}
+
+// check notifies the webhook of every rule the entry matches, unless the
+// rule is cooling down. Notifications are sent in the background.
+func (a *alerter) check(entry types.RequestLogEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, rule := range a.rules {
+		if !alertRuleMatches(rule, entry) {
+			continue
+		}
+
+		cooldown := time.Duration(rule.CooldownSeconds) * time.Second
+		if fired, exists := a.lastFired[rule.Name]; exists && entry.Timestamp.Sub(fired) < cooldown {
+			continue
+		}
+		a.lastFired[rule.Name] = entry.Timestamp
+
+		go a.notify(rule, entry)
+	}
+}
+
+// alertRuleMatches reports whether an entry satisfies all conditions of a rule
+func alertRuleMatches(rule types.AlertRule, entry types.RequestLogEntry) bool {
+	filter := &types.RequestLogFilter{Path: rule.Path, Method: rule.Method, MinStatus: rule.MinStatus}
+	if !requestLogFilterMatches(filter, entry) {
+		return false
+	}
+	if rule.MaxStatus > 0 && entry.StatusCode > rule.MaxStatus {
+		return false
+	}
+	return entry.Duration >= rule.MinDurationMs
+}
+
+// notify posts an alert notification to the rule's webhook
+func (a *alerter) notify(rule types.AlertRule, entry types.RequestLogEntry) {
+	var payload interface{} = types.AlertNotification{
+		Rule:      rule.Name,
+		Timestamp: time.Now(),
+		Entry:     entry,
+	}
+	if rule.Format == types.AlertFormatSlack {
+		payload = map[string]string{
+			"text": fmt.Sprintf(":rotating_light: Alert '%s': %s %s returned %d in %dms (request #%d)",
+				rule.Name, entry.Method, entry.Path, entry.StatusCode, entry.Duration, entry.ID),
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logging.Errorf("Failed to encode alert '%s': %v", rule.Name, err)
+		return
+	}
+
+	resp, err := a.client.Post(rule.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logging.Warnf("Failed to send alert '%s': %v", rule.Name, err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		logging.Warnf("Alert webhook for '%s' returned status %d", rule.Name, resp.StatusCode)
+	}
+}
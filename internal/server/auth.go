@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"webserver/pkg/types"
+)
+
+// requestToken extracts a management API token from the Authorization header
+// ("Bearer <token>") or, failing that, the token query parameter
+func requestToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return r.URL.Query().Get("token")
+}
+
+// requiredPermission returns the permission a management request needs:
+// read for GET and HEAD, write for everything else
+func requiredPermission(r *http.Request) string {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return types.PermissionRead
+	}
+	return types.PermissionWrite
+}
+
+// authenticate resolves the token presented by a request. ok is false when
+// management auth is enabled and the request has no valid token; the returned
+// token is nil when auth is disabled.
+func (s *Server) authenticate(r *http.Request) (token *types.AuthToken, ok bool) {
+	auth := s.config.GetConfig().Server.ManagementAuth
+	if !auth.Enabled() {
+		return nil, true
+	}
+
+	token = auth.Authenticate(requestToken(r))
+	return token, token != nil
+}
+
+// requireAuth wraps a management API handler so that, when management auth is
+// enabled, it only runs for requests whose token grants the needed permission
+func (s *Server) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := s.authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="webserver"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if token != nil && !token.Allows(requiredPermission(r)) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
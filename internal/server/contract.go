@@ -0,0 +1,327 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"webserver/internal/logging"
+	"webserver/pkg/types"
+)
+
+// defaultContractTimeout limits upstream requests when no timeout_ms is set
+const defaultContractTimeout = 10 * time.Second
+
+// maxContractMismatches limits the differences reported for one response
+const maxContractMismatches = 20
+
+// contractProxy forwards requests to the backend checked in contract mode
+type contractProxy struct {
+	config  types.ContractConfig
+	timeout time.Duration
+	proxy   *httputil.ReverseProxy
+}
+
+// contractResponse is what came back from upstream for one request
+type contractResponse struct {
+	statusCode int
+	body       []byte
+	err        error
+}
+
+// contractResponseKey carries a request's contractResponse to the proxy
+type contractResponseKey struct{}
+
+// newContractProxy creates a proxy to a contract upstream, validated with
+// the configuration
+func newContractProxy(contract types.ContractConfig) *contractProxy {
+	upstream, _ := url.Parse(contract.Upstream)
+	timeout := defaultContractTimeout
+	if contract.TimeoutMs > 0 {
+		timeout = time.Duration(contract.TimeoutMs) * time.Millisecond
+	}
+
+	cp := &contractProxy{config: contract, timeout: timeout}
+	cp.proxy = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(upstream)
+			r.SetXForwarded()
+			forwardRequestTimeout(r.In.Context(), r.In.Header, r.Out.Header)
+		},
+		ModifyResponse: captureContractResponse,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if captured, ok := r.Context().Value(contractResponseKey{}).(*contractResponse); ok {
+				captured.err = err
+			}
+			logging.Warnf("%s %s: upstream error: %v", r.Method, r.URL.Path, err)
+			http.Error(w, "upstream error: "+err.Error(), upstreamErrorStatus(err))
+		},
+	}
+	return cp
+}
+
+// captureContractResponse reads an upstream response so it can be compared,
+// leaving it intact for the client
+func captureContractResponse(resp *http.Response) error {
+	captured, ok := resp.Request.Context().Value(contractResponseKey{}).(*contractResponse)
+	if !ok {
+		return nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	body := data
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		if reader, err := gzip.NewReader(bytes.NewReader(data)); err == nil {
+			if decoded, err := io.ReadAll(reader); err == nil {
+				body = decoded
+			}
+		}
+	}
+	captured.statusCode, captured.body = resp.StatusCode, body
+	return nil
+}
+
+// upstreamErrorStatus is the status answering a failed upstream request:
+// 504 when it ran out of time, 502 otherwise
+func upstreamErrorStatus(err error) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// configureContract switches contract mode on, off or to new settings
+func (s *Server) configureContract(contract *types.ContractConfig) {
+	if contract == nil {
+		if s.contract.Swap(nil) != nil {
+			logging.Infof("Contract mode disabled, serving mocked endpoints")
+		}
+		return
+	}
+	if previous := s.contract.Swap(newContractProxy(*contract)); previous == nil || previous.config.Upstream != contract.Upstream {
+		logging.Infof("Contract mode: proxying to %s and checking responses against the endpoints", contract.Upstream)
+	}
+}
+
+// handleContractRequest proxies a request upstream and, for a configured
+// endpoint, compares the response with the endpoint's
+func (s *Server) handleContractRequest(w http.ResponseWriter, r *http.Request, key string, cp *contractProxy, endpointConfig types.EndpointConfig, configured bool) {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(r.Context(), cp.timeout)
+	defer cancel()
+	captured := &contractResponse{}
+	cp.proxy.ServeHTTP(w, r.WithContext(context.WithValue(ctx, contractResponseKey{}, captured)))
+
+	statusCode := captured.statusCode
+	if captured.err != nil {
+		statusCode = upstreamErrorStatus(captured.err)
+	}
+
+	if configured {
+		if mismatches, checked := contractMismatches(endpointConfig, cp.config, captured); checked {
+			s.stats.GetEndpointStats(key).RecordContractCheck(len(mismatches) == 0)
+			if len(mismatches) > 0 {
+				logging.Warnf("Contract mismatch on %s %s: %s", r.Method, r.URL.Path, strings.Join(mismatches, "; "))
+				if rw, ok := w.(*responseWriter); ok {
+					rw.contractMismatches = mismatches
+				}
+			}
+		}
+	}
+
+	s.stats.RecordRequest(key, time.Since(start), statusCode)
+}
+
+// contractMismatches compares an upstream response with what an endpoint
+// serves: the status code must be one the endpoint answers with, and a
+// successful response must match the endpoint's response. checked is false
+// for endpoints without a response to compare with.
+func contractMismatches(endpointConfig types.EndpointConfig, contract types.ContractConfig, captured *contractResponse) (mismatches []string, checked bool) {
+	var statusCodes []int
+	var golden map[string]interface{}
+	switch endpointConfig.Type {
+	case "error":
+		statusCodes = []int{endpointConfig.StatusCode}
+	case "delay":
+		statusCodes, golden = []int{http.StatusOK}, endpointConfig.Response
+	case "conditional_error":
+		statusCodes, golden = []int{http.StatusOK, endpointConfig.StatusCode}, endpointConfig.SuccessResponse
+	default:
+		return nil, false
+	}
+
+	if captured.err != nil {
+		return []string{fmt.Sprintf("upstream error: %v", captured.err)}, true
+	}
+
+	expected := false
+	for _, code := range statusCodes {
+		expected = expected || captured.statusCode == code
+	}
+	if !expected {
+		want := make([]string, len(statusCodes))
+		for i, code := range statusCodes {
+			want[i] = fmt.Sprint(code)
+		}
+		return []string{fmt.Sprintf("status %d, want %s", captured.statusCode, strings.Join(want, " or "))}, true
+	}
+	if captured.statusCode >= 400 || golden == nil {
+		return nil, true
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(captured.body, &body); err != nil {
+		return []string{"response is not JSON"}, true
+	}
+
+	// Compare the golden response as it is served, with JSON numbers
+	var want interface{}
+	data, _ := json.Marshal(golden)
+	json.Unmarshal(data, &want)
+
+	comparison := contractComparison{
+		exact:   contract.Match == types.ContractMatchExact,
+		ignored: make(map[string]bool),
+	}
+	for _, field := range contract.IgnoreFields {
+		comparison.ignored[field] = true
+	}
+	comparison.compare("", want, body)
+
+	if extra := len(comparison.mismatches) - maxContractMismatches; extra > 0 {
+		comparison.mismatches = append(comparison.mismatches[:maxContractMismatches], fmt.Sprintf("and %d more", extra))
+	}
+	return comparison.mismatches, true
+}
+
+// contractComparison collects the differences between a golden response and
+// an upstream one
+type contractComparison struct {
+	exact      bool
+	ignored    map[string]bool // Field paths without array indexes
+	mismatches []string
+}
+
+// arrayIndex matches the indexes in a field path, e.g. "[3]" in "items[3].id"
+var arrayIndex = regexp.MustCompile(`\[\d+\]`)
+
+// compare checks an upstream value against a golden one. In shape mode
+// golden fields must exist with the same JSON type, extra fields are
+// allowed, every array element must match the first golden element and a
+// golden null accepts anything; in exact mode values must be equal.
+func (c *contractComparison) compare(path string, want, got interface{}) {
+	if path != "" && c.ignored[arrayIndex.ReplaceAllString(path, "")] {
+		return
+	}
+	field := path
+	if field == "" {
+		field = "response"
+	}
+
+	if want == nil && !c.exact {
+		return
+	}
+	if wantKind, gotKind := jsonKind(want), jsonKind(got); wantKind != gotKind {
+		c.mismatches = append(c.mismatches, fmt.Sprintf("%s: got %s, want %s", field, gotKind, wantKind))
+		return
+	}
+
+	switch want := want.(type) {
+	case map[string]interface{}:
+		got := got.(map[string]interface{})
+		keys := make([]string, 0, len(want))
+		for key := range want {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := joinFieldPath(path, key)
+			value, exists := got[key]
+			if !exists {
+				if !c.ignored[arrayIndex.ReplaceAllString(child, "")] {
+					c.mismatches = append(c.mismatches, fmt.Sprintf("%s: missing", child))
+				}
+				continue
+			}
+			c.compare(child, want[key], value)
+		}
+		if c.exact {
+			var extra []string
+			for key := range got {
+				if _, exists := want[key]; !exists && !c.ignored[arrayIndex.ReplaceAllString(joinFieldPath(path, key), "")] {
+					extra = append(extra, joinFieldPath(path, key))
+				}
+			}
+			sort.Strings(extra)
+			for _, child := range extra {
+				c.mismatches = append(c.mismatches, fmt.Sprintf("%s: unexpected field", child))
+			}
+		}
+	case []interface{}:
+		got := got.([]interface{})
+		if c.exact {
+			if len(got) != len(want) {
+				c.mismatches = append(c.mismatches, fmt.Sprintf("%s: %d elements, want %d", field, len(got), len(want)))
+			}
+			for i := 0; i < len(want) && i < len(got); i++ {
+				c.compare(fmt.Sprintf("%s[%d]", path, i), want[i], got[i])
+			}
+		} else if len(want) > 0 {
+			for i, element := range got {
+				c.compare(fmt.Sprintf("%s[%d]", path, i), want[0], element)
+			}
+		}
+	default:
+		if c.exact && !reflect.DeepEqual(want, got) {
+			wantJSON, _ := json.Marshal(want)
+			gotJSON, _ := json.Marshal(got)
+			c.mismatches = append(c.mismatches, fmt.Sprintf("%s: got %s, want %s", field, gotJSON, wantJSON))
+		}
+	}
+}
+
+// joinFieldPath appends a key to a dotted field path
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// jsonKind names the JSON type of a decoded value
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers carrying a client's deadline: X-Request-Timeout takes a duration
+// such as 250ms or a bare number of milliseconds, grpc-timeout the gRPC
+// encoding, e.g. 250m or 2S
+const (
+	requestTimeoutHeader = "X-Request-Timeout"
+	grpcTimeoutHeader    = "Grpc-Timeout"
+)
+
+// grpcTimeoutUnits maps the units of grpc-timeout to durations
+var grpcTimeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
+
+// parseRequestTimeout reads the deadline a client sent, preferring
+// X-Request-Timeout. ok is false when the request has none.
+func parseRequestTimeout(header http.Header) (timeout time.Duration, ok bool, err error) {
+	if value := header.Get(requestTimeoutHeader); value != "" {
+		if ms, err := strconv.ParseInt(value, 10, 64); err == nil && ms >= 0 {
+			return time.Duration(ms) * time.Millisecond, true, nil
+		}
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout < 0 {
+			return 0, false, fmt.Errorf("invalid %s header: %s", requestTimeoutHeader, value)
+		}
+		return timeout, true, nil
+	}
+
+	if value := header.Get(grpcTimeoutHeader); value != "" {
+		// At most 8 digits followed by a unit, as the gRPC spec says
+		unit, known := grpcTimeoutUnits[value[len(value)-1]]
+		amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+		if !known || err != nil || amount < 0 || len(value) > 9 {
+			return 0, false, fmt.Errorf("invalid grpc-timeout header: %s", value)
+		}
+		return time.Duration(amount) * unit, true, nil
+	}
+	return 0, false, nil
+}
+
+// withRequestDeadline gives a request carrying a deadline header a context
+// that expires with it, so endpoints stop waiting once the client gave up.
+// The cancel function must be called once the request is answered.
+func withRequestDeadline(r *http.Request) (*http.Request, context.CancelFunc, error) {
+	timeout, ok, err := parseRequestTimeout(r.Header)
+	if err != nil {
+		return r, func() {}, err
+	}
+	if !ok {
+		return r, func() {}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	return r.WithContext(ctx), cancel, nil
+}
+
+// waitDelay waits for an endpoint's delay, reporting false when the
+// request's deadline passes first. A delay longer than the time left fails
+// at once rather than waiting for the deadline.
+func waitDelay(ctx context.Context, delay time.Duration) bool {
+	if ctx.Err() == context.DeadlineExceeded {
+		return false
+	}
+	if delay <= 0 {
+		return true
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return ctx.Err() != context.DeadlineExceeded
+	}
+}
+
+// forwardRequestTimeout rewrites the deadline header of a proxied request to
+// the time left, so the upstream sees the budget that remains
+func forwardRequestTimeout(ctx context.Context, in, out http.Header) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+	remaining := time.Until(deadline)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	if in.Get(requestTimeoutHeader) != "" {
+		out.Set(requestTimeoutHeader, strconv.FormatInt(remaining.Milliseconds(), 10))
+	} else if in.Get(grpcTimeoutHeader) != "" {
+		out.Set(grpcTimeoutHeader, strconv.FormatInt(remaining.Milliseconds(), 10)+"m")
+	}
+}
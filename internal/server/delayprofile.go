@@ -0,0 +1,31 @@
+package server
+
+import (
+	"time"
+
+	"webserver/pkg/types"
+)
+
+// delayProfileRun is a delay profile being followed by an endpoint
+type delayProfileRun struct {
+	profile types.DelayProfile
+	start   time.Time
+}
+
+// profileDelay returns the delay the profile of the endpoint with the given
+// key prescribes now. The profile starts with the endpoint's first request,
+// and over again whenever the endpoint's profile changes.
+func (s *Server) profileDelay(key string, profile *types.DelayProfile) time.Duration {
+	now := time.Now()
+
+	s.delayProfilesMu.Lock()
+	run, exists := s.delayProfiles[key]
+	if !exists || run.profile != *profile {
+		run = &delayProfileRun{profile: *profile, start: now}
+		s.delayProfiles[key] = run
+	}
+	start := run.start
+	s.delayProfilesMu.Unlock()
+
+	return profile.DelayAt(now.Sub(start))
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"webserver/internal/fuzz"
+	"webserver/pkg/types"
+)
+
+// handleFuzzEndpoint answers with a broken version of an endpoint's response.
+// Each request is the next case of the endpoint's seed; a request with an
+// X-Fuzz-Case header replays that case instead.
+func (s *Server) handleFuzzEndpoint(w http.ResponseWriter, r *http.Request, key string, config types.EndpointConfig, session string) {
+	start := time.Now()
+
+	settings := types.FuzzConfig{}
+	if config.Fuzz != nil {
+		settings = *config.Fuzz
+	}
+
+	caseNumber, err := strconv.ParseInt(r.Header.Get("X-Fuzz-Case"), 10, 64)
+	if err != nil || caseNumber < 1 {
+		caseNumber = s.requestCount(key, config, session)
+	}
+	payload := fuzz.Generate(config.Response, settings.Seed, caseNumber, settings.Mutations, settings.HugeStringBytes)
+
+	statusCode := config.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	// The headers tell how to reproduce a payload that broke a client
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Fuzz-Seed", strconv.FormatInt(settings.Seed, 10))
+	w.Header().Set("X-Fuzz-Case", strconv.FormatInt(caseNumber, 10))
+	w.Header().Set("X-Fuzz-Mutation", payload.Mutation)
+	if payload.Field != "" {
+		w.Header().Set("X-Fuzz-Field", payload.Field)
+	}
+	w.WriteHeader(statusCode)
+	w.Write(payload.Body)
+
+	s.recordEndpointRequest(key, config, time.Since(start), statusCode)
+}
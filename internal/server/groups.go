@@ -0,0 +1,218 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"time"
+
+	"webserver/internal/config"
+	"webserver/internal/logging"
+	"webserver/pkg/types"
+)
+
+var (
+	errGroupNotFound        = errors.New("no such group")
+	errChaosProfileNotFound = errors.New("no such chaos profile")
+)
+
+// Groups returns the endpoint groups of the configuration, by name, with the
+// chaos profile applied to each
+func (s *Server) Groups() []types.EndpointGroup {
+	cfg := s.config.GetConfig()
+	if cfg == nil {
+		return nil
+	}
+
+	byName := make(map[string]*types.EndpointGroup)
+	for path, endpoint := range cfg.Endpoints {
+		if endpoint.Group == "" {
+			continue
+		}
+		group, exists := byName[endpoint.Group]
+		if !exists {
+			group = &types.EndpointGroup{Name: endpoint.Group}
+			byName[endpoint.Group] = group
+		}
+		group.Endpoints = append(group.Endpoints, path)
+		if endpoint.Disabled {
+			group.Disabled++
+		}
+	}
+
+	s.groupChaosMu.Lock()
+	defer s.groupChaosMu.Unlock()
+
+	groups := make([]types.EndpointGroup, 0, len(byName))
+	for name, group := range byName {
+		sort.Strings(group.Endpoints)
+		group.Chaos = s.groupChaos[name]
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// SetGroupDisabled disables or enables every endpoint of a group, returning
+// how many endpoints the group has
+func (s *Server) SetGroupDisabled(group string, disabled bool) (int, error) {
+	count, err := s.config.SetGroupDisabled(group, disabled)
+	if errors.Is(err, config.ErrGroupNotFound) {
+		return 0, fmt.Errorf("%w: %v", errGroupNotFound, err)
+	}
+	return count, err
+}
+
+// ApplyChaos makes the endpoints of a group suffer a chaos profile of the
+// configuration until it is cleared
+func (s *Server) ApplyChaos(group, profile string) error {
+	cfg := s.config.GetConfig()
+	if cfg == nil {
+		return fmt.Errorf("configuration not loaded")
+	}
+	if _, exists := cfg.ChaosProfiles[profile]; !exists {
+		return errChaosProfileNotFound
+	}
+	grouped := false
+	for _, endpoint := range cfg.Endpoints {
+		if endpoint.Group == group {
+			grouped = true
+			break
+		}
+	}
+	if !grouped {
+		return errGroupNotFound
+	}
+
+	s.groupChaosMu.Lock()
+	defer s.groupChaosMu.Unlock()
+	s.groupChaos[group] = profile
+	logging.Infof("Group %s: chaos profile %s applied", group, profile)
+	return nil
+}
+
+// ClearChaos removes the chaos profile of a group, or of every group when
+// group is empty, reporting how many groups were cleared
+func (s *Server) ClearChaos(group string) int {
+	s.groupChaosMu.Lock()
+	defer s.groupChaosMu.Unlock()
+
+	if group == "" {
+		cleared := len(s.groupChaos)
+		s.groupChaos = make(map[string]string)
+		return cleared
+	}
+	if _, exists := s.groupChaos[group]; !exists {
+		return 0
+	}
+	delete(s.groupChaos, group)
+	logging.Infof("Group %s: chaos cleared", group)
+	return 1
+}
+
+// injectChaos delays and fails a request to an endpoint as the chaos profile
+// applied to its group says. It reports whether the request was answered.
+func (s *Server) injectChaos(w http.ResponseWriter, r *http.Request, key string, cfg *types.Config, config types.EndpointConfig) bool {
+	s.groupChaosMu.Lock()
+	name, applied := s.groupChaos[config.Group]
+	s.groupChaosMu.Unlock()
+	if !applied {
+		return false
+	}
+	profile, exists := cfg.ChaosProfiles[name]
+	if !exists {
+		// Removed from the configuration since it was applied
+		return false
+	}
+
+	start := time.Now()
+	var statusCode int
+	var message string
+	switch {
+	case !waitDelay(r.Context(), time.Duration(profile.LatencyMs)*time.Millisecond):
+		statusCode, message = http.StatusGatewayTimeout, "Request deadline exceeded"
+	case profile.ErrorRate > 0 && rand.Float64() < profile.ErrorRate:
+		statusCode, message = profile.StatusCode, profile.Message
+		if statusCode == 0 {
+			statusCode = http.StatusServiceUnavailable
+		}
+		if message == "" {
+			message = fmt.Sprintf("Chaos profile %s injected a failure", name)
+		}
+	default:
+		return false
+	}
+
+	encoding := negotiateEncoding(r.Header.Get("Accept"), responseEncodings)
+	body, _ := s.encodeResponse(encoding, config, map[string]string{"error": message})
+	w.Header().Set("Content-Type", encoding.mediaType)
+	w.Header().Set("Vary", "Accept")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+
+	s.recordEndpointRequest(key, config, time.Since(start), statusCode)
+	return true
+}
+
+// handleGroups lists endpoint groups on GET. POST ?group= with
+// ?action=enable or disable toggles the group's endpoints, and with
+// ?action=chaos&profile= applies a chaos profile to it. DELETE clears the
+// chaos profile of the group in ?group=, or of every group.
+func (s *Server) handleGroups(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	group := query.Get("group")
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.Groups())
+	case http.MethodPost:
+		if group == "" {
+			http.Error(w, "Group name is required", http.StatusBadRequest)
+			return
+		}
+
+		var message string
+		var err error
+		switch action := query.Get("action"); action {
+		case "enable", "disable":
+			var count int
+			if count, err = s.SetGroupDisabled(group, action == "disable"); err == nil {
+				message = fmt.Sprintf("%d endpoints %sd", count, action)
+			}
+		case "chaos":
+			profile := query.Get("profile")
+			if err = s.ApplyChaos(group, profile); err == nil {
+				message = fmt.Sprintf("Chaos profile %s applied", profile)
+			}
+		default:
+			http.Error(w, fmt.Sprintf("Unknown action: %q (use enable, disable or chaos)", action), http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case errors.Is(err, errGroupNotFound):
+			http.Error(w, fmt.Sprintf("Group not found: %s", group), http.StatusNotFound)
+			return
+		case errors.Is(err, errChaosProfileNotFound):
+			http.Error(w, fmt.Sprintf("Chaos profile not found: %s", query.Get("profile")), http.StatusBadRequest)
+			return
+		case errors.Is(err, config.ErrInvalidConfig):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": message})
+	case http.MethodDelete:
+		cleared := s.ClearChaos(group)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "cleared": cleared})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -1,17 +1,22 @@
 package server
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"webserver/internal/buildinfo"
+	"webserver/internal/logging"
 	"webserver/pkg/types"
-
-	"github.com/gorilla/websocket"
 )
 
 // handleConfig handles configuration management endpoints
@@ -44,7 +49,7 @@ func (s *Server) handleGetConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(config)
+	json.NewEncoder(w).Encode(config.WithoutSecrets())
 }
 
 // handleUpdateConfig updates the entire configuration
@@ -55,6 +60,9 @@ func (s *Server) handleUpdateConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Keep auth token secrets that were masked when the configuration was read
+	newConfig.RestoreSecrets(s.config.GetConfig())
+
 	if err := s.config.UpdateConfig(&newConfig); err != nil {
 		http.Error(w, fmt.Sprintf("Failed to update configuration: %v", err), http.StatusBadRequest)
 		return
@@ -64,6 +72,104 @@ func (s *Server) handleUpdateConfig(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": "Configuration updated"})
 }
 
+// handleValidateConfig checks a full configuration, as accepted by PUT /config, without applying it
+func (s *Server) handleValidateConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result := types.ConfigValidation{Valid: true}
+	var newConfig types.Config
+	if err := json.NewDecoder(r.Body).Decode(&newConfig); err != nil {
+		result = types.ConfigValidation{Error: fmt.Sprintf("Invalid JSON: %v", err)}
+	} else {
+		newConfig.RestoreSecrets(s.config.GetConfig())
+		if err := s.config.ValidateConfig(&newConfig); err != nil {
+			result = types.ConfigValidation{Error: err.Error()}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !result.Valid {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleConfigStatus reports where the configuration comes from and whether
+// the latest reload of its files failed
+func (s *Server) handleConfigStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.config.Status())
+}
+
+// handleVersion reports the server's build and API protocol version
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildinfo.Get())
+}
+
+// handleLivez answers liveness probes: the process is up and serving HTTP
+func (s *Server) handleLivez(w http.ResponseWriter, r *http.Request) {
+	s.writeProbe(w, r, "")
+}
+
+// handleReadyz answers readiness probes, failing until the server has
+// started, while the latest configuration reload failed, and while the server
+// drains before shutting down
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	reason := ""
+	switch {
+	case !s.started.Load():
+		reason = "starting"
+	case s.draining.Load():
+		reason = "shutting down"
+	default:
+		if err := s.config.LoadError(); err != nil {
+			reason = fmt.Sprintf("configuration reload failed: %v", err)
+		}
+	}
+	s.writeProbe(w, r, reason)
+}
+
+// handleStartupz answers startup probes, failing until the server has
+// started
+func (s *Server) handleStartupz(w http.ResponseWriter, r *http.Request) {
+	reason := ""
+	if !s.started.Load() {
+		reason = "starting"
+	}
+	s.writeProbe(w, r, reason)
+}
+
+// writeProbe answers a probe with 200 and {"status": "ok"}, or with 503 and
+// the reason it fails
+func (s *Server) writeProbe(w http.ResponseWriter, r *http.Request, reason string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if reason != "" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"status": "unavailable", "reason": reason})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
 // handleAddEndpoint adds or updates a specific endpoint
 func (s *Server) handleAddEndpoint(w http.ResponseWriter, r *http.Request) {
 	var request struct {
@@ -107,97 +213,372 @@ func (s *Server) handleRemoveEndpoint(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": "Endpoint removed"})
 }
 
-// handleStats returns server statistics
+// handleStats returns server statistics, or resets them on DELETE
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete {
+		s.ResetStats()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": "Statistics reset"})
+		return
+	}
+
 	start := time.Now()
+	statusCode := http.StatusOK
 	defer func() {
-		s.stats.RecordRequest("/stats", time.Since(start), http.StatusOK)
+		s.stats.RecordRequest("/stats", time.Since(start), statusCode)
 	}()
 
 	stats := s.stats.GetAllStats()
+
+	// ?changed_since leaves out endpoints with no request since then, for
+	// clients that merge changes into statistics they already have
+	if param := r.URL.Query().Get("changed_since"); param != "" {
+		since, err := time.Parse(time.RFC3339Nano, param)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			http.Error(w, fmt.Sprintf("Invalid changed_since parameter: %s", param), statusCode)
+			return
+		}
+		for path, endpoint := range stats.Endpoints {
+			if endpoint.LastRequest.Before(since) {
+				delete(stats.Endpoints, path)
+			}
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
 
-// handleWebSocket handles WebSocket connections for TUI
-func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	conn, err := s.wsUpgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
+// handleStatsTop returns the top N endpoints ranked by requests, errors or latency
+func (s *Server) handleStatsTop(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	statusCode := http.StatusOK
+	defer func() {
+		s.stats.RecordRequest("/stats/top", time.Since(start), statusCode)
+	}()
+
+	if r.Method != http.MethodGet {
+		statusCode = http.StatusMethodNotAllowed
+		http.Error(w, "Method not allowed", statusCode)
 		return
 	}
-	defer conn.Close()
 
-	// Add connection to active connections
-	s.addWebSocketConnection(conn)
-	defer s.removeWebSocketConnection(conn)
+	by := r.URL.Query().Get("by")
+	if by == "" {
+		by = "requests"
+	}
 
-	log.Printf("New WebSocket connection from %s", r.RemoteAddr)
+	n := 10
+	if nParam := r.URL.Query().Get("n"); nParam != "" {
+		parsed, err := strconv.Atoi(nParam)
+		if err != nil || parsed < 1 {
+			statusCode = http.StatusBadRequest
+			http.Error(w, fmt.Sprintf("Invalid n parameter: %s", nParam), statusCode)
+			return
+		}
+		n = parsed
+	}
 
-	// Send initial data
-	s.sendInitialData(conn)
+	stats := s.stats.GetAllStats()
+	endpoints := make([]*types.EndpointStats, 0, len(stats.Endpoints))
+	for _, endpointStats := range stats.Endpoints {
+		endpoints = append(endpoints, endpointStats)
+	}
 
-	// Handle incoming messages
-	for {
-		var message map[string]interface{}
-		if err := conn.ReadJSON(&message); err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
-			}
-			break
+	var metric func(*types.EndpointStats) float64
+	switch by {
+	case "requests":
+		metric = func(es *types.EndpointStats) float64 { return float64(es.RequestCount) }
+	case "errors":
+		metric = func(es *types.EndpointStats) float64 { return float64(es.ErrorCount) }
+	case "latency":
+		metric = averageTimeMs
+	default:
+		statusCode = http.StatusBadRequest
+		http.Error(w, fmt.Sprintf("Invalid by parameter: %s (use requests, errors or latency)", by), statusCode)
+		return
+	}
+
+	// Rank by the chosen metric, falling back to path for a stable order
+	sort.Slice(endpoints, func(i, j int) bool {
+		mi, mj := metric(endpoints[i]), metric(endpoints[j])
+		if mi != mj {
+			return mi > mj
 		}
+		return endpoints[i].Path < endpoints[j].Path
+	})
 
-		// Handle different message types
-		s.handleWebSocketMessage(conn, message)
+	if len(endpoints) > n {
+		endpoints = endpoints[:n]
 	}
-}
 
-// sendInitialData sends initial configuration and statistics to new WebSocket client
-func (s *Server) sendInitialData(conn *websocket.Conn) {
-	// Send current configuration
-	config := s.config.GetConfig()
-	if config != nil {
-		conn.WriteJSON(types.TUIMessage{
-			Type:      "config",
-			Timestamp: time.Now(),
-			Data:      config,
+	top := make([]types.TopEndpoint, 0, len(endpoints))
+	for i, es := range endpoints {
+		top = append(top, types.TopEndpoint{
+			Rank:         i + 1,
+			Path:         es.Path,
+			RequestCount: es.RequestCount,
+			ErrorCount:   es.ErrorCount,
+			AvgTimeMs:    averageTimeMs(es),
+			MaxTimeMs:    es.MaxTimeMs,
 		})
 	}
 
-	// Send current statistics
-	stats := s.stats.GetAllStats()
-	conn.WriteJSON(types.TUIMessage{
-		Type:      "stats",
-		Timestamp: time.Now(),
-		Data:      stats,
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.TopEndpointsResponse{
+		By:        by,
+		N:         n,
+		Endpoints: top,
 	})
 }
 
-// handleWebSocketMessage handles incoming WebSocket messages
-func (s *Server) handleWebSocketMessage(conn *websocket.Conn, message map[string]interface{}) {
-	msgType, ok := message["type"].(string)
-	if !ok {
+// handleStatsTimeSeries returns per-second request counts, errors and latency
+// for the recent window, optionally for a single endpoint
+func (s *Server) handleStatsTimeSeries(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	statusCode := http.StatusOK
+	defer func() {
+		s.stats.RecordRequest("/stats/timeseries", time.Since(start), statusCode)
+	}()
+
+	if r.Method != http.MethodGet {
+		statusCode = http.StatusMethodNotAllowed
+		http.Error(w, "Method not allowed", statusCode)
 		return
 	}
 
-	switch msgType {
-	case "get_config":
-		config := s.config.GetConfig()
-		conn.WriteJSON(types.TUIMessage{
-			Type:      "config",
-			Timestamp: time.Now(),
-			Data:      config,
+	query := r.URL.Query()
+	window, step := types.DefaultTimeSeriesSeconds, 1
+	for name, value := range map[string]*int{"window": &window, "step": &step} {
+		param := query.Get(name)
+		if param == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(param)
+		if err != nil {
+			statusCode = http.StatusBadRequest
+			http.Error(w, fmt.Sprintf("Invalid %s parameter: %s", name, param), statusCode)
+			return
+		}
+		*value = parsed
+	}
+	if err := types.ValidateTimeSeriesRange(window, step); err != nil {
+		statusCode = http.StatusBadRequest
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.stats.TimeSeries(window, step, query.Get("path")))
+}
+
+// handleStatsExport flattens per-endpoint statistics into CSV or TSV rows
+func (s *Server) handleStatsExport(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	statusCode := http.StatusOK
+	defer func() {
+		s.stats.RecordRequest("/stats/export", time.Since(start), statusCode)
+	}()
+
+	if r.Method != http.MethodGet {
+		statusCode = http.StatusMethodNotAllowed
+		http.Error(w, "Method not allowed", statusCode)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+
+	var contentType string
+	var delimiter rune
+	switch format {
+	case "csv":
+		contentType = "text/csv"
+		delimiter = ','
+	case "tsv":
+		contentType = "text/tab-separated-values"
+		delimiter = '\t'
+	default:
+		statusCode = http.StatusBadRequest
+		http.Error(w, fmt.Sprintf("Invalid format: %s (use csv or tsv)", format), statusCode)
+		return
+	}
+
+	stats := s.stats.GetAllStats()
+
+	// Sort endpoint paths alphabetically for a stable row order
+	paths := make([]string, 0, len(stats.Endpoints))
+	for path := range stats.Endpoints {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"stats.%s\"", format))
+
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	writer.Write([]string{
+		"path", "request_count", "error_count", "success_count", "error_rate_pct",
+		"avg_time_ms", "min_time_ms", "max_time_ms", "status_codes",
+		"first_request", "last_request", "req_per_sec_1m", "err_per_sec_1m",
+	})
+
+	for _, path := range paths {
+		es := stats.Endpoints[path]
+
+		errorRate := 0.0
+		if es.RequestCount > 0 {
+			errorRate = float64(es.ErrorCount) / float64(es.RequestCount) * 100
+		}
+
+		writer.Write([]string{
+			es.Path,
+			strconv.FormatInt(es.RequestCount, 10),
+			strconv.FormatInt(es.ErrorCount, 10),
+			strconv.FormatInt(es.RequestCount-es.ErrorCount, 10),
+			strconv.FormatFloat(errorRate, 'f', 2, 64),
+			strconv.FormatFloat(averageTimeMs(es), 'f', 2, 64),
+			strconv.FormatInt(es.MinTimeMs, 10),
+			strconv.FormatInt(es.MaxTimeMs, 10),
+			formatStatusCodes(es.StatusCodes),
+			formatExportTime(es.FirstRequest),
+			formatExportTime(es.LastRequest),
+			strconv.FormatFloat(es.Rates.OneMinute.RequestsPerSec, 'f', 4, 64),
+			strconv.FormatFloat(es.Rates.OneMinute.ErrorsPerSec, 'f', 4, 64),
 		})
-	case "get_stats":
-		stats := s.stats.GetAllStats()
-		conn.WriteJSON(types.TUIMessage{
-			Type:      "stats",
-			Timestamp: time.Now(),
-			Data:      stats,
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logging.Warnf("Failed to write stats export: %v", err)
+	}
+}
+
+// formatStatusCodes renders a status code distribution as "code:count" pairs
+func formatStatusCodes(statusCodes map[int]int64) string {
+	codes := make([]int, 0, len(statusCodes))
+	for code := range statusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	parts := make([]string, 0, len(codes))
+	for _, code := range codes {
+		parts = append(parts, fmt.Sprintf("%d:%d", code, statusCodes[code]))
+	}
+	return strings.Join(parts, ";")
+}
+
+// formatExportTime formats a timestamp for export, leaving unset times empty
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// handleStatsSnapshot takes a named snapshot (POST) or lists snapshots (GET)
+func (s *Server) handleStatsSnapshot(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		name := r.URL.Query().Get("name")
+		if name == "" && r.ContentLength != 0 {
+			var request struct {
+				Name string `json:"name"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+				return
+			}
+			name = request.Name
+		}
+
+		if name == "" {
+			http.Error(w, "Snapshot name is required", http.StatusBadRequest)
+			return
+		}
+		if name == "current" {
+			http.Error(w, "Snapshot name 'current' is reserved", http.StatusBadRequest)
+			return
+		}
+
+		snapshot := s.TakeSnapshot(name)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":   "success",
+			"name":     snapshot.Name,
+			"taken_at": snapshot.TakenAt,
+		})
+	case http.MethodGet:
+		s.snapshotsMu.RLock()
+		snapshots := make([]map[string]interface{}, 0, len(s.snapshots))
+		for _, snapshot := range s.snapshots {
+			snapshots = append(snapshots, map[string]interface{}{
+				"name":     snapshot.Name,
+				"taken_at": snapshot.TakenAt,
+			})
+		}
+		s.snapshotsMu.RUnlock()
+
+		// Oldest snapshot first
+		sort.Slice(snapshots, func(i, j int) bool {
+			return snapshots[i]["taken_at"].(time.Time).Before(snapshots[j]["taken_at"].(time.Time))
 		})
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(snapshots)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
+// handleStatsDiff returns the deltas between two named snapshots
+func (s *Server) handleStatsDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fromName := r.URL.Query().Get("from")
+	toName := r.URL.Query().Get("to")
+	if fromName == "" {
+		http.Error(w, "from parameter is required", http.StatusBadRequest)
+		return
+	}
+	if toName == "" {
+		toName = "current"
+	}
+
+	from, exists := s.GetSnapshot(fromName)
+	if !exists {
+		http.Error(w, fmt.Sprintf("Snapshot not found: %s", fromName), http.StatusNotFound)
+		return
+	}
+
+	to, exists := s.GetSnapshot(toName)
+	if !exists {
+		http.Error(w, fmt.Sprintf("Snapshot not found: %s", toName), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.DiffSnapshots(from, to))
+}
+
+// averageTimeMs returns the mean response time for an endpoint
+func averageTimeMs(es *types.EndpointStats) float64 {
+	if es.RequestCount == 0 {
+		return 0
+	}
+	return float64(es.TotalTimeMs) / float64(es.RequestCount)
+}
+
 // handleRequest handles all other requests (dynamic endpoints and static files)
 func (s *Server) handleRequest(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
@@ -205,26 +586,94 @@ func (s *Server) handleRequest(w http.ResponseWriter, r *http.Request) {
 	config := s.config.GetConfig()
 	if config == nil {
 		http.Error(w, "Server configuration not loaded", http.StatusInternalServerError)
-		s.stats.RecordRequest(r.URL.Path, time.Since(start), http.StatusInternalServerError)
+		s.stats.RecordRequest(s.stats.PathKey(r.URL.Path), time.Since(start), http.StatusInternalServerError)
 		return
 	}
 
 	// Note: Request logging is now handled by middleware to avoid duplication
 
-	// Check if this is a configured dynamic endpoint
-	if endpointConfig, exists := config.Endpoints[r.URL.Path]; exists {
-		s.handleDynamicEndpoint(w, r, endpointConfig)
+	// Check if this is a dynamic endpoint that is enabled, as configured or
+	// as a running scenario changed it. Endpoints are counted by their key,
+	// so those sharing a path for different methods are kept apart, and
+	// other paths by path up to a limit.
+	key, endpointConfig, exists := s.endpointConfig(config, r.Method, r.URL.Path)
+	if !exists {
+		key = s.stats.PathKey(r.URL.Path)
+	}
+
+	// Track in-flight requests for the concurrency high-water mark
+	endpointStats := s.stats.GetEndpointStats(key)
+	endpointStats.BeginRequest()
+	defer endpointStats.EndRequest()
+
+	// A client's deadline bounds how long endpoints wait; a malformed one is
+	// counted like any other answer
+	r, cancel, err := withRequestDeadline(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		if exists {
+			s.recordEndpointRequest(key, endpointConfig, time.Since(start), http.StatusBadRequest)
+		} else {
+			s.stats.RecordRequest(key, time.Since(start), http.StatusBadRequest)
+		}
+		return
+	}
+	defer cancel()
+
+	// In contract mode the real backend answers, checked against the endpoint
+	if contract := s.contract.Load(); contract != nil {
+		s.handleContractRequest(w, r, key, contract, endpointConfig, exists)
+		return
+	}
+
+	// Overrides set at runtime answer before scenarios and the configuration
+	if override, overridden := s.takeOverride(r); overridden {
+		endpointConfig, exists = override, true
+	}
+
+	if exists {
+		session := requestSession(w, r, config.Server.Sessions)
+		if endpointConfig.Group != "" && s.injectChaos(w, r, key, config, endpointConfig) {
+			return
+		}
+		if endpointConfig.Requires != nil && s.rejectOutOfOrder(w, r, key, endpointConfig, session) {
+			return
+		}
+		if endpointConfig.RequestSchema != nil && s.rejectInvalidBody(w, r, key, endpointConfig) {
+			return
+		}
+		s.handleDynamicEndpoint(w, r, key, endpointConfig, session)
+
+		// Remember successful calls for endpoints that require them first
+		if rw, ok := w.(*responseWriter); ok && rw.statusCode < 400 && s.isRequired(config, r.URL.Path) {
+			s.sessions.recordCall(session, r.URL.Path, time.Now())
+		}
+		return
+	}
+
+	// Paths served only for other methods answer 405 rather than a file
+	if allowed := s.allowedMethods(config, r.URL.Path); len(allowed) > 0 {
+		s.rejectMethod(w, r, key, allowed)
 		return
 	}
 
 	// Handle static file serving
-	s.handleStaticFile(w, r, config.Server.StaticDir)
+	s.handleStaticFile(w, r, key, config.Server.StaticDir)
 }
 
-// handleDynamicEndpoint handles configured dynamic endpoints
-func (s *Server) handleDynamicEndpoint(w http.ResponseWriter, r *http.Request, config types.EndpointConfig) {
+// handleDynamicEndpoint handles the configured dynamic endpoint with the
+// given key for a session
+func (s *Server) handleDynamicEndpoint(w http.ResponseWriter, r *http.Request, key string, config types.EndpointConfig, session string) {
+	switch config.Type {
+	case "fuzz":
+		s.handleFuzzEndpoint(w, r, key, config, session)
+		return
+	case "proxy":
+		s.handleProxyEndpoint(w, r, key, config)
+		return
+	}
+
 	start := time.Now()
-	endpointStats := s.stats.GetEndpointStats(r.URL.Path)
 
 	var statusCode int
 	var responseData interface{}
@@ -235,15 +684,22 @@ func (s *Server) handleDynamicEndpoint(w http.ResponseWriter, r *http.Request, c
 		responseData = map[string]string{"error": config.Message}
 
 	case "delay":
-		if config.DelayMs > 0 {
-			time.Sleep(time.Duration(config.DelayMs) * time.Millisecond)
+		delay := time.Duration(config.DelayMs) * time.Millisecond
+		if config.DelayProfile != nil {
+			delay = s.profileDelay(key, config.DelayProfile)
+		}
+
+		// A client's deadline cuts the delay short
+		if !waitDelay(r.Context(), delay) {
+			statusCode = http.StatusGatewayTimeout
+			responseData = map[string]string{"error": "Request deadline exceeded"}
+			break
 		}
 		statusCode = http.StatusOK
 		responseData = config.Response
 
 	case "conditional_error":
-		endpointStats.IncrementConditionalCount()
-		count := endpointStats.GetConditionalCount()
+		count := s.requestCount(key, config, session)
 
 		if count%int64(config.ErrorEveryN) == 0 {
 			statusCode = config.StatusCode
@@ -253,31 +709,90 @@ func (s *Server) handleDynamicEndpoint(w http.ResponseWriter, r *http.Request, c
 			responseData = config.SuccessResponse
 		}
 
+	case "redirect":
+		statusCode = config.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusFound
+		}
+		w.Header().Set("Location", config.Location)
+		responseData = map[string]string{"location": config.Location}
+
 	default:
 		statusCode = http.StatusInternalServerError
 		responseData = map[string]string{"error": "Unknown endpoint type"}
 	}
 
+	// Encode the response as the client prefers; successful responses of
+	// protobuf endpoints default to their message
+	offers := responseEncodings
+	if config.Protobuf != nil && statusCode < 400 {
+		offers = append([]responseEncoding{protobufEncoding}, responseEncodings...)
+	}
+	encoding := negotiateEncoding(r.Header.Get("Accept"), offers)
+	body, err := s.encodeResponse(encoding, config, responseData)
+	if err != nil {
+		logging.Errorf("Failed to encode %s as %s: %v", r.URL.Path, encoding.mediaType, err)
+		statusCode = http.StatusInternalServerError
+		encoding = negotiateEncoding(r.Header.Get("Accept"), responseEncodings)
+		body, _ = s.encodeResponse(encoding, config, map[string]string{"error": fmt.Sprintf("response encoding failed: %v", err)})
+	}
+
 	// Send response
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", encoding.mediaType)
+	w.Header().Set("Vary", "Accept")
 	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(responseData)
+	w.Write(body)
 
 	// Record statistics
-	s.stats.RecordRequest(r.URL.Path, time.Since(start), statusCode)
+	s.recordEndpointRequest(key, config, time.Since(start), statusCode)
 
 	// Note: Request logging is now handled by middleware to avoid duplication
 }
 
-// handleStaticFile serves static files
-func (s *Server) handleStaticFile(w http.ResponseWriter, r *http.Request, staticDir string) {
+// recordEndpointRequest records a request to the dynamic endpoint with the
+// given key along with the endpoint's thresholds
+func (s *Server) recordEndpointRequest(key string, config types.EndpointConfig, duration time.Duration, statusCode int) {
+	endpointStats := s.stats.GetEndpointStats(key)
+	endpointStats.SetApdexThreshold(config.ApdexThresholdMs)
+	endpointStats.SetSlowThreshold(config.SlowThresholdMs)
+	endpointStats.SetSLO(config.SLO)
+	s.stats.RecordRequest(key, duration, statusCode)
+}
+
+// encodeProtobuf encodes a response as an endpoint's protobuf message
+func (s *Server) encodeProtobuf(config *types.ProtobufConfig, response map[string]interface{}) ([]byte, error) {
+	set, err := s.protobufSet(config.DescriptorSet)
+	if err != nil {
+		return nil, err
+	}
+	return set.Marshal(config.Message, response)
+}
+
+// rejectMethod answers a request to a path whose endpoints do not accept its
+// method with 405, naming the methods they do accept, and counts it under key
+func (s *Server) rejectMethod(w http.ResponseWriter, r *http.Request, key string, allowed []string) {
+	start := time.Now()
+
+	encoding := negotiateEncoding(r.Header.Get("Accept"), responseEncodings)
+	body, _ := s.encodeResponse(encoding, types.EndpointConfig{}, map[string]string{"error": "Method not allowed"})
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.Header().Set("Content-Type", encoding.mediaType)
+	w.Header().Set("Vary", "Accept")
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	w.Write(body)
+
+	s.stats.RecordRequest(key, time.Since(start), http.StatusMethodNotAllowed)
+}
+
+// handleStaticFile serves static files, counting requests under key
+func (s *Server) handleStaticFile(w http.ResponseWriter, r *http.Request, key, staticDir string) {
 	start := time.Now()
 
 	// Ensure static directory exists
 	if err := s.ensureStaticDir(staticDir); err != nil {
-		log.Printf("Failed to ensure static directory: %v", err)
+		logging.Errorf("Failed to ensure static directory: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		s.stats.RecordRequest(r.URL.Path, time.Since(start), http.StatusInternalServerError)
+		s.stats.RecordRequest(key, time.Since(start), http.StatusInternalServerError)
 		return
 	}
 
@@ -294,20 +809,20 @@ func (s *Server) handleStaticFile(w http.ResponseWriter, r *http.Request, static
 	absStaticDir, err := filepath.Abs(staticDir)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		s.stats.RecordRequest(r.URL.Path, time.Since(start), http.StatusInternalServerError)
+		s.stats.RecordRequest(key, time.Since(start), http.StatusInternalServerError)
 		return
 	}
 
 	absFilePath, err := filepath.Abs(filePath)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		s.stats.RecordRequest(r.URL.Path, time.Since(start), http.StatusInternalServerError)
+		s.stats.RecordRequest(key, time.Since(start), http.StatusInternalServerError)
 		return
 	}
 
 	if !strings.HasPrefix(absFilePath, absStaticDir) {
 		http.Error(w, "Forbidden", http.StatusForbidden)
-		s.stats.RecordRequest(r.URL.Path, time.Since(start), http.StatusForbidden)
+		s.stats.RecordRequest(key, time.Since(start), http.StatusForbidden)
 		return
 	}
 
@@ -315,45 +830,390 @@ func (s *Server) handleStaticFile(w http.ResponseWriter, r *http.Request, static
 	http.ServeFile(w, r, filePath)
 
 	// Record statistics (assume success, ServeFile handles errors)
-	s.stats.RecordRequest(r.URL.Path, time.Since(start), http.StatusOK)
+	s.stats.RecordRequest(key, time.Since(start), http.StatusOK)
 }
 
 // logRequest logs the incoming request
-func (s *Server) logRequest(r *http.Request) {
-	log.Printf("%s %s %s", r.Method, r.URL.RequestURI(), r.RemoteAddr)
-}
-
-// broadcastRequestLog broadcasts request information to WebSocket clients
-func (s *Server) broadcastRequestLog(r *http.Request, statusCode int, duration time.Duration) {
-	logEntry := types.RequestLogEntry{
-		Timestamp:  time.Now(),
-		Method:     r.Method,
-		Path:       r.URL.RequestURI(), // Use full request URI including query parameters
-		StatusCode: statusCode,
-		Duration:   duration.Milliseconds(),
-		RemoteAddr: r.RemoteAddr,
-	}
-
-	s.broadcastToWebSockets(types.TUIMessage{
-		Type:      "request_log",
-		Timestamp: time.Now(),
-		Data:      logEntry,
-	})
+func (s *Server) logRequest(entry types.RequestLogEntry) {
+	logging.Infof("%s %s %s", entry.Method, entry.Path, entry.RemoteAddr)
 }
 
 // handleRequestLog serves the current request log
 func (s *Server) handleRequestLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete {
+		s.ClearRequestLog()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": "Request log cleared"})
+		return
+	}
+
 	if r.Method != "GET" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	// ?since returns only the entries logged after a position taken from the
+	// X-Request-Log-Position header of an earlier response
+	var since uint64
+	if param := r.URL.Query().Get("since"); param != "" {
+		parsed, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid since parameter: %s", param), http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+	entries, position := s.requestLog.EntriesSince(since)
+	requestLog := filterRequestLogByTag(entries, r.URL.Query().Get("tag"))
 
-	requestLog := s.GetRequestLog()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(types.RequestLogPositionHeader, strconv.FormatUint(position, 10))
 	if err := json.NewEncoder(w).Encode(requestLog); err != nil {
-		log.Printf("Failed to encode request log: %v", err)
+		logging.Errorf("Failed to encode request log: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 }
+
+// handleRequestLogGroups serves the request log grouped by normalized route
+func (s *Server) handleRequestLogGroups(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	requestLog := filterRequestLogByTag(s.GetRequestLog(), r.URL.Query().Get("tag"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.GroupRequestLog(requestLog))
+}
+
+// handleRequestLogDiff serves a structured diff of two request log entries
+func (s *Server) handleRequestLogDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	entries := make([]types.RequestLogEntry, 0, 2)
+	for _, param := range []string{"from", "to"} {
+		value := r.URL.Query().Get(param)
+		if value == "" {
+			http.Error(w, "Both 'from' and 'to' request log IDs are required", http.StatusBadRequest)
+			return
+		}
+
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid request log ID: %s", value), http.StatusBadRequest)
+			return
+		}
+
+		entry, exists := s.GetRequestLogEntry(id)
+		if !exists {
+			http.Error(w, fmt.Sprintf("Request log entry %d not found", id), http.StatusNotFound)
+			return
+		}
+		entries = append(entries, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.DiffRequestLogEntries(entries[0], entries[1]))
+}
+
+// filterRequestLogByTag returns the entries carrying the given test tag, or
+// all entries when tag is empty
+func filterRequestLogByTag(entries []types.RequestLogEntry, tag string) []types.RequestLogEntry {
+	if tag == "" {
+		return entries
+	}
+
+	filtered := make([]types.RequestLogEntry, 0)
+	for _, entry := range entries {
+		if entry.Tag == tag {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// handleRequestLogExport streams the request log as NDJSON, oldest entry
+// first, optionally following live traffic with ?follow=true
+func (s *Server) handleRequestLogExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "ndjson" {
+		http.Error(w, fmt.Sprintf("Invalid format: %s (use ndjson)", format), http.StatusBadRequest)
+		return
+	}
+	follow := r.URL.Query().Get("follow") == "true"
+	tag := r.URL.Query().Get("tag")
+
+	// Subscribe before reading the stored log so no entries are missed
+	var updates chan types.RequestLogEntry
+	if follow {
+		updates = s.subscribeRequestLog()
+		defer s.unsubscribeRequestLog(updates)
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+
+	requestLog := filterRequestLogByTag(s.GetRequestLog(), tag)
+	written := make(map[uint64]bool, len(requestLog))
+	for i := len(requestLog) - 1; i >= 0; i-- {
+		if err := encoder.Encode(requestLog[i]); err != nil {
+			return
+		}
+		written[requestLog[i].ID] = true
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	if !follow {
+		return
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case entry, ok := <-updates:
+			if !ok {
+				return
+			}
+			// Skip entries already written from the stored log or with another tag
+			if written[entry.ID] || (tag != "" && entry.Tag != tag) {
+				continue
+			}
+			if err := encoder.Encode(entry); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// handleRequestLogEntry serves a single request log entry by ID, and actions
+// on it under /requestlog/{id}/...
+func (s *Server) handleRequestLogEntry(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/requestlog/"), "/"), "/")
+	if len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request log ID: %s", parts[0]), http.StatusBadRequest)
+		return
+	}
+
+	entry, exists := s.GetRequestLogEntry(id)
+	if !exists {
+		http.Error(w, fmt.Sprintf("Request log entry %d not found", id), http.StatusNotFound)
+		return
+	}
+
+	if len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(entry)
+		return
+	}
+
+	switch parts[1] {
+	case "replay":
+		s.handleRequestLogReplay(w, r, entry)
+	case "curl":
+		s.handleRequestLogCurl(w, r, entry)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleRequestLogReplay re-issues a logged request against the server itself,
+// or against the URL given with ?target= or {"target": "..."}
+func (s *Server) handleRequestLogReplay(w http.ResponseWriter, r *http.Request, entry types.RequestLogEntry) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	target := r.URL.Query().Get("target")
+	if target == "" && r.ContentLength != 0 {
+		var body struct {
+			Target string `json:"target"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		target = body.Target
+	}
+	if target == "" {
+		target = s.selfURL(r)
+	}
+
+	baseURL, err := url.Parse(target)
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		http.Error(w, fmt.Sprintf("Invalid target URL: %s", target), http.StatusBadRequest)
+		return
+	}
+
+	// Other servers must be allowed in the configuration, and never get the
+	// credentials the request was logged with
+	self := sameOrigin(baseURL, s.selfURL(r))
+	if !self && !s.replayTargetAllowed(baseURL) {
+		http.Error(w, fmt.Sprintf("Replay target not allowed: %s (add it to server.replay_targets)", target), http.StatusForbidden)
+		return
+	}
+
+	replayReq, err := http.NewRequestWithContext(r.Context(), entry.Method,
+		strings.TrimSuffix(baseURL.String(), "/")+entry.Path, strings.NewReader(entry.Body))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to build replay request: %v", err), http.StatusBadRequest)
+		return
+	}
+	replayReq.Header = entry.Headers.Clone()
+	if replayReq.Header == nil {
+		replayReq.Header = make(http.Header)
+	}
+	for _, header := range types.NonReplayableHeaders {
+		replayReq.Header.Del(header)
+	}
+	if !self {
+		for _, header := range types.CredentialHeaders {
+			replayReq.Header.Del(header)
+		}
+	}
+	replayReq.Header.Set("X-Replayed-From", strconv.FormatUint(entry.ID, 10))
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	start := time.Now()
+	resp, err := client.Do(replayReq)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Replay failed: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, types.MaxCapturedBodyBytes+1))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read replay response: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	result := types.ReplayResult{
+		Original: entry,
+		Target:   baseURL.String(),
+		Response: types.ReplayResponse{
+			StatusCode: resp.StatusCode,
+			Duration:   time.Since(start).Milliseconds(),
+			Headers:    resp.Header,
+		},
+	}
+	if len(respBody) > types.MaxCapturedBodyBytes {
+		respBody = respBody[:types.MaxCapturedBodyBytes]
+		result.Response.BodyTruncated = true
+	}
+	result.Response.Body = string(respBody)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleRequestLogCurl renders a logged request as a curl command, sent to
+// ?target= or the host the request was originally made against
+func (s *Server) handleRequestLogCurl(w http.ResponseWriter, r *http.Request, entry types.RequestLogEntry) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, entry.CurlCommand(r.URL.Query().Get("target")))
+}
+
+// replayTargetAllowed reports whether a base URL is one of the configured
+// replay targets
+func (s *Server) replayTargetAllowed(target *url.URL) bool {
+	for _, allowed := range s.config.GetConfig().Server.ReplayTargets {
+		if sameOrigin(target, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameOrigin reports whether a URL has the scheme, host, port and user
+// information of a base URL, default ports made explicit
+func sameOrigin(target *url.URL, base string) bool {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return false
+	}
+	targetScheme, targetHost, targetPort := normalizedOrigin(target)
+	baseScheme, baseHost, basePort := normalizedOrigin(baseURL)
+	return targetScheme == baseScheme && targetHost == baseHost && targetPort == basePort &&
+		target.User.String() == baseURL.User.String()
+}
+
+// normalizedOrigin returns the lower-case scheme and host of a URL and its
+// port, the scheme's default when the URL has none
+func normalizedOrigin(u *url.URL) (scheme, host, port string) {
+	scheme = strings.ToLower(u.Scheme)
+	host = strings.ToLower(u.Hostname())
+	port = u.Port()
+	if port == "" {
+		switch scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		}
+	}
+	return scheme, host, port
+}
+
+// selfURL returns the base URL the server can be reached on locally: the
+// address it listens on once started, else the host the request was sent to,
+// as when the server is mounted with Handler
+func (s *Server) selfURL(r *http.Request) string {
+	s.mu.RLock()
+	listenAddr := s.listenAddr
+	s.mu.RUnlock()
+
+	if tcpAddr, ok := listenAddr.(*net.TCPAddr); ok {
+		host := "127.0.0.1"
+		if tcpAddr.IP != nil && !tcpAddr.IP.IsUnspecified() {
+			host = tcpAddr.IP.String()
+		}
+		return fmt.Sprintf("http://%s", net.JoinHostPort(host, strconv.Itoa(tcpAddr.Port)))
+	}
+	if r.Host != "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		return fmt.Sprintf("%s://%s", scheme, r.Host)
+	}
+
+	cfg := s.config.GetConfig()
+	host := cfg.Server.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, strconv.Itoa(cfg.Server.Port)))
+}
@@ -0,0 +1,241 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"webserver/internal/protobuf"
+	"webserver/pkg/types"
+)
+
+// responseEncoding is a media type dynamic endpoints can answer with
+type responseEncoding struct {
+	mediaType string
+	format    string // json, xml, msgpack or protobuf
+}
+
+// responseEncodings are offered by every dynamic endpoint, the first being
+// the default for requests without a usable Accept header
+var responseEncodings = []responseEncoding{
+	{"application/json", "json"},
+	{"application/xml", "xml"},
+	{"text/xml", "xml"},
+	{"application/msgpack", "msgpack"},
+	{"application/x-msgpack", "msgpack"},
+}
+
+// protobufEncoding is offered, and the default, for successful responses of
+// protobuf endpoints
+var protobufEncoding = responseEncoding{protobuf.ContentType, "protobuf"}
+
+// negotiateEncoding picks the offer an Accept header prefers: the highest
+// quality wins, the more specific media range decides an offer's quality,
+// and ties go to the earlier offer. Without an Accept header, or when it
+// accepts no offer, the first offer is used.
+func negotiateEncoding(accept string, offers []responseEncoding) responseEncoding {
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, exists := params["q"]; exists {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil && parsed >= 0 && parsed <= 1 {
+				quality = parsed
+			}
+		}
+		ranges = append(ranges, mediaRange{mediaType, quality})
+	}
+
+	best, bestQuality := offers[0], 0.0
+	for _, offer := range offers {
+		offerType, _, _ := strings.Cut(offer.mediaType, "/")
+		quality, specificity := 0.0, -1
+		for _, r := range ranges {
+			rangeSpecificity := -1
+			switch {
+			case r.mediaType == offer.mediaType:
+				rangeSpecificity = 2
+			case r.mediaType == offerType+"/*":
+				rangeSpecificity = 1
+			case r.mediaType == "*/*":
+				rangeSpecificity = 0
+			}
+			if rangeSpecificity > specificity {
+				quality, specificity = r.quality, rangeSpecificity
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+	return best
+}
+
+// encodeResponse encodes a dynamic endpoint's response in a negotiated
+// format
+func (s *Server) encodeResponse(encoding responseEncoding, config types.EndpointConfig, responseData interface{}) ([]byte, error) {
+	switch encoding.format {
+	case "protobuf":
+		response, _ := responseData.(map[string]interface{})
+		return s.encodeProtobuf(config.Protobuf, response)
+	case "xml":
+		generic, err := genericValue(responseData)
+		if err != nil {
+			return nil, err
+		}
+		return marshalXML(generic)
+	case "msgpack":
+		generic, err := genericValue(responseData)
+		if err != nil {
+			return nil, err
+		}
+		return types.MarshalMsgpack(msgpackNumbers(generic))
+	}
+
+	data, err := json.Marshal(responseData)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// genericValue converts a response to the maps, slices, strings, numbers,
+// booleans and nils of its JSON form, keeping numbers as written
+func genericValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// msgpackNumbers turns the JSON numbers of a generic value into integers
+// where they are whole and floats otherwise, as MessagePack clients expect
+func msgpackNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, element := range v {
+			v[key] = msgpackNumbers(element)
+		}
+	case []interface{}:
+		for i, element := range v {
+			v[i] = msgpackNumbers(element)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	}
+	return value
+}
+
+// marshalXML encodes a generic value under a <response> element. Object
+// keys become elements in sorted order, array elements repeat the element
+// of their key (or are <item> elements in a top-level or nested array), and
+// keys that are not XML names become <entry key="...">.
+func marshalXML(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := writeXMLElement(&buf, "response", value); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// writeXMLField writes an object field: one element, or one per element of
+// an array
+func writeXMLField(buf *bytes.Buffer, key string, value interface{}) error {
+	if elements, ok := value.([]interface{}); ok {
+		for _, element := range elements {
+			if err := writeXMLElement(buf, key, element); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return writeXMLElement(buf, key, value)
+}
+
+// writeXMLElement writes one element holding a value
+func writeXMLElement(buf *bytes.Buffer, name string, value interface{}) error {
+	closing := name
+	if isXMLName(name) {
+		fmt.Fprintf(buf, "<%s>", name)
+	} else {
+		closing = "entry"
+		buf.WriteString(`<entry key="`)
+		if err := xml.EscapeText(buf, []byte(name)); err != nil {
+			return err
+		}
+		buf.WriteString(`">`)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := writeXMLField(buf, key, v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		// A top-level array, or an array nested in another
+		for _, element := range v {
+			if err := writeXMLElement(buf, "item", element); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := xml.EscapeText(buf, []byte(fmt.Sprint(v))); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(buf, "</%s>", closing)
+	return nil
+}
+
+// isXMLName reports whether a key can be used as an element name as is
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
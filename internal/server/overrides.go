@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"webserver/internal/config"
+	"webserver/internal/logging"
+	"webserver/pkg/types"
+)
+
+// AddOverride makes matching requests be answered by an override's endpoint
+// until its count is used up or its TTL passes. Overrides take precedence
+// over scenarios and the configuration, the oldest first when several match.
+func (s *Server) AddOverride(override types.EndpointOverride) (types.EndpointOverride, error) {
+	if err := s.config.ValidateOverride(&override); err != nil {
+		return types.EndpointOverride{}, err
+	}
+
+	s.overridesMu.Lock()
+	defer s.overridesMu.Unlock()
+
+	s.nextOverrideID++
+	override.ID = strconv.FormatUint(s.nextOverrideID, 10)
+	override.Method = strings.ToUpper(override.Method)
+	override.Remaining = override.Count
+	override.CreatedAt = time.Now()
+	if override.TTL != "" {
+		ttl, _ := time.ParseDuration(override.TTL) // Checked by ValidateOverride
+		expiresAt := override.CreatedAt.Add(ttl)
+		override.ExpiresAt = &expiresAt
+	}
+	s.overrides = append(s.overrides, &override)
+
+	logging.Infof("Override %s: %s %s answered as %s", override.ID, methodOrAny(override.Method), override.Path, override.Endpoint.Type)
+	return override, nil
+}
+
+// Overrides returns the overrides that still apply, oldest first
+func (s *Server) Overrides() []types.EndpointOverride {
+	s.overridesMu.Lock()
+	defer s.overridesMu.Unlock()
+
+	s.dropExpiredOverrides(time.Now())
+	overrides := make([]types.EndpointOverride, len(s.overrides))
+	for i, override := range s.overrides {
+		overrides[i] = *override
+	}
+	return overrides
+}
+
+// RemoveOverride removes an override by ID, or every override when id is
+// empty, reporting how many were removed
+func (s *Server) RemoveOverride(id string) int {
+	s.overridesMu.Lock()
+	defer s.overridesMu.Unlock()
+
+	if id == "" {
+		removed := len(s.overrides)
+		s.overrides = nil
+		return removed
+	}
+	for i, override := range s.overrides {
+		if override.ID == id {
+			s.overrides = append(s.overrides[:i], s.overrides[i+1:]...)
+			return 1
+		}
+	}
+	return 0
+}
+
+// takeOverride returns the endpoint of the oldest override matching a
+// request, counting the request against it
+func (s *Server) takeOverride(r *http.Request) (types.EndpointConfig, bool) {
+	s.overridesMu.Lock()
+	defer s.overridesMu.Unlock()
+
+	if len(s.overrides) == 0 {
+		return types.EndpointConfig{}, false
+	}
+	s.dropExpiredOverrides(time.Now())
+
+	for i, override := range s.overrides {
+		if override.Method != "" && override.Method != r.Method {
+			continue
+		}
+		if !config.MatchPathPattern(override.Path, r.URL.Path) {
+			continue
+		}
+
+		if override.Count > 0 {
+			override.Remaining--
+			if override.Remaining == 0 {
+				s.overrides = append(s.overrides[:i], s.overrides[i+1:]...)
+				logging.Infof("Override %s used up", override.ID)
+			}
+		}
+		return override.Endpoint, true
+	}
+	return types.EndpointConfig{}, false
+}
+
+// dropExpiredOverrides removes the overrides whose TTL passed. The caller
+// holds overridesMu.
+func (s *Server) dropExpiredOverrides(now time.Time) {
+	active := s.overrides[:0]
+	for _, override := range s.overrides {
+		if override.ExpiresAt != nil && !now.Before(*override.ExpiresAt) {
+			logging.Infof("Override %s expired", override.ID)
+			continue
+		}
+		active = append(active, override)
+	}
+	s.overrides = active
+}
+
+// methodOrAny names an override's method in logs
+func methodOrAny(method string) string {
+	if method == "" {
+		return "any method"
+	}
+	return method
+}
+
+// handleOverrides lists the active overrides on GET, adds one on POST and
+// removes the one in ?id=, or all of them, on DELETE
+func (s *Server) handleOverrides(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.Overrides())
+	case http.MethodPost:
+		var override types.EndpointOverride
+		if err := json.NewDecoder(r.Body).Decode(&override); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+			return
+		}
+		added, err := s.AddOverride(override)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid override: %v", err), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(added)
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		removed := s.RemoveOverride(id)
+		if id != "" && removed == 0 {
+			http.Error(w, fmt.Sprintf("Override not found: %s", id), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "removed": removed})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
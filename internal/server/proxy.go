@@ -0,0 +1,40 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+
+	"webserver/internal/logging"
+	"webserver/pkg/types"
+)
+
+// handleProxyEndpoint forwards a request to a proxy endpoint's upstream, the
+// request path appended to the target URL's, recording it like any other
+// endpoint
+func (s *Server) handleProxyEndpoint(w http.ResponseWriter, r *http.Request, key string, config types.EndpointConfig) {
+	start := time.Now()
+	target, _ := url.Parse(config.TargetURL) // Checked when the configuration loaded
+
+	statusCode := http.StatusBadGateway
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			forwardRequestTimeout(pr.In.Context(), pr.In.Header, pr.Out.Header)
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			statusCode = resp.StatusCode
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			statusCode = upstreamErrorStatus(err)
+			logging.Warnf("%s %s: upstream error: %v", r.Method, r.URL.Path, err)
+			http.Error(w, "upstream error: "+err.Error(), statusCode)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+
+	s.recordEndpointRequest(key, config, time.Since(start), statusCode)
+}
@@ -0,0 +1,353 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"webserver/internal/config"
+	"webserver/internal/logging"
+	"webserver/pkg/types"
+)
+
+var (
+	errScenarioNotFound = errors.New("no such scenario")
+	errScenarioRunning  = errors.New("a scenario is already running")
+)
+
+// scenarioRun is the scenario being played
+type scenarioRun struct {
+	file      config.ScenarioFile
+	startedAt time.Time
+	step      int       // Steps applied in the current pass
+	nextAt    time.Time // When the next step is applied
+	stop      chan struct{}
+}
+
+// loadScenarios reads the configuration's scenario files again. A running
+// scenario keeps its steps, unless it is no longer configured.
+func (s *Server) loadScenarios(cfg *types.Config) {
+	var files []config.ScenarioFile
+	if len(cfg.Scenarios) > 0 {
+		var err error
+		if files, err = config.LoadScenarios(cfg.Scenarios); err != nil {
+			logging.Warnf("Failed to load scenarios: %v", err)
+		}
+	}
+
+	s.scenariosMu.Lock()
+	s.scenarios = files
+	run := s.scenarioRun
+	s.scenariosMu.Unlock()
+
+	if run != nil {
+		for _, file := range files {
+			if file.Scenario.Name == run.file.Scenario.Name {
+				return
+			}
+		}
+		logging.Warnf("Scenario %s is no longer configured, stopping it", run.file.Scenario.Name)
+		s.StopScenario()
+	}
+}
+
+// Scenarios returns the loaded scenarios, the running one marked, and the
+// endpoints scenarios currently change
+func (s *Server) Scenarios() types.ScenarioList {
+	s.scenariosMu.RLock()
+	defer s.scenariosMu.RUnlock()
+
+	list := types.ScenarioList{Scenarios: make([]types.ScenarioStatus, 0, len(s.scenarios))}
+	for _, file := range s.scenarios {
+		status := types.ScenarioStatus{
+			Name:        file.Scenario.Name,
+			Description: file.Scenario.Description,
+			File:        file.Path,
+			Steps:       len(file.Scenario.Steps),
+			Loop:        file.Scenario.Loop,
+		}
+		if run := s.scenarioRun; run != nil && run.file.Scenario.Name == file.Scenario.Name {
+			startedAt, nextAt := run.startedAt, run.nextAt
+			status.Running = true
+			status.StartedAt = &startedAt
+			status.Step = run.step
+			status.NextStep = scenarioStepLabel(run.file.Scenario, run.step)
+			status.NextStepAt = &nextAt
+		}
+		list.Scenarios = append(list.Scenarios, status)
+	}
+
+	for path := range s.scenarioEndpoints {
+		list.Changed = append(list.Changed, path)
+	}
+	sort.Strings(list.Changed)
+	return list
+}
+
+// StartScenario plays a scenario from its first step. Endpoints changed by
+// an earlier scenario go back to their configuration first.
+func (s *Server) StartScenario(name string) error {
+	s.scenariosMu.Lock()
+	defer s.scenariosMu.Unlock()
+
+	if s.scenarioRun != nil {
+		return errScenarioRunning
+	}
+	for _, file := range s.scenarios {
+		if file.Scenario.Name != name {
+			continue
+		}
+		run := &scenarioRun{file: file, startedAt: time.Now(), stop: make(chan struct{})}
+		s.scenarioRun = run
+		s.scenarioEndpoints = make(map[string]*types.EndpointConfig)
+		go s.runScenario(run)
+		logging.Infof("Scenario %s started", name)
+		return nil
+	}
+	return errScenarioNotFound
+}
+
+// StopScenario stops the running scenario, if any, and serves every endpoint
+// from the configuration again. It reports whether anything changed.
+func (s *Server) StopScenario() bool {
+	s.scenariosMu.Lock()
+	run := s.scenarioRun
+	changed := run != nil || len(s.scenarioEndpoints) > 0
+	if run != nil {
+		close(run.stop)
+		s.scenarioRun = nil
+		logging.Infof("Scenario %s stopped", run.file.Scenario.Name)
+	}
+	s.scenarioEndpoints = nil
+	s.scenariosMu.Unlock()
+
+	if changed {
+		s.broadcastScenarios()
+	}
+	return changed
+}
+
+// runScenario applies a scenario's steps as their delays pass, starting
+// over from the configuration after the last step of a looping scenario
+func (s *Server) runScenario(run *scenarioRun) {
+	scenario := run.file.Scenario
+	for {
+		for i, step := range scenario.Steps {
+			delay, _ := config.ScenarioStepDelay(step) // Checked when the configuration loaded
+			if !s.scheduleScenarioStep(run, i, time.Now().Add(delay)) {
+				return
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-run.stop:
+				timer.Stop()
+				return
+			}
+
+			if !s.applyScenarioStep(run, i) {
+				return
+			}
+			s.broadcastScenarios()
+		}
+
+		if !scenario.Loop {
+			break
+		}
+		s.scenariosMu.Lock()
+		if s.scenarioRun != run {
+			s.scenariosMu.Unlock()
+			return
+		}
+		s.scenarioEndpoints = make(map[string]*types.EndpointConfig)
+		s.scenariosMu.Unlock()
+	}
+
+	// A finished scenario leaves its last changes in place until stopped
+	s.scenariosMu.Lock()
+	if s.scenarioRun == run {
+		s.scenarioRun = nil
+		logging.Infof("Scenario %s finished", scenario.Name)
+	}
+	s.scenariosMu.Unlock()
+	s.broadcastScenarios()
+}
+
+// scheduleScenarioStep records when the next step is applied, reporting
+// false once the run was stopped
+func (s *Server) scheduleScenarioStep(run *scenarioRun, step int, at time.Time) bool {
+	s.scenariosMu.Lock()
+	defer s.scenariosMu.Unlock()
+
+	if s.scenarioRun != run {
+		return false
+	}
+	run.step, run.nextAt = step, at
+	return true
+}
+
+// applyScenarioStep changes the endpoints as a step says, reporting false
+// once the run was stopped
+func (s *Server) applyScenarioStep(run *scenarioRun, i int) bool {
+	s.scenariosMu.Lock()
+	defer s.scenariosMu.Unlock()
+
+	if s.scenarioRun != run {
+		return false
+	}
+
+	step := run.file.Scenario.Steps[i]
+	for _, path := range step.Restore {
+		if path == "*" {
+			s.scenarioEndpoints = make(map[string]*types.EndpointConfig)
+			continue
+		}
+		delete(s.scenarioEndpoints, path)
+	}
+	for _, path := range step.Remove {
+		s.scenarioEndpoints[path] = nil
+	}
+	for path, endpointConfig := range step.Set {
+		endpointConfig := endpointConfig
+		s.scenarioEndpoints[path] = &endpointConfig
+	}
+
+	run.step = i + 1
+	logging.Infof("Scenario %s: applied %s (%d/%d)", run.file.Scenario.Name,
+		scenarioStepLabel(run.file.Scenario, i), i+1, len(run.file.Scenario.Steps))
+	return true
+}
+
+// endpointConfig returns the endpoint serving a request and its key: among
+// the ones for its path, as a scenario set them or else as configured, one
+// listing the method, or else one answering every method. Endpoints a
+// scenario removed and disabled endpoints are not served.
+func (s *Server) endpointConfig(cfg *types.Config, method, path string) (string, types.EndpointConfig, bool) {
+	keys, endpoints := s.pathEndpoints(cfg, path)
+	for i, endpoint := range endpoints {
+		if len(endpoint.Methods) > 0 && endpoint.AcceptsMethod(method) {
+			return keys[i], endpoint, true
+		}
+	}
+	for i, endpoint := range endpoints {
+		if len(endpoint.Methods) == 0 {
+			return keys[i], endpoint, true
+		}
+	}
+	return "", types.EndpointConfig{}, false
+}
+
+// allowedMethods returns the methods the endpoints for a path answer, for
+// the Allow header of 405 responses. It is empty when no endpoint serves
+// the path.
+func (s *Server) allowedMethods(cfg *types.Config, path string) []string {
+	seen := make(map[string]bool)
+	var methods []string
+	_, endpoints := s.pathEndpoints(cfg, path)
+	for _, endpoint := range endpoints {
+		for _, method := range endpoint.Methods {
+			if !seen[method] {
+				seen[method] = true
+				methods = append(methods, method)
+			}
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// pathEndpoints returns the enabled endpoints serving a path and their keys,
+// in key order
+func (s *Server) pathEndpoints(cfg *types.Config, path string) ([]string, []types.EndpointConfig) {
+	s.scenariosMu.RLock()
+	defer s.scenariosMu.RUnlock()
+
+	var keys []string
+	for key := range cfg.Endpoints {
+		if types.EndpointPath(key) == path {
+			keys = append(keys, key)
+		}
+	}
+	for key := range s.scenarioEndpoints {
+		if _, configured := cfg.Endpoints[key]; !configured && types.EndpointPath(key) == path {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var served []string
+	var endpoints []types.EndpointConfig
+	for _, key := range keys {
+		endpoint, exists := cfg.Endpoints[key]
+		if override, overridden := s.scenarioEndpoints[key]; overridden {
+			if override == nil {
+				continue
+			}
+			endpoint, exists = *override, true
+		}
+		if exists && !endpoint.Disabled {
+			served = append(served, key)
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return served, endpoints
+}
+
+// broadcastScenarios tells WebSocket clients following the configuration
+// that scenarios changed endpoints
+func (s *Server) broadcastScenarios() {
+	s.broadcastToWebSockets(types.TopicConfig, types.MessageScenarios, s.Scenarios())
+}
+
+// scenarioStepLabel names a step in logs and statuses
+func scenarioStepLabel(scenario *types.Scenario, i int) string {
+	if i >= len(scenario.Steps) {
+		return ""
+	}
+	if name := scenario.Steps[i].Name; name != "" {
+		return name
+	}
+	return fmt.Sprintf("step %d", i+1)
+}
+
+// handleScenarios lists scenarios on GET, starts one on POST ?name= and
+// stops the running one on DELETE
+func (s *Server) handleScenarios(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.Scenarios())
+	case http.MethodPost:
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			http.Error(w, "Scenario name is required", http.StatusBadRequest)
+			return
+		}
+
+		switch err := s.StartScenario(name); {
+		case errors.Is(err, errScenarioNotFound):
+			http.Error(w, fmt.Sprintf("Scenario not found: %s", name), http.StatusNotFound)
+			return
+		case errors.Is(err, errScenarioRunning):
+			http.Error(w, "A scenario is already running; stop it first", http.StatusConflict)
+			return
+		}
+		s.broadcastScenarios()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": "Scenario started"})
+	case http.MethodDelete:
+		message := "Scenario stopped"
+		if !s.StopScenario() {
+			message = "No scenario running"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": message})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
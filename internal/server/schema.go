@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"webserver/internal/jsonschema"
+	"webserver/internal/logging"
+	"webserver/pkg/types"
+)
+
+// maxValidatedBodyBytes limits the request bodies checked against a schema
+const maxValidatedBodyBytes = 10 << 20
+
+// rejectInvalidBody checks a request body against an endpoint's schema,
+// answering 400 with the violations when it does not match. It reports
+// whether the request was answered. Requests without a body are only
+// checked for methods that usually send one.
+func (s *Server) rejectInvalidBody(w http.ResponseWriter, r *http.Request, key string, config types.EndpointConfig) bool {
+	start := time.Now()
+
+	schema, err := s.requestSchema(key, config.RequestSchema)
+	if err != nil {
+		// Checked when the configuration loaded
+		logging.Errorf("Invalid request schema for %s: %v", r.URL.Path, err)
+		return false
+	}
+
+	var data []byte
+	if r.Body != nil {
+		data, err = io.ReadAll(io.LimitReader(r.Body, maxValidatedBodyBytes+1))
+		if err != nil {
+			return s.writeSchemaViolations(w, r, key, config, start, []string{fmt.Sprintf("body: failed to read: %v", err)})
+		}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	var violations []string
+	switch {
+	case len(bytes.TrimSpace(data)) == 0:
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+			return false
+		}
+		violations = []string{"body: missing"}
+	case len(data) > maxValidatedBodyBytes:
+		violations = []string{fmt.Sprintf("body: larger than %d bytes", maxValidatedBodyBytes)}
+	default:
+		var body interface{}
+		if err := json.Unmarshal(data, &body); err != nil {
+			violations = []string{fmt.Sprintf("body: not JSON: %v", err)}
+		} else {
+			violations = schema.Validate(body)
+		}
+	}
+
+	if len(violations) == 0 {
+		return false
+	}
+	return s.writeSchemaViolations(w, r, key, config, start, violations)
+}
+
+// compiledSchema is a request schema compiled for an endpoint, with the
+// schema it was compiled from
+type compiledSchema struct {
+	source map[string]interface{}
+	schema *jsonschema.Schema
+}
+
+// compileRequestSchemas replaces the compiled request schemas with those of
+// the endpoints of a configuration
+func (s *Server) compileRequestSchemas(cfg *types.Config) {
+	schemas := make(map[string]compiledSchema)
+	for key, endpoint := range cfg.Endpoints {
+		if endpoint.RequestSchema == nil {
+			continue
+		}
+		schema, err := jsonschema.Compile(endpoint.RequestSchema)
+		if err != nil {
+			// Checked when the configuration loaded
+			logging.Errorf("Invalid request schema for %s: %v", key, err)
+			continue
+		}
+		schemas[key] = compiledSchema{source: endpoint.RequestSchema, schema: schema}
+	}
+
+	s.requestSchemasMu.Lock()
+	s.requestSchemas = schemas
+	s.requestSchemasMu.Unlock()
+}
+
+// requestSchema returns the compiled request schema of an endpoint. Schemas
+// of scenarios and overrides, or of a configuration not yet applied, differ
+// from the one compiled for the key and are compiled on first use.
+func (s *Server) requestSchema(key string, source map[string]interface{}) (*jsonschema.Schema, error) {
+	s.requestSchemasMu.Lock()
+	defer s.requestSchemasMu.Unlock()
+
+	if compiled, exists := s.requestSchemas[key]; exists && reflect.DeepEqual(compiled.source, source) {
+		return compiled.schema, nil
+	}
+	schema, err := jsonschema.Compile(source)
+	if err != nil {
+		return nil, err
+	}
+	s.requestSchemas[key] = compiledSchema{source: source, schema: schema}
+	return schema, nil
+}
+
+// writeSchemaViolations answers a request whose body did not match with a
+// 400 listing why
+func (s *Server) writeSchemaViolations(w http.ResponseWriter, r *http.Request, key string, config types.EndpointConfig, start time.Time, violations []string) bool {
+	logging.Warnf("%s %s: request body does not match the schema: %s", r.Method, r.URL.Path, strings.Join(violations, "; "))
+
+	encoding := negotiateEncoding(r.Header.Get("Accept"), responseEncodings)
+	body, _ := s.encodeResponse(encoding, config, map[string]interface{}{
+		"error":      "Request body does not match the schema",
+		"violations": violations,
+	})
+	w.Header().Set("Content-Type", encoding.mediaType)
+	w.Header().Set("Vary", "Accept")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(body)
+
+	s.recordEndpointRequest(key, config, time.Since(start), http.StatusBadRequest)
+	return true
+}
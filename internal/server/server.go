@@ -1,15 +1,25 @@
 package server
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"io"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"webserver/internal/config"
+	"webserver/internal/logging"
+	"webserver/internal/protobuf"
 	"webserver/pkg/types"
 
 	"github.com/gorilla/websocket"
@@ -22,21 +32,101 @@ type Server struct {
 	httpServer      *http.Server
 	stats           *types.ServerStats
 	mux             *http.ServeMux
+	handler         http.Handler // mux wrapped with the request logging middleware
 	wsUpgrader      websocket.Upgrader
-	wsConnections   map[*websocket.Conn]bool
+	wsConnections   map[*wsClient]bool
 	wsConnectionsMu sync.RWMutex
+	wsReaperStop    chan struct{} // Closed on Stop to end the heartbeat reaper
 	isRunning       bool
+	listenAddr      net.Addr // Address of the listener being served, nil before Start
 	mu              sync.RWMutex
 
+	// Probe state: started once serving, draining while shutting down
+	started  atomic.Bool
+	draining atomic.Bool
+
 	// Request logging
-	requestLog   []types.RequestLogEntry
-	requestLogMu sync.RWMutex
-	maxLogSize   int
+	requestLog    *types.RequestLogBuffer
+	requestLogMu  sync.RWMutex    // Guards the exclusion and redaction rules below
+	logExclusions []string        // Path patterns excluded from the request log
+	logRedactor   *types.Redactor // Masks sensitive data before entries are stored
+
+	// Counts requests seen by the logging middleware for 1-in-N sampling
+	nextLogID         uint64 // Last assigned request log entry ID
+	logSampleCounter  uint64
+	logSampleEvery    int64 // Effective request log sampling rate
+	logSampleAboveRPS int64 // Only sample when traffic exceeds this rate; 0 always samples
+	traffic           trafficMeter
+
+	// Live request log subscribers (NDJSON follow streams)
+	logSubscribers   map[chan types.RequestLogEntry]struct{}
+	logSubscribersMu sync.RWMutex
+
+	// Webhook alerts on matching request log entries
+	alerts *alerter
+
+	// Named statistics snapshots
+	snapshots   map[string]*types.StatsSnapshot
+	snapshotsMu sync.RWMutex
+
+	// Protobuf descriptor sets by file, read again after configuration changes
+	protobufSets   map[string]*protobuf.Set
+	protobufSetsMu sync.Mutex
+
+	// Compiled request schemas by endpoint key, compiled again after
+	// configuration changes
+	requestSchemas   map[string]compiledSchema
+	requestSchemasMu sync.Mutex
+
+	// Scenarios from the configuration, the one playing and the endpoints
+	// scenarios changed in memory; a nil endpoint is removed
+	scenarios         []config.ScenarioFile
+	scenarioRun       *scenarioRun
+	scenarioEndpoints map[string]*types.EndpointConfig
+	scenariosMu       sync.RWMutex
+
+	// Backend proxied to and checked against the endpoints in contract mode;
+	// nil while mocking
+	contract atomic.Pointer[contractProxy]
+
+	// Per-session state: endpoints called and per_session request counters
+	sessions *sessionStore
+
+	// Delay profiles followed by endpoints, by path, with when they started
+	delayProfiles   map[string]*delayProfileRun
+	delayProfilesMu sync.Mutex
+
+	// Endpoint overrides set at runtime, oldest first, until used up or expired
+	overrides      []*types.EndpointOverride
+	nextOverrideID uint64
+	overridesMu    sync.Mutex
+
+	// Chaos profiles applied to endpoint groups, by group
+	groupChaos   map[string]string
+	groupChaosMu sync.Mutex
 }
 
 // NewServer creates a new configurable web server
 func NewServer(configPath string) (*Server, error) {
-	configManager := config.NewManager(configPath)
+	return NewServerWithOverrides([]string{configPath}, config.Overrides{})
+}
+
+// NewServerWithOverrides creates a server from one or more configuration
+// files, each merged over the ones before it, whose host, port or static
+// directory replace the files'
+func NewServerWithOverrides(configPaths []string, overrides config.Overrides) (*Server, error) {
+	if len(configPaths) == 0 {
+		return nil, fmt.Errorf("no configuration file")
+	}
+	configManager := config.NewManager(configPaths[0])
+	configManager.SetLayers(configPaths[1:])
+	configManager.SetOverrides(overrides)
+	return NewServerWithManager(configManager)
+}
+
+// NewServerWithManager creates a server whose configuration comes from a
+// manager, e.g. one reading it from the environment
+func NewServerWithManager(configManager *config.Manager) (*Server, error) {
 	configWatcher := config.NewWatcher(configManager)
 
 	s := &Server{
@@ -44,13 +134,22 @@ func NewServer(configPath string) (*Server, error) {
 		configWatcher: configWatcher,
 		stats: &types.ServerStats{
 			StartTime: time.Now(),
-			Endpoints: make(map[string]*types.EndpointStats),
 		},
-		mux:           http.NewServeMux(),
-		wsUpgrader:    websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
-		wsConnections: make(map[*websocket.Conn]bool),
-		requestLog:    make([]types.RequestLogEntry, 0),
-		maxLogSize:    1000, // Keep last 1000 requests
+		mux: http.NewServeMux(),
+		wsUpgrader: websocket.Upgrader{
+			CheckOrigin:       func(r *http.Request) bool { return true },
+			EnableCompression: true, // Negotiate permessage-deflate for busy request_log streams
+			Subprotocols:      []string{types.WSEncodingMsgpack, types.WSEncodingJSON},
+		},
+		wsConnections:  make(map[*wsClient]bool),
+		requestLog:     types.NewRequestLogBuffer(types.DefaultRequestLogSize, 0),
+		snapshots:      make(map[string]*types.StatsSnapshot),
+		protobufSets:   make(map[string]*protobuf.Set),
+		logSubscribers: make(map[chan types.RequestLogEntry]struct{}),
+		alerts:         newAlerter(),
+		sessions:       newSessionStore(),
+		delayProfiles:  make(map[string]*delayProfileRun),
+		groupChaos:     make(map[string]string),
 	}
 
 	// Load initial configuration
@@ -58,21 +157,38 @@ func NewServer(configPath string) (*Server, error) {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	// Apply statistics sampling, request log limits and alert rules from the initial configuration
+	s.stats.SetSampleEvery(s.config.GetConfig().Server.SampleEvery)
+	s.configureRequestLog(s.config.GetConfig().Server)
+	s.alerts.configure(s.config.GetConfig().Alerts)
+	s.loadScenarios(s.config.GetConfig())
+	s.configureContract(s.config.GetConfig().Server.Contract)
+	s.compileRequestSchemas(s.config.GetConfig())
+
 	// Set up configuration change watcher
 	s.config.AddWatcher(s.onConfigChange)
+	s.config.AddReloadWatcher(s.onConfigReload)
 
 	// Set up routes
 	s.setupRoutes()
+	s.handler = s.logRequestMiddleware(s.mux)
 
 	return s, nil
 }
 
-// Start starts the web server
-func (s *Server) Start() error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// Handler returns the server's routes with their middleware, to mount in
+// another mux or serve with httptest.NewServer. Without Start or
+// StartListener configuration files are not watched and WebSocket clients
+// are not pinged, but changes through the API and ReloadConfig still apply.
+func (s *Server) Handler() http.Handler {
+	// The embedding application serves the routes from now on
+	s.started.Store(true)
+	return s.handler
+}
 
-	if s.isRunning {
+// Start starts the web server on the configured address
+func (s *Server) Start() error {
+	if s.IsRunning() {
 		return fmt.Errorf("server is already running")
 	}
 
@@ -81,11 +197,34 @@ func (s *Server) Start() error {
 		return fmt.Errorf("no configuration loaded")
 	}
 
+	addr := net.JoinHostPort(currentConfig.Server.Host, strconv.Itoa(currentConfig.Server.Port))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	if err := s.StartListener(listener); err != nil {
+		listener.Close()
+		return err
+	}
+	return nil
+}
+
+// StartListener starts the web server on a listener, e.g. one on an
+// ephemeral port, ignoring the configured address
+func (s *Server) StartListener(listener net.Listener) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isRunning {
+		return fmt.Errorf("server is already running")
+	}
+
 	// Create HTTP server
-	addr := fmt.Sprintf("%s:%d", currentConfig.Server.Host, currentConfig.Server.Port)
+	s.listenAddr = listener.Addr()
+	addr := s.listenAddr.String()
 	s.httpServer = &http.Server{
 		Addr:    addr,
-		Handler: s.logRequestMiddleware(s.mux), // Wrap with logging middleware
+		Handler: s.handler,
 	}
 
 	// Start configuration file watcher
@@ -93,21 +232,40 @@ func (s *Server) Start() error {
 		return fmt.Errorf("failed to start config watcher: %w", err)
 	}
 
+	// Ping WebSocket clients and drop unresponsive ones
+	s.wsReaperStop = make(chan struct{})
+	go s.reapWebSockets(s.wsReaperStop)
+
 	// Start server in goroutine
 	go func() {
-		log.Printf("Starting server on %s", addr)
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("Server error: %v", err)
+		logging.Infof("Starting server on %s", addr)
+		if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("Server error: %v", err)
 		}
 	}()
 
 	s.isRunning = true
-	log.Printf("Server started successfully on %s", addr)
+	s.draining.Store(false)
+	s.started.Store(true)
+	logging.Infof("Server started successfully on %s", addr)
 	return nil
 }
 
-// Stop stops the web server
+// Stop stops the web server. /readyz fails from the start, and with
+// shutdown_drain_ms set the listener stays open that long so load balancers
+// stop sending requests before it closes.
 func (s *Server) Stop() error {
+	if !s.IsRunning() {
+		return nil
+	}
+
+	s.draining.Store(true)
+	if cfg := s.config.GetConfig(); cfg != nil && cfg.Server.ShutdownDrainMs > 0 {
+		drain := time.Duration(cfg.Server.ShutdownDrainMs) * time.Millisecond
+		logging.Infof("Draining for %v before shutting down", drain)
+		time.Sleep(drain)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -115,17 +273,27 @@ func (s *Server) Stop() error {
 		return nil
 	}
 
-	// Stop configuration watcher
+	// Stop configuration watcher and the running scenario
 	s.configWatcher.Stop()
+	s.StopScenario()
 
 	// Close all WebSocket connections
+	close(s.wsReaperStop)
 	s.wsConnectionsMu.Lock()
-	for conn := range s.wsConnections {
-		conn.Close()
+	for client := range s.wsConnections {
+		client.conn.Close()
 	}
-	s.wsConnections = make(map[*websocket.Conn]bool)
+	s.wsConnections = make(map[*wsClient]bool)
 	s.wsConnectionsMu.Unlock()
 
+	// End streaming request log subscribers
+	s.logSubscribersMu.Lock()
+	for ch := range s.logSubscribers {
+		close(ch)
+	}
+	s.logSubscribers = make(map[chan types.RequestLogEntry]struct{})
+	s.logSubscribersMu.Unlock()
+
 	// Shutdown HTTP server
 	if s.httpServer != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -136,7 +304,7 @@ func (s *Server) Stop() error {
 	}
 
 	s.isRunning = false
-	log.Println("Server stopped successfully")
+	logging.Infof("Server stopped successfully")
 	return nil
 }
 
@@ -147,24 +315,146 @@ func (s *Server) IsRunning() bool {
 	return s.isRunning
 }
 
+// GetConfig returns a copy of the current configuration
+func (s *Server) GetConfig() *types.Config {
+	return s.config.GetConfig()
+}
+
+// UpdateEndpoint adds or replaces an endpoint, as POST /config does
+func (s *Server) UpdateEndpoint(path string, endpointConfig types.EndpointConfig) error {
+	return s.config.UpdateEndpoint(path, endpointConfig)
+}
+
+// RemoveEndpoint removes an endpoint, as DELETE /config?path= does
+func (s *Server) RemoveEndpoint(path string) error {
+	return s.config.RemoveEndpoint(path)
+}
+
 // GetStats returns the current server statistics
-func (s *Server) GetStats() types.ServerStats {
+func (s *Server) GetStats() *types.ServerStats {
 	return s.stats.GetAllStats()
 }
 
+// ResetStats clears all request statistics and tells every WebSocket client
+func (s *Server) ResetStats() {
+	s.stats.Reset()
+	logging.Infof("Statistics reset")
+	s.broadcastToAllWebSockets(types.MessageStatsReset, s.stats.GetAllStats())
+}
+
+// ClearRequestLog removes all stored request log entries and tells every WebSocket client
+func (s *Server) ClearRequestLog() {
+	s.requestLog.Clear()
+	logging.Infof("Request log cleared")
+	s.broadcastToAllWebSockets(types.MessageLogCleared, nil)
+}
+
+// ReloadConfig reads the configuration files again and applies them, as a
+// change through the API would be. An invalid configuration is not applied:
+// the previous one stays in use and GET /config/status reports the error.
+func (s *Server) ReloadConfig() error {
+	return s.config.Reload()
+}
+
+// DumpState writes the statistics and the request log to timestamped JSON
+// files in dir, in the format of the TUI's exports, returning their paths
+func (s *Server) DumpState(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create dump directory: %w", err)
+	}
+
+	stamp := time.Now().Format("20060102-150405")
+	dumps := []struct {
+		name string
+		data interface{}
+	}{
+		{"stats", s.GetStats()},
+		{"requestlog", s.GetRequestLog()},
+	}
+
+	var paths []string
+	for _, dump := range dumps {
+		data, err := json.MarshalIndent(dump.data, "", "  ")
+		if err != nil {
+			return paths, fmt.Errorf("failed to marshal %s: %w", dump.name, err)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("webserver-%s-%s.json", dump.name, stamp))
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return paths, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// TakeSnapshot stores a named copy of the current statistics, replacing any
+// existing snapshot with the same name
+func (s *Server) TakeSnapshot(name string) *types.StatsSnapshot {
+	snapshot := &types.StatsSnapshot{
+		Name:    name,
+		TakenAt: time.Now(),
+		Stats:   s.stats.GetAllStats(),
+	}
+
+	s.snapshotsMu.Lock()
+	defer s.snapshotsMu.Unlock()
+	s.snapshots[name] = snapshot
+	return snapshot
+}
+
+// GetSnapshot returns the named snapshot, or a live snapshot for "current"
+func (s *Server) GetSnapshot(name string) (*types.StatsSnapshot, bool) {
+	if name == "current" {
+		return &types.StatsSnapshot{Name: name, TakenAt: time.Now(), Stats: s.stats.GetAllStats()}, true
+	}
+
+	s.snapshotsMu.RLock()
+	defer s.snapshotsMu.RUnlock()
+	snapshot, exists := s.snapshots[name]
+	return snapshot, exists
+}
+
 // setupRoutes sets up the HTTP routes
 func (s *Server) setupRoutes() {
-	// Configuration management endpoint
-	s.mux.HandleFunc("/config", s.handleConfig)
-
-	// WebSocket endpoint for TUI
+	// Configuration management endpoint (management routes require a token once
+	// management auth is enabled)
+	s.mux.HandleFunc("/config", s.requireAuth(s.handleConfig))
+	s.mux.HandleFunc("/config/validate", s.requireAuth(s.handleValidateConfig))
+	s.mux.HandleFunc("/config/status", s.requireAuth(s.handleConfigStatus))
+	s.mux.HandleFunc("/config/overrides", s.requireAuth(s.handleOverrides))
+	s.mux.HandleFunc("/scenarios", s.requireAuth(s.handleScenarios))
+	s.mux.HandleFunc("/sessions", s.requireAuth(s.handleSessions))
+	s.mux.HandleFunc("/groups", s.requireAuth(s.handleGroups))
+
+	// WebSocket endpoint for TUI; authenticates in the handler so the token can
+	// also arrive as the first message
 	s.mux.HandleFunc("/ws", s.handleWebSocket)
+	s.mux.HandleFunc("/ws/clients", s.requireAuth(s.handleWebSocketClients))
 
 	// Statistics endpoint
-	s.mux.HandleFunc("/stats", s.handleStats)
+	s.mux.HandleFunc("/stats", s.requireAuth(s.handleStats))
+	s.mux.HandleFunc("/stats/top", s.requireAuth(s.handleStatsTop))
+	s.mux.HandleFunc("/stats/timeseries", s.requireAuth(s.handleStatsTimeSeries))
+	s.mux.HandleFunc("/stats/export", s.requireAuth(s.handleStatsExport))
+	s.mux.HandleFunc("/stats/snapshot", s.requireAuth(s.handleStatsSnapshot))
+	s.mux.HandleFunc("/stats/diff", s.requireAuth(s.handleStatsDiff))
 
 	// Request log endpoint
-	s.mux.HandleFunc("/requestlog", s.handleRequestLog)
+	s.mux.HandleFunc("/requestlog", s.requireAuth(s.handleRequestLog))
+	s.mux.HandleFunc("/requestlog/export", s.requireAuth(s.handleRequestLogExport))
+	s.mux.HandleFunc("/requestlog/groups", s.requireAuth(s.handleRequestLogGroups))
+	s.mux.HandleFunc("/requestlog/diff", s.requireAuth(s.handleRequestLogDiff))
+	s.mux.HandleFunc("/requestlog/", s.requireAuth(s.handleRequestLogEntry))
+
+	// Build information and health, public so deployment tooling can check them;
+	// /healthz predates the probes below and answers as /livez does
+	s.mux.HandleFunc("/version", s.handleVersion)
+	s.mux.HandleFunc("/healthz", s.handleLivez)
+
+	// Kubernetes probes
+	s.mux.HandleFunc("/livez", s.handleLivez)
+	s.mux.HandleFunc("/readyz", s.handleReadyz)
+	s.mux.HandleFunc("/startupz", s.handleStartupz)
 
 	// Catch-all handler for dynamic endpoints and static files
 	s.mux.HandleFunc("/", s.handleRequest)
@@ -172,53 +462,62 @@ func (s *Server) setupRoutes() {
 
 // onConfigChange handles configuration changes
 func (s *Server) onConfigChange(newConfig *types.Config) {
-	log.Println("Configuration changed, updating server...")
+	logging.Infof("Configuration changed, updating server...")
 
 	// Check if server address changed
 	currentConfig := s.config.GetConfig()
 	if currentConfig.Server.Host != newConfig.Server.Host ||
 		currentConfig.Server.Port != newConfig.Server.Port {
-		log.Println("Server address changed, restart required")
+		logging.Warnf("Server address changed, restart required")
 		// In a production system, you might want to handle this more gracefully
 	}
 
-	// Broadcast configuration change to WebSocket clients
-	s.broadcastToWebSockets(types.TUIMessage{
-		Type:      "config_updated",
-		Timestamp: time.Now(),
-		Data:      newConfig,
-	})
+	// Apply statistics sampling, request log limit and alert rule changes
+	s.stats.SetSampleEvery(newConfig.Server.SampleEvery)
+	s.configureRequestLog(newConfig.Server)
+	s.alerts.configure(newConfig.Alerts)
+	s.configureContract(newConfig.Server.Contract)
 
-	log.Println("Configuration updated successfully")
-}
+	// Descriptor sets may have been rebuilt along with the configuration
+	s.protobufSetsMu.Lock()
+	s.protobufSets = make(map[string]*protobuf.Set)
+	s.protobufSetsMu.Unlock()
 
-// addWebSocketConnection adds a new WebSocket connection
-func (s *Server) addWebSocketConnection(conn *websocket.Conn) {
-	s.wsConnectionsMu.Lock()
-	defer s.wsConnectionsMu.Unlock()
-	s.wsConnections[conn] = true
+	// Request schemas may have changed along with the configuration
+	s.compileRequestSchemas(newConfig)
+
+	// Scenario files may have changed along with the configuration
+	s.loadScenarios(newConfig)
+
+	// Refresh WebSocket permissions, dropping connections whose token was revoked
+	s.reauthorizeWebSockets(newConfig.Server.ManagementAuth)
+
+	// Broadcast configuration change to WebSocket clients
+	s.broadcastToWebSockets(types.TopicConfig, types.MessageConfigUpdated, newConfig.WithoutSecrets())
+
+	logging.Infof("Configuration updated successfully")
 }
 
-// removeWebSocketConnection removes a WebSocket connection
-func (s *Server) removeWebSocketConnection(conn *websocket.Conn) {
-	s.wsConnectionsMu.Lock()
-	defer s.wsConnectionsMu.Unlock()
-	delete(s.wsConnections, conn)
+// onConfigReload tells WebSocket clients following the configuration how a
+// reload of the configuration files went
+func (s *Server) onConfigReload(status types.ConfigStatus) {
+	s.broadcastToWebSockets(types.TopicConfig, types.MessageConfigStatus, status)
 }
 
-// broadcastToWebSockets broadcasts a message to all connected WebSocket clients
-func (s *Server) broadcastToWebSockets(message types.TUIMessage) {
-	s.wsConnectionsMu.RLock()
-	defer s.wsConnectionsMu.RUnlock()
+// protobufSet returns the descriptor set of a file, reading it on first use
+func (s *Server) protobufSet(path string) (*protobuf.Set, error) {
+	s.protobufSetsMu.Lock()
+	defer s.protobufSetsMu.Unlock()
 
-	for conn := range s.wsConnections {
-		if err := conn.WriteJSON(message); err != nil {
-			log.Printf("Failed to send WebSocket message: %v", err)
-			// Remove bad connection
-			delete(s.wsConnections, conn)
-			conn.Close()
-		}
+	if set, exists := s.protobufSets[path]; exists {
+		return set, nil
 	}
+	set, err := protobuf.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	s.protobufSets[path] = set
+	return set, nil
 }
 
 // ensureStaticDir ensures the static directory exists
@@ -283,33 +582,170 @@ func (s *Server) ensureStaticDir(staticDir string) error {
 			return fmt.Errorf("failed to create index.html: %w", err)
 		}
 
-		log.Printf("Created static directory and default index.html at %s", staticDir)
+		logging.Infof("Created static directory and default index.html at %s", staticDir)
 	}
 	return nil
 }
 
-// GetRequestLog returns a copy of the current request log
+// GetRequestLog returns a copy of the current request log, newest first
 func (s *Server) GetRequestLog() []types.RequestLogEntry {
+	return s.requestLog.Entries()
+}
+
+// configureRequestLog applies the request log capacity and memory budget,
+// evicting the oldest entries if the log now exceeds them
+func (s *Server) configureRequestLog(serverConfig types.ServerConfig) {
+	s.requestLog.Resize(serverConfig.RequestLogSize, serverConfig.RequestLogMemoryMB*1024*1024)
+
+	s.requestLogMu.Lock()
+	defer s.requestLogMu.Unlock()
+
+	// Log-specific sampling falls back to the statistics sampling rate
+	logSampleEvery := serverConfig.RequestLogSampleEvery
+	if logSampleEvery == 0 {
+		logSampleEvery = serverConfig.SampleEvery
+	}
+	atomic.StoreInt64(&s.logSampleEvery, int64(logSampleEvery))
+	atomic.StoreInt64(&s.logSampleAboveRPS, int64(serverConfig.RequestLogSampleAboveRPS))
+
+	s.logExclusions = append([]string(nil), serverConfig.RequestLogExclude...)
+
+	// Rules are validated on load; keep the previous ones if they somehow fail to compile
+	if redactor, err := types.NewRedactor(serverConfig.RequestLogRedact); err != nil {
+		logging.Warnf("Invalid request log redaction rules: %v", err)
+	} else {
+		s.logRedactor = redactor
+	}
+}
+
+// isSlowRequest reports whether a request took longer than its endpoint's
+// configured slow threshold
+func (s *Server) isSlowRequest(method, requestPath string, duration time.Duration) bool {
+	cfg := s.config.GetConfig()
+	if cfg == nil {
+		return false
+	}
+	_, endpointConfig, exists := s.endpointConfig(cfg, method, requestPath)
+	return exists && endpointConfig.SlowThresholdMs > 0 &&
+		duration.Milliseconds() > int64(endpointConfig.SlowThresholdMs)
+}
+
+// requestLogFilterMatches reports whether an entry satisfies a request log
+// filter; a nil filter matches every entry
+func requestLogFilterMatches(filter *types.RequestLogFilter, entry types.RequestLogEntry) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.Path != "" {
+		requestPath := entry.Path
+		if i := strings.IndexByte(requestPath, '?'); i >= 0 {
+			requestPath = requestPath[:i]
+		}
+		if !config.MatchPathPattern(filter.Path, requestPath) {
+			return false
+		}
+	}
+	if filter.PathPrefix != "" && !strings.HasPrefix(entry.Path, filter.PathPrefix) {
+		return false
+	}
+	if filter.Method != "" && !strings.EqualFold(filter.Method, entry.Method) {
+		return false
+	}
+	if filter.Tag != "" && filter.Tag != entry.Tag {
+		return false
+	}
+	if len(filter.StatusClasses) > 0 {
+		class := types.StatusClass(entry.StatusCode)
+		matched := false
+		for _, want := range filter.StatusClasses {
+			if strings.EqualFold(want, class) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return entry.StatusCode >= filter.MinStatus
+}
+
+// redactRequestLogEntry masks credentials and applies the configured
+// redaction rules to an entry
+func (s *Server) redactRequestLogEntry(entry *types.RequestLogEntry) {
+	s.requestLogMu.RLock()
+	redactor := s.logRedactor
+	s.requestLogMu.RUnlock()
+
+	types.MaskCredentials(entry)
+	redactor.Redact(entry)
+}
+
+// isExcludedFromLog reports whether a path matches a request log exclusion pattern
+func (s *Server) isExcludedFromLog(requestPath string) bool {
 	s.requestLogMu.RLock()
 	defer s.requestLogMu.RUnlock()
 
-	// Return a copy to avoid race conditions
-	logCopy := make([]types.RequestLogEntry, len(s.requestLog))
-	copy(logCopy, s.requestLog)
-	return logCopy
+	for _, pattern := range s.logExclusions {
+		if config.MatchPathPattern(pattern, requestPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestLogSampleEvery returns the request log sampling rate for the current
+// traffic level; sampling only applies once traffic exceeds the configured rate
+func (s *Server) requestLogSampleEvery(requestsPerSec int64) int {
+	sampleEvery := int(atomic.LoadInt64(&s.logSampleEvery))
+	if sampleEvery < 1 {
+		return 1
+	}
+
+	if threshold := atomic.LoadInt64(&s.logSampleAboveRPS); threshold > 0 && requestsPerSec <= threshold {
+		return 1
+	}
+	return sampleEvery
+}
+
+// GetRequestLogEntry returns the stored request log entry with the given ID
+func (s *Server) GetRequestLogEntry(id uint64) (types.RequestLogEntry, bool) {
+	return s.requestLog.Find(id)
 }
 
 // addToRequestLog adds a request entry to the stored request log
 func (s *Server) addToRequestLog(entry types.RequestLogEntry) {
-	s.requestLogMu.Lock()
-	defer s.requestLogMu.Unlock()
+	s.requestLog.Add(entry)
+}
+
+// subscribeRequestLog registers a channel that receives new request log entries
+func (s *Server) subscribeRequestLog() chan types.RequestLogEntry {
+	ch := make(chan types.RequestLogEntry, 256)
+
+	s.logSubscribersMu.Lock()
+	defer s.logSubscribersMu.Unlock()
+	s.logSubscribers[ch] = struct{}{}
+	return ch
+}
+
+// unsubscribeRequestLog removes a request log subscriber
+func (s *Server) unsubscribeRequestLog(ch chan types.RequestLogEntry) {
+	s.logSubscribersMu.Lock()
+	defer s.logSubscribersMu.Unlock()
+	delete(s.logSubscribers, ch)
+}
 
-	// Add to beginning of slice (newest first)
-	s.requestLog = append([]types.RequestLogEntry{entry}, s.requestLog...)
+// publishRequestLog sends an entry to all subscribers, dropping it for
+// subscribers that are not keeping up
+func (s *Server) publishRequestLog(entry types.RequestLogEntry) {
+	s.logSubscribersMu.RLock()
+	defer s.logSubscribersMu.RUnlock()
 
-	// Trim to max size
-	if len(s.requestLog) > s.maxLogSize {
-		s.requestLog = s.requestLog[:s.maxLogSize]
+	for ch := range s.logSubscribers {
+		select {
+		case ch <- entry:
+		default:
+		}
 	}
 }
 
@@ -321,38 +757,142 @@ func (s *Server) logRequestMiddleware(next http.Handler) http.Handler {
 		// Create a response writer that captures the status code
 		rw := &responseWriter{ResponseWriter: w, statusCode: 200}
 
+		// Excluded paths are never logged or broadcast, but still count
+		// towards the User-Agent breakdown
+		if s.isExcludedFromLog(r.URL.Path) {
+			next.ServeHTTP(rw, r)
+			s.stats.RecordUserAgent(r.UserAgent())
+			return
+		}
+
+		// Under sampling only 1-in-N requests are logged; statistics
+		// counters are kept exact by the handlers themselves
+		sampleEvery := s.requestLogSampleEvery(s.traffic.record(startTime))
+		if sampleEvery > 1 && atomic.AddUint64(&s.logSampleCounter, 1)%uint64(sampleEvery) != 0 {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		// Every logged request gets an ID clients can use to look up its entry
+		id := atomic.AddUint64(&s.nextLogID, 1)
+		rw.Header().Set(types.RequestIDHeader, strconv.FormatUint(id, 10))
+
+		// Capture the request so it can be inspected and replayed later
+		headers := r.Header.Clone()
+		body, bodyTruncated := captureRequestBody(r)
+
 		// Call the next handler
 		next.ServeHTTP(rw, r)
 
-		// Log the request (this calls the existing logRequest method)
-		s.logRequest(r)
+		// Track which clients exercised the server
+		s.stats.RecordUserAgentWeighted(r.UserAgent(), int64(sampleEvery))
 
 		// Add to stored request log and broadcast to WebSocket clients
 		duration := time.Since(startTime)
 		entry := types.RequestLogEntry{
-			Timestamp:  startTime,
-			Method:     r.Method,
-			Path:       r.URL.RequestURI(), // Use full request URI including query parameters
-			StatusCode: rw.statusCode,
-			Duration:   duration.Milliseconds(),
-			RemoteAddr: r.RemoteAddr,
+			ID:            id,
+			Timestamp:     startTime,
+			Method:        r.Method,
+			Path:          r.URL.RequestURI(), // Use full request URI including query parameters
+			StatusCode:    rw.statusCode,
+			Duration:      duration.Milliseconds(),
+			RemoteAddr:    r.RemoteAddr,
+			Tag:           r.Header.Get(types.TestTagHeader),
+			Slow:          s.isSlowRequest(r.Method, r.URL.Path, duration),
+			Host:          r.Host,
+			Headers:       headers,
+			Body:          string(body),
+			BodyTruncated: bodyTruncated,
+
+			ContractMismatches: rw.contractMismatches,
 		}
 
+		// Mask sensitive data before the entry is printed, stored or broadcast
+		s.redactRequestLogEntry(&entry)
+		s.logRequest(entry)
+
 		s.addToRequestLog(entry)
-		s.broadcastToWebSockets(types.TUIMessage{
-			Type: "request_log",
-			Data: entry,
-		})
+		s.publishRequestLog(entry)
+		s.alerts.check(entry)
+		s.broadcastRequestLogEntry(entry)
 	})
 }
 
+// captureRequestBody reads up to types.MaxCapturedBodyBytes of the request
+// body for the log, leaving the full body readable by the handler
+func captureRequestBody(r *http.Request) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, false
+	}
+
+	captured, err := io.ReadAll(io.LimitReader(r.Body, types.MaxCapturedBodyBytes+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(captured), r.Body), r.Body}
+	if err != nil {
+		return nil, false
+	}
+
+	if len(captured) > types.MaxCapturedBodyBytes {
+		return captured[:types.MaxCapturedBodyBytes], true
+	}
+	return captured, false
+}
+
 // responseWriter wraps http.ResponseWriter to capture the status code
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+
+	contractMismatches []string // Set by contract mode for the request log
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Hijack lets WebSocket upgrades take over the connection through the wrapper
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	rw.statusCode = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Flush lets streaming handlers flush through the wrapper
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// trafficMeter measures the request rate over the current and previous second
+type trafficMeter struct {
+	second   int64
+	current  int64
+	previous int64
+}
+
+// record counts a request and returns the recent requests per second
+func (tm *trafficMeter) record(now time.Time) int64 {
+	second := now.Unix()
+	if last := atomic.LoadInt64(&tm.second); last != second {
+		if atomic.CompareAndSwapInt64(&tm.second, last, second) {
+			previous := atomic.SwapInt64(&tm.current, 0)
+			if second != last+1 {
+				previous = 0
+			}
+			atomic.StoreInt64(&tm.previous, previous)
+		}
+	}
+
+	current := atomic.AddInt64(&tm.current, 1)
+	if previous := atomic.LoadInt64(&tm.previous); previous > current {
+		return previous
+	}
+	return current
+}
@@ -0,0 +1,247 @@
+package server
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"webserver/pkg/types"
+)
+
+// maxSessions limits the sessions remembered; the least recently active are
+// forgotten first
+const maxSessions = 10000
+
+// sessionStore keeps per-session state: the required endpoints each session
+// called successfully, for endpoints that require another to be called
+// first, and the request counters of per_session endpoints
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*list.Element // Values are *types.SessionState
+	order    *list.List               // Sessions, least recently active first
+}
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{sessions: make(map[string]*list.Element), order: list.New()}
+}
+
+// get returns a session's state, creating it when new, and marks the
+// session active. The caller holds the lock.
+func (st *sessionStore) get(session string, now time.Time) *types.SessionState {
+	element, exists := st.sessions[session]
+	if exists {
+		st.order.MoveToBack(element)
+	} else {
+		if st.order.Len() >= maxSessions {
+			oldest := st.order.Front()
+			delete(st.sessions, oldest.Value.(*types.SessionState).Session)
+			st.order.Remove(oldest)
+		}
+		element = st.order.PushBack(&types.SessionState{Session: session, Calls: make(map[string]time.Time)})
+		st.sessions[session] = element
+	}
+	state := element.Value.(*types.SessionState)
+	state.LastSeen = now
+	return state
+}
+
+// recordCall notes a successful call of a session to a path
+func (st *sessionStore) recordCall(session, path string, at time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.get(session, at).Calls[path] = at
+}
+
+// called reports whether a session called a path successfully
+func (st *sessionStore) called(session, path string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	element, exists := st.sessions[session]
+	if !exists {
+		return false
+	}
+	_, called := element.Value.(*types.SessionState).Calls[path]
+	return called
+}
+
+// increment counts a session's request to an endpoint, returning the new count
+func (st *sessionStore) increment(session, key string) int64 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	state := st.get(session, time.Now())
+	if state.Counters == nil {
+		state.Counters = make(map[string]int64)
+	}
+	state.Counters[key]++
+	return state.Counters[key]
+}
+
+// list returns the state of every session, or of one when session is not
+// empty, in session order
+func (st *sessionStore) list(session string) []types.SessionState {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	states := make([]types.SessionState, 0, len(st.sessions))
+	for name, element := range st.sessions {
+		if session != "" && name != session {
+			continue
+		}
+		state := element.Value.(*types.SessionState)
+		copied := types.SessionState{Session: name, LastSeen: state.LastSeen, Calls: make(map[string]time.Time, len(state.Calls))}
+		for path, at := range state.Calls {
+			copied.Calls[path] = at
+		}
+		if state.Counters != nil {
+			copied.Counters = make(map[string]int64, len(state.Counters))
+			for path, count := range state.Counters {
+				copied.Counters[path] = count
+			}
+		}
+		states = append(states, copied)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Session < states[j].Session })
+	return states
+}
+
+// forget drops the state of one session, or of all when session is empty,
+// reporting how many sessions were forgotten
+func (st *sessionStore) forget(session string) int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if session == "" {
+		forgotten := len(st.sessions)
+		st.sessions = make(map[string]*list.Element)
+		st.order.Init()
+		return forgotten
+	}
+	element, exists := st.sessions[session]
+	if !exists {
+		return 0
+	}
+	delete(st.sessions, session)
+	st.order.Remove(element)
+	return 1
+}
+
+// requestSession names the session a request belongs to: the configured
+// cookie, then the session header, then the caller's IP address. Callers
+// without the configured cookie are handed a new one.
+func requestSession(w http.ResponseWriter, r *http.Request, sessions *types.SessionConfig) string {
+	header := types.SessionHeader
+	if sessions != nil && sessions.Header != "" {
+		header = sessions.Header
+	}
+
+	if sessions != nil && sessions.Cookie != "" {
+		if cookie, err := r.Cookie(sessions.Cookie); err == nil && cookie.Value != "" {
+			return cookie.Value
+		}
+		if session := r.Header.Get(header); session != "" {
+			return session
+		}
+		session := newSessionID()
+		http.SetCookie(w, &http.Cookie{Name: sessions.Cookie, Value: session, Path: "/", HttpOnly: true})
+		return session
+	}
+
+	if session := r.Header.Get(header); session != "" {
+		return session
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// newSessionID returns a random session cookie value
+func newSessionID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// requestCount counts a request to the counting endpoint with the given key,
+// per session for per_session endpoints and for all callers otherwise
+func (s *Server) requestCount(key string, config types.EndpointConfig, session string) int64 {
+	if config.PerSession {
+		return s.sessions.increment(session, key)
+	}
+	return s.stats.GetEndpointStats(key).IncrementConditionalCount()
+}
+
+// isRequired reports whether an endpoint, as configured or as a scenario
+// set it, requires a path to be called first. Calls to other paths are not
+// remembered.
+func (s *Server) isRequired(cfg *types.Config, path string) bool {
+	for _, endpoint := range cfg.Endpoints {
+		if endpoint.Requires != nil && endpoint.Requires.Path == path {
+			return true
+		}
+	}
+
+	s.scenariosMu.RLock()
+	defer s.scenariosMu.RUnlock()
+	for _, endpoint := range s.scenarioEndpoints {
+		if endpoint != nil && endpoint.Requires != nil && endpoint.Requires.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectOutOfOrder answers a request to an endpoint that requires another
+// to be called first, when the session has not called it yet. It reports
+// whether the request was answered.
+func (s *Server) rejectOutOfOrder(w http.ResponseWriter, r *http.Request, key string, config types.EndpointConfig, session string) bool {
+	start := time.Now()
+	requires := config.Requires
+	if s.sessions.called(session, requires.Path) {
+		return false
+	}
+
+	statusCode := requires.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusUnauthorized
+	}
+	message := requires.Message
+	if message == "" {
+		message = fmt.Sprintf("Call %s first", requires.Path)
+	}
+
+	encoding := negotiateEncoding(r.Header.Get("Accept"), responseEncodings)
+	body, _ := s.encodeResponse(encoding, config, map[string]string{"error": message})
+	w.Header().Set("Content-Type", encoding.mediaType)
+	w.Header().Set("Vary", "Accept")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+
+	s.recordEndpointRequest(key, config, time.Since(start), statusCode)
+	return true
+}
+
+// handleSessions lists the state of sessions on GET and forgets it on
+// DELETE, for every session or the one in ?session=
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	session := r.URL.Query().Get("session")
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.sessions.list(session))
+	case http.MethodDelete:
+		forgotten := s.sessions.forget(session)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "forgotten": forgotten})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,828 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"webserver/internal/config"
+	"webserver/internal/logging"
+	"webserver/pkg/types"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsWriteTimeout bounds how long a single WebSocket write may block
+	wsWriteTimeout = 10 * time.Second
+
+	// maxRequestLogBacklog bounds the entries sent when subscribing to the request log
+	maxRequestLogBacklog = 1000
+
+	// wsCompressionThreshold is the payload size from which messages are sent
+	// compressed on connections that negotiated permessage-deflate; smaller
+	// messages cost more to deflate than they save
+	wsCompressionThreshold = 512
+
+	// wsAuthTimeout bounds how long an unauthenticated client may take to send its auth message
+	wsAuthTimeout = 10 * time.Second
+)
+
+var (
+	errWSClientClosed = errors.New("websocket client closed")
+	errWSSlowClient   = errors.New("websocket client too slow; disconnected")
+)
+
+// wsClient represents a connected WebSocket client and its topic subscriptions.
+// Messages are queued and written by a dedicated goroutine, so a slow client
+// never blocks request handling or other clients.
+type wsClient struct {
+	conn        *websocket.Conn
+	remoteAddr  string
+	connectedAt time.Time
+	lastSeen    atomic.Int64 // UnixNano of the last message or pong received
+
+	outbox    chan []byte   // Encoded messages waiting to be written
+	done      chan struct{} // Closed when the client is shut down
+	closeOnce sync.Once
+	policy    string       // What push does when the outbox is full
+	encoding  string       // types.WSEncodingJSON or types.WSEncodingMsgpack
+	dropped   atomic.Int64 // Messages dropped since the last dropped notice
+
+	mu            sync.Mutex
+	token         *types.AuthToken // Token the connection authenticated with; nil when auth is disabled
+	subscriptions map[string]types.WSClientMessage
+	statsStop     chan struct{} // Closed to stop the stats pusher
+}
+
+// newWSClient wraps a WebSocket connection without subscriptions and starts
+// its writer goroutine
+func newWSClient(conn *websocket.Conn, queueSize int, policy, encoding string) *wsClient {
+	client := &wsClient{
+		conn:          conn,
+		remoteAddr:    conn.RemoteAddr().String(),
+		connectedAt:   time.Now(),
+		outbox:        make(chan []byte, queueSize),
+		done:          make(chan struct{}),
+		policy:        policy,
+		encoding:      encoding,
+		subscriptions: make(map[string]types.WSClientMessage),
+	}
+	client.touch()
+	conn.SetPongHandler(func(string) error {
+		client.touch()
+		return nil
+	})
+
+	go client.writePump()
+	return client
+}
+
+// touch records that the client is alive
+func (c *wsClient) touch() {
+	c.lastSeen.Store(time.Now().UnixNano())
+}
+
+// lastSeenAt returns when the client last sent a message or pong
+func (c *wsClient) lastSeenAt() time.Time {
+	return time.Unix(0, c.lastSeen.Load())
+}
+
+// close shuts the client down; messages already queued are still flushed
+// before the connection is closed
+func (c *wsClient) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+// writePump writes queued messages until the client is closed or a write fails
+func (c *wsClient) writePump() {
+	defer c.conn.Close()
+
+	for {
+		select {
+		case payload := <-c.outbox:
+			if err := c.write(payload); err != nil {
+				c.close()
+				return
+			}
+			if err := c.writeDroppedNotice(); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			// Flush what is already queued, e.g. a final error message
+			for {
+				select {
+				case payload := <-c.outbox:
+					if c.write(payload) != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// write sends one encoded message on the connection
+func (c *wsClient) write(payload []byte) error {
+	// Compression only applies when the client negotiated it
+	c.conn.EnableWriteCompression(len(payload) >= wsCompressionThreshold)
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if c.encoding == types.WSEncodingMsgpack {
+		return c.conn.WriteMessage(websocket.BinaryMessage, payload)
+	}
+	return c.conn.WriteMessage(websocket.TextMessage, payload)
+}
+
+// writeDroppedNotice tells the client how many pushed messages it missed
+// while its queue was full
+func (c *wsClient) writeDroppedNotice() error {
+	count := c.dropped.Swap(0)
+	if count == 0 {
+		return nil
+	}
+
+	payload, err := encodeWSMessage(c.encoding, types.MessageDropped, types.WSDropped{Count: count})
+	if err != nil {
+		return err
+	}
+	return c.write(payload)
+}
+
+// encodeWSMessage encodes a message envelope in the given encoding
+func encodeWSMessage(encoding, messageType string, data interface{}) ([]byte, error) {
+	message := types.TUIMessage{
+		Type:      messageType,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+	if encoding == types.WSEncodingMsgpack {
+		return types.MarshalMsgpack(message)
+	}
+	return json.Marshal(message)
+}
+
+// decodeWSClientMessage decodes a client message from a JSON text frame or a
+// MessagePack binary frame
+func decodeWSClientMessage(frameType int, data []byte) (types.WSClientMessage, error) {
+	var message types.WSClientMessage
+	if frameType == websocket.BinaryMessage {
+		generic, err := types.UnmarshalMsgpack(data)
+		if err != nil {
+			return message, err
+		}
+		// Client messages are small; reuse the JSON field mapping
+		if data, err = json.Marshal(generic); err != nil {
+			return message, err
+		}
+	}
+	err := json.Unmarshal(data, &message)
+	return message, err
+}
+
+// wsOutgoing is a message queued for several clients, encoded at most once per encoding
+type wsOutgoing struct {
+	messageType string
+	data        interface{}
+	payloads    map[string][]byte
+}
+
+// newWSOutgoing creates a message for push
+func newWSOutgoing(messageType string, data interface{}) *wsOutgoing {
+	return &wsOutgoing{messageType: messageType, data: data, payloads: make(map[string][]byte)}
+}
+
+// payload returns the message encoded for a client's encoding
+func (m *wsOutgoing) payload(encoding string) ([]byte, error) {
+	if payload, exists := m.payloads[encoding]; exists {
+		return payload, nil
+	}
+	payload, err := encodeWSMessage(encoding, m.messageType, m.data)
+	if err != nil {
+		return nil, err
+	}
+	m.payloads[encoding] = payload
+	return payload, nil
+}
+
+// send queues a reply to the client, waiting for room in its queue. It is
+// used from the client's own goroutines; a client whose queue stays full for
+// wsWriteTimeout is disconnected.
+func (c *wsClient) send(messageType string, data interface{}) error {
+	payload, err := encodeWSMessage(c.encoding, messageType, data)
+	if err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(wsWriteTimeout)
+	defer timer.Stop()
+
+	select {
+	case c.outbox <- payload:
+		return nil
+	case <-c.done:
+		return errWSClientClosed
+	case <-timer.C:
+		c.close()
+		return errWSSlowClient
+	}
+}
+
+// push queues a broadcast message without blocking. When the queue is full,
+// droppable messages (request log entries and stats updates) are dropped
+// under the "drop" policy; anything else, or any message under the
+// "disconnect" policy, disconnects the slow client.
+func (c *wsClient) push(message *wsOutgoing) error {
+	select {
+	case <-c.done:
+		return errWSClientClosed
+	default:
+	}
+
+	payload, err := message.payload(c.encoding)
+	if err != nil {
+		logging.Errorf("Failed to encode WebSocket message: %v", err)
+		return err
+	}
+
+	select {
+	case c.outbox <- payload:
+		return nil
+	default:
+	}
+
+	droppable := message.messageType == types.MessageRequestLog || message.messageType == types.MessageStatsUpdate
+	if droppable && c.policy != types.SlowClientDisconnect {
+		c.dropped.Add(1)
+		return nil
+	}
+
+	c.close()
+	return errWSSlowClient
+}
+
+// sendError reports a rejected client message
+func (c *wsClient) sendError(format string, args ...interface{}) {
+	c.send(types.MessageError, types.WSError{Error: fmt.Sprintf(format, args...)})
+}
+
+// authToken returns the token the connection authenticated with
+func (c *wsClient) authToken() *types.AuthToken {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// setAuthToken associates a token, and so its permissions, with the connection
+func (c *wsClient) setAuthToken(token *types.AuthToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// allows reports whether the connection's token grants a permission
+func (c *wsClient) allows(permission string) bool {
+	token := c.authToken()
+	return token == nil || token.Allows(permission)
+}
+
+// authResult describes the permissions granted to the connection
+func (c *wsClient) authResult() types.WSAuthResult {
+	token := c.authToken()
+	if token == nil {
+		return types.WSAuthResult{Permissions: []string{types.PermissionRead, types.PermissionWrite}}
+	}
+	return types.WSAuthResult{Name: token.Name, Permissions: token.Permissions}
+}
+
+// info describes the client for the connected clients listing
+func (c *wsClient) info() types.WSClientInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info := types.WSClientInfo{
+		RemoteAddr:    c.remoteAddr,
+		ConnectedAt:   c.connectedAt,
+		Encoding:      c.encoding,
+		Subscriptions: make([]types.WSSubscription, 0, len(c.subscriptions)),
+	}
+	if c.token != nil {
+		info.TokenName = c.token.Name
+	}
+	for _, sub := range c.subscriptions {
+		info.Subscriptions = append(info.Subscriptions, types.WSSubscription{
+			Topic:      sub.Topic,
+			Filter:     sub.Filter,
+			IntervalMs: sub.IntervalMs,
+		})
+	}
+	sort.Slice(info.Subscriptions, func(i, j int) bool {
+		return info.Subscriptions[i].Topic < info.Subscriptions[j].Topic
+	})
+	return info
+}
+
+// subscription returns the client's subscription to a topic
+func (c *wsClient) subscription(topic string) (types.WSClientMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sub, exists := c.subscriptions[topic]
+	return sub, exists
+}
+
+// setSubscription records a subscription, replacing any previous one for the topic
+func (c *wsClient) setSubscription(sub types.WSClientMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscriptions[sub.Topic] = sub
+}
+
+// removeSubscription drops the subscription to a topic
+func (c *wsClient) removeSubscription(topic string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, exists := c.subscriptions[topic]
+	delete(c.subscriptions, topic)
+	if topic == types.TopicStats {
+		c.stopStatsLocked()
+	}
+	return exists
+}
+
+// startStats replaces the stats pusher stop channel, stopping any previous pusher
+func (c *wsClient) startStats() chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopStatsLocked()
+	c.statsStop = make(chan struct{})
+	return c.statsStop
+}
+
+// stopStats stops the stats pusher, if running
+func (c *wsClient) stopStats() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopStatsLocked()
+}
+
+// stopStatsLocked stops the stats pusher. Callers must hold c.mu.
+func (c *wsClient) stopStatsLocked() {
+	if c.statsStop != nil {
+		close(c.statsStop)
+		c.statsStop = nil
+	}
+}
+
+// handleWebSocket handles WebSocket connections for TUI communication. When
+// management auth is enabled, the token must be passed as a query parameter
+// or Authorization header, or sent as the first message.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	serverConfig := s.config.GetConfig().Server
+	auth := serverConfig.ManagementAuth
+	var token *types.AuthToken
+	if auth.Enabled() && requestToken(r) != "" {
+		if token = auth.Authenticate(requestToken(r)); token == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="webserver"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	conn, err := s.wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logging.Warnf("WebSocket upgrade error: %v", err)
+		return
+	}
+
+	queueSize := serverConfig.WSSendQueueSize
+	if queueSize == 0 {
+		queueSize = types.DefaultWSSendQueueSize
+	}
+	encoding := types.WSEncodingJSON
+	if conn.Subprotocol() == types.WSEncodingMsgpack || r.URL.Query().Get("encoding") == types.WSEncodingMsgpack {
+		encoding = types.WSEncodingMsgpack
+	}
+	client := newWSClient(conn, queueSize, serverConfig.WSSlowClientPolicy, encoding)
+	defer client.close()
+	if auth.Enabled() {
+		if token == nil {
+			if token = s.awaitWebSocketAuth(client, auth); token == nil {
+				return
+			}
+		}
+		tokenCopy := *token
+		client.setAuthToken(&tokenCopy)
+		client.send(types.MessageAuthenticated, client.authResult())
+	}
+
+	// Add connection to active connections
+	s.addWebSocketConnection(client)
+	defer s.removeWebSocketConnection(client)
+
+	logging.Infof("New WebSocket connection from %s", r.RemoteAddr)
+
+	// Send initial data
+	s.sendInitialData(client)
+
+	// Handle incoming messages
+	for {
+		frameType, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				logging.Warnf("WebSocket error: %v", err)
+			}
+			break
+		}
+		client.touch()
+
+		message, err := decodeWSClientMessage(frameType, data)
+		if err != nil {
+			client.sendError("invalid message: %v", err)
+			continue
+		}
+
+		// Handle different message types
+		s.handleWebSocketMessage(client, message)
+	}
+}
+
+// awaitWebSocketAuth waits for an unauthenticated client's auth message and
+// returns the matching token, or nil after telling the client why it is
+// being disconnected
+func (s *Server) awaitWebSocketAuth(client *wsClient, auth *types.ManagementAuthConfig) *types.AuthToken {
+	client.conn.SetReadDeadline(time.Now().Add(wsAuthTimeout))
+	defer client.conn.SetReadDeadline(time.Time{})
+
+	frameType, data, err := client.conn.ReadMessage()
+	if err != nil {
+		client.sendError("authentication required")
+		return nil
+	}
+	message, err := decodeWSClientMessage(frameType, data)
+	if err != nil {
+		client.sendError("authentication required: invalid message: %v", err)
+		return nil
+	}
+	if message.Type != types.ClientAuth {
+		client.sendError("authentication required: send an auth message first")
+		return nil
+	}
+
+	token := auth.Authenticate(message.Token)
+	if token == nil {
+		client.sendError("invalid token")
+	}
+	return token
+}
+
+// sendInitialData sends initial configuration and statistics to new WebSocket client
+func (s *Server) sendInitialData(client *wsClient) {
+	// Send current configuration
+	if config := s.config.GetConfig(); config != nil {
+		client.send(types.MessageConfig, config.WithoutSecrets())
+	}
+
+	// Send current statistics
+	client.send(types.MessageStats, s.stats.GetAllStats())
+}
+
+// handleWebSocketMessage handles incoming WebSocket messages
+func (s *Server) handleWebSocketMessage(client *wsClient, message types.WSClientMessage) {
+	if message.Type == types.ClientAuth {
+		s.reauthenticateWebSocket(client, message.Token)
+		return
+	}
+	if !client.allows(types.PermissionRead) {
+		client.sendError("permission denied: %s requires %s", message.Type, types.PermissionRead)
+		return
+	}
+
+	switch message.Type {
+	case types.ClientGetConfig:
+		client.send(types.MessageConfig, s.config.GetConfig().WithoutSecrets())
+	case types.ClientGetStats:
+		client.send(types.MessageStats, s.stats.GetAllStats())
+	case types.ClientSubscribe:
+		s.subscribeWebSocket(client, message)
+	case types.ClientResetStats, types.ClientClearLog:
+		if !client.allows(types.PermissionWrite) {
+			client.sendError("permission denied: %s requires %s", message.Type, types.PermissionWrite)
+			return
+		}
+		if message.Type == types.ClientResetStats {
+			s.ResetStats()
+		} else {
+			s.ClearRequestLog()
+		}
+	case types.ClientUnsubscribe:
+		if !client.removeSubscription(message.Topic) {
+			client.sendError("not subscribed to topic: %s", message.Topic)
+			return
+		}
+		client.send(types.MessageUnsubscribed, types.WSSubscription{Topic: message.Topic})
+	default:
+		client.sendError("unknown message type: %s", message.Type)
+	}
+}
+
+// reauthenticateWebSocket switches an open connection to another token's permissions
+func (s *Server) reauthenticateWebSocket(client *wsClient, secret string) {
+	auth := s.config.GetConfig().Server.ManagementAuth
+	if auth.Enabled() {
+		token := auth.Authenticate(secret)
+		if token == nil {
+			client.sendError("invalid token")
+			return
+		}
+		tokenCopy := *token
+		client.setAuthToken(&tokenCopy)
+	}
+	client.send(types.MessageAuthenticated, client.authResult())
+}
+
+// subscribeWebSocket subscribes a client to a topic and sends the topic's current state
+func (s *Server) subscribeWebSocket(client *wsClient, sub types.WSClientMessage) {
+	switch sub.Topic {
+	case types.TopicConfig:
+		client.setSubscription(sub)
+		client.send(types.MessageSubscribed, types.WSSubscription{Topic: sub.Topic})
+		client.send(types.MessageConfig, s.config.GetConfig().WithoutSecrets())
+
+	case types.TopicStats:
+		if sub.IntervalMs == 0 {
+			sub.IntervalMs = types.DefaultStatsIntervalMs
+		}
+		if sub.IntervalMs < types.MinStatsIntervalMs {
+			client.sendError("interval_ms must be at least %d", types.MinStatsIntervalMs)
+			return
+		}
+
+		client.setSubscription(sub)
+		client.send(types.MessageSubscribed, types.WSSubscription{Topic: sub.Topic, IntervalMs: sub.IntervalMs})
+
+		stats := s.stats.GetAllStats()
+		client.send(types.MessageStats, stats)
+		go s.pushStats(client, time.Duration(sub.IntervalMs)*time.Millisecond, stats, client.startStats())
+
+	case types.TopicRequestLog:
+		if err := validateRequestLogFilter(sub.Filter); err != nil {
+			client.sendError("invalid filter: %v", err)
+			return
+		}
+
+		client.setSubscription(sub)
+		client.send(types.MessageSubscribed, types.WSSubscription{Topic: sub.Topic, Filter: sub.Filter})
+
+		// Send the most recent matching entries, oldest first
+		if sub.Backlog > 0 {
+			backlog := make([]types.RequestLogEntry, 0)
+			for _, entry := range s.GetRequestLog() {
+				if len(backlog) >= sub.Backlog || len(backlog) >= maxRequestLogBacklog {
+					break
+				}
+				if requestLogFilterMatches(sub.Filter, entry) {
+					backlog = append(backlog, entry)
+				}
+			}
+			for i := len(backlog) - 1; i >= 0; i-- {
+				client.send(types.MessageRequestLog, backlog[i])
+			}
+		}
+
+	default:
+		client.sendError("unknown topic: %s", sub.Topic)
+	}
+}
+
+// validateRequestLogFilter checks a request_log subscription filter
+func validateRequestLogFilter(filter *types.RequestLogFilter) error {
+	if filter == nil {
+		return nil
+	}
+	if filter.Path != "" {
+		if err := config.ValidatePathPattern(filter.Path); err != nil {
+			return fmt.Errorf("path pattern '%s': %w", filter.Path, err)
+		}
+	}
+	for _, class := range filter.StatusClasses {
+		if !types.ValidStatusClass(class) {
+			return fmt.Errorf("unknown status class: %s (use 1xx to 5xx)", class)
+		}
+	}
+	return nil
+}
+
+// pushStats periodically sends a stats update holding only the endpoints that
+// changed since the previous push, until stop is closed
+func (s *Server) pushStats(client *wsClient, interval time.Duration, initial *types.ServerStats, stop <-chan struct{}) {
+	type endpointVersion struct {
+		requests int64
+		inFlight int64
+	}
+
+	versions := make(map[string]endpointVersion)
+	for path, endpoint := range initial.Endpoints {
+		versions[path] = endpointVersion{endpoint.RequestCount, endpoint.InFlight}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-client.done:
+			return
+		case <-ticker.C:
+			stats := s.stats.GetAllStats()
+			changed := make(map[string]*types.EndpointStats)
+			for path, endpoint := range stats.Endpoints {
+				version := endpointVersion{endpoint.RequestCount, endpoint.InFlight}
+				if versions[path] != version {
+					changed[path] = endpoint
+					versions[path] = version
+				}
+			}
+			stats.Endpoints = changed
+
+			if err := client.push(newWSOutgoing(types.MessageStatsUpdate, stats)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// addWebSocketConnection adds a new WebSocket connection
+func (s *Server) addWebSocketConnection(client *wsClient) {
+	s.wsConnectionsMu.Lock()
+	defer s.wsConnectionsMu.Unlock()
+	s.wsConnections[client] = true
+}
+
+// removeWebSocketConnection removes a WebSocket connection and stops its pushers
+func (s *Server) removeWebSocketConnection(client *wsClient) {
+	s.wsConnectionsMu.Lock()
+	delete(s.wsConnections, client)
+	s.wsConnectionsMu.Unlock()
+
+	client.stopStats()
+}
+
+// webSocketClients returns a snapshot of the connected clients
+func (s *Server) webSocketClients() []*wsClient {
+	s.wsConnectionsMu.RLock()
+	defer s.wsConnectionsMu.RUnlock()
+
+	clients := make([]*wsClient, 0, len(s.wsConnections))
+	for client := range s.wsConnections {
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+// handleWebSocketClients lists the connected WebSocket clients, oldest first
+func (s *Server) handleWebSocketClients(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	clients := s.webSocketClients()
+	list := types.WSClientList{
+		Count:   len(clients),
+		Clients: make([]types.WSClientInfo, 0, len(clients)),
+	}
+	for _, client := range clients {
+		list.Clients = append(list.Clients, client.info())
+	}
+	sort.Slice(list.Clients, func(i, j int) bool {
+		return list.Clients[i].ConnectedAt.Before(list.Clients[j].ConnectedAt)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// wsHeartbeatInterval returns the configured WebSocket ping interval
+func (s *Server) wsHeartbeatInterval() time.Duration {
+	seconds := types.DefaultWSHeartbeatSeconds
+	if config := s.config.GetConfig(); config != nil && config.Server.WSHeartbeatSeconds > 0 {
+		seconds = config.Server.WSHeartbeatSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// reapWebSockets pings every WebSocket client once per heartbeat interval and
+// closes connections that sent nothing (not even a pong) for two intervals,
+// until stop is closed. Clients that vanish without a close frame would
+// otherwise stay registered until a broadcast to them fails.
+func (s *Server) reapWebSockets(stop <-chan struct{}) {
+	for {
+		interval := s.wsHeartbeatInterval()
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+
+		staleBefore := time.Now().Add(-2 * interval)
+		for _, client := range s.webSocketClients() {
+			if client.lastSeenAt().Before(staleBefore) {
+				logging.Warnf("Dropping unresponsive WebSocket connection from %s", client.conn.RemoteAddr())
+				// Closing ends the client's read loop, which removes the connection
+				client.conn.Close()
+				continue
+			}
+			client.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+		}
+	}
+}
+
+// webSocketSubscribers returns the clients subscribed to a topic along with
+// their subscriptions
+func (s *Server) webSocketSubscribers(topic string) map[*wsClient]types.WSClientMessage {
+	s.wsConnectionsMu.RLock()
+	defer s.wsConnectionsMu.RUnlock()
+
+	subscribers := make(map[*wsClient]types.WSClientMessage)
+	for client := range s.wsConnections {
+		if sub, exists := client.subscription(topic); exists {
+			subscribers[client] = sub
+		}
+	}
+	return subscribers
+}
+
+// broadcastToWebSockets queues a message for all clients subscribed to a topic
+func (s *Server) broadcastToWebSockets(topic, messageType string, data interface{}) {
+	subscribers := s.webSocketSubscribers(topic)
+	clients := make([]*wsClient, 0, len(subscribers))
+	for client := range subscribers {
+		clients = append(clients, client)
+	}
+	pushToWebSockets(clients, messageType, data)
+}
+
+// broadcastToAllWebSockets queues a message for every connected client, regardless of subscriptions
+func (s *Server) broadcastToAllWebSockets(messageType string, data interface{}) {
+	pushToWebSockets(s.webSocketClients(), messageType, data)
+}
+
+// pushToWebSockets queues a message for each client, encoding it once per encoding
+func pushToWebSockets(clients []*wsClient, messageType string, data interface{}) {
+	message := newWSOutgoing(messageType, data)
+	for _, client := range clients {
+		if err := client.push(message); err == errWSSlowClient {
+			logging.Warnf("Disconnecting slow WebSocket client %s", client.conn.RemoteAddr())
+		}
+	}
+}
+
+// reauthorizeWebSockets applies a management auth change to open connections:
+// permissions are refreshed from the new token list, and connections whose
+// token was revoked (or that connected while auth was disabled) are closed
+func (s *Server) reauthorizeWebSockets(auth *types.ManagementAuthConfig) {
+	for _, client := range s.webSocketClients() {
+		if !auth.Enabled() {
+			client.setAuthToken(nil)
+			continue
+		}
+
+		var token *types.AuthToken
+		if current := client.authToken(); current != nil {
+			token = auth.Authenticate(current.Token)
+		}
+		if token == nil {
+			client.push(newWSOutgoing(types.MessageError, types.WSError{Error: "authentication required: token revoked or management auth enabled"}))
+			client.close()
+			continue
+		}
+		tokenCopy := *token
+		client.setAuthToken(&tokenCopy)
+	}
+}
+
+// broadcastRequestLogEntry queues a request log entry for subscribers whose filter matches it
+func (s *Server) broadcastRequestLogEntry(entry types.RequestLogEntry) {
+	message := newWSOutgoing(types.MessageRequestLog, entry)
+	for client, sub := range s.webSocketSubscribers(types.TopicRequestLog) {
+		if !requestLogFilterMatches(sub.Filter, entry) {
+			continue
+		}
+		if err := client.push(message); err == errWSSlowClient {
+			logging.Warnf("Disconnecting slow WebSocket client %s", client.conn.RemoteAddr())
+		}
+	}
+}
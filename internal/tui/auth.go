@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TokenEnvVar is the environment variable the management token is read from
+// when -token is not given
+const TokenEnvVar = "WEBSERVER_TOKEN"
+
+var (
+	errUnauthorized = errors.New("unauthorized: the server requires a valid management token (-token or " + TokenEnvVar + ")")
+	errForbidden    = errors.New("forbidden: the management token does not allow this request")
+)
+
+// UnauthorizedMsg reports that the server rejected the management token
+type UnauthorizedMsg struct{}
+
+// authorize adds the management token, if any, to a request
+func authorize(header http.Header, token string) {
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// authError returns the error for a 401 or 403 response, or nil
+func authError(statusCode int) error {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return errUnauthorized
+	case http.StatusForbidden:
+		return errForbidden
+	}
+	return nil
+}
+
+// newRequest creates a request to the server carrying the management token
+func (m *Model) newRequest(method, requestURL string, body io.Reader) (*http.Request, error) {
+	if m.snapshot != nil {
+		return nil, errOffline
+	}
+	req, err := http.NewRequest(method, requestURL, body)
+	if err != nil {
+		return nil, err
+	}
+	authorize(req.Header, m.token)
+	return req, nil
+}
+
+// get fetches from the server with the management token, returning
+// errUnauthorized when the server rejects it
+func (m *Model) get(requestURL string) (*http.Response, error) {
+	req, err := m.newRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		return nil, errUnauthorized
+	}
+	return resp, nil
+}
+
+// fetchError turns a failed fetch into an UnauthorizedMsg when the token was
+// rejected, and into an ErrorMsg otherwise. Offline there is nothing to fetch.
+func fetchError(action string, err error) tea.Msg {
+	if errors.Is(err, errUnauthorized) {
+		return UnauthorizedMsg{}
+	}
+	if errors.Is(err, errOffline) {
+		return nil
+	}
+	return ErrorMsg{Error: fmt.Sprintf("%s: %v", action, err)}
+}
+
+// disconnectedView explains why the tabs have nothing to show
+func (m *Model) disconnectedView() string {
+	if !m.unauthorized {
+		return "❌ Not connected to server\n\nTry pressing 'r' to refresh or check if the server is running."
+	}
+	if m.token == "" {
+		return fmt.Sprintf("🔒 Unauthorized: the server requires a management token\n\nStart the client with -token <token> or set %s.", TokenEnvVar)
+	}
+	return fmt.Sprintf("🔒 Unauthorized: the server rejected the management token\n\nCheck -token or %s, then press 'r' to retry.", TokenEnvVar)
+}
@@ -0,0 +1,225 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"webserver/pkg/types"
+)
+
+// chartSeconds is the window shown by the charts, one column per second
+const chartSeconds = 60
+
+// sparkLevels are the block characters of a sparkline, lowest first
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as one block character each, scaled to the
+// largest value. NaN values are gaps and render as spaces.
+func sparkline(values []float64) string {
+	return scaledSparkline(values, peakValue(values))
+}
+
+// scaledSparkline renders values as one block character each, with peak as
+// a full block
+func scaledSparkline(values []float64, peak float64) string {
+	var b strings.Builder
+	for _, value := range values {
+		switch {
+		case math.IsNaN(value):
+			b.WriteRune(' ')
+		case peak <= 0:
+			b.WriteRune(sparkLevels[0])
+		default:
+			level := int(math.Round(math.Min(value/peak, 1) * float64(len(sparkLevels)-1)))
+			b.WriteRune(sparkLevels[level])
+		}
+	}
+	return b.String()
+}
+
+// barChart renders values as columns height rows tall, scaled to the largest
+// value, with eighth-row resolution. Rows are returned top first.
+func barChart(values []float64, height int) []string {
+	peak := peakValue(values)
+	rows := make([]string, height)
+	for row := range rows {
+		floor := float64((height - 1 - row) * len(sparkLevels))
+		var b strings.Builder
+		for _, value := range values {
+			eighths := 0.0
+			if peak > 0 && !math.IsNaN(value) {
+				eighths = math.Round(value/peak*float64(height*len(sparkLevels))) - floor
+			}
+			switch {
+			case eighths <= 0:
+				b.WriteRune(' ')
+			case eighths >= float64(len(sparkLevels)):
+				b.WriteRune(sparkLevels[len(sparkLevels)-1])
+			default:
+				b.WriteRune(sparkLevels[int(eighths)-1])
+			}
+		}
+		rows[row] = b.String()
+	}
+	return rows
+}
+
+// peakValue returns the largest value, ignoring NaN gaps
+func peakValue(values []float64) float64 {
+	peak := 0.0
+	for _, value := range values {
+		if !math.IsNaN(value) && value > peak {
+			peak = value
+		}
+	}
+	return peak
+}
+
+// requestValues returns the request count of each point
+func requestValues(points []types.TimeSeriesPoint) []float64 {
+	values := make([]float64, len(points))
+	for i, point := range points {
+		values[i] = float64(point.Requests)
+	}
+	return values
+}
+
+// latencyValues returns the average latency of each point; seconds without
+// sampled requests are gaps
+func latencyValues(points []types.TimeSeriesPoint) []float64 {
+	values := make([]float64, len(points))
+	for i, point := range points {
+		values[i] = math.NaN()
+		if point.Requests > 0 {
+			values[i] = point.AvgTimeMs
+		}
+	}
+	return values
+}
+
+// errorRateValues returns the percentage of failed requests in each point;
+// seconds without requests are gaps
+func errorRateValues(points []types.TimeSeriesPoint) []float64 {
+	values := make([]float64, len(points))
+	for i, point := range points {
+		values[i] = math.NaN()
+		if point.Requests > 0 {
+			values[i] = float64(point.Errors) / float64(point.Requests) * 100
+		}
+	}
+	return values
+}
+
+// trafficChart renders the combined request rate as a bar chart with the
+// error rate below it, newest second on the right
+func trafficChart(points []types.TimeSeriesPoint) string {
+	if len(points) == 0 {
+		return ""
+	}
+
+	var total int64
+	for _, point := range points {
+		total += point.Requests
+	}
+	requests := requestValues(points)
+	current := points[len(points)-1]
+
+	content := fmt.Sprintf("req/s (peak %.0f)\n", peakValue(requests))
+	for _, row := range barChart(requests, 4) {
+		content += "│" + row + "\n"
+	}
+	content += "└" + strings.Repeat("─", len(points)) + "\n"
+	content += fmt.Sprintf(" %s err%% (0-100)\n", scaledSparkline(errorRateValues(points), 100))
+
+	errorRate := 0.0
+	if current.Requests > 0 {
+		errorRate = float64(current.Errors) / float64(current.Requests) * 100
+	}
+	content += fmt.Sprintf("• Now: %d req/s, %.1f%% errors\n", current.Requests, errorRate)
+	content += fmt.Sprintf("• Average: %.1f req/s over %ds\n", float64(total)/float64(len(points)), len(points))
+	return content
+}
+
+// endpointCharts renders the request rate and latency sparklines of an endpoint
+func endpointCharts(points []types.TimeSeriesPoint) string {
+	var peakRequests, peakLatency int64
+	for _, point := range points {
+		if point.Requests > peakRequests {
+			peakRequests = point.Requests
+		}
+		if point.MaxTimeMs > peakLatency {
+			peakLatency = point.MaxTimeMs
+		}
+	}
+
+	content := fmt.Sprintf("Last %ds (1 column = 1s):\n", len(points))
+	content += fmt.Sprintf("  req/s   %s peak %d\n", sparkline(requestValues(points)), peakRequests)
+	content += fmt.Sprintf("  latency %s max %dms\n", sparkline(latencyValues(points)), peakLatency)
+	return content
+}
+
+// heatLevels shade heatmap cells by their share of a column's requests,
+// lowest first
+var heatLevels = []rune(" ░▒▓█")
+
+// latencyBucketLabel names a latency histogram bucket by its upper bound,
+// e.g. "≤250ms", or ">2.5s" for the last bucket, which has none
+func latencyBucketLabel(boundsMs []int64, bucket int) string {
+	format := func(ms int64) string {
+		if ms < 1000 {
+			return fmt.Sprintf("%dms", ms)
+		}
+		return strconv.FormatFloat(float64(ms)/1000, 'f', -1, 64) + "s"
+	}
+	if bucket < len(boundsMs) {
+		return "≤" + format(boundsMs[bucket])
+	}
+	return ">" + format(boundsMs[len(boundsMs)-1])
+}
+
+// latencyHeatmap renders the latency histogram of each point as a column,
+// slowest bucket on top and newest second on the right. Each cell is shaded
+// by its share of the column's sampled requests, so shifts in the
+// distribution stand out even while the request rate changes. Only the
+// buckets from the fastest to the slowest one used are shown; nothing is
+// rendered without sampled requests.
+func latencyHeatmap(points []types.TimeSeriesPoint, boundsMs []int64) string {
+	buckets := len(boundsMs) + 1
+	lowest, highest := buckets, -1
+	totals := make([]int64, len(points))
+	for i, point := range points {
+		for bucket, count := range point.LatencyBuckets {
+			if count == 0 || bucket >= buckets {
+				continue
+			}
+			totals[i] += count
+			if bucket < lowest {
+				lowest = bucket
+			}
+			highest = max(highest, bucket)
+		}
+	}
+	if highest < 0 {
+		return ""
+	}
+
+	content := fmt.Sprintf("Latency heatmap, last %ds (shade = share of each second's requests):\n", len(points))
+	for bucket := highest; bucket >= lowest; bucket-- {
+		var row strings.Builder
+		for i, point := range points {
+			if totals[i] == 0 || bucket >= len(point.LatencyBuckets) {
+				row.WriteRune(heatLevels[0])
+				continue
+			}
+			share := float64(point.LatencyBuckets[bucket]) / float64(totals[i])
+			// Any request in the bucket shows, however small its share
+			level := int(math.Ceil(share * float64(len(heatLevels)-1)))
+			row.WriteRune(heatLevels[level])
+		}
+		content += fmt.Sprintf("  %7s │%s\n", latencyBucketLabel(boundsMs, bucket), row.String())
+	}
+	content += fmt.Sprintf("  %7s └%s\n", "", strings.Repeat("─", len(points)))
+	return content
+}
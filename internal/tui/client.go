@@ -21,28 +21,85 @@ type Model struct {
 	serverURL string
 	httpURL   string
 	connected bool
+	live      *liveConn // Push stream; nil while falling back to HTTP polling
+
+	// Server build, fetched on connecting; nil until then or when the server
+	// predates GET /version
+	serverVersion  *types.VersionInfo
+	versionFetched bool
+
+	// Monitored servers; the model shows servers[serverIndex]
+	servers         []string
+	serverIndex     int
+	summaries       map[string]serverSummary // Latest statistics of the other servers
+	addServerMode   bool                     // whether a server URL is being typed
+	addServerBuffer string
 
 	// Application state
 	config     *types.Config
 	stats      *types.ServerStats
 	requestLog []types.RequestLogEntry
+	wsClients  *types.WSClientList // nil until fetched
+	timeSeries *types.TimeSeries   // Recent per-second traffic for the charts; nil until fetched
 
 	// UI state
-	activeTab int
-	width     int
-	height    int
+	activeTab    int
+	width        int
+	height       int
+	confirmReset *serverReset // data waiting for "y" to wipe it on the server
+	helpOverlay  bool         // whether the "?" key overlay covers the active tab
+	paneLayout   paneLayout   // tabs shown at once on big enough terminals
+
+	// Polling and Request Log column preferences from the client config
+	refreshInterval  time.Duration // how often data is polled
+	lowBandwidth     bool          // whether polls fetch only changes, and charts only while shown
+	logPosition      uint64        // X-Request-Log-Position of the last request log fetch; 0 when unknown
+	polls            int           // polls since connecting, for the low-bandwidth resync
+	hideDateColumn   bool
+	hideRemoteColumn bool
 
 	// Scrolling state
-	scrollPositions []int // scroll position for each tab
-	contentHeights  []int // content height for each tab
-	viewportHeight  int   // available height for content
+	scrollPositions []int      // scroll position for each tab
+	contentHeights  []int      // content height for each tab
+	tabLines        [][]string // content lines of each tab as last rendered
+	viewportHeight  int        // available height for content
+
+	// "/" search state
+	searchMode   bool   // whether the search prompt is open
+	searchBuffer string // text typed at the prompt
+	searchQuery  string // text highlighted and jumped between with n/N
+	searchOrigin int    // scroll position restored when the search is cancelled
+	searchLine   int    // content line of the current match
 
 	// Request log filtering state
-	filterMode        bool      // whether we're in filter input mode
-	filterText        string    // current filter text
-	filterBuffer      string    // typing buffer for debouncing
-	hideStatsRequests bool      // toggle to hide /stats requests
-	lastFilterUpdate  time.Time // for debouncing
+	filterMode        bool           // whether we're in filter input mode
+	filterText        string         // current filter text
+	filterBuffer      string         // typing buffer for debouncing
+	hideStatsRequests bool           // toggle to hide /stats requests
+	statusClasses     [6]bool        // status classes (2-5 for 2xx-5xx) to show exclusively; none shows all
+	groupByRoute      bool           // toggle to group entries by normalized route
+	logSort           requestLogSort // column the entries are ordered by
+	logSortAscending  bool           // whether the smallest values come first
+	lastFilterUpdate  time.Time      // for debouncing
+
+	// Request log rows as last rendered, used to pick the entry under the cursor
+	requestLogRows []requestLogRow
+	selectedLogID  uint64                 // entry under the cursor; 0 selects the first row in the viewport
+	detailEntry    *types.RequestLogEntry // entry shown in the detail pane
+	replayResult   *types.ReplayResult    // result shown in the replay pane, over the detail pane if open
+	replayingID    uint64                 // entry whose replay is in flight
+	detailScroll   int                    // request log scroll position restored when the panes close
+	bodyFoldDepth  int                    // nesting depth from which the pane's JSON body is folded; 0 shows everything
+	bodyScrollX    int                    // columns the pane's body is scrolled sideways
+	curlEntry      *types.RequestLogEntry // entry whose curl command is shown
+	diffBase       *types.RequestLogEntry // entry marked as the base of a diff
+	requestDiff    *types.RequestLogDiff  // diff of diffBase against another entry
+
+	// Statistics tab endpoint selection
+	statsEndpointLines map[string]int // line of each endpoint's statistics as last rendered
+	statsSelected      string         // endpoint under the cursor; empty selects the first
+	drillDownPath      string         // endpoint shown in the drill-down view
+	drillDownScroll    int            // statistics scroll position restored when the view closes
 
 	// Configuration filtering state
 	configFilterMode       bool      // whether we're in config filter input mode
@@ -50,11 +107,37 @@ type Model struct {
 	configFilterBuffer     string    // typing buffer for debouncing
 	lastConfigFilterUpdate time.Time // for debouncing
 
+	// Endpoint editor state (Configuration tab edit mode); nil when not editing
+	editor *endpointEditor
+
+	// Raw configuration being edited in $EDITOR; nil when not editing
+	rawConfig *rawConfigEdit
+
+	// Scenarios on the server, and the picker starting them; nil when closed
+	scenarios      *types.ScenarioList
+	scenarioPicker *scenarioPicker
+
+	// Outcome of the server's latest configuration reload; nil until known
+	configStatus *types.ConfigStatus
+
 	// Auto-refresh state
 	autoRefresh  bool // whether auto-refresh is enabled
 	manualScroll bool // whether user has manually scrolled
+	follow       bool // whether the Request Log stays scrolled to the newest entry
+
+	// Pause state: while paused, data messages are queued in pausedMsgs and
+	// applied on resume
+	paused     bool
+	pausedMsgs []tea.Msg
+
+	// Screen positions of clickable elements, as last rendered
+	layout mouseLayout
+
+	// Key bindings
+	keys KeyMap
 
 	// Styles
+	theme          *Theme
 	tabStyle       lipgloss.Style
 	activeTabStyle lipgloss.Style
 	contentStyle   lipgloss.Style
@@ -62,7 +145,13 @@ type Model struct {
 	filterStyle    lipgloss.Style
 
 	// Error state
-	lastError string
+	lastError    string
+	token        string    // management token sent with every request; empty when auth is off
+	unauthorized bool      // the server rejected the token; cleared on reconnecting
+	snapshot     *Snapshot // offline snapshot being browsed; nil when monitoring a server
+
+	// Notice shown under the status line, e.g. where an export was written
+	notice string
 }
 
 // Tab represents a tab in the TUI
@@ -81,17 +170,15 @@ var tabs = []Tab{
 
 // NewModel creates a new TUI model
 func NewModel(serverURL string) *Model {
-	// Convert WebSocket URL to HTTP URL
-	httpURL := strings.Replace(serverURL, "ws://", "http://", 1)
-	httpURL = strings.Replace(httpURL, "wss://", "https://", 1)
-	httpURL = strings.Replace(httpURL, "/ws", "", 1)
-
-	return &Model{
+	m := &Model{
 		serverURL:              serverURL,
-		httpURL:                httpURL,
+		httpURL:                httpURLFor(serverURL),
+		servers:                []string{serverURL},
+		summaries:              make(map[string]serverSummary),
 		requestLog:             make([]types.RequestLogEntry, 0),
 		scrollPositions:        make([]int, len(tabs)),
 		contentHeights:         make([]int, len(tabs)),
+		tabLines:               make([][]string, len(tabs)),
 		viewportHeight:         20, // Default height, will be updated
 		filterMode:             false,
 		filterText:             "",
@@ -103,45 +190,38 @@ func NewModel(serverURL string) *Model {
 		configFilterBuffer:     "",
 		lastConfigFilterUpdate: time.Now(),
 		autoRefresh:            true, // Auto-refresh is enabled by default
+		refreshInterval:        time.Second,
 		manualScroll:           false,
-		tabStyle: lipgloss.NewStyle().
-			Padding(0, 1).
-			Background(lipgloss.Color("#3C3C3C")).
-			Foreground(lipgloss.Color("#FFFFFF")),
-		activeTabStyle: lipgloss.NewStyle().
-			Padding(0, 1).
-			Background(lipgloss.Color("#7C7C7C")).
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Bold(true),
-		contentStyle: lipgloss.NewStyle().
-			Padding(1, 2).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#7C7C7C")),
-		headerStyle: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#5F5F5F")).
-			Padding(0, 1).
-			Bold(true),
-		filterStyle: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFF00")).
-			Background(lipgloss.Color("#333333")).
-			Padding(0, 1).
-			Bold(true),
+		keys:                   DefaultKeyMap(),
 	}
+	theme, _ := resolveTheme(DefaultThemeName, nil)
+	m.setTheme(theme)
+	return m
 }
 
 // Init initializes the TUI model
 func (m *Model) Init() tea.Cmd {
+	if m.snapshot != nil {
+		// Nothing to connect to or poll
+		return tea.Batch(
+			tea.EnterAltScreen,
+			tea.Tick(time.Millisecond*200, func(time.Time) tea.Msg { return FilterDebounceMsg{} }),
+		)
+	}
 	return tea.Batch(
 		m.connectToServer,
 		tea.EnterAltScreen,
-		tea.Tick(time.Second*1, func(time.Time) tea.Msg { return RefreshMsg{} }),               // Update every 1 second
+		m.refreshTick(), // Poll until pushed updates arrive
 		tea.Tick(time.Millisecond*200, func(time.Time) tea.Msg { return FilterDebounceMsg{} }), // Debounce timer
 	)
 }
 
 // Update handles TUI updates
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.paused && m.bufferWhilePaused(msg) {
+		return m, nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -153,7 +233,52 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
+
 	case tea.KeyMsg:
+		// Handle server URL input
+		if m.addServerMode {
+			switch msg.String() {
+			case "enter":
+				m.addServerMode = false
+				return m.addServer(m.addServerBuffer)
+			case "esc":
+				m.addServerMode = false
+				return m, nil
+			case "backspace":
+				if len(m.addServerBuffer) > 0 {
+					m.addServerBuffer = m.addServerBuffer[:len(m.addServerBuffer)-1]
+				}
+				return m, nil
+			case "ctrl+c":
+				return m, tea.Quit
+			default:
+				if msg.Type == tea.KeyRunes {
+					m.addServerBuffer += string(msg.Runes)
+				}
+				return m, nil
+			}
+		}
+
+		// Handle search prompt input
+		if m.confirmReset != nil {
+			return m.handleResetConfirmKey(msg)
+		}
+
+		// Any key closes the help overlay
+		if m.helpOverlay {
+			m.helpOverlay = false
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
+		if m.searchMode {
+			return m.handleSearchKey(msg)
+		}
+
 		// Handle filter mode input
 		if m.filterMode && m.activeTab == 3 { // Request Log tab
 			switch msg.String() {
@@ -198,8 +323,26 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		// Normal mode key handling
-		switch msg.String() {
+		// Handle input while an edited raw configuration is pending
+		if m.rawConfig != nil && m.activeTab == 1 { // Configuration tab
+			if cmd, handled := m.handleRawConfigKey(msg); handled {
+				return m, cmd
+			}
+		}
+
+		// Handle endpoint editor input
+		if m.editor != nil && m.activeTab == 1 { // Configuration tab
+			return m.handleEditorKey(msg)
+		}
+
+		// Handle scenario picker input
+		if m.scenarioPicker != nil && m.activeTab == 1 { // Configuration tab
+			return m.handleScenarioPickerKey(msg)
+		}
+
+		// Normal mode key handling, by the default key of the action pressed
+		key := m.keys.resolve(msg.String())
+		switch key {
 		case "ctrl+c", "q":
 			return m, tea.Quit
 		case "tab":
@@ -208,18 +351,90 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "shift+tab":
 			m.activeTab = (m.activeTab - 1 + len(tabs)) % len(tabs)
 			return m, nil
+		case "[", "]":
+			// Switch to the previous or next monitored server
+			if len(m.servers) > 1 {
+				step := 1
+				if key == "[" {
+					step = -1
+				}
+				return m.switchServer((m.serverIndex + step + len(m.servers)) % len(m.servers))
+			}
+			return m, nil
+		case "/":
+			// Search the active tab's content
+			m.startSearch()
+			return m, nil
+		case "n", "N":
+			// Jump to the next or previous search match
+			if m.searchQuery != "" {
+				direction := 1
+				if key == "N" {
+					direction = -1
+				}
+				m.jumpToMatch(direction)
+			}
+			return m, nil
+		case " ":
+			// Pause or resume the data shown on every tab
+			return m, m.togglePause()
+		case "t":
+			// Switch to the next built-in color theme
+			m.cycleTheme()
+			return m, nil
+		case "|":
+			// Switch between one tab and the split layouts
+			return m, m.cycleLayout()
+		case "<":
+			// Poll more often
+			return m, m.stepRefreshInterval(-1)
+		case ">":
+			// Poll less often
+			return m, m.stepRefreshInterval(1)
+		case "b":
+			// Fetch only changes on each poll
+			return m, m.toggleLowBandwidth()
+		case "?":
+			// Show the active tab's keys over it
+			m.helpOverlay = true
+			return m, nil
+		case "R":
+			// Reset the server's statistics, once confirmed
+			m.confirmReset = resetStats
+			return m, nil
+		case "C":
+			// Clear the server's request log, once confirmed
+			m.confirmReset = clearRequests
+			return m, nil
+		case "+":
+			// Start monitoring another server
+			if m.snapshot != nil {
+				return m, m.setNotice("Adding servers is " + errOffline.Error())
+			}
+			m.addServerMode = true
+			m.addServerBuffer = ""
+			return m, nil
 		case "up", "k":
+			// Move the request log cursor, scrolling with it
+			if m.activeTab == 3 && !m.requestPaneOpen() && m.moveLogCursor(-1) {
+				m.pauseRequestLog()
+				return m, nil
+			}
 			// Scroll up
 			if m.scrollPositions[m.activeTab] > 0 {
 				m.scrollPositions[m.activeTab]--
 				// Disable auto-refresh when user scrolls in Request Log tab
 				if m.activeTab == 3 { // Request Log tab
-					m.manualScroll = true
-					m.autoRefresh = false
+					m.pauseRequestLog()
 				}
 			}
 			return m, nil
 		case "down", "j":
+			// Move the request log cursor, scrolling with it
+			if m.activeTab == 3 && !m.requestPaneOpen() && m.moveLogCursor(1) {
+				m.pauseRequestLog()
+				return m, nil
+			}
 			// Scroll down
 			maxScroll := m.contentHeights[m.activeTab] - m.viewportHeight
 			if maxScroll < 0 {
@@ -229,8 +444,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.scrollPositions[m.activeTab]++
 				// Disable auto-refresh when user scrolls in Request Log tab
 				if m.activeTab == 3 { // Request Log tab
-					m.manualScroll = true
-					m.autoRefresh = false
+					m.pauseRequestLog()
 				}
 			}
 			return m, nil
@@ -240,10 +454,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.scrollPositions[m.activeTab] < 0 {
 				m.scrollPositions[m.activeTab] = 0
 			}
-			// Disable auto-refresh when user scrolls in Request Log tab
+			// Disable auto-refresh when user scrolls in Request Log tab; the
+			// cursor returns to the first row in the viewport
 			if m.activeTab == 3 { // Request Log tab
-				m.manualScroll = true
-				m.autoRefresh = false
+				m.pauseRequestLog()
+				m.selectedLogID = 0
 			}
 			return m, nil
 		case "pgdown", "d":
@@ -256,19 +471,21 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.scrollPositions[m.activeTab] > maxScroll {
 				m.scrollPositions[m.activeTab] = maxScroll
 			}
-			// Disable auto-refresh when user scrolls in Request Log tab
+			// Disable auto-refresh when user scrolls in Request Log tab; the
+			// cursor returns to the first row in the viewport
 			if m.activeTab == 3 { // Request Log tab
-				m.manualScroll = true
-				m.autoRefresh = false
+				m.pauseRequestLog()
+				m.selectedLogID = 0
 			}
 			return m, nil
 		case "home", "g":
 			// Go to top
 			m.scrollPositions[m.activeTab] = 0
-			// Disable auto-refresh when user scrolls in Request Log tab
+			// Disable auto-refresh when user scrolls in Request Log tab; the
+			// cursor returns to the first row in the viewport
 			if m.activeTab == 3 { // Request Log tab
-				m.manualScroll = true
-				m.autoRefresh = false
+				m.pauseRequestLog()
+				m.selectedLogID = 0
 			}
 			return m, nil
 		case "end", "G":
@@ -278,10 +495,11 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				maxScroll = 0
 			}
 			m.scrollPositions[m.activeTab] = maxScroll
-			// Disable auto-refresh when user scrolls in Request Log tab
+			// Disable auto-refresh when user scrolls in Request Log tab; the
+			// cursor returns to the first row in the viewport
 			if m.activeTab == 3 { // Request Log tab
-				m.manualScroll = true
-				m.autoRefresh = false
+				m.pauseRequestLog()
+				m.selectedLogID = 0
 			}
 			return m, nil
 		case "r":
@@ -290,17 +508,29 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.activeTab == 3 { // Request Log tab
 				// No-op, log generation is removed
 			}
-			return m, tea.Batch(m.fetchConfig, m.fetchStats, m.fetchRequestLog)
+			if !m.connected {
+				return m, m.connectToServer
+			}
+			return m, tea.Batch(m.fetchConfig, m.fetchConfigStatus, m.fetchStats, m.fetchRequestLog, m.fetchWebSocketClients, m.fetchTimeSeries)
 		case "a":
 			// Toggle auto-refresh (only in Request Log tab)
 			if m.activeTab == 3 {
 				m.autoRefresh = !m.autoRefresh
+				m.follow = m.follow && m.autoRefresh
 				if m.autoRefresh {
 					// When re-enabling auto-refresh, reset manual scroll flag
 					m.manualScroll = false
+					// Pushed entries were ignored while paused; catch up
+					return m, m.fetchRequestLog
 				}
 			}
 			return m, nil
+		case "l":
+			// Toggle following the newest entry (only in Request Log tab)
+			if m.activeTab == 3 {
+				return m, m.toggleFollow()
+			}
+			return m, nil
 		case "f":
 			// Toggle filter mode (Request Log and Configuration tabs)
 			if m.activeTab == 3 { // Request Log tab
@@ -321,11 +551,172 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.hideStatsRequests = !m.hideStatsRequests
 			}
 			return m, nil
+		case "y", "Y":
+			// Copy the selected entry as JSON (y) or a curl command (Y) (only in Request Log tab)
+			if m.activeTab == 3 {
+				return m, m.yankEntry(key == "Y")
+			}
+			return m, nil
+		case "w", "W":
+			// Export the filtered request log or the statistics as JSON (w) or CSV (W)
+			format := "json"
+			if key == "W" {
+				format = "csv"
+			}
+			return m, m.exportView(format)
+		case "2", "3", "4", "5":
+			// Toggle showing only a status class (only in Request Log tab)
+			if m.activeTab == 3 {
+				class := int(key[0] - '0')
+				m.statusClasses[class] = !m.statusClasses[class]
+				m.scrollPositions[3] = 0
+			}
+			return m, nil
+		case "m":
+			// Mark the selected entry, then diff it against the next one picked (only in Request Log tab)
+			if m.activeTab == 3 {
+				entry, ok := m.selectedLogEntry()
+				switch {
+				case m.requestDiff != nil:
+					m.requestDiff = nil
+					m.diffBase = nil
+				case !ok:
+				case m.diffBase == nil:
+					m.diffBase = &entry
+				default:
+					m.requestDiff = types.DiffRequestLogEntries(*m.diffBase, entry)
+				}
+			}
+			return m, nil
+		case "e":
+			// Enter endpoint edit mode (only in Configuration tab)
+			if m.activeTab == 1 && m.config != nil {
+				m.editor = &endpointEditor{}
+				m.scrollPositions[1] = 0
+			}
+			return m, nil
+		case "S":
+			// Start or stop scenarios (only in Configuration tab)
+			if m.activeTab == 1 && m.config != nil {
+				m.scenarioPicker = &scenarioPicker{}
+				m.scrollPositions[1] = 0
+				return m, m.fetchScenarios
+			}
+			return m, nil
+		case "o":
+			// Edit the full configuration in $EDITOR (only in Configuration tab)
+			if m.activeTab == 1 && m.config != nil {
+				return m, m.openRawConfigEditor()
+			}
+			// Order by the next column (only in Request Log tab)
+			if m.activeTab == 3 {
+				m.cycleLogSort()
+			}
+			return m, nil
+		case "O":
+			// Reverse the ordering (only in Request Log tab)
+			if m.activeTab == 3 {
+				m.reverseLogSort()
+			}
+			return m, nil
+		case "left", "right":
+			// Scroll the open pane's body sideways (only in Request Log tab)
+			if m.activeTab == 3 && m.requestPaneOpen() {
+				delta := 8
+				if key == "left" {
+					delta = -8
+				}
+				m.scrollBody(delta)
+				return m, nil
+			}
+			// Select the previous or next endpoint (only in Statistics tab)
+			if m.activeTab == 2 {
+				delta := 1
+				if key == "left" {
+					delta = -1
+				}
+				m.moveStatsCursor(delta)
+			}
+			return m, nil
+		case "enter":
+			// Open or close the selected endpoint's drill-down view (Statistics tab)
+			if m.activeTab == 2 {
+				if m.drillDownPath != "" {
+					m.closeDrillDown()
+				} else {
+					m.openDrillDown()
+				}
+				return m, nil
+			}
+			// Open or close the detail pane of the selected entry (only in Request Log tab)
+			if m.activeTab == 3 {
+				if m.requestPaneOpen() {
+					m.closeRequestPane()
+				} else if entry, ok := m.selectedLogEntry(); ok {
+					m.openRequestPane()
+					m.detailEntry = &entry
+				}
+			}
+			return m, nil
+		case "esc":
+			// Clear search highlights before closing views
+			if m.searchQuery != "" {
+				m.searchQuery = ""
+				return m, nil
+			}
+			if m.activeTab == 2 && m.drillDownPath != "" {
+				m.closeDrillDown()
+			}
+			if m.activeTab == 3 && m.requestPaneOpen() {
+				m.closeRequestPane()
+			}
+			return m, nil
+		case "z", "Z":
+			// Fold or unfold the open pane's JSON body (only in Request Log tab)
+			if m.activeTab == 3 && m.requestPaneOpen() {
+				step := 1
+				if key == "Z" {
+					step = -1
+				}
+				m.foldBody(step)
+			}
+			return m, nil
+		case "p":
+			// Replay the selected entry through the server (only in Request Log tab)
+			if m.activeTab == 3 && m.replayingID == 0 {
+				entry, ok := m.selectedLogEntry()
+				if m.replayResult != nil {
+					entry, ok = m.replayResult.Original, true
+				}
+				if ok {
+					m.replayingID = entry.ID
+					return m, m.replayRequest(entry.ID)
+				}
+			}
+			return m, nil
+		case "v":
+			// Toggle grouping by normalized route (only in Request Log tab)
+			if m.activeTab == 3 {
+				m.groupByRoute = !m.groupByRoute
+				m.scrollPositions[3] = 0
+			}
+			return m, nil
+		case "x":
+			// Toggle the curl command for the selected entry (only in Request Log tab)
+			if m.activeTab == 3 {
+				if m.curlEntry != nil {
+					m.curlEntry = nil
+				} else if entry, ok := m.selectedLogEntry(); ok {
+					m.curlEntry = &entry
+				}
+			}
+			return m, nil
 		case "c":
 			// Clear filters
 			if m.activeTab == 3 { // Request Log tab
 				m.filterText = ""
 				m.filterBuffer = ""
+				m.statusClasses = [6]bool{}
 			} else if m.activeTab == 1 { // Configuration tab
 				m.configFilterText = ""
 				m.configFilterBuffer = ""
@@ -335,8 +726,53 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case ConnectedMsg:
 		m.connected = true
+		m.unauthorized = false
+		m.lastError = ""
+		// The request log arrives as the push stream's backlog, or is polled if that fails
+		return m, tea.Batch(m.fetchConfig, m.fetchConfigStatus, m.fetchStats, m.fetchWebSocketClients, m.fetchTimeSeries, m.fetchVersion, m.connectWebSocket)
+
+	case VersionMsg:
+		m.serverVersion = msg.Info
+		m.versionFetched = true
+		return m, nil
+
+	case LiveConnectedMsg:
+		m.closeLive()
+		m.live = msg.Conn
 		m.lastError = ""
-		return m, tea.Batch(m.fetchConfig, m.fetchStats, m.fetchRequestLog)
+		return m, m.live.next
+
+	case LiveFailedMsg:
+		m.lastError = fmt.Sprintf("Live updates unavailable, polling instead: %s", msg.Error)
+		return m, tea.Batch(
+			m.fetchRequestLog,
+			tea.Tick(liveRetryInterval, func(time.Time) tea.Msg { return LiveRetryMsg{} }),
+		)
+
+	case LiveDisconnectedMsg:
+		if msg.Conn != m.live {
+			return m, nil
+		}
+		m.closeLive()
+		m.lastError = fmt.Sprintf("Live updates lost, polling instead: %s", msg.Error)
+		return m, tea.Tick(liveRetryInterval, func(time.Time) tea.Msg { return LiveRetryMsg{} })
+
+	case LiveRetryMsg:
+		if m.connected && m.live == nil {
+			return m, m.connectWebSocket
+		}
+		return m, nil
+
+	case LiveEventMsg:
+		// Ignore events still queued from a replaced connection
+		if msg.Conn != m.live {
+			return m, nil
+		}
+		model, cmd := m.Update(msg.Msg)
+		return model, tea.Batch(cmd, m.live.next)
+
+	case LiveDroppedMsg:
+		return m, m.fetchRequestLog
 
 	case DisconnectedMsg:
 		m.connected = false
@@ -350,11 +786,31 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case RefreshMsg:
+		if m.connected && m.live != nil {
+			// Config, stats and the request log are pushed; only the client list,
+			// the chart series and the other servers are polled
+			cmds := m.fetchServerSummaries()
+			if m.showsCharts() {
+				cmds = append(cmds, m.fetchWebSocketClients, m.fetchTimeSeries)
+			}
+			cmds = append(cmds, m.refreshTick())
+			return m, tea.Batch(cmds...)
+		}
+		if m.connected {
+			m.polls++
+		}
+		if m.connected && m.lowBandwidth && m.polls%lowBandwidthResyncPolls != 0 {
+			cmds := append(m.fetchChanges(), m.refreshTick())
+			return m, tea.Batch(cmds...)
+		}
 		if m.connected {
-			// Always fetch config and stats
+			// Always fetch config, stats, connected clients and the chart series
 			cmds := []tea.Cmd{
 				m.fetchConfig,
+				m.fetchConfigStatus,
 				m.fetchStats,
+				m.fetchWebSocketClients,
+				m.fetchTimeSeries,
 			}
 
 			// Only fetch request log if auto-refresh is enabled
@@ -362,12 +818,15 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds = append(cmds, m.fetchRequestLog)
 			}
 
+			cmds = append(cmds, m.fetchServerSummaries()...)
+
 			// Continue the refresh cycle
-			cmds = append(cmds, tea.Tick(time.Second*1, func(time.Time) tea.Msg { return RefreshMsg{} }))
+			cmds = append(cmds, m.refreshTick())
 
 			return m, tea.Batch(cmds...)
 		}
-		return m, tea.Tick(time.Second*1, func(time.Time) tea.Msg { return RefreshMsg{} })
+		cmds := append(m.fetchServerSummaries(), m.refreshTick())
+		return m, tea.Batch(cmds...)
 
 	case FilterDebounceMsg:
 		// Apply filters after debounce period
@@ -386,14 +845,32 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case ConfigMsg:
 		m.config = msg.Config
+		if len(m.config.Scenarios) > 0 || m.scenarios != nil {
+			return m, m.fetchScenarios
+		}
 		return m, nil
 
+	case ScenariosMsg:
+		m.scenarios = msg.List
+		return m, nil
+
+	case ConfigStatusMsg:
+		m.configStatus = msg.Status
+		return m, nil
+
+	case ScenarioChangedMsg:
+		if m.scenarioPicker != nil {
+			m.scenarioPicker.status = msg.Message
+		}
+		return m, m.fetchScenarios
+
 	case StatsMsg:
 		m.stats = msg.Stats
 		return m, nil
 
 	case RequestLogMsg:
 		m.requestLog = msg.Entries
+		m.logPosition = msg.Position
 		// Sort by timestamp (newest first)
 		sort.Slice(m.requestLog, func(i, j int) bool {
 			return m.requestLog[i].Timestamp.After(m.requestLog[j].Timestamp)
@@ -402,9 +879,103 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// No-op, log generation is removed
 		return m, nil
 
+	case StatsUpdateMsg:
+		m.stats = types.MergeStatsUpdate(m.stats, msg.Stats)
+		return m, nil
+
+	case RequestLogChangesMsg:
+		if !m.autoRefresh {
+			return m, nil
+		}
+		m.applyRequestLogChanges(msg)
+		return m, nil
+
+	case RequestLogEntryMsg:
+		// While auto-refresh is paused the view stays frozen; re-enabling it refetches the log
+		if !m.autoRefresh {
+			return m, nil
+		}
+		m.requestLog = append([]types.RequestLogEntry{msg.Entry}, m.requestLog...)
+		if limit := m.requestLogLimit(); len(m.requestLog) > limit {
+			m.requestLog = m.requestLog[:limit]
+		}
+		return m, nil
+
+	case RawConfigEditedMsg:
+		return m, m.handleRawConfigEdited(msg)
+
+	case RawConfigResultMsg:
+		if m.rawConfig == nil {
+			return m, nil
+		}
+		if msg.Error != "" {
+			m.rawConfig.applying = false
+			m.rawConfig.err, m.rawConfig.errLine = msg.Error, 0
+			return m, nil
+		}
+		m.rawConfig = nil
+		return m, m.fetchConfig
+
+	case ReplayResultMsg:
+		m.replayingID = 0
+		m.openRequestPane()
+		m.replayResult = &msg.Result
+		return m, nil
+
+	case ReplayErrorMsg:
+		m.replayingID = 0
+		m.lastError = msg.Error
+		return m, nil
+
+	case EndpointSavedMsg:
+		if m.editor != nil {
+			m.editor.form = nil
+			m.editor.status = msg.Message
+		}
+		return m, m.fetchConfig
+
+	case EndpointEditErrorMsg:
+		if m.editor != nil && m.editor.form != nil {
+			m.editor.form.submitting = false
+			m.editor.form.err = msg.Error
+		} else if m.editor != nil {
+			m.editor.status = "Error: " + msg.Error
+		}
+		return m, nil
+
+	case WSClientsMsg:
+		m.wsClients = msg.Clients
+		return m, nil
+
+	case TimeSeriesMsg:
+		m.timeSeries = msg.Series
+		return m, nil
+
+	case ServerSummaryMsg:
+		m.summaries[msg.Server] = serverSummary{stats: msg.Stats, err: msg.Error}
+		return m, nil
+
+	case UnauthorizedMsg:
+		// Stay disconnected until the server accepts the token again ('r' retries)
+		m.connected = false
+		m.unauthorized = true
+		m.closeLive()
+		m.lastError = errUnauthorized.Error()
+		return m, nil
+
 	case ErrorMsg:
 		m.lastError = msg.Error
 		return m, nil
+
+	case NoticeMsg:
+		return m, m.setNotice(msg.Notice)
+
+	case NoticeExpiredMsg:
+		// A newer notice stays for its own duration
+		if m.notice == msg.Notice {
+			m.notice = ""
+		}
+		return m, nil
 	}
 
 	return m, nil
@@ -420,31 +991,71 @@ func (m *Model) View() string {
 	header := m.headerStyle.Width(m.width).Render("WebServer Monitor")
 
 	// Connection status
+	serverPosition := ""
+	if len(m.servers) > 1 {
+		serverPosition = fmt.Sprintf(" [%d/%d]", m.serverIndex+1, len(m.servers))
+	}
 	connectionStatus := "❌ Disconnected"
-	if m.connected {
-		connectionStatus = "✅ Connected"
+	if m.unauthorized {
+		connectionStatus = "🔒 Unauthorized"
+	}
+	if m.connected && m.live != nil {
+		connectionStatus = "✅ Connected (live)"
+	} else if m.connected {
+		connectionStatus = fmt.Sprintf("✅ Connected (polling every %s)", m.refreshInterval)
+	}
+	if m.connected && m.lowBandwidth {
+		connectionStatus += " | 📉 Low bandwidth"
 	}
 
+	status := fmt.Sprintf("Server: %s%s%s | Status: %s", m.httpURL, m.versionLabel(), serverPosition, connectionStatus)
+	if m.snapshot != nil {
+		status = m.snapshotStatus()
+	}
 	statusLine := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888")).
-		Render(fmt.Sprintf("Server: %s | Status: %s", m.httpURL, connectionStatus))
+		Foreground(lipgloss.Color(m.theme.Muted)).
+		Render(status)
+	if m.searchQuery != "" && !m.searchMode {
+		statusLine += lipgloss.NewStyle().
+			Foreground(lipgloss.Color(m.theme.Muted)).
+			Render(fmt.Sprintf(" | 🔎 %s (n/N: next/previous, Esc: clear)", m.searchStatus()))
+	}
+	if warning := m.protocolWarning(); warning != "" && m.snapshot == nil {
+		statusLine += lipgloss.NewStyle().
+			Foreground(lipgloss.Color(m.theme.Warning)).
+			Render(" | " + warning)
+	}
+	if m.paused {
+		statusLine += lipgloss.NewStyle().
+			Foreground(lipgloss.Color(m.theme.Warning)).
+			Bold(true).
+			Render(fmt.Sprintf(" | ⏸ Paused, %d new requests buffered (Space to resume)", m.pausedEntries()))
+	}
 
 	// Error display
 	errorLine := ""
 	if m.lastError != "" {
 		errorLine = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF6B6B")).
+			Foreground(lipgloss.Color(m.theme.Error)).
 			Render(fmt.Sprintf("Error: %s", m.lastError))
+	} else if m.notice != "" {
+		errorLine = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(m.theme.Success)).
+			Render(m.notice)
 	}
 
 	// Tabs
 	var tabViews []string
+	m.layout.tabEnds = m.layout.tabEnds[:0]
+	tabEnd := 0
 	for i, tab := range tabs {
 		style := m.tabStyle
 		if i == m.activeTab {
 			style = m.activeTabStyle
 		}
 		tabViews = append(tabViews, style.Render(tab.Name))
+		tabEnd += lipgloss.Width(tabViews[i])
+		m.layout.tabEnds = append(m.layout.tabEnds, tabEnd)
 	}
 
 	tabBar := lipgloss.JoinHorizontal(lipgloss.Top, tabViews...)
@@ -455,14 +1066,14 @@ func (m *Model) View() string {
 		filterInfo := ""
 
 		if m.filterMode {
-			filterInfo = m.filterStyle.Render(fmt.Sprintf("Filter: %s|", m.filterBuffer))
+			filterInfo = m.filterStyle.Render(fmt.Sprintf("Filter: %s|", m.filterBuffer)) + m.filterError(m.filterBuffer)
 		} else {
 			// Show active filter in green right after "F: Filter"
 			if m.filterText != "" {
 				filterInfo = fmt.Sprintf("F: Filter '%s'", m.filterText)
 				filterInfo = lipgloss.NewStyle().
-					Foreground(lipgloss.Color("#00FF00")).
-					Render(filterInfo)
+					Foreground(lipgloss.Color(m.theme.ActiveFilter)).
+					Render(filterInfo) + m.filterError(m.filterText)
 			}
 		}
 
@@ -481,6 +1092,13 @@ func (m *Model) View() string {
 		}
 		controlParts = append(controlParts, fmt.Sprintf("S: %s Hide /stats", statsCheckbox))
 
+		// Status class toggles, with the picked classes
+		statusControl := "2-5: Status"
+		if classes := m.statusClassNames(); len(classes) > 0 {
+			statusControl += " " + strings.Join(classes, " ")
+		}
+		controlParts = append(controlParts, statusControl)
+
 		// Auto-refresh toggle with checkbox
 		autoRefreshCheckbox := "❌"
 		if m.autoRefresh {
@@ -488,11 +1106,18 @@ func (m *Model) View() string {
 		}
 		controlParts = append(controlParts, fmt.Sprintf("A: %s Auto-refresh", autoRefreshCheckbox))
 
+		// Follow toggle with checkbox
+		followCheckbox := "❌"
+		if m.follow {
+			followCheckbox = "✅"
+		}
+		controlParts = append(controlParts, fmt.Sprintf("L: %s Follow", followCheckbox))
+
 		// Clear control
 		controlParts = append(controlParts, "C: Clear")
 
 		controls := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#666666")).
+			Foreground(lipgloss.Color(m.theme.Dim)).
 			Render(strings.Join(controlParts, " | "))
 
 		if filterInfo != "" {
@@ -500,17 +1125,17 @@ func (m *Model) View() string {
 		} else {
 			filterLine = controls
 		}
-	} else if m.activeTab == 1 { // Configuration tab
+	} else if m.activeTab == 1 && m.editor == nil && m.rawConfig == nil { // Configuration tab
 		filterInfo := ""
 
 		if m.configFilterMode {
-			filterInfo = m.filterStyle.Render(fmt.Sprintf("Filter: %s|", m.configFilterBuffer))
+			filterInfo = m.filterStyle.Render(fmt.Sprintf("Filter: %s|", m.configFilterBuffer)) + m.filterError(m.configFilterBuffer)
 		} else {
 			if m.configFilterText != "" {
 				filterInfo = fmt.Sprintf("F: Filter '%s'", m.configFilterText)
 				filterInfo = lipgloss.NewStyle().
-					Foreground(lipgloss.Color("#00FF00")).
-					Render(filterInfo)
+					Foreground(lipgloss.Color(m.theme.ActiveFilter)).
+					Render(filterInfo) + m.filterError(m.configFilterText)
 			}
 		}
 
@@ -526,7 +1151,7 @@ func (m *Model) View() string {
 		controlParts = append(controlParts, "C: Clear")
 
 		controls := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#666666")).
+			Foreground(lipgloss.Color(m.theme.Dim)).
 			Render(strings.Join(controlParts, " | "))
 
 		if filterInfo != "" {
@@ -536,18 +1161,48 @@ func (m *Model) View() string {
 		}
 	}
 
+	// Search prompt replaces the filter line
+	if m.searchMode {
+		filterLine = m.filterStyle.Render(fmt.Sprintf("Search: %s|", m.searchBuffer))
+		if m.searchQuery != "" {
+			filterLine += "  " + lipgloss.NewStyle().
+				Foreground(lipgloss.Color(m.theme.Dim)).
+				Render(m.searchStatus())
+		}
+	}
+
+	// Server URL input replaces the filter line
+	if m.addServerMode {
+		filterLine = m.filterStyle.Render(fmt.Sprintf("Add server: %s|", m.addServerBuffer))
+	}
+
+	// So does a reset confirmation
+	if m.confirmReset != nil {
+		filterLine = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(m.theme.Error)).
+			Render(fmt.Sprintf("%s on %s? Press 'y' to confirm, any other key to cancel", m.confirmReset.prompt, m.httpURL))
+	}
+
 	// Content with scrolling
 	content := ""
-	if m.activeTab < len(tabs) {
+	if m.helpOverlay {
+		content = m.helpOverlayView()
+	} else if panes, split := m.splitPanes(); split {
+		content = m.renderPanes(panes)
+	} else if m.activeTab < len(tabs) {
 		fullContent := tabs[m.activeTab].View(m)
 		content = m.renderScrollableContent(fullContent, m.activeTab)
 	}
 
 	// Footer with scroll info and filter controls
-	footerText := "Tab/Shift+Tab: Switch tabs | ↑↓/j/k: Scroll | PgUp/PgDn/u/d: Page | Home/End/g/G: Top/Bottom | R: Refresh | Q: Quit"
+	footerText := "Tab/Shift+Tab: Switch tabs | ↑↓/j/k: Scroll | PgUp/PgDn/u/d: Page | Home/End/g/G: Top/Bottom | /: Search | Space: Pause | R: Refresh | ?: Keys | Q: Quit"
 	if m.activeTab == 3 { // Request Log tab
 		if m.filterMode {
 			footerText = "Filter Mode - Type to filter | Enter/Esc: Exit filter mode | Ctrl+C: Quit"
+		} else if m.replayResult != nil {
+			footerText = "Replay Result - ↑↓/j/k: Scroll | ←→: Scroll body | Z/Shift+Z: Fold/unfold | P: Replay again | Enter/Esc: Close | Q: Quit"
+		} else if m.detailEntry != nil {
+			footerText = "Request Detail - ↑↓/j/k: Scroll | ←→: Scroll body | Z/Shift+Z: Fold/unfold | P: Replay | Enter/Esc: Close | Q: Quit"
 		} else {
 			// Build footer with checkbox status
 			statsStatus := "❌"
@@ -558,16 +1213,45 @@ func (m *Model) View() string {
 			if m.autoRefresh {
 				autoRefreshStatus = "✅"
 			}
-			footerText = fmt.Sprintf("F: Filter | S: %s Hide /stats | A: %s Auto-refresh | C: Clear | %s",
+			footerText = fmt.Sprintf("F: Filter | S: %s Hide /stats | A: %s Auto-refresh | C: Clear | ↑↓: Select | Enter: Details | O: Sort | P: Replay | V: Group | X: curl | M: Diff | %s",
 				statsStatus, autoRefreshStatus, footerText)
 		}
+	} else if m.activeTab == 2 { // Statistics tab
+		if m.drillDownPath != "" {
+			footerText = "Endpoint Drill-down - ↑↓/j/k: Scroll | ←→: Previous/Next endpoint | Enter/Esc: Close | Q: Quit"
+		} else {
+			footerText = "←→: Select endpoint | Enter: Drill down | " + footerText
+		}
 	} else if m.activeTab == 1 { // Configuration tab
 		if m.configFilterMode {
 			footerText = "Filter Mode - Type to filter endpoints | Enter/Esc: Exit filter mode | Ctrl+C: Quit"
+		} else if m.rawConfig != nil {
+			footerText = "Config Rejected - O/Enter: Edit again | Esc: Discard changes | Ctrl+C: Quit"
+		} else if m.editor != nil && m.editor.form != nil && m.editor.form.step == stepType {
+			footerText = "New Endpoint - ↑↓: Choose type | Enter: Next | Esc: Cancel | Ctrl+C: Quit"
+		} else if m.editor != nil && m.editor.form != nil && m.editor.form.step == stepFields {
+			footerText = "New Endpoint - Tab/↑↓: Field | Enter: Next field, then preview | Esc: Back | Ctrl+C: Quit"
+		} else if m.editor != nil && m.editor.form != nil && m.editor.form.step == stepPreview {
+			footerText = "New Endpoint - Enter: Create | Esc: Back | Ctrl+C: Quit"
+		} else if m.editor != nil && m.editor.form != nil {
+			footerText = "Edit Endpoint - Tab/↑↓: Field | ←→: Type | Enter: Save | Esc: Cancel | Ctrl+C: Quit"
+		} else if m.scenarioPicker != nil {
+			footerText = "Scenarios - ↑↓/j/k: Select | Enter: Start/Stop | X: Stop and restore | R: Refresh | S/Esc: Close | Ctrl+C: Quit"
+		} else if m.editor != nil {
+			footerText = "Edit Mode - ↑↓/j/k: Select | N: New | Enter: Edit | Space: Enable/Disable | G: Group | C: Chaos | X/Del: Delete | E/Esc: Exit | Ctrl+C: Quit"
 		} else {
-			footerText = "F: Filter | C: Clear | " + footerText
+			footerText = "F: Filter | C: Clear | E: Edit | O: Edit JSON | S: Scenarios | " + footerText
 		}
 	}
+	if m.addServerMode {
+		footerText = "Add Server - Type a URL (ws://host:port/ws, http://host:port or host:port) | Enter: Add and switch | Esc: Cancel"
+	}
+	if m.helpOverlay {
+		footerText = "Keys - Any key: Close"
+	}
+	if m.confirmReset != nil {
+		footerText = "Confirm - Y: " + m.confirmReset.prompt + " | Any other key: Cancel"
+	}
 	if m.contentHeights[m.activeTab] > m.viewportHeight {
 		scrollInfo := fmt.Sprintf(" | Scroll: %d/%d",
 			m.scrollPositions[m.activeTab]+1,
@@ -576,18 +1260,21 @@ func (m *Model) View() string {
 	}
 
 	footer := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888")).
+		Foreground(lipgloss.Color(m.theme.Muted)).
 		Render(footerText)
 
-	// Combine all parts
+	// Combine all parts, noting where the mouse can click
 	parts := []string{header, statusLine}
 	if errorLine != "" {
 		parts = append(parts, errorLine)
 	}
+	m.layout.tabBarY = lipgloss.Height(strings.Join(parts, "\n"))
 	parts = append(parts, tabBar)
 	if filterLine != "" {
 		parts = append(parts, filterLine)
 	}
+	// Content starts inside the border and top padding
+	m.layout.contentY = lipgloss.Height(strings.Join(parts, "\n")) + 2
 	parts = append(parts, content, footer)
 
 	return lipgloss.JoinVertical(lipgloss.Left, parts...)
@@ -597,11 +1284,12 @@ func (m *Model) View() string {
 func (m *Model) renderScrollableContent(content string, tabIndex int) string {
 	lines := strings.Split(content, "\n")
 	m.contentHeights[tabIndex] = len(lines)
+	m.tabLines[tabIndex] = lines
 
 	// If content fits in viewport, no scrolling needed
 	if len(lines) <= m.viewportHeight {
 		m.scrollPositions[tabIndex] = 0
-		return m.contentStyle.Render(content)
+		return m.contentStyle.Render(strings.Join(m.highlightSearch(lines), "\n"))
 	}
 
 	// Apply scrolling
@@ -615,7 +1303,7 @@ func (m *Model) renderScrollableContent(content string, tabIndex int) string {
 		end = len(lines)
 	}
 
-	visibleLines := lines[start:end]
+	visibleLines := m.highlightSearch(lines[start:end])
 	scrolledContent := strings.Join(visibleLines, "\n")
 
 	// Add scroll indicators
@@ -629,13 +1317,201 @@ func (m *Model) renderScrollableContent(content string, tabIndex int) string {
 
 	if scrollIndicator != "" {
 		scrolledContent += "\n" + lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#666666")).
+			Foreground(lipgloss.Color(m.theme.Dim)).
 			Render(fmt.Sprintf("    %s", scrollIndicator))
 	}
 
 	return m.contentStyle.Render(scrolledContent)
 }
 
+// requestLogRow maps a rendered request log line to its entry
+type requestLogRow struct {
+	line  int
+	entry types.RequestLogEntry
+}
+
+// noticeDuration is how long a notice stays in the status area
+const noticeDuration = 5 * time.Second
+
+// refreshTick schedules the next poll
+func (m *Model) refreshTick() tea.Cmd {
+	return tea.Tick(m.refreshInterval, func(time.Time) tea.Msg { return RefreshMsg{} })
+}
+
+// setNotice shows a notice under the status line and clears it after noticeDuration
+func (m *Model) setNotice(notice string) tea.Cmd {
+	m.notice = notice
+	return tea.Tick(noticeDuration, func(time.Time) tea.Msg { return NoticeExpiredMsg{Notice: notice} })
+}
+
+// pauseRequestLog stops the Request Log from updating and following once
+// the user scrolls or moves the cursor, so rows stay where they were read
+func (m *Model) pauseRequestLog() {
+	m.manualScroll = true
+	m.autoRefresh = false
+	m.follow = false
+}
+
+// toggleFollow pins the Request Log to the newest entry, like tail -f,
+// resuming auto-refresh if scrolling had paused it
+func (m *Model) toggleFollow() tea.Cmd {
+	m.follow = !m.follow
+	if !m.follow {
+		return nil
+	}
+	m.selectedLogID = 0
+	if m.requestPaneOpen() {
+		m.closeRequestPane()
+	}
+	m.manualScroll = false
+	if !m.autoRefresh {
+		m.autoRefresh = true
+		// Pushed entries were ignored while paused; catch up
+		return m.fetchRequestLog
+	}
+	return nil
+}
+
+// followNewest scrolls the rendered Request Log so the newest entry is in view
+func (m *Model) followNewest() {
+	newest := -1
+	for i, row := range m.requestLogRows {
+		if newest < 0 || row.entry.ID > m.requestLogRows[newest].entry.ID {
+			newest = i
+		}
+	}
+	if newest < 0 {
+		m.scrollPositions[3] = 0
+		return
+	}
+	line := m.requestLogRows[newest].line
+	if line < m.viewportHeight {
+		m.scrollPositions[3] = 0
+	} else {
+		// Keep the newest entry on the last lines, as when sorted oldest first
+		m.scrollPositions[3] = line - m.viewportHeight + 2
+	}
+}
+
+// selectedLogRow returns the index of the row under the cursor, and whether
+// it was explicitly selected rather than being the first row in the viewport
+func (m *Model) selectedLogRow() (int, bool) {
+	if m.selectedLogID != 0 {
+		for i, row := range m.requestLogRows {
+			if row.entry.ID == m.selectedLogID {
+				return i, true
+			}
+		}
+	}
+	for i, row := range m.requestLogRows {
+		if row.line >= m.scrollPositions[3] {
+			return i, false
+		}
+	}
+	return len(m.requestLogRows) - 1, false
+}
+
+// selectedLogEntry returns the request log entry under the cursor
+func (m *Model) selectedLogEntry() (types.RequestLogEntry, bool) {
+	if len(m.requestLogRows) == 0 {
+		return types.RequestLogEntry{}, false
+	}
+	index, _ := m.selectedLogRow()
+	return m.requestLogRows[index].entry, true
+}
+
+// moveLogCursor moves the request log cursor by delta rows and scrolls to keep
+// it in the viewport. The first move only selects the first visible row. It
+// reports false when no rows are shown, e.g. while grouping by route.
+func (m *Model) moveLogCursor(delta int) bool {
+	if len(m.requestLogRows) == 0 {
+		return false
+	}
+
+	index, selected := m.selectedLogRow()
+	if selected {
+		index += delta
+	}
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(m.requestLogRows) {
+		index = len(m.requestLogRows) - 1
+	}
+
+	row := m.requestLogRows[index]
+	m.selectedLogID = row.entry.ID
+	if row.line < m.scrollPositions[3] {
+		m.scrollPositions[3] = row.line
+	} else if row.line >= m.scrollPositions[3]+m.viewportHeight {
+		m.scrollPositions[3] = row.line - m.viewportHeight + 1
+	}
+	return true
+}
+
+// requestPaneOpen reports whether the detail or replay pane replaces the request log
+func (m *Model) requestPaneOpen() bool {
+	return m.detailEntry != nil || m.replayResult != nil
+}
+
+// openRequestPane remembers where the log was scrolled before a pane covers it
+func (m *Model) openRequestPane() {
+	if !m.requestPaneOpen() {
+		m.detailScroll = m.scrollPositions[3]
+	}
+	m.scrollPositions[3] = 0
+	m.bodyFoldDepth, m.bodyScrollX = 0, 0
+}
+
+// closeRequestPane closes the replay pane, or else the detail pane, returning
+// to where the log was scrolled once both are closed
+func (m *Model) closeRequestPane() {
+	if m.replayResult != nil {
+		m.replayResult = nil
+	} else {
+		m.detailEntry = nil
+	}
+	m.scrollPositions[3] = 0
+	m.bodyFoldDepth, m.bodyScrollX = 0, 0
+	if !m.requestPaneOpen() {
+		m.scrollPositions[3] = m.detailScroll
+	}
+}
+
+// replayRequest re-sends a logged request with POST /requestlog/{id}/replay
+func (m *Model) replayRequest(id uint64) tea.Cmd {
+	return func() tea.Msg {
+		// The server waits up to 30 seconds for the replayed request
+		client := &http.Client{Timeout: 35 * time.Second}
+		req, err := m.newRequest(http.MethodPost, fmt.Sprintf("%s/requestlog/%d/replay", m.httpURL, id), nil)
+		if err != nil {
+			return ReplayErrorMsg{Error: err.Error()}
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return ReplayErrorMsg{Error: fmt.Sprintf("Failed to replay request #%d: %v", id, err)}
+		}
+		defer resp.Body.Close()
+		if err := authError(resp.StatusCode); err != nil {
+			return ReplayErrorMsg{Error: fmt.Sprintf("Failed to replay request #%d: %v", id, err)}
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return ReplayErrorMsg{Error: fmt.Sprintf("Failed to read replay response: %v", err)}
+		}
+		if resp.StatusCode != http.StatusOK {
+			return ReplayErrorMsg{Error: fmt.Sprintf("Replay of request #%d failed: %s", id, strings.TrimSpace(string(body)))}
+		}
+
+		var result types.ReplayResult
+		if err := json.Unmarshal(body, &result); err != nil {
+			return ReplayErrorMsg{Error: fmt.Sprintf("Failed to parse replay result: %v", err)}
+		}
+		return ReplayResultMsg{Result: result}
+	}
+}
+
 // filterRequestLog filters the request log based on current filter settings
 func (m *Model) filterRequestLog() []types.RequestLogEntry {
 	if len(m.requestLog) == 0 {
@@ -643,6 +1519,8 @@ func (m *Model) filterRequestLog() []types.RequestLogEntry {
 	}
 
 	filtered := make([]types.RequestLogEntry, 0)
+	filter := parseTextFilter(m.filterText)
+	statusClasses := m.statusClassNames()
 
 	for _, entry := range m.requestLog {
 		// Skip /stats requests if toggle is enabled
@@ -650,19 +1528,22 @@ func (m *Model) filterRequestLog() []types.RequestLogEntry {
 			continue
 		}
 
-		// Apply text filter if set
-		if m.filterText != "" {
-			filterLower := strings.ToLower(m.filterText)
-			if !strings.Contains(strings.ToLower(entry.Path), filterLower) &&
-				!strings.Contains(strings.ToLower(entry.Method), filterLower) &&
-				!strings.Contains(strings.ToLower(entry.RemoteAddr), filterLower) {
+		// Skip entries outside the status classes picked with 2-5
+		if len(statusClasses) > 0 {
+			if class := entry.StatusCode / 100; class < 2 || class > 5 || !m.statusClasses[class] {
 				continue
 			}
 		}
 
+		// Apply text filter if set; "tag:<name>" matches the test tag exactly
+		if !filter.keep(entry.Tag, entry.Path, entry.Method, entry.RemoteAddr, entry.Tag) {
+			continue
+		}
+
 		filtered = append(filtered, entry)
 	}
 
+	m.sortRequestLog(filtered)
 	return filtered
 }
 
@@ -673,16 +1554,12 @@ func (m *Model) filterConfigEndpoints() map[string]types.EndpointConfig {
 	}
 
 	filtered := make(map[string]types.EndpointConfig)
+	filter := parseTextFilter(m.configFilterText)
 
 	for path, endpoint := range m.config.Endpoints {
 		// Apply text filter if set
-		if m.configFilterText != "" {
-			filterLower := strings.ToLower(m.configFilterText)
-			if !strings.Contains(strings.ToLower(path), filterLower) &&
-				!strings.Contains(strings.ToLower(endpoint.Type), filterLower) &&
-				!strings.Contains(strings.ToLower(endpoint.Message), filterLower) {
-				continue
-			}
+		if !filter.keep("", path, endpoint.Type, endpoint.Message) {
+			continue
 		}
 
 		filtered[path] = endpoint
@@ -694,10 +1571,9 @@ func (m *Model) filterConfigEndpoints() map[string]types.EndpointConfig {
 // connectToServer connects to the server
 func (m *Model) connectToServer() tea.Msg {
 	// Test connection by making a simple HTTP request
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(m.httpURL + "/stats")
+	resp, err := m.get(m.httpURL + "/stats")
 	if err != nil {
-		return ErrorMsg{Error: fmt.Sprintf("Failed to connect: %v", err)}
+		return fetchError("Failed to connect", err)
 	}
 	defer resp.Body.Close()
 
@@ -710,10 +1586,9 @@ func (m *Model) connectToServer() tea.Msg {
 
 // fetchConfig fetches configuration from the server
 func (m *Model) fetchConfig() tea.Msg {
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(m.httpURL + "/config")
+	resp, err := m.get(m.httpURL + "/config")
 	if err != nil {
-		return ErrorMsg{Error: fmt.Sprintf("Failed to fetch config: %v", err)}
+		return fetchError("Failed to fetch config", err)
 	}
 	defer resp.Body.Close()
 
@@ -736,10 +1611,9 @@ func (m *Model) fetchConfig() tea.Msg {
 
 // fetchStats fetches statistics from the server
 func (m *Model) fetchStats() tea.Msg {
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(m.httpURL + "/stats")
+	resp, err := m.get(m.httpURL + "/stats")
 	if err != nil {
-		return ErrorMsg{Error: fmt.Sprintf("Failed to fetch stats: %v", err)}
+		return fetchError("Failed to fetch stats", err)
 	}
 	defer resp.Body.Close()
 
@@ -762,10 +1636,9 @@ func (m *Model) fetchStats() tea.Msg {
 
 // fetchRequestLog fetches real request log data from the server
 func (m *Model) fetchRequestLog() tea.Msg {
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get(m.httpURL + "/requestlog")
+	resp, err := m.get(m.httpURL + "/requestlog")
 	if err != nil {
-		return ErrorMsg{Error: fmt.Sprintf("Failed to fetch request log: %v", err)}
+		return fetchError("Failed to fetch request log", err)
 	}
 	defer resp.Body.Close()
 
@@ -783,7 +1656,68 @@ func (m *Model) fetchRequestLog() tea.Msg {
 		return ErrorMsg{Error: fmt.Sprintf("Failed to parse request log: %v", err)}
 	}
 
-	return RequestLogMsg{Entries: requestLog}
+	return RequestLogMsg{Entries: requestLog, Position: requestLogPosition(resp)}
+}
+
+// fetchWebSocketClients fetches the connected WebSocket clients from the server
+func (m *Model) fetchWebSocketClients() tea.Msg {
+	resp, err := m.get(m.httpURL + "/ws/clients")
+	if err != nil {
+		return fetchError("Failed to fetch WebSocket clients", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ErrorMsg{Error: fmt.Sprintf("WebSocket clients request failed: %d", resp.StatusCode)}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ErrorMsg{Error: fmt.Sprintf("Failed to read WebSocket clients response: %v", err)}
+	}
+
+	var clients types.WSClientList
+	if err := json.Unmarshal(body, &clients); err != nil {
+		return ErrorMsg{Error: fmt.Sprintf("Failed to parse WebSocket clients: %v", err)}
+	}
+
+	return WSClientsMsg{Clients: &clients}
+}
+
+// fetchTimeSeries fetches the recent per-second traffic shown in the charts
+func (m *Model) fetchTimeSeries() tea.Msg {
+	resp, err := m.get(fmt.Sprintf("%s/stats/timeseries?window=%d", m.httpURL, chartSeconds))
+	if err != nil {
+		return fetchError("Failed to fetch time series", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ErrorMsg{Error: fmt.Sprintf("Time series request failed: %d", resp.StatusCode)}
+	}
+
+	var series types.TimeSeries
+	if err := json.NewDecoder(resp.Body).Decode(&series); err != nil {
+		return ErrorMsg{Error: fmt.Sprintf("Failed to parse time series: %v", err)}
+	}
+
+	return TimeSeriesMsg{Series: &series}
+}
+
+// requestLogLimit returns how many request log entries the TUI keeps, matching the server's buffer
+func (m *Model) requestLogLimit() int {
+	if m.config != nil && m.config.Server.RequestLogSize > 0 {
+		return m.config.Server.RequestLogSize
+	}
+	return types.DefaultRequestLogSize
+}
+
+// closeLive closes the push stream, if open
+func (m *Model) closeLive() {
+	if m.live != nil {
+		m.live.close()
+		m.live = nil
+	}
 }
 
 // Helper function
@@ -802,17 +1736,96 @@ type RefreshMsg struct{}
 type FilterDebounceMsg struct{}
 type ConfigMsg struct{ Config *types.Config }
 type StatsMsg struct{ Stats *types.ServerStats }
-type RequestLogMsg struct{ Entries []types.RequestLogEntry }
+type RequestLogMsg struct {
+	Entries  []types.RequestLogEntry
+	Position uint64 // X-Request-Log-Position when polled; 0 when pushed
+}
+type WSClientsMsg struct{ Clients *types.WSClientList }
+type TimeSeriesMsg struct{ Series *types.TimeSeries }
+type ServerSummaryMsg struct {
+	Server string
+	Stats  *types.ServerStats
+	Error  string
+}
+type RawConfigEditedMsg struct {
+	Buffer string
+	Error  string
+}
+type RawConfigResultMsg struct{ Error string }
+type ReplayResultMsg struct{ Result types.ReplayResult }
+type ReplayErrorMsg struct{ Error string }
+type EndpointSavedMsg struct{ Message string }
+type EndpointEditErrorMsg struct{ Error string }
+type StatsUpdateMsg struct{ Stats *types.ServerStats }
+type RequestLogEntryMsg struct{ Entry types.RequestLogEntry }
+type LiveConnectedMsg struct{ Conn *liveConn }
+type LiveFailedMsg struct{ Error string }
+type LiveRetryMsg struct{}
+type LiveDroppedMsg struct{}
+type LiveEventMsg struct {
+	Conn *liveConn
+	Msg  tea.Msg
+}
+type LiveDisconnectedMsg struct {
+	Conn  *liveConn
+	Error string
+}
 type ErrorMsg struct{ Error string }
+type NoticeMsg struct{ Notice string }
+type NoticeExpiredMsg struct{ Notice string }
+
+// Options configures the TUI client
+type Options struct {
+	Servers  []string  // WebSocket URLs of the monitored servers; the first is shown at start
+	Theme    *Theme    // Colors; the dark theme when nil
+	Keys     *KeyMap   // Key bindings; the defaults when nil
+	Layout   string    // Pane layout: tabs (default), side-by-side or stacked
+	Token    string    // Management token sent to every server; empty when auth is off
+	Snapshot *Snapshot // Saved test run to browse offline instead of monitoring Servers
+
+	// Refresh interval, filters and columns to start with; the built-in
+	// defaults when nil
+	Config *ClientConfig
+}
 
 // RunTUI starts the TUI application
-func RunTUI(serverURL string) error {
-	model := NewModel(serverURL)
+func RunTUI(options Options) error {
+	var model *Model
+	switch {
+	case options.Snapshot != nil:
+		model = NewModel("")
+		model.servers = nil
+		model.loadSnapshot(options.Snapshot)
+	case len(options.Servers) == 0:
+		return fmt.Errorf("no server URL given")
+	default:
+		model = NewModel(options.Servers[0])
+		model.servers = options.Servers
+	}
+	model.token = options.Token
+	if options.Theme != nil {
+		model.setTheme(options.Theme)
+	}
+	if options.Keys != nil {
+		model.keys = *options.Keys
+	}
+	layout, err := parsePaneLayout(options.Layout)
+	if err != nil {
+		return err
+	}
+	model.paneLayout = layout
+	if options.Config != nil {
+		options.Config.applyTo(model)
+	}
 
-	p := tea.NewProgram(model, tea.WithAltScreen())
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
-	// Start the program
-	if _, err := p.Run(); err != nil {
+	// Start the program; switching servers replaces the model
+	final, err := p.Run()
+	if final, ok := final.(*Model); ok {
+		final.closeLive()
+	}
+	if err != nil {
 		return fmt.Errorf("failed to start TUI: %w", err)
 	}
 
@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ClientConfig is the TUI client's configuration file
+type ClientConfig struct {
+	Servers         []string            `json:"servers,omitempty" yaml:"servers,omitempty"`                   // Servers monitored when -server is not given
+	RefreshInterval string              `json:"refresh_interval,omitempty" yaml:"refresh_interval,omitempty"` // How often data is polled, e.g. "2s"
+	LowBandwidth    bool                `json:"low_bandwidth,omitempty" yaml:"low_bandwidth,omitempty"`       // Poll only changes, and charts only while shown
+	Theme           string              `json:"theme,omitempty" yaml:"theme,omitempty"`                       // Built-in theme to start from
+	Colors          map[string]string   `json:"colors,omitempty" yaml:"colors,omitempty"`                     // Theme colors to override, e.g. {"error": "#C00000"}
+	Keys            map[string]KeyNames `json:"keys,omitempty" yaml:"keys,omitempty"`                         // Keys to bind actions to, e.g. {"down": ["ctrl+n", "j"]}
+	Layout          string              `json:"layout,omitempty" yaml:"layout,omitempty"`                     // Pane layout: tabs, side-by-side or stacked
+	Filters         ClientFilters       `json:"filters,omitempty" yaml:"filters,omitempty"`                   // Filters applied at startup
+	Columns         ClientColumns       `json:"columns,omitempty" yaml:"columns,omitempty"`                   // Optional Request Log columns
+}
+
+// ClientFilters are the filters and ordering the TUI starts with
+type ClientFilters struct {
+	Text         string   `json:"text,omitempty" yaml:"text,omitempty"`                     // Request Log filter, e.g. "!/health" or "tag:smoke"
+	Config       string   `json:"config,omitempty" yaml:"config,omitempty"`                 // Configuration tab filter
+	HideStats    bool     `json:"hide_stats,omitempty" yaml:"hide_stats,omitempty"`         // Hide /stats requests
+	Status       []string `json:"status,omitempty" yaml:"status,omitempty"`                 // Status classes to show, e.g. ["4xx", "5xx"]
+	Sort         string   `json:"sort,omitempty" yaml:"sort,omitempty"`                     // time, duration, status or path
+	Reverse      bool     `json:"reverse,omitempty" yaml:"reverse,omitempty"`               // Reverse the sort order, as Shift+O does
+	GroupByRoute bool     `json:"group_by_route,omitempty" yaml:"group_by_route,omitempty"` // Group requests by normalized route
+}
+
+// ClientColumns turns optional Request Log columns off. Columns left unset
+// are shown when the terminal is wide enough.
+type ClientColumns struct {
+	Date   *bool `json:"date,omitempty" yaml:"date,omitempty"`
+	Remote *bool `json:"remote,omitempty" yaml:"remote,omitempty"`
+}
+
+// DefaultClientConfigPath returns where the client configuration is read
+// from when no path is given, e.g. ~/.config/webserver-tui/config.yaml
+func DefaultClientConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "webserver-tui", "config.yaml")
+}
+
+// legacyClientConfigPath is where the client configuration was read from
+// before it moved to DefaultClientConfigPath
+func legacyClientConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "webserver", "tui.json")
+}
+
+// LoadClientConfig reads a client configuration file, as YAML for .yaml and
+// .yml files and as JSON otherwise. An empty path reads the default
+// location, or the legacy one, where a missing file is not an error.
+func LoadClientConfig(path string) (*ClientConfig, error) {
+	if path != "" {
+		return readClientConfig(path)
+	}
+	for _, path := range []string{DefaultClientConfigPath(), legacyClientConfigPath()} {
+		if path == "" {
+			continue
+		}
+		config, err := readClientConfig(path)
+		if !errors.Is(err, os.ErrNotExist) {
+			return config, err
+		}
+	}
+	return &ClientConfig{}, nil
+}
+
+// readClientConfig reads and checks one client configuration file
+func readClientConfig(path string) (*ClientConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client config: %w", err)
+	}
+
+	config := &ClientConfig{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, config)
+	default:
+		err = json.Unmarshal(data, config)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client config %s: %w", path, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid client config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// validate checks the settings that are not checked when they are applied
+func (c *ClientConfig) validate() error {
+	if _, err := c.refreshInterval(); err != nil {
+		return err
+	}
+	if _, err := parsePaneLayout(c.Layout); err != nil {
+		return err
+	}
+	if c.Filters.Sort != "" {
+		if _, err := parseLogSort(c.Filters.Sort); err != nil {
+			return err
+		}
+	}
+	for _, class := range c.Filters.Status {
+		if _, err := parseStatusClass(class); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshInterval returns how often data is polled; a second when unset
+func (c *ClientConfig) refreshInterval() (time.Duration, error) {
+	if c.RefreshInterval == "" {
+		return time.Second, nil
+	}
+	interval, err := time.ParseDuration(c.RefreshInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid refresh_interval %q: %w", c.RefreshInterval, err)
+	}
+	if interval < 100*time.Millisecond {
+		return 0, fmt.Errorf("refresh_interval must be at least 100ms, got %s", c.RefreshInterval)
+	}
+	return interval, nil
+}
+
+// applyTo sets a new model's polling, filters and columns
+func (c *ClientConfig) applyTo(m *Model) {
+	m.refreshInterval, _ = c.refreshInterval()
+	m.lowBandwidth = c.LowBandwidth
+
+	filters := c.Filters
+	m.filterText, m.filterBuffer = filters.Text, filters.Text
+	m.configFilterText, m.configFilterBuffer = filters.Config, filters.Config
+	m.hideStatsRequests = filters.HideStats
+	for _, name := range filters.Status {
+		class, _ := parseStatusClass(name)
+		m.statusClasses[class] = true
+	}
+	if filters.Sort != "" {
+		m.logSort, _ = parseLogSort(filters.Sort)
+		m.logSortAscending = m.logSort == sortByPath
+	}
+	if filters.Reverse {
+		m.logSortAscending = !m.logSortAscending
+	}
+	m.groupByRoute = filters.GroupByRoute
+
+	m.hideDateColumn = c.Columns.Date != nil && !*c.Columns.Date
+	m.hideRemoteColumn = c.Columns.Remote != nil && !*c.Columns.Remote
+}
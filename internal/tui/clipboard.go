@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"webserver/pkg/types"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// yankEntry copies the Request Log entry under the cursor, or the one open in
+// the detail pane, to the system clipboard as JSON or as a curl command
+func (m *Model) yankEntry(asCurl bool) tea.Cmd {
+	var entry types.RequestLogEntry
+	switch {
+	case m.replayResult != nil:
+		entry = m.replayResult.Original
+	case m.detailEntry != nil:
+		entry = *m.detailEntry
+	default:
+		selected, ok := m.selectedLogEntry()
+		if !ok {
+			return nil
+		}
+		entry = selected
+	}
+
+	text, what := entry.CurlCommand(""), "curl command"
+	if !asCurl {
+		data, err := json.MarshalIndent(entry, "", "  ")
+		if err != nil {
+			m.lastError = fmt.Sprintf("Failed to encode request #%d: %v", entry.ID, err)
+			return nil
+		}
+		text, what = string(data), "JSON"
+	}
+	return func() tea.Msg {
+		if err := copyToClipboard(text); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to copy to clipboard: %v", err)}
+		}
+		return NoticeMsg{Notice: fmt.Sprintf("Copied request #%d as %s to the clipboard", entry.ID, what)}
+	}
+}
+
+// copyToClipboard sets the clipboard with an OSC 52 escape sequence, which
+// terminals apply locally even over SSH. The sequence goes to stderr, which
+// is the terminal too but is not written by the renderer.
+func copyToClipboard(text string) error {
+	sequence := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		sequence = sequence.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		sequence = sequence.Screen()
+	}
+	_, err := sequence.WriteTo(os.Stderr)
+	return err
+}
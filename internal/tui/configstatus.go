@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"webserver/pkg/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ConfigStatusMsg carries whether the server's latest configuration reload
+// failed; Status is nil when the server predates GET /config/status
+type ConfigStatusMsg struct{ Status *types.ConfigStatus }
+
+// fetchConfigStatus fetches the outcome of the latest configuration reload
+func (m *Model) fetchConfigStatus() tea.Msg {
+	resp, err := m.get(m.httpURL + "/config/status")
+	if err != nil {
+		return fetchError("Failed to fetch configuration status", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ConfigStatusMsg{}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return ErrorMsg{Error: fmt.Sprintf("Configuration status request failed: %d", resp.StatusCode)}
+	}
+
+	var status types.ConfigStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return ErrorMsg{Error: fmt.Sprintf("Failed to parse configuration status: %v", err)}
+	}
+	return ConfigStatusMsg{Status: &status}
+}
+
+// configReloadWarning tells, on the Configuration tab, that the latest
+// reload failed and which configuration is still served; empty while the
+// latest reload succeeded
+func (m *Model) configReloadWarning() string {
+	status := m.configStatus
+	if status == nil || status.Error == "" {
+		return ""
+	}
+
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Error))
+	warning := "⚠️  Configuration reload failed"
+	if status.FailedAt != nil {
+		warning += " at " + status.FailedAt.Local().Format("15:04:05")
+	}
+	warning = errorStyle.Render(warning+": "+status.Error) + "\n"
+	if !status.LoadedAt.IsZero() {
+		warning += fmt.Sprintf("Still serving the configuration loaded at %s; fix the file to reload it\n", status.LoadedAt.Local().Format("15:04:05"))
+	}
+	return warning + "\n"
+}
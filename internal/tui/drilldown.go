@@ -0,0 +1,221 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"webserver/pkg/types"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// drillDownRecentRequests is how many logged requests the drill-down view lists
+const drillDownRecentRequests = 10
+
+// drillDownPercentiles are the latency percentiles shown in the drill-down view
+var drillDownPercentiles = []int{50, 90, 95, 99}
+
+// statsPaths returns the endpoints with statistics in display order
+func (m *Model) statsPaths() []string {
+	if m.stats == nil {
+		return nil
+	}
+	paths := make([]string, 0, len(m.stats.Endpoints))
+	for path := range m.stats.Endpoints {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// selectedStatsPath returns the endpoint under the Statistics tab cursor,
+// falling back to the first endpoint
+func (m *Model) selectedStatsPath() (string, bool) {
+	paths := m.statsPaths()
+	if len(paths) == 0 {
+		return "", false
+	}
+	for _, path := range paths {
+		if path == m.statsSelected {
+			return path, true
+		}
+	}
+	return paths[0], true
+}
+
+// moveStatsCursor selects the endpoint delta places away, following it with
+// the drill-down view when open, or else scrolling its statistics into view
+func (m *Model) moveStatsCursor(delta int) {
+	paths := m.statsPaths()
+	current, ok := m.selectedStatsPath()
+	if !ok {
+		return
+	}
+
+	index := sort.SearchStrings(paths, current) + delta
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(paths) {
+		index = len(paths) - 1
+	}
+	m.statsSelected = paths[index]
+
+	if m.drillDownPath != "" {
+		m.drillDownPath = m.statsSelected
+		m.scrollPositions[2] = 0
+		return
+	}
+
+	line, rendered := m.statsEndpointLines[m.statsSelected]
+	if !rendered {
+		return
+	}
+	if line < m.scrollPositions[2] || line >= m.scrollPositions[2]+m.viewportHeight {
+		maxScroll := m.contentHeights[2] - m.viewportHeight
+		if line > maxScroll {
+			line = maxScroll
+		}
+		if line < 0 {
+			line = 0
+		}
+		m.scrollPositions[2] = line
+	}
+}
+
+// openDrillDown replaces the statistics list with the selected endpoint's view
+func (m *Model) openDrillDown() {
+	path, ok := m.selectedStatsPath()
+	if !ok {
+		return
+	}
+	m.statsSelected = path
+	m.drillDownPath = path
+	m.drillDownScroll = m.scrollPositions[2]
+	m.scrollPositions[2] = 0
+}
+
+// closeDrillDown returns to the statistics list where it was scrolled
+func (m *Model) closeDrillDown() {
+	m.drillDownPath = ""
+	m.scrollPositions[2] = m.drillDownScroll
+}
+
+// endpointDrillDownView renders one endpoint's statistics, latency
+// percentiles and configuration side by side, with its recent requests below
+func (m *Model) endpointDrillDownView(path string) string {
+	labelStyle := lipgloss.NewStyle().Bold(true)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Dim))
+
+	header := fmt.Sprintf("🔬 %s (Enter/Esc to close, ←→ for previous/next endpoint)\n\n", path)
+	stats, exists := m.stats.Endpoints[path]
+	if !exists {
+		return header + "No statistics recorded for this endpoint since the last reset\n"
+	}
+
+	// Logged requests to this endpoint, newest first
+	var entries []types.RequestLogEntry
+	for _, entry := range m.requestLog {
+		if strings.SplitN(entry.Path, "?", 2)[0] == path {
+			entries = append(entries, entry)
+		}
+	}
+
+	// Left column: counters, status codes and latency percentiles
+	summary := labelStyle.Render("📊 Summary") + "\n"
+	summary += fmt.Sprintf("Requests: %d\n", stats.RequestCount)
+	if stats.RequestCount > 0 {
+		summary += fmt.Sprintf("Errors: %d (%.2f%%)\n", stats.ErrorCount,
+			float64(stats.ErrorCount)/float64(stats.RequestCount)*100)
+		summary += fmt.Sprintf("Latency: avg %.2fms, min %dms, max %dms\n",
+			float64(stats.TotalTimeMs)/float64(stats.RequestCount), stats.MinTimeMs, stats.MaxTimeMs)
+	}
+	summary += fmt.Sprintf("Rate (1m): %.2f req/s, %.2f err/s\n",
+		stats.Rates.OneMinute.RequestsPerSec, stats.Rates.OneMinute.ErrorsPerSec)
+	if stats.MaxInFlight > 0 {
+		summary += fmt.Sprintf("In Flight: %d (max %d)\n", stats.InFlight, stats.MaxInFlight)
+	}
+
+	summary += "\n" + labelStyle.Render("Status Codes") + "\n"
+	if len(stats.StatusCodes) == 0 {
+		summary += dim.Render("  (none)") + "\n"
+	}
+	statusCodes := make([]int, 0, len(stats.StatusCodes))
+	for code := range stats.StatusCodes {
+		statusCodes = append(statusCodes, code)
+	}
+	sort.Ints(statusCodes)
+	for _, code := range statusCodes {
+		count := stats.StatusCodes[code]
+		percentage := float64(count) / float64(stats.RequestCount) * 100
+		summary += fmt.Sprintf("  %d %-20s %d (%.1f%%)\n", code,
+			strings.Repeat("█", int(percentage/5+0.5)), count, percentage)
+	}
+
+	summary += "\n" + labelStyle.Render(fmt.Sprintf("Latency Percentiles (%d logged requests)", len(entries))) + "\n"
+	if len(entries) == 0 {
+		summary += dim.Render("  (no logged requests)") + "\n"
+	} else {
+		durations := make([]int64, len(entries))
+		for i, entry := range entries {
+			durations[i] = entry.Duration
+		}
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		for _, p := range drillDownPercentiles {
+			summary += fmt.Sprintf("  p%d: %dms\n", p, durations[(len(durations)*p+99)/100-1])
+		}
+	}
+
+	// Right column: the endpoint's configuration
+	configuration := labelStyle.Render("⚙️ Configuration") + "\n"
+	var endpoint types.EndpointConfig
+	configured := false
+	if m.config != nil {
+		endpoint, configured = m.config.Endpoints[path]
+	}
+	if configured {
+		data, err := json.MarshalIndent(endpoint, "", "  ")
+		if err != nil {
+			data = []byte(err.Error())
+		}
+		configuration += string(data) + "\n"
+	} else {
+		configuration += dim.Render("Not a configured endpoint") + "\n"
+	}
+
+	var columns string
+	if width := m.width/2 - 2; width >= 40 {
+		columns = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(width).Render(summary),
+			lipgloss.NewStyle().Width(width).Render(configuration))
+	} else {
+		columns = summary + "\n" + configuration
+	}
+
+	content := header + strings.TrimRight(columns, " \n") + "\n"
+	if m.timeSeries != nil {
+		if points, ok := m.timeSeries.Endpoints[path]; ok {
+			content += "\n" + endpointCharts(points)
+			if heatmap := latencyHeatmap(points, m.timeSeries.LatencyBucketsMs); heatmap != "" {
+				content += "\n" + heatmap
+			}
+		}
+	}
+
+	content += "\n" + labelStyle.Render("🕒 Recent Requests") + "\n"
+	if len(entries) == 0 {
+		content += dim.Render("  (none in the request log)") + "\n"
+	}
+	for i, entry := range entries {
+		if i >= drillDownRecentRequests {
+			break
+		}
+		content += fmt.Sprintf("  %s %s %s %d %s %dms\n",
+			entry.Timestamp.Format("15:04:05"), entry.Method, entry.Path,
+			entry.StatusCode, http.StatusText(entry.StatusCode), entry.Duration)
+	}
+	return content
+}
@@ -0,0 +1,499 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"webserver/pkg/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editableEndpointTypes are the endpoint types the editor cycles through
+var editableEndpointTypes = []string{"error", "delay", "conditional_error", "fuzz"}
+
+// Endpoint form fields, in display order
+const (
+	fieldPath = iota
+	fieldType
+	fieldStatusCode
+	fieldMessage
+	fieldDelayMs
+	fieldErrorEveryN
+	fieldBody
+	fieldCount
+)
+
+var endpointFieldLabels = [fieldCount]string{
+	"Path", "Type", "Status Code", "Message", "Delay (ms)", "Error Every N", "Body (JSON)",
+}
+
+// endpointEditor is the state of the Configuration tab's edit mode
+type endpointEditor struct {
+	cursor        int           // Selected endpoint in the list
+	form          *endpointForm // Open form; nil while browsing the list
+	confirmDelete string        // Endpoint waiting for delete confirmation
+	status        string        // Outcome of the last change
+}
+
+// endpointForm holds the fields of an endpoint being created or modified
+type endpointForm struct {
+	originalPath string               // Empty for a new endpoint
+	base         types.EndpointConfig // Settings not on the form are kept as they were
+	values       [fieldCount]string
+	focus        int
+	err          string
+	submitting   bool
+	step         int // Wizard step of a new endpoint; stepForm when editing
+}
+
+// newEndpointForm opens the form for an existing endpoint, or a new one when path is empty
+func newEndpointForm(path string, config types.EndpointConfig) *endpointForm {
+	form := &endpointForm{originalPath: path, base: config}
+	if path == "" {
+		form.values[fieldPath] = "/api/"
+		form.values[fieldType] = "error"
+		form.values[fieldStatusCode] = "500"
+		return form
+	}
+
+	form.values[fieldPath] = path
+	form.values[fieldType] = config.Type
+	form.values[fieldMessage] = config.Message
+	if config.StatusCode != 0 {
+		form.values[fieldStatusCode] = strconv.Itoa(config.StatusCode)
+	}
+	if config.DelayMs != 0 {
+		form.values[fieldDelayMs] = strconv.Itoa(config.DelayMs)
+	}
+	if config.ErrorEveryN != 0 {
+		form.values[fieldErrorEveryN] = strconv.Itoa(config.ErrorEveryN)
+	}
+
+	body := config.Response
+	if config.Type == "conditional_error" {
+		body = config.SuccessResponse
+	}
+	if body != nil {
+		if data, err := json.Marshal(body); err == nil {
+			form.values[fieldBody] = string(data)
+		}
+	}
+	return form
+}
+
+// fieldUsed reports whether a field applies to the selected endpoint type
+func (f *endpointForm) fieldUsed(field int) bool {
+	switch field {
+	case fieldStatusCode:
+		return f.values[fieldType] == "error" || f.values[fieldType] == "conditional_error" || f.values[fieldType] == "fuzz"
+	case fieldMessage:
+		return f.values[fieldType] == "error"
+	case fieldDelayMs:
+		return f.values[fieldType] == "delay"
+	case fieldErrorEveryN:
+		return f.values[fieldType] == "conditional_error"
+	case fieldBody:
+		return f.values[fieldType] == "delay" || f.values[fieldType] == "conditional_error" || f.values[fieldType] == "fuzz"
+	}
+	return true
+}
+
+// cycleType switches to the next or previous editable endpoint type
+func (f *endpointForm) cycleType(step int) {
+	index := -1
+	for i, endpointType := range editableEndpointTypes {
+		if endpointType == f.values[fieldType] {
+			index = i
+		}
+	}
+	index = (index + step + len(editableEndpointTypes)) % len(editableEndpointTypes)
+	f.values[fieldType] = editableEndpointTypes[index]
+}
+
+// endpoint builds the endpoint path and configuration from the form fields.
+// The server validates the result; this only checks that fields parse.
+func (f *endpointForm) endpoint() (string, types.EndpointConfig, error) {
+	path := strings.TrimSpace(f.values[fieldPath])
+	if path == "" {
+		return "", types.EndpointConfig{}, fmt.Errorf("path is required")
+	}
+
+	config := f.base
+	config.Type = f.values[fieldType]
+	config.StatusCode, config.Message, config.DelayMs, config.ErrorEveryN = 0, "", 0, 0
+	config.Response, config.SuccessResponse = nil, nil
+
+	number := func(field int) (int, error) {
+		value := strings.TrimSpace(f.values[field])
+		if value == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a number: %s", strings.ToLower(endpointFieldLabels[field]), value)
+		}
+		return n, nil
+	}
+
+	var body map[string]interface{}
+	if value := strings.TrimSpace(f.values[fieldBody]); value != "" && f.fieldUsed(fieldBody) {
+		if err := json.Unmarshal([]byte(value), &body); err != nil {
+			return "", types.EndpointConfig{}, fmt.Errorf("body must be a JSON object: %v", err)
+		}
+	}
+
+	var err error
+	switch config.Type {
+	case "error":
+		config.Message = f.values[fieldMessage]
+		config.StatusCode, err = number(fieldStatusCode)
+	case "delay":
+		config.DelayMs, err = number(fieldDelayMs)
+		config.Response = body
+	case "conditional_error":
+		if config.StatusCode, err = number(fieldStatusCode); err == nil {
+			config.ErrorEveryN, err = number(fieldErrorEveryN)
+		}
+		config.SuccessResponse = body
+	case "fuzz":
+		config.StatusCode, err = number(fieldStatusCode)
+		config.Response = body
+	}
+	if config.Type != "fuzz" {
+		config.Fuzz = nil
+	}
+	if config.Type != "proxy" {
+		config.TargetURL = ""
+	}
+	if config.Type != "delay" {
+		config.DelayProfile = nil
+	}
+	if config.Type != "redirect" {
+		config.Location = ""
+	}
+	if err != nil {
+		return "", types.EndpointConfig{}, err
+	}
+	return path, config, nil
+}
+
+// editorPaths returns the configured endpoint paths in display order
+func (m *Model) editorPaths() []string {
+	if m.config == nil {
+		return nil
+	}
+	paths := make([]string, 0, len(m.config.Endpoints))
+	for path := range m.config.Endpoints {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// handleEditorKey handles key presses while the Configuration tab is in edit mode
+func (m *Model) handleEditorKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	editor := m.editor
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	if form := editor.form; form != nil && form.step != stepForm {
+		return m.handleWizardKey(msg)
+	}
+	if form := editor.form; form != nil {
+		if form.submitting {
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc":
+			editor.form = nil
+		case "tab", "down":
+			form.focus = (form.focus + 1) % fieldCount
+		case "shift+tab", "up":
+			form.focus = (form.focus - 1 + fieldCount) % fieldCount
+		case "left", "right":
+			if form.focus == fieldType {
+				step := 1
+				if msg.String() == "left" {
+					step = -1
+				}
+				form.cycleType(step)
+			}
+		case "backspace":
+			if value := form.values[form.focus]; form.focus != fieldType && len(value) > 0 {
+				_, size := utf8.DecodeLastRuneInString(value)
+				form.values[form.focus] = value[:len(value)-size]
+			}
+		case "enter":
+			path, config, err := form.endpoint()
+			if err != nil {
+				form.err = err.Error()
+				return m, nil
+			}
+			form.err = ""
+			form.submitting = true
+			return m, m.saveEndpoint(form.originalPath, path, config)
+		default:
+			if form.focus != fieldType && (msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace) {
+				form.values[form.focus] += string(msg.Runes)
+			}
+		}
+		return m, nil
+	}
+
+	paths := m.editorPaths()
+	if editor.confirmDelete != "" {
+		path := editor.confirmDelete
+		editor.confirmDelete = ""
+		if msg.String() == "y" {
+			return m, m.deleteEndpoint(path)
+		}
+		editor.status = "Delete cancelled"
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "e":
+		m.editor = nil
+	case "up", "k":
+		if editor.cursor > 0 {
+			editor.cursor--
+		}
+	case "down", "j":
+		if editor.cursor < len(paths)-1 {
+			editor.cursor++
+		}
+	case "n":
+		editor.form = newEndpointWizard()
+		m.scrollPositions[1] = 0
+	case "enter":
+		if editor.cursor < len(paths) {
+			path := paths[editor.cursor]
+			editor.form = newEndpointForm(path, m.config.Endpoints[path])
+			m.scrollPositions[1] = 0
+		}
+	case "x", "delete":
+		if editor.cursor < len(paths) {
+			editor.confirmDelete = paths[editor.cursor]
+		}
+	case " ":
+		if editor.cursor < len(paths) {
+			return m, m.toggleEndpoint(paths[editor.cursor])
+		}
+	case "g", "c":
+		if editor.cursor >= len(paths) {
+			break
+		}
+		path := paths[editor.cursor]
+		group := m.config.Endpoints[path].Group
+		if group == "" {
+			editor.status = fmt.Sprintf("%s is not in a group", path)
+			break
+		}
+		if msg.String() == "g" {
+			return m, m.toggleGroup(group)
+		}
+		return m, m.cycleChaos(group)
+	}
+	return m, nil
+}
+
+// toggleGroup disables every endpoint of a group with POST /groups, or
+// enables them when all are disabled. The new state shows right away; the
+// config refetched afterwards undoes it if the server refused the change.
+func (m *Model) toggleGroup(group string) tea.Cmd {
+	disable := false
+	for _, config := range m.config.Endpoints {
+		if config.Group == group && !config.Disabled {
+			disable = true
+			break
+		}
+	}
+	action := "enable"
+	if disable {
+		action = "disable"
+	}
+	for path, config := range m.config.Endpoints {
+		if config.Group == group {
+			config.Disabled = disable
+			m.config.Endpoints[path] = config
+		}
+	}
+
+	save := func() tea.Msg {
+		requestURL := fmt.Sprintf("%s/groups?group=%s&action=%s", m.httpURL, url.QueryEscape(group), action)
+		if err := m.configRequest(http.MethodPost, requestURL, nil); err != nil {
+			return EndpointEditErrorMsg{Error: err.Error()}
+		}
+		return EndpointSavedMsg{Message: fmt.Sprintf("%sd group %s", strings.ToUpper(action[:1])+action[1:], group)}
+	}
+	return tea.Sequence(save, m.fetchConfig)
+}
+
+// cycleChaos applies the next chaos profile of the configuration, by name,
+// to a group, clearing it after the last one
+func (m *Model) cycleChaos(group string) tea.Cmd {
+	profiles := make([]string, 0, len(m.config.ChaosProfiles))
+	for name := range m.config.ChaosProfiles {
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+	if len(profiles) == 0 {
+		m.editor.status = "No chaos_profiles configured"
+		return nil
+	}
+
+	return func() tea.Msg {
+		current, err := m.groupChaos(group)
+		if err != nil {
+			return EndpointEditErrorMsg{Error: err.Error()}
+		}
+		next := profiles[0]
+		if current != "" {
+			next = ""
+			if i := sort.SearchStrings(profiles, current); i+1 < len(profiles) && profiles[i] == current {
+				next = profiles[i+1]
+			}
+		}
+
+		requestURL := m.httpURL + "/groups?group=" + url.QueryEscape(group)
+		if next == "" {
+			if err := m.configRequest(http.MethodDelete, requestURL, nil); err != nil {
+				return EndpointEditErrorMsg{Error: err.Error()}
+			}
+			return EndpointSavedMsg{Message: fmt.Sprintf("Cleared chaos from group %s", group)}
+		}
+		if err := m.configRequest(http.MethodPost, requestURL+"&action=chaos&profile="+url.QueryEscape(next), nil); err != nil {
+			return EndpointEditErrorMsg{Error: err.Error()}
+		}
+		return EndpointSavedMsg{Message: fmt.Sprintf("Applied chaos profile %s to group %s", next, group)}
+	}
+}
+
+// groupChaos returns the chaos profile applied to a group, from GET /groups
+func (m *Model) groupChaos(group string) (string, error) {
+	req, err := m.newRequest(http.MethodGet, m.httpURL+"/groups", nil)
+	if err != nil {
+		return "", err
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := authError(resp.StatusCode); err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to list groups: HTTP %d", resp.StatusCode)
+	}
+	var groups []types.EndpointGroup
+	if err := json.NewDecoder(resp.Body).Decode(&groups); err != nil {
+		return "", err
+	}
+	for _, g := range groups {
+		if g.Name == group {
+			return g.Chaos, nil
+		}
+	}
+	return "", nil
+}
+
+// toggleEndpoint enables or disables an endpoint with POST /config. The new
+// state shows right away; the config refetched afterwards undoes it if the
+// server refused the change.
+func (m *Model) toggleEndpoint(path string) tea.Cmd {
+	config := m.config.Endpoints[path]
+	config.Disabled = !config.Disabled
+	m.config.Endpoints[path] = config
+
+	save := func() tea.Msg {
+		body, err := json.Marshal(map[string]interface{}{"path": path, "config": config})
+		if err != nil {
+			return EndpointEditErrorMsg{Error: err.Error()}
+		}
+		if err := m.configRequest(http.MethodPost, m.httpURL+"/config", body); err != nil {
+			return EndpointEditErrorMsg{Error: err.Error()}
+		}
+		if config.Disabled {
+			return EndpointSavedMsg{Message: fmt.Sprintf("Disabled %s", path)}
+		}
+		return EndpointSavedMsg{Message: fmt.Sprintf("Enabled %s", path)}
+	}
+	return tea.Sequence(save, m.fetchConfig)
+}
+
+// saveEndpoint creates or updates an endpoint with POST /config. A renamed
+// endpoint is added under its new path before the old path is removed.
+func (m *Model) saveEndpoint(originalPath, path string, config types.EndpointConfig) tea.Cmd {
+	return func() tea.Msg {
+		body, err := json.Marshal(map[string]interface{}{"path": path, "config": config})
+		if err != nil {
+			return EndpointEditErrorMsg{Error: err.Error()}
+		}
+		if err := m.configRequest(http.MethodPost, m.httpURL+"/config", body); err != nil {
+			return EndpointEditErrorMsg{Error: err.Error()}
+		}
+
+		if originalPath != "" && originalPath != path {
+			if err := m.configRequest(http.MethodDelete, m.httpURL+"/config?path="+url.QueryEscape(originalPath), nil); err != nil {
+				return EndpointEditErrorMsg{Error: fmt.Sprintf("saved %s but failed to remove %s: %v", path, originalPath, err)}
+			}
+		}
+
+		if originalPath == "" {
+			return EndpointSavedMsg{Message: fmt.Sprintf("Added %s", path)}
+		}
+		return EndpointSavedMsg{Message: fmt.Sprintf("Saved %s", path)}
+	}
+}
+
+// deleteEndpoint removes an endpoint with DELETE /config
+func (m *Model) deleteEndpoint(path string) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.configRequest(http.MethodDelete, m.httpURL+"/config?path="+url.QueryEscape(path), nil); err != nil {
+			return EndpointEditErrorMsg{Error: err.Error()}
+		}
+		return EndpointSavedMsg{Message: fmt.Sprintf("Deleted %s", path)}
+	}
+}
+
+// configRequest sends a change to the config API, returning the server's error text on failure
+func (m *Model) configRequest(method, requestURL string, body []byte) error {
+	req, err := m.newRequest(method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := authError(resp.StatusCode); err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(resp.Body)
+		return errors.New(strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package tui
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"webserver/pkg/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// exportView writes what the active tab shows to a file in the working
+// directory: the filtered request log, or the statistics, as JSON or CSV. On
+// the Overview tab it saves a snapshot of everything, for browsing offline.
+func (m *Model) exportView(format string) tea.Cmd {
+	var name string
+	var write func(*os.File) error
+	var count int
+	switch m.activeTab {
+	case 0: // Overview tab
+		if format != "json" {
+			return nil
+		}
+		snapshot := m.newSnapshot()
+		name, count = "snapshot", len(snapshot.RequestLog)
+		write = func(file *os.File) error {
+			return writeJSON(file, snapshot)
+		}
+	case 2: // Statistics tab
+		if m.stats == nil {
+			return m.setNotice("No statistics to export yet")
+		}
+		stats := m.stats
+		name, count = "stats", len(stats.Endpoints)
+		write = func(file *os.File) error {
+			if format == "csv" {
+				return writeStatsCSV(file, stats)
+			}
+			return writeJSON(file, stats)
+		}
+	case 3: // Request Log tab
+		entries := m.filterRequestLog()
+		name, count = "requestlog", len(entries)
+		write = func(file *os.File) error {
+			if format == "csv" {
+				return writeRequestLogCSV(file, entries)
+			}
+			return writeJSON(file, entries)
+		}
+	default:
+		return nil
+	}
+
+	path := fmt.Sprintf("webserver-%s-%s.%s", name, time.Now().Format("20060102-150405"), format)
+	return func() tea.Msg {
+		file, err := os.Create(path)
+		if err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to export: %v", err)}
+		}
+		err = write(file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to export %s: %v", path, err)}
+		}
+		noun := "requests"
+		if name == "stats" {
+			noun = "endpoints"
+		}
+		return NoticeMsg{Notice: fmt.Sprintf("Exported %d %s to %s", count, noun, path)}
+	}
+}
+
+// writeJSON writes a value as indented JSON
+func writeJSON(file *os.File, value interface{}) error {
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// writeRequestLogCSV writes request log entries as CSV, one row per entry
+func writeRequestLogCSV(file *os.File, entries []types.RequestLogEntry) error {
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"id", "timestamp", "method", "path", "status_code", "duration_ms", "remote_addr", "tag", "slow"})
+	for _, entry := range entries {
+		writer.Write([]string{
+			strconv.FormatUint(entry.ID, 10),
+			entry.Timestamp.Format(time.RFC3339Nano),
+			entry.Method,
+			entry.Path,
+			strconv.Itoa(entry.StatusCode),
+			strconv.FormatInt(entry.Duration, 10),
+			entry.RemoteAddr,
+			entry.Tag,
+			strconv.FormatBool(entry.Slow),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeStatsCSV writes per-endpoint statistics as CSV, with the columns of GET /stats/export
+func writeStatsCSV(file *os.File, stats *types.ServerStats) error {
+	paths := make([]string, 0, len(stats.Endpoints))
+	for path := range stats.Endpoints {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{
+		"path", "request_count", "error_count", "success_count", "error_rate_pct",
+		"avg_time_ms", "min_time_ms", "max_time_ms", "status_codes",
+		"req_per_sec_1m", "err_per_sec_1m",
+	})
+	for _, path := range paths {
+		es := stats.Endpoints[path]
+		errorRate, avgTime := 0.0, 0.0
+		if es.RequestCount > 0 {
+			errorRate = float64(es.ErrorCount) / float64(es.RequestCount) * 100
+			avgTime = float64(es.TotalTimeMs) / float64(es.RequestCount)
+		}
+
+		codes := make([]int, 0, len(es.StatusCodes))
+		for code := range es.StatusCodes {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		statusCodes := make([]string, 0, len(codes))
+		for _, code := range codes {
+			statusCodes = append(statusCodes, fmt.Sprintf("%d:%d", code, es.StatusCodes[code]))
+		}
+
+		writer.Write([]string{
+			path,
+			strconv.FormatInt(es.RequestCount, 10),
+			strconv.FormatInt(es.ErrorCount, 10),
+			strconv.FormatInt(es.RequestCount-es.ErrorCount, 10),
+			strconv.FormatFloat(errorRate, 'f', 2, 64),
+			strconv.FormatFloat(avgTime, 'f', 2, 64),
+			strconv.FormatInt(es.MinTimeMs, 10),
+			strconv.FormatInt(es.MaxTimeMs, 10),
+			strings.Join(statusCodes, ";"),
+			strconv.FormatFloat(es.Rates.OneMinute.RequestsPerSec, 'f', 4, 64),
+			strconv.FormatFloat(es.Rates.OneMinute.ErrorsPerSec, 'f', 4, 64),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// textFilter is a parsed filter: a case-insensitive substring, a "re:"
+// regular expression or, in the Request Log, a "tag:" test tag. A leading
+// "!" keeps what the rest of the filter does not match, e.g. "!/health".
+type textFilter struct {
+	negate  bool
+	tag     string         // Exact test tag to match, for "tag:<name>"
+	isTag   bool           // whether the filter matches the test tag
+	pattern *regexp.Regexp // Pattern for substrings and regular expressions
+	err     error          // Why a regular expression did not compile
+}
+
+// parseTextFilter parses filter text; an empty filter matches everything
+func parseTextFilter(text string) textFilter {
+	var filter textFilter
+	if rest, ok := strings.CutPrefix(text, "!"); ok && rest != "" {
+		filter.negate = true
+		text = rest
+	}
+
+	if tag, ok := strings.CutPrefix(text, "tag:"); ok {
+		filter.tag, filter.isTag = tag, true
+		return filter
+	}
+	if expr, ok := strings.CutPrefix(text, "re:"); ok {
+		filter.pattern, filter.err = regexp.Compile("(?i)" + expr)
+		return filter
+	}
+	if text != "" {
+		filter.pattern = regexp.MustCompile("(?i)" + regexp.QuoteMeta(text))
+	}
+	return filter
+}
+
+// active reports whether the filter hides anything. An invalid regular
+// expression hides nothing, so the list stays visible while it is typed.
+func (f textFilter) active() bool {
+	return f.isTag || (f.pattern != nil && f.err == nil)
+}
+
+// keep reports whether an item with the given fields and test tag passes the filter
+func (f textFilter) keep(tag string, fields ...string) bool {
+	if !f.active() {
+		return true
+	}
+	matched := false
+	if f.isTag {
+		matched = tag == f.tag
+	} else {
+		for _, field := range fields {
+			if f.pattern.MatchString(field) {
+				matched = true
+				break
+			}
+		}
+	}
+	return matched != f.negate
+}
+
+// highlights returns the spans of text to highlight as matches. Negated
+// filters highlight nothing, as the items shown are the ones not matching.
+func (f textFilter) highlights(text string) [][]int {
+	if f.negate || !f.active() || f.isTag {
+		return nil
+	}
+	return f.pattern.FindAllStringIndex(text, -1)
+}
+
+// parseStatusClass returns the class (2-5) of a status class name, e.g. "4xx"
+func parseStatusClass(name string) (int, error) {
+	name = strings.ToLower(name)
+	if len(name) != 3 || name[0] < '2' || name[0] > '5' || name[1:] != "xx" {
+		return 0, fmt.Errorf("unknown status class %q (available: 2xx, 3xx, 4xx, 5xx)", name)
+	}
+	return int(name[0] - '0'), nil
+}
+
+// statusClassNames returns the Request Log status classes picked with 2-5,
+// e.g. ["4xx", "5xx"]
+func (m *Model) statusClassNames() []string {
+	var names []string
+	for class := 2; class <= 5; class++ {
+		if m.statusClasses[class] {
+			names = append(names, fmt.Sprintf("%dxx", class))
+		}
+	}
+	return names
+}
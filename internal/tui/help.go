@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpSection is a group of keyboard shortcuts listed on the Help tab
+type helpSection struct {
+	title string
+	tabs  []int // Tabs the keys apply to; nil for every tab
+	lines []string
+}
+
+// helpSections are the keyboard shortcuts, in the order the Help tab lists them
+var helpSections = []helpSection{
+	{"Navigation", nil, []string{
+		"• Tab             - Switch to next tab",
+		"• Shift+Tab       - Switch to previous tab",
+		"• [ / ]           - Switch to the previous/next monitored server",
+		"• +               - Add a server to monitor",
+	}},
+	{"Scrolling", nil, []string{
+		"• ↑ / k           - Scroll up one line",
+		"• ↓ / j           - Scroll down one line",
+		"• Page Up / u     - Scroll up half page",
+		"• Page Down / d   - Scroll down half page",
+		"• Home / g        - Go to top",
+		"• End / G         - Go to bottom",
+	}},
+	{"Search (any tab)", nil, []string{
+		"• /               - Search the tab's content (Enter: keep, Esc: cancel)",
+		"• n / N           - Jump to the next/previous match",
+		"• Esc             - Clear the search highlights",
+	}},
+	{"Mouse", nil, []string{
+		"• Click a tab     - Switch to it",
+		"• Wheel           - Scroll three lines",
+		"• Click a row     - Select a request or endpoint; click again to open it",
+		"• Shift+drag      - Select text (in most terminals)",
+	}},
+	{"Filtering", []int{1, 3}, []string{
+		"• F               - Enter/exit filter mode (Request Log & Configuration tabs)",
+		"• C               - Clear all filters (Request Log & Configuration tabs)",
+		"• Enter/Esc       - Exit filter mode (in filter mode)",
+		"• Backspace       - Delete filter characters (in filter mode)",
+	}},
+	{"Request Log Specific", []int{3}, []string{
+		"• S               - Toggle hide /stats requests",
+		"• 2 / 3 / 4 / 5   - Show only 2xx/3xx/4xx/5xx requests (toggles combine)",
+		"• A               - Toggle auto-refresh on/off",
+		"• L               - Follow the newest entry (tail -f); scrolling stops following",
+		"• V               - Group requests by normalized route",
+		"• O / Shift+O     - Sort by time, duration, status or path / reverse the order",
+		"• ↑ / ↓           - Move the cursor between requests (▶)",
+		"• Enter           - Open/close the detail pane (headers, query, body)",
+		"• Z / Shift+Z     - Fold/unfold a level of the JSON body in the detail pane",
+		"• ← / →           - Scroll long body lines sideways in the detail pane",
+		"• P               - Replay the selected request and show the fresh response",
+		"• X               - Show/hide curl command for the selected request",
+		"• Y / Shift+Y     - Copy the selected request to the clipboard as JSON / curl",
+		"• M               - Mark the selected request, then press again on another to diff them",
+	}},
+	{"Statistics Specific", []int{2}, []string{
+		"• ← / →           - Select the previous/next endpoint (▶)",
+		"• Enter           - Open/close the endpoint drill-down view",
+	}},
+	{"Configuration Specific", []int{1}, []string{
+		"• E               - Enter/exit endpoint edit mode",
+		"• O               - Edit the full configuration as JSON in $EDITOR",
+		"• Shift+S         - Start or stop a scenario; Enter toggles, X stops and restores",
+		"• N               - New endpoint wizard: type, fields, JSON preview (in edit mode)",
+		"• Enter           - Edit the selected endpoint, or save the form",
+		"• X / Delete      - Delete the selected endpoint (confirm with Y)",
+		"• Space           - Enable/disable the selected endpoint (in edit mode)",
+		"• G               - Enable/disable the selected endpoint's group (in edit mode)",
+		"• C               - Cycle the chaos profile of the selected endpoint's group (in edit mode)",
+		"• Tab / ←→        - Next form field / cycle endpoint type",
+		"• Esc             - Close the form or leave edit mode",
+	}},
+	{"Actions", nil, []string{
+		"• R               - Refresh data from server",
+		"• Shift+R         - Reset the server's statistics (confirm with Y)",
+		"• Shift+C         - Clear the server's request log (confirm with Y)",
+		"• Space           - Pause/resume updates; new data is buffered and shown on resume",
+		"• T               - Cycle color theme (dark, high-contrast, light)",
+		"• |               - Cycle layout: one tab, log and stats side by side, overview above log",
+		"• < / >           - Poll more / less often (250ms to 30s)",
+		"• B               - Low bandwidth: poll only changes, and charts only while shown",
+		"• W / Shift+W     - Export the filtered request log or statistics to JSON / CSV",
+		"• W (Overview)    - Save a snapshot to browse offline with -from-file",
+		"• ?               - Show the keys for the current tab over it",
+		"• Q / Ctrl+C      - Quit application",
+	}},
+}
+
+// helpOverlayView renders the "?" overlay: the keys of the active tab first,
+// then those that work everywhere, cut to fit the content area
+func (m *Model) helpOverlayView() string {
+	var sections []helpSection
+	for _, section := range helpSections {
+		for _, tab := range section.tabs {
+			if tab == m.activeTab {
+				sections = append(sections, section)
+			}
+		}
+	}
+	for _, section := range helpSections {
+		if section.tabs == nil {
+			sections = append(sections, section)
+		}
+	}
+
+	lines := []string{fmt.Sprintf("❓ Keys for the %s tab (any key closes)", tabs[m.activeTab].Name)}
+	for _, section := range sections {
+		lines = append(lines, "", section.title+":")
+		lines = append(lines, section.lines...)
+	}
+	if bindings := m.keys.customBindings(); len(bindings) > 0 {
+		lines = append(lines, "", "Custom key bindings:")
+		for _, binding := range bindings {
+			lines = append(lines, "• "+binding)
+		}
+	}
+	if len(lines) > m.viewportHeight {
+		lines = append(lines[:max(m.viewportHeight-1, 1)], "… more on the Help tab")
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(m.theme.Accent)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+}
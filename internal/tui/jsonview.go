@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// jsonNode is a parsed JSON value that keeps object keys in body order
+type jsonNode struct {
+	key      string // Encoded object key, e.g. `"id"`; empty in arrays and at the top
+	literal  string // Encoded scalar, e.g. `"text"`, `42` or `null`
+	open     byte   // '{' or '[' for objects and arrays, 0 for scalars
+	children []*jsonNode
+}
+
+// parseJSONTree parses a captured body, reporting false when it is not JSON
+func parseJSONTree(body string) (*jsonNode, bool) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	node, err := decodeJSONNode(decoder)
+	if err != nil {
+		return nil, false
+	}
+	// Anything after the value means the body was not a single JSON value
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, false
+	}
+	return node, true
+}
+
+// decodeJSONNode reads the next value from the decoder
+func decodeJSONNode(decoder *json.Decoder) (*jsonNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return &jsonNode{literal: encodeJSONScalar(token)}, nil
+	}
+
+	node := &jsonNode{open: byte(delim)}
+	for decoder.More() {
+		key := ""
+		if delim == '{' {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key = encodeJSONScalar(token)
+		}
+		child, err := decodeJSONNode(decoder)
+		if err != nil {
+			return nil, err
+		}
+		child.key = key
+		node.children = append(node.children, child)
+	}
+	// Consume the closing delimiter
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// encodeJSONScalar writes a decoded string, number, bool or null back as JSON
+// without escaping HTML characters
+func encodeJSONScalar(value interface{}) string {
+	if number, ok := value.(json.Number); ok {
+		return number.String()
+	}
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if encoder.Encode(value) != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// depth returns how deeply objects and arrays are nested, 1 for a flat object
+func (n *jsonNode) depth() int {
+	if n.open == 0 {
+		return 0
+	}
+	deepest := 0
+	for _, child := range n.children {
+		deepest = max(deepest, child.depth())
+	}
+	return deepest + 1
+}
+
+// jsonStyles color the parts of a highlighted JSON body
+type jsonStyles struct {
+	key, str, number, literal, folded lipgloss.Style
+}
+
+// newJSONStyles picks JSON colors from the theme
+func (m *Model) newJSONStyles() jsonStyles {
+	color := func(c string) lipgloss.Style { return lipgloss.NewStyle().Foreground(lipgloss.Color(c)) }
+	return jsonStyles{
+		key:     color(m.theme.Accent),
+		str:     color(m.theme.Success),
+		number:  color(m.theme.Warning),
+		literal: color(m.theme.Info),
+		folded:  color(m.theme.Dim),
+	}
+}
+
+// renderJSON renders the value as indented, highlighted lines. Objects and
+// arrays nested foldDepth or more levels deep are folded onto one line; a
+// foldDepth of 0 shows everything.
+func (n *jsonNode) renderJSON(styles jsonStyles, foldDepth int) []string {
+	var lines []string
+	n.render(&lines, styles, foldDepth, 0, "")
+	return lines
+}
+
+func (n *jsonNode) render(lines *[]string, styles jsonStyles, foldDepth, level int, comma string) {
+	prefix := strings.Repeat("  ", level)
+	if n.key != "" {
+		prefix += styles.key.Render(n.key) + ": "
+	}
+
+	if n.open == 0 {
+		*lines = append(*lines, prefix+n.styleScalar(styles)+comma)
+		return
+	}
+
+	end := "}"
+	if n.open == '[' {
+		end = "]"
+	}
+	if len(n.children) == 0 {
+		*lines = append(*lines, prefix+string(n.open)+end+comma)
+		return
+	}
+	if foldDepth > 0 && level >= foldDepth {
+		noun := "key"
+		if n.open == '[' {
+			noun = "item"
+		}
+		summary := fmt.Sprintf("%d %s", len(n.children), noun)
+		if len(n.children) != 1 {
+			summary += "s"
+		}
+		*lines = append(*lines, prefix+string(n.open)+styles.folded.Render("… "+summary)+end+comma)
+		return
+	}
+
+	*lines = append(*lines, prefix+string(n.open))
+	for i, child := range n.children {
+		childComma := ","
+		if i == len(n.children)-1 {
+			childComma = ""
+		}
+		child.render(lines, styles, foldDepth, level+1, childComma)
+	}
+	*lines = append(*lines, strings.Repeat("  ", level)+end+comma)
+}
+
+// styleScalar colors a string, number, bool or null
+func (n *jsonNode) styleScalar(styles jsonStyles) string {
+	switch {
+	case strings.HasPrefix(n.literal, `"`):
+		return styles.str.Render(n.literal)
+	case n.literal == "true" || n.literal == "false" || n.literal == "null":
+		return styles.literal.Render(n.literal)
+	}
+	return styles.number.Render(n.literal)
+}
+
+// paneBody returns the body shown in the open detail or replay pane
+func (m *Model) paneBody() string {
+	if m.replayResult != nil {
+		return m.replayResult.Response.Body
+	}
+	if m.detailEntry != nil {
+		return m.detailEntry.Body
+	}
+	return ""
+}
+
+// foldBody folds (1) or unfolds (-1) one more nesting level of the open
+// pane's JSON body
+func (m *Model) foldBody(step int) {
+	tree, ok := parseJSONTree(m.paneBody())
+	if !ok {
+		return
+	}
+	deepest := tree.depth()
+	depth := m.bodyFoldDepth
+	if depth == 0 {
+		depth = deepest
+	}
+	depth -= step
+	switch {
+	case depth < 1:
+		depth = 1
+	case depth >= deepest:
+		depth = 0 // Everything unfolded
+	}
+	m.bodyFoldDepth = depth
+}
+
+// scrollBody scrolls the open pane's body sideways by delta columns
+func (m *Model) scrollBody(delta int) {
+	m.bodyScrollX = max(m.bodyScrollX+delta, 0)
+}
@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keyAction is a normal-mode action and the keys bound to it by default. The
+// key handlers match an action by its first default key.
+type keyAction struct {
+	name string
+	keys []string
+}
+
+// keyActions are the actions that can be bound to other keys. Text input,
+// the endpoint editor and confirmation prompts keep their keys.
+var keyActions = []keyAction{
+	{"quit", []string{"q", "ctrl+c"}},
+	{"next_tab", []string{"tab"}},
+	{"previous_tab", []string{"shift+tab"}},
+	{"previous_server", []string{"["}},
+	{"next_server", []string{"]"}},
+	{"add_server", []string{"+"}},
+	{"up", []string{"up", "k"}},
+	{"down", []string{"down", "j"}},
+	{"page_up", []string{"pgup", "u"}},
+	{"page_down", []string{"pgdown", "d"}},
+	{"top", []string{"home", "g"}},
+	{"bottom", []string{"end", "G"}},
+	{"left", []string{"left"}},
+	{"right", []string{"right"}},
+	{"open", []string{"enter"}},
+	{"close", []string{"esc"}},
+	{"search", []string{"/"}},
+	{"next_match", []string{"n"}},
+	{"previous_match", []string{"N"}},
+	{"pause", []string{" "}},
+	{"refresh", []string{"r"}},
+	{"help", []string{"?"}},
+	{"layout", []string{"|"}},
+	{"shorter_interval", []string{"<"}},
+	{"longer_interval", []string{">"}},
+	{"low_bandwidth", []string{"b"}},
+	{"reset_stats", []string{"R"}},
+	{"clear_log", []string{"C"}},
+	{"theme", []string{"t"}},
+	{"export_json", []string{"w"}},
+	{"export_csv", []string{"W"}},
+	{"filter", []string{"f"}},
+	{"clear_filters", []string{"c"}},
+	{"hide_stats", []string{"s"}},
+	{"auto_refresh", []string{"a"}},
+	{"follow", []string{"l"}},
+	{"status_2xx", []string{"2"}},
+	{"status_3xx", []string{"3"}},
+	{"status_4xx", []string{"4"}},
+	{"status_5xx", []string{"5"}},
+	{"sort", []string{"o"}}, // Also edits the raw configuration in the Configuration tab
+	{"reverse_sort", []string{"O"}},
+	{"group", []string{"v"}},
+	{"replay", []string{"p"}},
+	{"curl", []string{"x"}},
+	{"copy_json", []string{"y"}},
+	{"copy_curl", []string{"Y"}},
+	{"mark_diff", []string{"m"}},
+	{"fold", []string{"z"}},
+	{"unfold", []string{"Z"}},
+	{"edit", []string{"e"}},
+	{"scenarios", []string{"S"}},
+}
+
+// KeyNames is one key or a list of keys, as written in the client config
+type KeyNames []string
+
+// UnmarshalJSON accepts a single key as well as a list
+func (k *KeyNames) UnmarshalJSON(data []byte) error {
+	var key string
+	if err := json.Unmarshal(data, &key); err == nil {
+		*k = KeyNames{key}
+		return nil
+	}
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("keys must be a string or a list of strings")
+	}
+	*k = keys
+	return nil
+}
+
+// UnmarshalYAML accepts a single key as well as a list
+func (k *KeyNames) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*k = KeyNames{value.Value}
+		return nil
+	}
+	var keys []string
+	if err := value.Decode(&keys); err != nil {
+		return fmt.Errorf("keys must be a string or a list of strings")
+	}
+	*k = keys
+	return nil
+}
+
+// KeyMap translates the keys pressed in normal mode to the default key of
+// the action they are bound to
+type KeyMap struct {
+	keys   map[string]string   // Pressed key to the action's default key
+	custom map[string][]string // Actions bound to other than their default keys
+}
+
+// DefaultKeyMap returns the built-in key bindings
+func DefaultKeyMap() KeyMap {
+	keyMap, _ := newKeyMap(nil)
+	return keyMap
+}
+
+// KeyMap returns the key bindings with the configured actions rebound.
+// Rebinding an action replaces its default keys.
+func (c *ClientConfig) KeyMap() (KeyMap, error) {
+	return newKeyMap(c.Keys)
+}
+
+func newKeyMap(bindings map[string]KeyNames) (KeyMap, error) {
+	keyMap := KeyMap{keys: make(map[string]string), custom: make(map[string][]string)}
+	actions := make(map[string]string)
+	for name := range bindings {
+		if !knownKeyAction(name) {
+			names := make([]string, 0, len(keyActions))
+			for _, action := range keyActions {
+				names = append(names, action.name)
+			}
+			sort.Strings(names)
+			return KeyMap{}, fmt.Errorf("unknown key action %q (available: %s)", name, strings.Join(names, ", "))
+		}
+	}
+
+	for _, action := range keyActions {
+		keys := action.keys
+		if custom, ok := bindings[action.name]; ok {
+			keys = make([]string, len(custom))
+			for i, key := range custom {
+				// Spaces are easy to miss in JSON
+				if key == "space" {
+					key = " "
+				}
+				keys[i] = key
+			}
+			keyMap.custom[action.name] = keys
+		}
+		for _, key := range keys {
+			if other, taken := actions[key]; taken {
+				return KeyMap{}, fmt.Errorf("key %q is bound to both %s and %s", key, other, action.name)
+			}
+			actions[key] = action.name
+			keyMap.keys[key] = action.keys[0]
+		}
+	}
+
+	// Ctrl+C quits unless it was bound to something else
+	if _, taken := actions["ctrl+c"]; !taken {
+		keyMap.keys["ctrl+c"] = "q"
+	}
+	return keyMap, nil
+}
+
+// knownKeyAction reports whether name is an action keys can be bound to
+func knownKeyAction(name string) bool {
+	for _, action := range keyActions {
+		if action.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the default key of the action a pressed key is bound to,
+// or an empty string for unbound keys
+func (k KeyMap) resolve(key string) string {
+	return k.keys[key]
+}
+
+// customBindings describes the rebound actions for the help tab, e.g.
+// "down: ctrl+n, j"
+func (k KeyMap) customBindings() []string {
+	var lines []string
+	for _, action := range keyActions {
+		keys, ok := k.custom[action.name]
+		if !ok {
+			continue
+		}
+		names := make([]string, len(keys))
+		for i, key := range keys {
+			names[i] = key
+			if key == " " {
+				names[i] = "space"
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", action.name, strings.Join(names, ", ")))
+	}
+	return lines
+}
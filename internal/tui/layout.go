@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// paneLayout is how many tabs are shown at once
+type paneLayout int
+
+const (
+	layoutTabs       paneLayout = iota // One tab at a time
+	layoutSideBySide                   // Request Log and Statistics next to each other
+	layoutStacked                      // Overview above the Request Log
+)
+
+var paneLayoutNames = []string{"tabs", "side-by-side", "stacked"}
+
+// Terminal sizes below which the split layouts fall back to one tab
+const (
+	minSideBySideWidth = 160
+	minStackedHeight   = 40
+)
+
+// parsePaneLayout returns the layout with the given name; empty means tabs
+func parsePaneLayout(name string) (paneLayout, error) {
+	if name == "" {
+		return layoutTabs, nil
+	}
+	for i, layoutName := range paneLayoutNames {
+		if strings.EqualFold(name, layoutName) {
+			return paneLayout(i), nil
+		}
+	}
+	return layoutTabs, fmt.Errorf("unknown layout %q (available: %s)", name, strings.Join(paneLayoutNames, ", "))
+}
+
+// cycleLayout switches to the next layout
+func (m *Model) cycleLayout() tea.Cmd {
+	m.paneLayout = (m.paneLayout + 1) % paneLayout(len(paneLayoutNames))
+	notice := fmt.Sprintf("Layout: %s", paneLayoutNames[m.paneLayout])
+	switch {
+	case m.paneLayout == layoutSideBySide && m.width < minSideBySideWidth:
+		notice += fmt.Sprintf(" (needs %d columns)", minSideBySideWidth)
+	case m.paneLayout == layoutStacked && m.height < minStackedHeight:
+		notice += fmt.Sprintf(" (needs %d rows)", minStackedHeight)
+	case m.paneLayout == layoutSideBySide:
+		notice += " (Request Log and Statistics tabs)"
+	case m.paneLayout == layoutStacked:
+		notice += " (Overview and Request Log tabs)"
+	}
+	return m.setNotice(notice)
+}
+
+// splitPanes returns the tabs shown together, the first on the left or top,
+// when the layout splits the active tab and the terminal is big enough
+func (m *Model) splitPanes() ([2]int, bool) {
+	switch {
+	case m.paneLayout == layoutSideBySide && m.width >= minSideBySideWidth && (m.activeTab == 2 || m.activeTab == 3):
+		return [2]int{3, 2}, true
+	case m.paneLayout == layoutStacked && m.height >= minStackedHeight && (m.activeTab == 0 || m.activeTab == 3):
+		return [2]int{0, 3}, true
+	}
+	return [2]int{}, false
+}
+
+// renderPanes renders two tabs in the content area. Each is rendered as if
+// the terminal were the size of its pane; keys go to the active tab, whose
+// pane has the accent border.
+func (m *Model) renderPanes(panes [2]int) string {
+	width, viewportHeight, style := m.width, m.viewportHeight, m.contentStyle
+	defer func() { m.width, m.viewportHeight, m.contentStyle = width, viewportHeight, style }()
+
+	// Stacked panes share the rows of one viewport and its border and padding
+	topHeight := (viewportHeight - 6) / 2
+	rendered := make([]string, len(panes))
+	for i, tab := range panes {
+		m.width, m.viewportHeight = width, viewportHeight
+		if m.paneLayout == layoutSideBySide {
+			m.width = width / 2
+		} else if i == 0 {
+			m.viewportHeight = topHeight
+		} else {
+			m.viewportHeight = viewportHeight - 6 - topHeight
+		}
+		m.viewportHeight = max(m.viewportHeight, 3)
+
+		m.contentStyle = style.Width(m.width - 2)
+		if tab == m.activeTab {
+			m.contentStyle = m.contentStyle.BorderForeground(lipgloss.Color(m.theme.Accent))
+		}
+
+		// Cut lines to the pane so they do not wrap
+		lines := strings.Split(tabs[tab].View(m), "\n")
+		for j, line := range lines {
+			lines[j] = ansi.Truncate(line, m.width-6, "…")
+		}
+		rendered[i] = m.renderScrollableContent(strings.Join(lines, "\n"), tab)
+	}
+
+	if m.paneLayout == layoutSideBySide {
+		return lipgloss.JoinHorizontal(lipgloss.Top, rendered[:]...)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rendered[:]...)
+}
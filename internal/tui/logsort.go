@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"webserver/pkg/types"
+)
+
+// requestLogSort is the column the Request Log is ordered by
+type requestLogSort int
+
+const (
+	sortByTime requestLogSort = iota
+	sortByDuration
+	sortByStatus
+	sortByPath
+)
+
+// requestLogSortNames are the sort columns' names, in the order O cycles them
+var requestLogSortNames = []string{"time", "duration", "status", "path"}
+
+// parseLogSort returns the sort column with the given name
+func parseLogSort(name string) (requestLogSort, error) {
+	for i, sortName := range requestLogSortNames {
+		if strings.EqualFold(name, sortName) {
+			return requestLogSort(i), nil
+		}
+	}
+	return sortByTime, fmt.Errorf("unknown sort %q (available: %s)", name, strings.Join(requestLogSortNames, ", "))
+}
+
+// cycleLogSort orders the Request Log by the next column, starting with the
+// direction that puts the interesting entries first: newest, slowest, highest
+// status code, or paths A to Z
+func (m *Model) cycleLogSort() {
+	m.logSort = (m.logSort + 1) % requestLogSort(len(requestLogSortNames))
+	m.logSortAscending = m.logSort == sortByPath
+	m.scrollPositions[3] = 0
+}
+
+// reverseLogSort flips the direction of the Request Log ordering
+func (m *Model) reverseLogSort() {
+	m.logSortAscending = !m.logSortAscending
+	m.scrollPositions[3] = 0
+}
+
+// sortRequestLog orders entries by the selected column. The sort is stable,
+// so entries that tie keep their newest-first order.
+func (m *Model) sortRequestLog(entries []types.RequestLogEntry) {
+	if m.logSort == sortByTime && !m.logSortAscending {
+		return // The log is kept newest first
+	}
+
+	less := func(a, b types.RequestLogEntry) bool {
+		switch m.logSort {
+		case sortByDuration:
+			return a.Duration < b.Duration
+		case sortByStatus:
+			return a.StatusCode < b.StatusCode
+		case sortByPath:
+			return a.Path < b.Path
+		default:
+			return a.Timestamp.Before(b.Timestamp)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if m.logSortAscending {
+			return less(entries[i], entries[j])
+		}
+		return less(entries[j], entries[i])
+	})
+}
+
+// logSortDescription describes the Request Log ordering, e.g. "slowest first"
+func (m *Model) logSortDescription() string {
+	switch m.logSort {
+	case sortByDuration:
+		if m.logSortAscending {
+			return "ordered by duration, fastest first"
+		}
+		return "ordered by duration, slowest first"
+	case sortByStatus:
+		if m.logSortAscending {
+			return "ordered by status code, lowest first"
+		}
+		return "ordered by status code, highest first"
+	case sortByPath:
+		if m.logSortAscending {
+			return "ordered by path, A to Z"
+		}
+		return "ordered by path, Z to A"
+	default:
+		if m.logSortAscending {
+			return "ordered by request time, oldest first"
+		}
+		return "ordered by request time, newest first"
+	}
+}
@@ -0,0 +1,118 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// wheelLines is how far one mouse wheel step scrolls
+const wheelLines = 3
+
+// mouseLayout records where View placed the clickable parts of the screen
+type mouseLayout struct {
+	tabBarY  int   // Screen row of the tab bar
+	tabEnds  []int // Column just past each tab
+	contentY int   // Screen row of the first visible content line
+}
+
+// handleMouse scrolls on wheel events, switches tabs on clicks in the tab bar
+// and selects rows on clicks in the Statistics and Request Log tabs
+func (m *Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// Text input and the endpoint editor are keyboard-only
+	if m.filterMode || m.configFilterMode || m.addServerMode || m.searchMode || m.confirmReset != nil || m.helpOverlay || ((m.editor != nil || m.scenarioPicker != nil) && m.activeTab == 1) {
+		return m, nil
+	}
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.scrollBy(-wheelLines)
+		return m, nil
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.scrollBy(wheelLines)
+		return m, nil
+	case msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress:
+		return m, nil
+	}
+
+	if msg.Y == m.layout.tabBarY {
+		for i, end := range m.layout.tabEnds {
+			if msg.X < end {
+				m.activeTab = i
+				break
+			}
+		}
+		return m, nil
+	}
+
+	// Rows are only clickable when one tab fills the content area
+	if _, split := m.splitPanes(); split {
+		return m, nil
+	}
+
+	line := m.scrollPositions[m.activeTab] + msg.Y - m.layout.contentY
+	if msg.Y < m.layout.contentY || line >= m.contentHeights[m.activeTab] {
+		return m, nil
+	}
+
+	switch m.activeTab {
+	case 2: // Statistics tab
+		if m.drillDownPath != "" {
+			return m, nil
+		}
+		for path, pathLine := range m.statsEndpointLines {
+			if pathLine != line {
+				continue
+			}
+			// A click on the selected endpoint opens its drill-down view
+			if selected, _ := m.selectedStatsPath(); selected == path {
+				m.openDrillDown()
+			} else {
+				m.statsSelected = path
+			}
+		}
+
+	case 3: // Request Log tab
+		if m.requestPaneOpen() {
+			return m, nil
+		}
+		compact := m.requestLogColumns().compact
+		for _, row := range m.requestLogRows {
+			// Compact entries take two lines
+			if row.line != line && !(compact && row.line+1 == line) {
+				continue
+			}
+			// A click on the selected entry opens its detail pane
+			if index, selected := m.selectedLogRow(); selected && m.requestLogRows[index].entry.ID == row.entry.ID {
+				entry := row.entry
+				m.openRequestPane()
+				m.detailEntry = &entry
+			} else {
+				m.selectedLogID = row.entry.ID
+				m.pauseRequestLog()
+			}
+		}
+	}
+	return m, nil
+}
+
+// scrollBy scrolls the active tab, pausing the request log like the scroll keys do
+func (m *Model) scrollBy(delta int) {
+	maxScroll := m.contentHeights[m.activeTab] - m.viewportHeight
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	position := m.scrollPositions[m.activeTab] + delta
+	if position > maxScroll {
+		position = maxScroll
+	}
+	if position < 0 {
+		position = 0
+	}
+	if position == m.scrollPositions[m.activeTab] {
+		return
+	}
+	m.scrollPositions[m.activeTab] = position
+
+	if m.activeTab == 3 { // Request Log tab
+		m.pauseRequestLog()
+	}
+}
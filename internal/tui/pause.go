@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"webserver/pkg/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// togglePause freezes the data shown, or resumes and back-fills what arrived
+// while paused
+func (m *Model) togglePause() tea.Cmd {
+	if !m.paused {
+		m.paused = true
+		return nil
+	}
+
+	m.paused = false
+	queued := m.pausedMsgs
+	m.pausedMsgs = nil
+	var cmds []tea.Cmd
+	for _, msg := range queued {
+		_, cmd := m.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	return tea.Batch(cmds...)
+}
+
+// bufferWhilePaused queues a data message to apply on resume, reporting
+// whether it was one. Snapshots replace what they supersede and statistics
+// deltas are merged, so only pushed request log entries accumulate, up to
+// the request log limit.
+func (m *Model) bufferWhilePaused(msg tea.Msg) bool {
+	switch msg := msg.(type) {
+	case StatsUpdateMsg:
+		for i, queued := range m.pausedMsgs {
+			if update, ok := queued.(StatsUpdateMsg); ok {
+				m.pausedMsgs[i] = StatsUpdateMsg{Stats: types.MergeStatsUpdate(update.Stats, msg.Stats)}
+				return true
+			}
+		}
+	case StatsMsg:
+		m.dropPaused(func(queued tea.Msg) bool {
+			_, snapshot := queued.(StatsMsg)
+			_, update := queued.(StatsUpdateMsg)
+			return snapshot || update
+		})
+	case RequestLogMsg:
+		m.dropPaused(func(queued tea.Msg) bool {
+			_, snapshot := queued.(RequestLogMsg)
+			_, entry := queued.(RequestLogEntryMsg)
+			_, changes := queued.(RequestLogChangesMsg)
+			return snapshot || entry || changes
+		})
+	case RequestLogChangesMsg:
+		for i, queued := range m.pausedMsgs {
+			if earlier, ok := queued.(RequestLogChangesMsg); ok {
+				entries := append(msg.Entries, earlier.Entries...)
+				if limit := m.requestLogLimit(); len(entries) > limit {
+					entries = entries[:limit]
+				}
+				m.pausedMsgs[i] = RequestLogChangesMsg{Entries: entries, Position: msg.Position}
+				return true
+			}
+		}
+	case RequestLogEntryMsg:
+		if m.pausedEntries() >= m.requestLogLimit() {
+			dropped := false
+			m.dropPaused(func(queued tea.Msg) bool {
+				_, entry := queued.(RequestLogEntryMsg)
+				if entry && !dropped {
+					dropped = true
+					return true
+				}
+				return false
+			})
+		}
+	case ConfigMsg:
+		m.dropPaused(func(queued tea.Msg) bool { _, ok := queued.(ConfigMsg); return ok })
+	case WSClientsMsg:
+		m.dropPaused(func(queued tea.Msg) bool { _, ok := queued.(WSClientsMsg); return ok })
+	case TimeSeriesMsg:
+		m.dropPaused(func(queued tea.Msg) bool { _, ok := queued.(TimeSeriesMsg); return ok })
+	case ServerSummaryMsg:
+		m.dropPaused(func(queued tea.Msg) bool {
+			summary, ok := queued.(ServerSummaryMsg)
+			return ok && summary.Server == msg.Server
+		})
+	default:
+		return false
+	}
+	m.pausedMsgs = append(m.pausedMsgs, msg)
+	return true
+}
+
+// dropPaused removes the queued messages a newer one supersedes
+func (m *Model) dropPaused(superseded func(tea.Msg) bool) {
+	kept := m.pausedMsgs[:0]
+	for _, queued := range m.pausedMsgs {
+		if !superseded(queued) {
+			kept = append(kept, queued)
+		}
+	}
+	m.pausedMsgs = kept
+}
+
+// pausedEntries counts the request log entries that arrived while paused
+func (m *Model) pausedEntries() int {
+	count := 0
+	for _, queued := range m.pausedMsgs {
+		switch queued := queued.(type) {
+		case RequestLogEntryMsg:
+			count++
+		case RequestLogMsg:
+			// A polled snapshot: count the entries newer than those shown
+			for _, entry := range queued.Entries {
+				if len(m.requestLog) == 0 || entry.ID > m.requestLog[0].ID {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
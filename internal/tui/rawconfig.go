@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"webserver/pkg/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rawConfigEdit is the state of editing the full configuration as JSON. The
+// buffer is edited in $EDITOR; while it is rejected, the Configuration tab
+// shows it with the error so it can be reopened and fixed.
+type rawConfigEdit struct {
+	buffer   string // JSON being edited
+	err      string // Why the last attempt was rejected
+	errLine  int    // 1-based line of a JSON syntax error; 0 when unknown
+	applying bool
+}
+
+// editorCommand returns the user's editor, as configured by $VISUAL or $EDITOR
+func editorCommand() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(name)); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// openRawConfigEditor starts editing the current configuration, or the
+// rejected buffer if there is one
+func (m *Model) openRawConfigEditor() tea.Cmd {
+	if m.rawConfig == nil {
+		data, err := json.MarshalIndent(m.config, "", "  ")
+		if err != nil {
+			m.lastError = fmt.Sprintf("Failed to encode config: %v", err)
+			return nil
+		}
+		m.rawConfig = &rawConfigEdit{buffer: string(data) + "\n"}
+	}
+
+	file, err := os.CreateTemp("", "webserver-config-*.json")
+	if err != nil {
+		m.rawConfig.err = fmt.Sprintf("failed to create temporary file: %v", err)
+		return nil
+	}
+	_, err = file.WriteString(m.rawConfig.buffer)
+	file.Close()
+	if err != nil {
+		os.Remove(file.Name())
+		m.rawConfig.err = fmt.Sprintf("failed to write temporary file: %v", err)
+		return nil
+	}
+
+	// The editor may carry arguments, e.g. "code --wait"
+	args := strings.Fields(editorCommand())
+	cmd := exec.Command(args[0], append(args[1:], file.Name())...)
+	path := file.Name()
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return RawConfigEditedMsg{Error: fmt.Sprintf("editor failed: %v", err)}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return RawConfigEditedMsg{Error: fmt.Sprintf("failed to read edited config: %v", err)}
+		}
+		return RawConfigEditedMsg{Buffer: string(data)}
+	})
+}
+
+// handleRawConfigEdited checks the edited buffer locally, then has the server validate it
+func (m *Model) handleRawConfigEdited(msg RawConfigEditedMsg) tea.Cmd {
+	edit := m.rawConfig
+	if edit == nil {
+		return nil
+	}
+	if msg.Error != "" {
+		edit.err, edit.errLine = msg.Error, 0
+		return nil
+	}
+
+	unchanged := msg.Buffer == edit.buffer && edit.err == ""
+	edit.buffer = msg.Buffer
+	if unchanged {
+		m.rawConfig = nil
+		return nil
+	}
+
+	var config types.Config
+	if err := json.Unmarshal([]byte(edit.buffer), &config); err != nil {
+		edit.err, edit.errLine = fmt.Sprintf("Invalid JSON: %v", err), 0
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			edit.errLine = 1 + strings.Count(edit.buffer[:syntaxErr.Offset], "\n")
+		}
+		return nil
+	}
+
+	edit.err, edit.errLine = "", 0
+	edit.applying = true
+	return m.applyRawConfig(edit.buffer)
+}
+
+// handleRawConfigKey handles key presses while an edited configuration is
+// shown; navigation keys are left to the normal key handling
+func (m *Model) handleRawConfigKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "o", "enter":
+		if !m.rawConfig.applying {
+			return m.openRawConfigEditor(), true
+		}
+	case "esc":
+		if !m.rawConfig.applying {
+			m.rawConfig = nil
+		}
+	}
+	switch m.keys.resolve(msg.String()) {
+	case "q", "tab", "shift+tab", "up", "down", "pgup", "pgdown", "home", "end":
+		return nil, false
+	}
+	return nil, true
+}
+
+// applyRawConfig validates the configuration with POST /config/validate and,
+// if it is accepted, applies it with PUT /config
+func (m *Model) applyRawConfig(buffer string) tea.Cmd {
+	return func() tea.Msg {
+		client := &http.Client{Timeout: 5 * time.Second}
+		req, err := m.newRequest(http.MethodPost, m.httpURL+"/config/validate", strings.NewReader(buffer))
+		if err != nil {
+			return RawConfigResultMsg{Error: err.Error()}
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return RawConfigResultMsg{Error: fmt.Sprintf("Failed to validate config: %v", err)}
+		}
+		if err := authError(resp.StatusCode); err != nil {
+			resp.Body.Close()
+			return RawConfigResultMsg{Error: err.Error()}
+		}
+		var validation types.ConfigValidation
+		err = json.NewDecoder(resp.Body).Decode(&validation)
+		resp.Body.Close()
+		if err != nil {
+			return RawConfigResultMsg{Error: fmt.Sprintf("Validation request failed: %d", resp.StatusCode)}
+		}
+		if !validation.Valid {
+			return RawConfigResultMsg{Error: validation.Error}
+		}
+
+		req, err = m.newRequest(http.MethodPut, m.httpURL+"/config", bytes.NewReader([]byte(buffer)))
+		if err != nil {
+			return RawConfigResultMsg{Error: err.Error()}
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err = client.Do(req)
+		if err != nil {
+			return RawConfigResultMsg{Error: fmt.Sprintf("Failed to apply config: %v", err)}
+		}
+		defer resp.Body.Close()
+		if err := authError(resp.StatusCode); err != nil {
+			return RawConfigResultMsg{Error: err.Error()}
+		}
+		if resp.StatusCode != http.StatusOK {
+			message, _ := io.ReadAll(resp.Body)
+			return RawConfigResultMsg{Error: strings.TrimSpace(string(message))}
+		}
+		return RawConfigResultMsg{}
+	}
+}
@@ -0,0 +1,208 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"webserver/pkg/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// refreshIntervals are the steps < and > move the refresh interval through
+var refreshIntervals = []time.Duration{
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+}
+
+// lowBandwidthResyncPolls is how many polls apart low-bandwidth mode fetches
+// everything again, catching up with configuration changes, statistics
+// resets and logs cleared by other clients
+const lowBandwidthResyncPolls = 30
+
+// RequestLogChangesMsg carries the entries logged since the last fetch
+type RequestLogChangesMsg struct {
+	Entries  []types.RequestLogEntry
+	Position uint64 // X-Request-Log-Position to fetch the next changes from
+}
+
+// stepRefreshInterval switches to the next shorter (-1) or longer (1)
+// refresh interval
+func (m *Model) stepRefreshInterval(step int) tea.Cmd {
+	// An interval from the client config may fall between two steps
+	i := sort.Search(len(refreshIntervals), func(i int) bool { return refreshIntervals[i] >= m.refreshInterval })
+	next := m.refreshInterval
+	switch {
+	case step < 0 && i > 0:
+		next = refreshIntervals[i-1]
+	case step > 0 && i < len(refreshIntervals) && refreshIntervals[i] > m.refreshInterval:
+		next = refreshIntervals[i]
+	case step > 0 && i+1 < len(refreshIntervals):
+		next = refreshIntervals[i+1]
+	}
+	m.refreshInterval = next
+
+	cmds := []tea.Cmd{m.setNotice(fmt.Sprintf("Refresh interval: %s", next))}
+	if m.live != nil {
+		// Pushed statistics follow the refresh interval too
+		live := m.live
+		cmds = append(cmds, func() tea.Msg {
+			if err := live.subscribeStats(next); err != nil {
+				return ErrorMsg{Error: fmt.Sprintf("Failed to change the statistics push interval: %v", err)}
+			}
+			return nil
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// statsIntervalMs returns the statistics push interval for a refresh interval
+func statsIntervalMs(interval time.Duration) int {
+	return max(int(interval.Milliseconds()), types.MinStatsIntervalMs)
+}
+
+// toggleLowBandwidth switches between fetching everything on each poll and
+// fetching only what changed
+func (m *Model) toggleLowBandwidth() tea.Cmd {
+	m.lowBandwidth = !m.lowBandwidth
+	if m.lowBandwidth {
+		return m.setNotice("Low bandwidth: polling only changes, charts only while shown")
+	}
+	return m.setNotice("Low bandwidth off: polling everything")
+}
+
+// pollingMode describes low-bandwidth polling after the refresh interval
+func (m *Model) pollingMode() string {
+	if m.lowBandwidth {
+		return ", changes only"
+	}
+	return ""
+}
+
+// showsCharts reports whether the client list and traffic charts are worth
+// polling: always, except in low-bandwidth mode while no tab shown has them
+func (m *Model) showsCharts() bool {
+	if !m.lowBandwidth || m.activeTab == 0 || m.activeTab == 2 {
+		return true
+	}
+	// Both split layouts include the Overview or the Statistics tab
+	_, split := m.splitPanes()
+	return split
+}
+
+// fetchChanges returns the low-bandwidth poll: the statistics of endpoints
+// hit since the last poll and the request log entries added since then.
+// Configuration is left to the periodic resync.
+func (m *Model) fetchChanges() []tea.Cmd {
+	var cmds []tea.Cmd
+	if m.stats == nil {
+		cmds = append(cmds, m.fetchStats)
+	} else {
+		cmds = append(cmds, m.fetchStatsChanges(m.stats))
+	}
+
+	if m.autoRefresh {
+		if m.logPosition == 0 {
+			cmds = append(cmds, m.fetchRequestLog)
+		} else {
+			cmds = append(cmds, m.fetchRequestLogChanges(m.logPosition))
+		}
+	}
+
+	if m.showsCharts() {
+		cmds = append(cmds, m.fetchWebSocketClients, m.fetchTimeSeries)
+	}
+	return append(cmds, m.fetchServerSummaries()...)
+}
+
+// fetchStatsChanges fetches the statistics of endpoints hit since the latest
+// request in current, to merge into it. Fewer requests than current counts
+// means the statistics were reset, and everything is fetched instead.
+func (m *Model) fetchStatsChanges(current *types.ServerStats) tea.Cmd {
+	var since time.Time
+	for _, endpoint := range current.Endpoints {
+		if endpoint.LastRequest.After(since) {
+			since = endpoint.LastRequest
+		}
+	}
+	requestCount := current.RequestCount
+
+	return func() tea.Msg {
+		resp, err := m.get(m.httpURL + "/stats?changed_since=" + url.QueryEscape(since.Format(time.RFC3339Nano)))
+		if err != nil {
+			return fetchError("Failed to fetch stats", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return ErrorMsg{Error: fmt.Sprintf("Stats request failed: %d", resp.StatusCode)}
+		}
+
+		var stats types.ServerStats
+		if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to parse stats: %v", err)}
+		}
+		if stats.RequestCount < requestCount {
+			return m.fetchStats()
+		}
+		return StatsUpdateMsg{Stats: &stats}
+	}
+}
+
+// fetchRequestLogChanges fetches the request log entries added after position
+func (m *Model) fetchRequestLogChanges(position uint64) tea.Cmd {
+	return func() tea.Msg {
+		resp, err := m.get(fmt.Sprintf("%s/requestlog?since=%d", m.httpURL, position))
+		if err != nil {
+			return fetchError("Failed to fetch request log", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return ErrorMsg{Error: fmt.Sprintf("Request log request failed: %d", resp.StatusCode)}
+		}
+
+		var entries []types.RequestLogEntry
+		if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to parse request log: %v", err)}
+		}
+		position := requestLogPosition(resp)
+		if position == 0 {
+			// A server without ?since sent its whole log
+			return RequestLogMsg{Entries: entries}
+		}
+		return RequestLogChangesMsg{Entries: entries, Position: position}
+	}
+}
+
+// requestLogPosition reads the X-Request-Log-Position header; 0 when the
+// server does not send it
+func requestLogPosition(resp *http.Response) uint64 {
+	position, _ := strconv.ParseUint(resp.Header.Get(types.RequestLogPositionHeader), 10, 64)
+	return position
+}
+
+// applyRequestLogChanges adds newly logged entries to the request log
+func (m *Model) applyRequestLogChanges(msg RequestLogChangesMsg) {
+	m.logPosition = msg.Position
+	if len(msg.Entries) == 0 {
+		return
+	}
+	m.requestLog = append(msg.Entries, m.requestLog...)
+	sort.SliceStable(m.requestLog, func(i, j int) bool {
+		return m.requestLog[i].Timestamp.After(m.requestLog[j].Timestamp)
+	})
+	if limit := m.requestLogLimit(); len(m.requestLog) > limit {
+		m.requestLog = m.requestLog[:limit]
+	}
+}
@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"fmt"
+	"net/http"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// serverReset is server data that can be wiped from the TUI after confirming
+type serverReset struct {
+	prompt string // What the confirmation asks, e.g. "Reset statistics"
+	action string // The same in an error message, e.g. "reset statistics"
+	path   string // API path deleted to wipe it
+	done   string // Notice shown once wiped
+}
+
+var (
+	resetStats    = &serverReset{prompt: "Reset statistics", action: "reset statistics", path: "/stats", done: "Statistics reset"}
+	clearRequests = &serverReset{prompt: "Clear the request log", action: "clear the request log", path: "/requestlog", done: "Request log cleared"}
+)
+
+// handleResetConfirmKey answers the confirmation prompt: "y" wipes the data,
+// any other key cancels
+func (m *Model) handleResetConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	reset := m.confirmReset
+	m.confirmReset = nil
+	switch msg.String() {
+	case "y":
+		// Pushed updates follow the reset; the refetch covers HTTP polling
+		return m, tea.Sequence(m.resetServerData(reset), m.fetchStats, m.fetchRequestLog)
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+	return m, m.setNotice(reset.prompt + " cancelled")
+}
+
+// resetServerData wipes the data on the server with a DELETE request
+func (m *Model) resetServerData(reset *serverReset) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.configRequest(http.MethodDelete, m.httpURL+reset.path, nil); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to %s: %v", reset.action, err)}
+		}
+		return NoticeMsg{Notice: fmt.Sprintf("%s on %s", reset.done, m.httpURL)}
+	}
+}
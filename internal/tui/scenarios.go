@@ -0,0 +1,199 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"webserver/pkg/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// scenarioPicker lists the server's scenarios to start or stop one
+// (Configuration tab); nil when closed
+type scenarioPicker struct {
+	cursor int
+	status string // Outcome of the last start or stop
+}
+
+// ScenariosMsg carries the server's scenarios; List is nil when the server
+// predates GET /scenarios
+type ScenariosMsg struct{ List *types.ScenarioList }
+
+// ScenarioChangedMsg reports that a scenario was started or stopped
+type ScenarioChangedMsg struct{ Message string }
+
+// fetchScenarios fetches the scenarios and the endpoints they changed
+func (m *Model) fetchScenarios() tea.Msg {
+	resp, err := m.get(m.httpURL + "/scenarios")
+	if err != nil {
+		return fetchError("Failed to fetch scenarios", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ScenariosMsg{}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return ErrorMsg{Error: fmt.Sprintf("Scenarios request failed: %d", resp.StatusCode)}
+	}
+
+	var list types.ScenarioList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return ErrorMsg{Error: fmt.Sprintf("Failed to parse scenarios: %v", err)}
+	}
+	return ScenariosMsg{List: &list}
+}
+
+// startScenario asks the server to play a scenario
+func (m *Model) startScenario(name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.configRequest(http.MethodPost, m.httpURL+"/scenarios?name="+url.QueryEscape(name), nil); err != nil {
+			return ScenarioChangedMsg{Message: "Error: " + err.Error()}
+		}
+		return ScenarioChangedMsg{Message: fmt.Sprintf("Started %s", name)}
+	}
+}
+
+// stopScenario asks the server to stop the running scenario and restore
+// the endpoints scenarios changed
+func (m *Model) stopScenario() tea.Cmd {
+	return func() tea.Msg {
+		if err := m.configRequest(http.MethodDelete, m.httpURL+"/scenarios", nil); err != nil {
+			return ScenarioChangedMsg{Message: "Error: " + err.Error()}
+		}
+		return ScenarioChangedMsg{Message: "Stopped; endpoints restored"}
+	}
+}
+
+// scenarioStatuses returns the known scenarios, none before they are fetched
+func (m *Model) scenarioStatuses() []types.ScenarioStatus {
+	if m.scenarios == nil {
+		return nil
+	}
+	return m.scenarios.Scenarios
+}
+
+// handleScenarioPickerKey handles key presses while the scenario picker is
+// open: Enter starts the selected scenario, or stops it while it runs
+func (m *Model) handleScenarioPickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	picker := m.scenarioPicker
+	scenarios := m.scenarioStatuses()
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc", "S":
+		m.scenarioPicker = nil
+	case "up", "k":
+		if picker.cursor > 0 {
+			picker.cursor--
+		}
+	case "down", "j":
+		if picker.cursor < len(scenarios)-1 {
+			picker.cursor++
+		}
+	case "enter":
+		if picker.cursor >= len(scenarios) {
+			return m, nil
+		}
+		scenario := scenarios[picker.cursor]
+		if scenario.Running {
+			return m, m.stopScenario()
+		}
+		return m, m.startScenario(scenario.Name)
+	case "x":
+		return m, m.stopScenario()
+	case "r":
+		return m, m.fetchScenarios
+	}
+	return m, nil
+}
+
+// scenarioPickerView renders the scenario picker
+func (m *Model) scenarioPickerView() string {
+	picker := m.scenarioPicker
+	highlight := m.filterStyle.UnsetPadding()
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Dim))
+
+	content := "🎬 Scenarios\n\n"
+	if picker.status != "" {
+		content += picker.status + "\n\n"
+	}
+
+	scenarios := m.scenarioStatuses()
+	if picker.cursor >= len(scenarios) && len(scenarios) > 0 {
+		picker.cursor = len(scenarios) - 1
+	}
+	if len(scenarios) == 0 {
+		content += "No scenarios loaded. List scenario files under \"scenarios\" in the configuration.\n"
+	}
+
+	for i, scenario := range scenarios {
+		line := fmt.Sprintf("%s (%d steps", scenario.Name, scenario.Steps)
+		if scenario.Loop {
+			line += ", loops"
+		}
+		line += ")"
+		if scenario.Running {
+			line += " [running]"
+		}
+		if i == picker.cursor {
+			line = highlight.Render("▶ " + line)
+		} else {
+			line = "  " + line
+		}
+		content += line + "\n"
+		if scenario.Description != "" {
+			content += dim.Render("    "+scenario.Description) + "\n"
+		}
+		if scenario.Running {
+			content += "    " + describeScenarioProgress(scenario) + "\n"
+		}
+	}
+
+	if m.scenarios != nil && len(m.scenarios.Changed) > 0 {
+		content += fmt.Sprintf("\nChanged endpoints: %s\n", strings.Join(m.scenarios.Changed, ", "))
+	}
+	return content
+}
+
+// scenariosSection summarizes the scenarios in the configuration view;
+// empty when there are none
+func (m *Model) scenariosSection() string {
+	scenarios := m.scenarioStatuses()
+	if len(scenarios) == 0 {
+		return ""
+	}
+
+	section := "🎬 Scenarios\n\n"
+	for _, scenario := range scenarios {
+		section += fmt.Sprintf("• %s (%d steps) - %s\n", scenario.Name, scenario.Steps, scenario.File)
+		if scenario.Running {
+			section += "  " + describeScenarioProgress(scenario) + "\n"
+		}
+	}
+	if len(m.scenarios.Changed) > 0 {
+		section += fmt.Sprintf("Changed by scenarios: %s\n", strings.Join(m.scenarios.Changed, ", "))
+	}
+	section += "\nPress 'S' to start or stop a scenario.\n"
+	return section
+}
+
+// describeScenarioProgress tells how far a running scenario got
+func describeScenarioProgress(scenario types.ScenarioStatus) string {
+	progress := fmt.Sprintf("Running: %d/%d steps applied", scenario.Step, scenario.Steps)
+	if scenario.NextStep != "" && scenario.NextStepAt != nil {
+		wait := time.Until(*scenario.NextStepAt).Round(time.Second)
+		if wait < 0 {
+			wait = 0
+		}
+		progress += fmt.Sprintf(", %s in %s", scenario.NextStep, wait)
+	}
+	return progress
+}
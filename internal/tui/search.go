@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// startSearch opens the "/" search prompt on the active tab
+func (m *Model) startSearch() {
+	m.searchMode = true
+	m.searchBuffer = ""
+	m.searchQuery = ""
+	m.searchOrigin = m.scrollPositions[m.activeTab]
+}
+
+// handleSearchKey handles typing in the search prompt. Every keystroke jumps
+// to the first match at or after where the search started.
+func (m *Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.searchMode = false
+		return m, nil
+	case "esc":
+		// Cancel the search and go back to where it started
+		m.searchMode = false
+		m.searchQuery = ""
+		m.scrollPositions[m.activeTab] = m.searchOrigin
+		return m, nil
+	case "ctrl+c":
+		return m, tea.Quit
+	case "backspace":
+		if len(m.searchBuffer) > 0 {
+			m.searchBuffer = m.searchBuffer[:len(m.searchBuffer)-1]
+		}
+	default:
+		if msg.Type != tea.KeyRunes && msg.Type != tea.KeySpace {
+			return m, nil
+		}
+		m.searchBuffer += string(msg.Runes)
+	}
+
+	m.searchQuery = m.searchBuffer
+	m.searchLine = m.searchOrigin - 1
+	m.jumpToMatch(1)
+	return m, nil
+}
+
+// searchMatches returns the active tab's content lines containing the query,
+// as last rendered
+func (m *Model) searchMatches() []int {
+	if m.searchQuery == "" {
+		return nil
+	}
+	query := strings.ToLower(m.searchQuery)
+	var matches []int
+	for i, line := range m.tabLines[m.activeTab] {
+		if strings.Contains(strings.ToLower(ansi.Strip(line)), query) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// jumpToMatch scrolls to the next (direction 1) or previous (-1) match after
+// searchLine, wrapping around the content
+func (m *Model) jumpToMatch(direction int) {
+	matches := m.searchMatches()
+	if len(matches) == 0 {
+		return
+	}
+
+	target := matches[0]
+	if direction < 0 {
+		target = matches[len(matches)-1]
+		for i := len(matches) - 1; i >= 0; i-- {
+			if matches[i] < m.searchLine {
+				target = matches[i]
+				break
+			}
+		}
+	} else {
+		for _, line := range matches {
+			if line > m.searchLine {
+				target = line
+				break
+			}
+		}
+	}
+	m.searchLine = target
+
+	// Show the match a third of the way down the viewport
+	position := target - m.viewportHeight/3
+	if maxScroll := m.contentHeights[m.activeTab] - m.viewportHeight; position > maxScroll {
+		position = maxScroll
+	}
+	m.scrollPositions[m.activeTab] = max(position, 0)
+
+	// Keep new requests from moving the match away
+	if m.activeTab == 3 { // Request Log tab
+		m.pauseRequestLog()
+	}
+}
+
+// highlightSearch highlights the query in rendered content lines
+func (m *Model) highlightSearch(lines []string) []string {
+	if m.searchQuery == "" {
+		return lines
+	}
+	style := lipgloss.NewStyle().
+		Background(lipgloss.Color(m.theme.MatchBackground)).
+		Foreground(lipgloss.Color(m.theme.MatchText))
+	query := strings.ToLower(m.searchQuery)
+
+	highlighted := make([]string, len(lines))
+	for i, line := range lines {
+		highlighted[i] = line
+		plain := ansi.Strip(line)
+		lower := strings.ToLower(plain)
+		if len(lower) != len(plain) {
+			continue // Case folding moved the byte offsets
+		}
+
+		// StyleRanges takes cell columns rather than byte offsets
+		var ranges []lipgloss.Range
+		for offset := 0; ; {
+			index := strings.Index(lower[offset:], query)
+			if index < 0 {
+				break
+			}
+			start := offset + index
+			end := start + len(query)
+			ranges = append(ranges, lipgloss.NewRange(ansi.StringWidth(plain[:start]), ansi.StringWidth(plain[:end]), style))
+			offset = end
+		}
+		highlighted[i] = lipgloss.StyleRanges(line, ranges...)
+	}
+	return highlighted
+}
+
+// searchStatus describes the search for the status area, e.g. "/api: 2 of 5"
+func (m *Model) searchStatus() string {
+	matches := m.searchMatches()
+	if len(matches) == 0 {
+		return fmt.Sprintf("/%s: no matches", m.searchQuery)
+	}
+	current := 0
+	for i, line := range matches {
+		if line == m.searchLine {
+			current = i + 1
+		}
+	}
+	if current == 0 {
+		return fmt.Sprintf("/%s: %d matches", m.searchQuery, len(matches))
+	}
+	return fmt.Sprintf("/%s: %d of %d", m.searchQuery, current, len(matches))
+}
@@ -0,0 +1,196 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"webserver/pkg/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// serverSummary is the last statistics fetched from a monitored server
+type serverSummary struct {
+	stats *types.ServerStats
+	err   string
+}
+
+// httpURLFor converts a WebSocket URL to the server's HTTP base URL
+func httpURLFor(serverURL string) string {
+	httpURL := strings.Replace(serverURL, "ws://", "http://", 1)
+	httpURL = strings.Replace(httpURL, "wss://", "https://", 1)
+	return strings.Replace(httpURL, "/ws", "", 1)
+}
+
+// normalizeServerURL accepts a WebSocket URL, an HTTP URL or a bare host:port
+// and returns the server's WebSocket URL
+func normalizeServerURL(input string) string {
+	input = strings.TrimSpace(input)
+	switch {
+	case strings.HasPrefix(input, "http://"):
+		input = "ws://" + strings.TrimPrefix(input, "http://")
+	case strings.HasPrefix(input, "https://"):
+		input = "wss://" + strings.TrimPrefix(input, "https://")
+	case !strings.HasPrefix(input, "ws://") && !strings.HasPrefix(input, "wss://"):
+		input = "ws://" + input
+	}
+	input = strings.TrimSuffix(input, "/")
+	if !strings.HasSuffix(input, "/ws") {
+		input += "/ws"
+	}
+	return input
+}
+
+// switchServer replaces the model with one connected to another monitored
+// server. Pending refresh ticks carry over to the new model; its push stream
+// and data are the new server's, while the UI settings are kept.
+func (m *Model) switchServer(index int) (tea.Model, tea.Cmd) {
+	if index == m.serverIndex || index < 0 || index >= len(m.servers) {
+		return m, nil
+	}
+	m.closeLive()
+
+	next := NewModel(m.servers[index])
+	next.servers = m.servers
+	next.serverIndex = index
+	next.summaries = m.summaries
+	next.activeTab = m.activeTab
+	next.width, next.height, next.viewportHeight = m.width, m.height, m.viewportHeight
+	next.filterText, next.filterBuffer = m.filterText, m.filterText
+	next.configFilterText, next.configFilterBuffer = m.configFilterText, m.configFilterText
+	next.hideStatsRequests = m.hideStatsRequests
+	next.statusClasses = m.statusClasses
+	next.groupByRoute = m.groupByRoute
+	next.setTheme(m.theme)
+	next.keys = m.keys
+	next.token = m.token
+	next.paneLayout = m.paneLayout
+	next.refreshInterval, next.lowBandwidth = m.refreshInterval, m.lowBandwidth
+	next.hideDateColumn, next.hideRemoteColumn = m.hideDateColumn, m.hideRemoteColumn
+	return next, next.connectToServer
+}
+
+// addServer starts monitoring another server and switches to it
+func (m *Model) addServer(input string) (tea.Model, tea.Cmd) {
+	if strings.TrimSpace(input) == "" {
+		return m, nil
+	}
+	serverURL := normalizeServerURL(input)
+	for i, existing := range m.servers {
+		if existing == serverURL {
+			return m.switchServer(i)
+		}
+	}
+	m.servers = append(m.servers, serverURL)
+	return m.switchServer(len(m.servers) - 1)
+}
+
+// fetchServerSummaries fetches the statistics of the servers not being viewed
+func (m *Model) fetchServerSummaries() []tea.Cmd {
+	if len(m.servers) < 2 {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for i, serverURL := range m.servers {
+		if i == m.serverIndex {
+			continue
+		}
+		serverURL := serverURL
+		cmds = append(cmds, func() tea.Msg { return fetchServerSummary(serverURL, m.token) })
+	}
+	return cmds
+}
+
+// fetchServerSummary fetches a monitored server's statistics
+func fetchServerSummary(serverURL, token string) tea.Msg {
+	req, err := http.NewRequest(http.MethodGet, httpURLFor(serverURL)+"/stats", nil)
+	if err != nil {
+		return ServerSummaryMsg{Server: serverURL, Error: err.Error()}
+	}
+	authorize(req.Header, token)
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ServerSummaryMsg{Server: serverURL, Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ServerSummaryMsg{Server: serverURL, Error: "unauthorized"}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return ServerSummaryMsg{Server: serverURL, Error: fmt.Sprintf("stats request failed: %d", resp.StatusCode)}
+	}
+
+	var stats types.ServerStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return ServerSummaryMsg{Server: serverURL, Error: fmt.Sprintf("failed to parse stats: %v", err)}
+	}
+	return ServerSummaryMsg{Server: serverURL, Stats: &stats}
+}
+
+// serversView renders the aggregate overview of every monitored server
+func (m *Model) serversView() string {
+	content := fmt.Sprintf("🖥️ Servers (%d monitored, [ ] to switch, + to add)\n\n", len(m.servers))
+	// The status emoji is two columns wide, one more than fmt pads for
+	content += fmt.Sprintf("  %-32s %-7s %10s %8s %8s %8s\n", "Server", "Status", "Requests", "Errors", "Err %", "req/s")
+
+	var totalRequests, totalErrors int64
+	var totalRate float64
+	for i, serverURL := range m.servers {
+		marker := "  "
+		if i == m.serverIndex {
+			marker = "▶ "
+		}
+
+		var stats *types.ServerStats
+		var problem string
+		if i == m.serverIndex {
+			stats = m.stats
+			if !m.connected {
+				problem = "❌ disconnected"
+			}
+		} else if summary, ok := m.summaries[serverURL]; ok {
+			stats = summary.stats
+			if summary.err != "" {
+				problem = "❌ " + summary.err
+			}
+		}
+
+		name := httpURLFor(serverURL)
+		if problem != "" || stats == nil {
+			if problem == "" {
+				problem = "⏳ loading"
+			}
+			content += fmt.Sprintf("%s%-32s %s\n", marker, name, problem)
+			continue
+		}
+
+		var rate float64
+		for _, endpoint := range stats.Endpoints {
+			rate += endpoint.Rates.OneMinute.RequestsPerSec
+		}
+		errorRate := 0.0
+		if stats.RequestCount > 0 {
+			errorRate = float64(stats.ErrorCount) / float64(stats.RequestCount) * 100
+		}
+		content += fmt.Sprintf("%s%-32s %-6s %10d %8d %7.2f%% %8.2f\n",
+			marker, name, "✅", stats.RequestCount, stats.ErrorCount, errorRate, rate)
+
+		totalRequests += stats.RequestCount
+		totalErrors += stats.ErrorCount
+		totalRate += rate
+	}
+
+	totalErrorRate := 0.0
+	if totalRequests > 0 {
+		totalErrorRate = float64(totalErrors) / float64(totalRequests) * 100
+	}
+	content += lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("  %-32s %-7s %10d %8d %7.2f%% %8.2f",
+		"All servers", "", totalRequests, totalErrors, totalErrorRate, totalRate)) + "\n"
+	return content
+}
@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"webserver/pkg/types"
+)
+
+// errOffline is returned for server requests while browsing a snapshot
+var errOffline = errors.New("not available while browsing an offline snapshot")
+
+// Snapshot is a saved test run that the TUI can browse without a server
+type Snapshot struct {
+	SavedAt    time.Time               `json:"saved_at"`
+	Server     string                  `json:"server,omitempty"` // HTTP URL the snapshot was taken from
+	Config     *types.Config           `json:"config,omitempty"`
+	Stats      *types.ServerStats      `json:"stats,omitempty"`
+	RequestLog []types.RequestLogEntry `json:"request_log,omitempty"`
+	path       string                  // File the snapshot was loaded from
+}
+
+// LoadSnapshot reads a snapshot saved with W on the Overview tab. Exports of
+// a single kind of data are read too: statistics from GET /stats or W on the
+// Statistics tab, and request logs from GET /requestlog, W on the Request
+// Log tab or the NDJSON of GET /requestlog/export.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	snapshot, err := parseSnapshot(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	if snapshot.Stats == nil && len(snapshot.RequestLog) == 0 {
+		return nil, fmt.Errorf("no statistics or request log found in %s", path)
+	}
+	snapshot.path = path
+	if snapshot.SavedAt.IsZero() {
+		if info, err := os.Stat(path); err == nil {
+			snapshot.SavedAt = info.ModTime()
+		}
+	}
+	return snapshot, nil
+}
+
+// parseSnapshot tells the supported file contents apart by their shape
+func parseSnapshot(data []byte) (*Snapshot, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var first json.RawMessage
+	if err := decoder.Decode(&first); err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{}
+	switch {
+	case decoder.More():
+		// NDJSON: one request log entry per line
+		var entry types.RequestLogEntry
+		if err := json.Unmarshal(first, &entry); err != nil {
+			return nil, err
+		}
+		snapshot.RequestLog = append(snapshot.RequestLog, entry)
+		for decoder.More() {
+			var entry types.RequestLogEntry
+			if err := decoder.Decode(&entry); err != nil {
+				return nil, err
+			}
+			snapshot.RequestLog = append(snapshot.RequestLog, entry)
+		}
+	case bytes.HasPrefix(bytes.TrimSpace(first), []byte("[")):
+		if err := json.Unmarshal(first, &snapshot.RequestLog); err != nil {
+			return nil, err
+		}
+	default:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(first, &fields); err != nil {
+			return nil, err
+		}
+		_, hasStats := fields["stats"]
+		_, hasRequestLog := fields["request_log"]
+		if hasStats || hasRequestLog {
+			if err := json.Unmarshal(first, snapshot); err != nil {
+				return nil, err
+			}
+			break
+		}
+		snapshot.Stats = &types.ServerStats{}
+		if err := json.Unmarshal(first, snapshot.Stats); err != nil {
+			return nil, err
+		}
+		if snapshot.Stats.Endpoints == nil {
+			return nil, errors.New("not a snapshot, statistics or request log export")
+		}
+	}
+	return snapshot, nil
+}
+
+// loadSnapshot shows a snapshot's data in place of a server's
+func (m *Model) loadSnapshot(snapshot *Snapshot) {
+	m.snapshot = snapshot
+	m.connected = true
+	m.config = snapshot.Config
+	m.stats = snapshot.Stats
+	m.requestLog = snapshot.RequestLog
+	sort.SliceStable(m.requestLog, func(i, j int) bool {
+		return m.requestLog[i].Timestamp.After(m.requestLog[j].Timestamp)
+	})
+}
+
+// snapshotStatus describes the snapshot being browsed for the status line
+func (m *Model) snapshotStatus() string {
+	status := fmt.Sprintf("Snapshot: %s (saved %s", filepath.Base(m.snapshot.path), m.snapshot.SavedAt.Format("2006-01-02 15:04:05"))
+	if m.snapshot.Server != "" {
+		status += " from " + m.snapshot.Server
+	}
+	return status + ") | Status: 📁 Offline"
+}
+
+// newSnapshot captures the current server's configuration, statistics and
+// full request log
+func (m *Model) newSnapshot() *Snapshot {
+	server := m.httpURL
+	if m.snapshot != nil {
+		server = m.snapshot.Server
+	}
+	return &Snapshot{
+		SavedAt:    time.Now(),
+		Server:     server,
+		Config:     m.config,
+		Stats:      m.stats,
+		RequestLog: m.requestLog,
+	}
+}
+
+// pending describes data not received yet, or not included in the snapshot
+// being browsed
+func (m *Model) pending(what string) string {
+	if m.snapshot != nil {
+		return fmt.Sprintf("📁 No %s in this snapshot", what)
+	}
+	return fmt.Sprintf("⏳ Loading %s...", what)
+}
+
+// uptime returns how long the server has been running, or had been when the
+// snapshot being browsed was saved
+func (m *Model) uptime() time.Duration {
+	if m.snapshot != nil {
+		return m.snapshot.SavedAt.Sub(m.stats.StartTime)
+	}
+	return time.Since(m.stats.StartTime)
+}
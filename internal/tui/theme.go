@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds the colors of the TUI. Colors are anything lipgloss accepts:
+// hex values such as "#FF6B6B" or ANSI color numbers such as "9".
+type Theme struct {
+	Name                string `json:"-"` // Built-in theme the colors started from
+	HeaderText          string `json:"header_text"`
+	HeaderBackground    string `json:"header_background"` // Title bar and table headers
+	TabText             string `json:"tab_text"`
+	TabBackground       string `json:"tab_background"`
+	ActiveTabText       string `json:"active_tab_text"`
+	ActiveTabBackground string `json:"active_tab_background"`
+	Border              string `json:"border"`
+	Muted               string `json:"muted"`   // Status line and footer
+	Dim                 string `json:"dim"`     // Hints, separators and placeholders
+	Error               string `json:"error"`   // 5xx responses and failures
+	Warning             string `json:"warning"` // 4xx responses and changes
+	Success             string `json:"success"` // 3xx responses and additions
+	Info                string `json:"info"`    // 2xx responses and commands
+	Slow                string `json:"slow"`
+	Tag                 string `json:"tag"`
+	Accent              string `json:"accent"` // Input prompts and the selection
+	AccentBackground    string `json:"accent_background"`
+	ActiveFilter        string `json:"active_filter"`
+	MatchText           string `json:"match_text"` // Filter matches
+	MatchBackground     string `json:"match_background"`
+}
+
+// DefaultThemeName is the theme used when none is configured
+const DefaultThemeName = "dark"
+
+// themes are the built-in themes by name
+var themes = map[string]Theme{
+	"dark": {
+		HeaderText:          "#FFFFFF",
+		HeaderBackground:    "#5F5F5F",
+		TabText:             "#FFFFFF",
+		TabBackground:       "#3C3C3C",
+		ActiveTabText:       "#FFFFFF",
+		ActiveTabBackground: "#7C7C7C",
+		Border:              "#7C7C7C",
+		Muted:               "#888888",
+		Dim:                 "#666666",
+		Error:               "#FF6B6B",
+		Warning:             "#FFD93D",
+		Success:             "#6BCF7F",
+		Info:                "#4ECDC4",
+		Slow:                "#FF9F43",
+		Tag:                 "#B39DDB",
+		Accent:              "#FFFF00",
+		AccentBackground:    "#333333",
+		ActiveFilter:        "#00FF00",
+		MatchText:           "#000000",
+		MatchBackground:     "#FFFF00",
+	},
+	"light": {
+		HeaderText:          "#FFFFFF",
+		HeaderBackground:    "#4A4A4A",
+		TabText:             "#1A1A1A",
+		TabBackground:       "#D7D7D7",
+		ActiveTabText:       "#FFFFFF",
+		ActiveTabBackground: "#5F5F5F",
+		Border:              "#8A8A8A",
+		Muted:               "#5A5A5A",
+		Dim:                 "#7A7A7A",
+		Error:               "#C62828",
+		Warning:             "#B26A00",
+		Success:             "#2E7D32",
+		Info:                "#00838F",
+		Slow:                "#E65100",
+		Tag:                 "#6A1B9A",
+		Accent:              "#000000",
+		AccentBackground:    "#FFF59D",
+		ActiveFilter:        "#2E7D32",
+		MatchText:           "#000000",
+		MatchBackground:     "#FFEB3B",
+	},
+	"high-contrast": {
+		HeaderText:          "#FFFFFF",
+		HeaderBackground:    "#000000",
+		TabText:             "#FFFFFF",
+		TabBackground:       "#000000",
+		ActiveTabText:       "#000000",
+		ActiveTabBackground: "#FFFFFF",
+		Border:              "#FFFFFF",
+		Muted:               "#FFFFFF",
+		Dim:                 "#C0C0C0",
+		Error:               "#FF0000",
+		Warning:             "#FFFF00",
+		Success:             "#00FF00",
+		Info:                "#00FFFF",
+		Slow:                "#FF8000",
+		Tag:                 "#FF00FF",
+		Accent:              "#000000",
+		AccentBackground:    "#FFFF00",
+		ActiveFilter:        "#00FF00",
+		MatchText:           "#FFFFFF",
+		MatchBackground:     "#FF00FF",
+	},
+}
+
+// ThemeNames returns the names of the built-in themes
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveTheme returns the configured theme, with name (when set) replacing
+// the file's built-in theme but keeping its color overrides
+func (c *ClientConfig) ResolveTheme(name string) (*Theme, error) {
+	if name == "" {
+		name = c.Theme
+	}
+	return resolveTheme(name, c.Colors)
+}
+
+// resolveTheme returns a built-in theme with colors overridden by name
+func resolveTheme(name string, colors map[string]string) (*Theme, error) {
+	if name == "" {
+		name = DefaultThemeName
+	}
+	theme, exists := themes[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	theme.Name = name
+	if len(colors) == 0 {
+		return &theme, nil
+	}
+
+	// Overlay the overrides on the theme's colors, keyed by their JSON names
+	data, err := json.Marshal(theme)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	for key, color := range colors {
+		if _, known := values[key]; !known {
+			names := make([]string, 0, len(values))
+			for name := range values {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("unknown theme color %q (available: %s)", key, strings.Join(names, ", "))
+		}
+		values[key] = color
+	}
+	data, err = json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return nil, err
+	}
+	return &theme, nil
+}
+
+// setTheme switches the model to a theme, rebuilding its shared styles
+func (m *Model) setTheme(theme *Theme) {
+	m.theme = theme
+	m.tabStyle = lipgloss.NewStyle().
+		Padding(0, 1).
+		Background(lipgloss.Color(theme.TabBackground)).
+		Foreground(lipgloss.Color(theme.TabText))
+	m.activeTabStyle = lipgloss.NewStyle().
+		Padding(0, 1).
+		Background(lipgloss.Color(theme.ActiveTabBackground)).
+		Foreground(lipgloss.Color(theme.ActiveTabText)).
+		Bold(true)
+	m.contentStyle = lipgloss.NewStyle().
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(theme.Border))
+	m.headerStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.HeaderText)).
+		Background(lipgloss.Color(theme.HeaderBackground)).
+		Padding(0, 1).
+		Bold(true)
+	m.filterStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(theme.Accent)).
+		Background(lipgloss.Color(theme.AccentBackground)).
+		Padding(0, 1).
+		Bold(true)
+}
+
+// cycleTheme switches to the next built-in theme. Color overrides from the
+// client configuration only apply to the theme the TUI started with.
+func (m *Model) cycleTheme() {
+	names := ThemeNames()
+	index := 0
+	for i, name := range names {
+		if name == m.theme.Name {
+			index = (i + 1) % len(names)
+		}
+	}
+	theme, _ := resolveTheme(names[index], nil)
+	m.setTheme(theme)
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"webserver/internal/buildinfo"
+	"webserver/pkg/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// VersionMsg carries the server's build information; Info is nil when the
+// server predates GET /version
+type VersionMsg struct{ Info *types.VersionInfo }
+
+// fetchVersion asks the server which build it is
+func (m *Model) fetchVersion() tea.Msg {
+	resp, err := m.get(m.httpURL + "/version")
+	if err != nil {
+		return fetchError("Failed to fetch server version", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return VersionMsg{}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return ErrorMsg{Error: fmt.Sprintf("Version request failed: %d", resp.StatusCode)}
+	}
+
+	var info types.VersionInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return ErrorMsg{Error: fmt.Sprintf("Failed to parse server version: %v", err)}
+	}
+	return VersionMsg{Info: &info}
+}
+
+// versionLabel describes the server's build for the status line; empty
+// until it is known
+func (m *Model) versionLabel() string {
+	if !m.versionFetched {
+		return ""
+	}
+	if m.serverVersion == nil {
+		return " (version unknown)"
+	}
+	if commit := m.serverVersion.ShortCommit(); commit != "" {
+		return fmt.Sprintf(" (%s, %s)", m.serverVersion.Version, commit)
+	}
+	return fmt.Sprintf(" (%s)", m.serverVersion.Version)
+}
+
+// protocolWarning explains a server speaking another API protocol version
+// than this client; empty when they match or the server's is unknown
+func (m *Model) protocolWarning() string {
+	if m.serverVersion == nil || m.serverVersion.Protocol == buildinfo.ProtocolVersion {
+		return ""
+	}
+	return fmt.Sprintf("⚠ Server speaks protocol %d, this client %d: some features may not work",
+		m.serverVersion.Protocol, buildinfo.ProtocolVersion)
+}
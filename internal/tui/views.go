@@ -2,21 +2,31 @@ package tui
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"sort"
 	"strings"
 	"time"
 
+	"webserver/pkg/types"
+
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
 // overviewView renders the overview tab
 func (m *Model) overviewView() string {
 	if !m.connected {
-		return "❌ Not connected to server\n\nTry pressing 'R' to refresh or check if the server is running."
+		return m.disconnectedView()
 	}
 
 	var sections []string
 
+	// Every monitored server at a glance
+	if len(m.servers) > 1 {
+		sections = append(sections, m.serversView())
+	}
+
 	// Server info
 	serverInfo := "📊 Server Overview\n\n"
 	if m.config != nil {
@@ -24,6 +34,9 @@ func (m *Model) overviewView() string {
 		serverInfo += fmt.Sprintf("• Port: %d\n", m.config.Server.Port)
 		serverInfo += fmt.Sprintf("• Static Directory: %s\n", m.config.Server.StaticDir)
 		serverInfo += fmt.Sprintf("• Configured Endpoints: %d\n", len(m.config.Endpoints))
+		if m.wsClients != nil {
+			serverInfo += fmt.Sprintf("• WebSocket Clients: %d\n", m.wsClients.Count)
+		}
 
 		// Add endpoint details
 		if len(m.config.Endpoints) > 0 {
@@ -42,14 +55,14 @@ func (m *Model) overviewView() string {
 			}
 		}
 	} else {
-		serverInfo += "• Loading configuration...\n"
+		serverInfo += "• " + m.pending("configuration") + "\n"
 	}
 
 	sections = append(sections, serverInfo)
 
 	// Quick stats
 	if m.stats != nil {
-		uptime := time.Since(m.stats.StartTime).Truncate(time.Second)
+		uptime := m.uptime().Truncate(time.Second)
 		quickStats := "📈 Quick Statistics\n\n"
 		quickStats += fmt.Sprintf("• Uptime: %s\n", uptime)
 		quickStats += fmt.Sprintf("• Total Requests: %d\n", m.stats.RequestCount)
@@ -57,14 +70,19 @@ func (m *Model) overviewView() string {
 		if m.stats.RequestCount > 0 {
 			errorRate := float64(m.stats.ErrorCount) / float64(m.stats.RequestCount) * 100
 			quickStats += fmt.Sprintf("• Error Rate: %.2f%%\n", errorRate)
-			avgReqPerMin := float64(m.stats.RequestCount) / (time.Since(m.stats.StartTime).Minutes() + 0.01)
+			avgReqPerMin := float64(m.stats.RequestCount) / (m.uptime().Minutes() + 0.01)
 			quickStats += fmt.Sprintf("• Avg Requests/min: %.1f\n", avgReqPerMin)
 		}
 		quickStats += fmt.Sprintf("• Active Endpoints: %d\n", len(m.stats.Endpoints))
 
 		sections = append(sections, quickStats)
 	} else {
-		sections = append(sections, "📈 Quick Statistics\n\n• Loading statistics...\n")
+		sections = append(sections, "📈 Quick Statistics\n\n• "+m.pending("statistics")+"\n")
+	}
+
+	// Live traffic chart, scrolling left as seconds complete
+	if m.timeSeries != nil {
+		sections = append(sections, fmt.Sprintf("📉 Traffic (last %ds)\n\n", len(m.timeSeries.Total))+trafficChart(m.timeSeries.Total))
 	}
 
 	// Recent activity
@@ -102,7 +120,11 @@ func (m *Model) overviewView() string {
 	connectionInfo := "🔗 Connection Information\n\n"
 	connectionInfo += fmt.Sprintf("• Server URL: %s\n", m.httpURL)
 	connectionInfo += fmt.Sprintf("• WebSocket URL: %s\n", m.serverURL)
-	connectionInfo += "• Protocol: HTTP polling (every 1 second)\n"
+	if m.live != nil {
+		connectionInfo += "• Protocol: WebSocket push\n"
+	} else {
+		connectionInfo += fmt.Sprintf("• Protocol: HTTP polling (every %s%s)\n", m.refreshInterval, m.pollingMode())
+	}
 	connectionInfo += "• Connection Status: "
 	if m.connected {
 		connectionInfo += "✅ Connected\n"
@@ -119,22 +141,91 @@ func (m *Model) overviewView() string {
 // configView renders the configuration tab
 func (m *Model) configView() string {
 	if !m.connected {
-		return "❌ Not connected to server\n\nTry pressing 'R' to refresh or check if the server is running."
+		return m.disconnectedView()
 	}
 
 	if m.config == nil {
-		return "⏳ Loading configuration..."
+		return m.pending("configuration")
+	}
+
+	if m.rawConfig != nil {
+		return m.rawConfigView()
+	}
+
+	if m.editor != nil {
+		return m.endpointEditorView()
+	}
+
+	if m.scenarioPicker != nil {
+		return m.scenarioPickerView()
 	}
 
 	var sections []string
 
-	// Server configuration
-	serverConfig := "🔧 Server Configuration\n\n"
+	// Server configuration, after a warning when the latest reload failed
+	serverConfig := m.configReloadWarning() + "🔧 Server Configuration\n\n"
 	serverConfig += fmt.Sprintf("Host: %s\n", m.config.Server.Host)
 	serverConfig += fmt.Sprintf("Port: %d\n", m.config.Server.Port)
 	serverConfig += fmt.Sprintf("Static Directory: %s\n", m.config.Server.StaticDir)
 	serverConfig += fmt.Sprintf("Full Address: http://%s:%d\n", m.config.Server.Host, m.config.Server.Port)
 
+	requestLogSize := m.config.Server.RequestLogSize
+	if requestLogSize <= 0 {
+		requestLogSize = types.DefaultRequestLogSize
+	}
+	serverConfig += fmt.Sprintf("Request Log Capacity: %d entries", requestLogSize)
+	if m.config.Server.RequestLogMemoryMB > 0 {
+		serverConfig += fmt.Sprintf(" (memory budget %d MB)", m.config.Server.RequestLogMemoryMB)
+	}
+	serverConfig += "\n"
+
+	logSampleEvery := m.config.Server.RequestLogSampleEvery
+	if logSampleEvery == 0 {
+		logSampleEvery = m.config.Server.SampleEvery
+	}
+	if len(m.config.Server.RequestLogExclude) > 0 {
+		serverConfig += fmt.Sprintf("Request Log Exclusions: %s\n", strings.Join(m.config.Server.RequestLogExclude, ", "))
+	}
+	for _, rule := range m.config.Alerts {
+		serverConfig += fmt.Sprintf("Alert: %s (%s)\n", rule.Name, describeAlertRule(rule))
+	}
+	if redact := m.config.Server.RequestLogRedact; redact != nil {
+		serverConfig += fmt.Sprintf("Request Log Redaction: %d headers, %d JSON fields, %d patterns\n",
+			len(redact.Headers), len(redact.JSONFields), len(redact.Patterns))
+	}
+	if auth := m.config.Server.ManagementAuth; auth.Enabled() {
+		names := make([]string, 0, len(auth.Tokens))
+		for _, token := range auth.Tokens {
+			names = append(names, fmt.Sprintf("%s (%s)", token.Name, strings.Join(token.Permissions, "/")))
+		}
+		serverConfig += fmt.Sprintf("Management Auth: %s\n", strings.Join(names, ", "))
+	}
+	if contract := m.config.Server.Contract; contract != nil {
+		match := contract.Match
+		if match == "" {
+			match = types.ContractMatchShape
+		}
+		serverConfig += fmt.Sprintf("Contract Mode: proxying to %s, %s match\n", contract.Upstream, match)
+	}
+	if sessions := m.config.Server.Sessions; sessions != nil {
+		header := sessions.Header
+		if header == "" {
+			header = types.SessionHeader
+		}
+		if sessions.Cookie != "" {
+			serverConfig += fmt.Sprintf("Sessions: %s cookie, then %s header\n", sessions.Cookie, header)
+		} else {
+			serverConfig += fmt.Sprintf("Sessions: %s header\n", header)
+		}
+	}
+	if logSampleEvery > 1 {
+		serverConfig += fmt.Sprintf("Request Log Sampling: 1 in %d", logSampleEvery)
+		if m.config.Server.RequestLogSampleAboveRPS > 0 {
+			serverConfig += fmt.Sprintf(" above %d req/s", m.config.Server.RequestLogSampleAboveRPS)
+		}
+		serverConfig += "\n"
+	}
+
 	sections = append(sections, serverConfig)
 
 	// Endpoints configuration
@@ -151,7 +242,7 @@ func (m *Model) configView() string {
 
 	if len(m.config.Endpoints) == 0 {
 		endpointsConfig += "No endpoints configured\n"
-		endpointsConfig += "\nYou can add endpoints using the configuration API:\n"
+		endpointsConfig += "\nPress 'E' to add endpoints here, or use the configuration API:\n"
 		endpointsConfig += "curl -X POST http://localhost:8080/config -H 'Content-Type: application/json' \\\n"
 		endpointsConfig += "  -d '{\"path\": \"/api/test\", \"config\": {\"type\": \"error\", \"status_code\": 404}}'\n"
 	} else if len(filteredEndpoints) == 0 && m.configFilterText != "" {
@@ -162,6 +253,7 @@ func (m *Model) configView() string {
 		endpointsConfig += "• Press 'C' to clear filter\n"
 		endpointsConfig += "• Press 'F' to change filter\n"
 		endpointsConfig += "• Filter matches endpoint path, type, and message\n"
+		endpointsConfig += "• re:<pattern> matches a regular expression; a leading ! negates the filter\n"
 	} else {
 		// Sort endpoint paths alphabetically for consistent display
 		paths := make([]string, 0, len(filteredEndpoints))
@@ -172,8 +264,18 @@ func (m *Model) configView() string {
 
 		for _, path := range paths {
 			endpoint := filteredEndpoints[path]
-			endpointsConfig += fmt.Sprintf("• %s\n", path)
+			if endpoint.Disabled {
+				endpointsConfig += fmt.Sprintf("• %s [disabled, not served]\n", path)
+			} else {
+				endpointsConfig += fmt.Sprintf("• %s\n", path)
+			}
 			endpointsConfig += fmt.Sprintf("  Type: %s\n", endpoint.Type)
+			if len(endpoint.Methods) > 0 {
+				endpointsConfig += fmt.Sprintf("  Methods: %s\n", strings.Join(endpoint.Methods, ", "))
+			}
+			if endpoint.Group != "" {
+				endpointsConfig += fmt.Sprintf("  Group: %s\n", endpoint.Group)
+			}
 
 			switch endpoint.Type {
 			case "error":
@@ -183,7 +285,15 @@ func (m *Model) configView() string {
 				}
 				endpointsConfig += fmt.Sprintf("  Test: curl http://localhost:8080%s\n", path)
 			case "delay":
-				endpointsConfig += fmt.Sprintf("  Delay: %dms\n", endpoint.DelayMs)
+				if profile := endpoint.DelayProfile; profile != nil {
+					endpointsConfig += fmt.Sprintf("  Delay Profile: %s from %dms to %dms over %s", profile.Shape, profile.FromMs, profile.ToMs, profile.Duration)
+					if profile.Repeat {
+						endpointsConfig += ", repeating"
+					}
+					endpointsConfig += "\n"
+				} else {
+					endpointsConfig += fmt.Sprintf("  Delay: %dms\n", endpoint.DelayMs)
+				}
 				if endpoint.Response != nil {
 					endpointsConfig += "  Returns: Custom JSON response\n"
 				}
@@ -195,6 +305,45 @@ func (m *Model) configView() string {
 					endpointsConfig += "  Success Response: Custom JSON\n"
 				}
 				endpointsConfig += fmt.Sprintf("  Test: curl http://localhost:8080%s (multiple times)\n", path)
+			case "proxy":
+				endpointsConfig += fmt.Sprintf("  Forwards To: %s\n", endpoint.TargetURL)
+				endpointsConfig += fmt.Sprintf("  Test: curl http://localhost:8080%s\n", path)
+			case "redirect":
+				statusCode := endpoint.StatusCode
+				if statusCode == 0 {
+					statusCode = 302
+				}
+				endpointsConfig += fmt.Sprintf("  Redirects To: %s (%d)\n", endpoint.Location, statusCode)
+				endpointsConfig += fmt.Sprintf("  Test: curl -i http://localhost:8080%s\n", path)
+			case "fuzz":
+				if endpoint.Fuzz != nil {
+					endpointsConfig += fmt.Sprintf("  Seed: %d\n", endpoint.Fuzz.Seed)
+					if len(endpoint.Fuzz.Mutations) > 0 {
+						endpointsConfig += fmt.Sprintf("  Mutations: %s\n", strings.Join(endpoint.Fuzz.Mutations, ", "))
+					}
+				}
+				if endpoint.Response != nil {
+					endpointsConfig += "  Breaks: Custom JSON response\n"
+				}
+				endpointsConfig += fmt.Sprintf("  Test: curl -i http://localhost:8080%s (multiple times)\n", path)
+			}
+			if endpoint.ApdexThresholdMs > 0 {
+				endpointsConfig += fmt.Sprintf("  Apdex Threshold: %dms\n", endpoint.ApdexThresholdMs)
+			}
+			if endpoint.SlowThresholdMs > 0 {
+				endpointsConfig += fmt.Sprintf("  Slow Threshold: %dms\n", endpoint.SlowThresholdMs)
+			}
+			if endpoint.SLO != nil {
+				endpointsConfig += fmt.Sprintf("  SLO Target: %.2f%%\n", endpoint.SLO.Target)
+			}
+			if endpoint.PerSession {
+				endpointsConfig += "  Per Session: Requests counted per session\n"
+			}
+			if endpoint.Requires != nil {
+				endpointsConfig += fmt.Sprintf("  Requires: %s first\n", endpoint.Requires.Path)
+			}
+			if endpoint.RequestSchema != nil {
+				endpointsConfig += "  Request Schema: Bodies are validated\n"
 			}
 			endpointsConfig += "\n"
 		}
@@ -210,6 +359,10 @@ func (m *Model) configView() string {
 
 	sections = append(sections, endpointsConfig)
 
+	if scenarios := m.scenariosSection(); scenarios != "" {
+		sections = append(sections, scenarios)
+	}
+
 	content := strings.Join(sections, "\n")
 	return content
 }
@@ -217,23 +370,30 @@ func (m *Model) configView() string {
 // statsView renders the statistics tab
 func (m *Model) statsView() string {
 	if !m.connected {
-		return "❌ Not connected to server\n\nTry pressing 'R' to refresh or check if the server is running."
+		return m.disconnectedView()
 	}
 
 	if m.stats == nil {
-		return "⏳ Loading statistics..."
+		return m.pending("statistics")
+	}
+
+	if m.drillDownPath != "" {
+		return m.endpointDrillDownView(m.drillDownPath)
 	}
 
 	var sections []string
 
 	// Overall statistics
-	uptime := time.Since(m.stats.StartTime).Truncate(time.Second)
+	uptime := m.uptime().Truncate(time.Second)
 	overallStats := "📊 Overall Statistics\n\n"
 	overallStats += fmt.Sprintf("Server Start Time: %s\n", m.stats.StartTime.Format("2006-01-02 15:04:05"))
 	overallStats += fmt.Sprintf("Uptime: %s\n", uptime)
 	overallStats += fmt.Sprintf("Total Requests: %d\n", m.stats.RequestCount)
 	overallStats += fmt.Sprintf("Total Errors: %d\n", m.stats.ErrorCount)
 	overallStats += fmt.Sprintf("Success Requests: %d\n", m.stats.RequestCount-m.stats.ErrorCount)
+	if m.stats.SampleEvery > 1 {
+		overallStats += fmt.Sprintf("Sampling: 1 in %d requests (latency and log are estimates)\n", m.stats.SampleEvery)
+	}
 
 	if m.stats.RequestCount > 0 {
 		errorRate := float64(m.stats.ErrorCount) / float64(m.stats.RequestCount) * 100
@@ -241,7 +401,7 @@ func (m *Model) statsView() string {
 		overallStats += fmt.Sprintf("Success Rate: %.2f%%\n", successRate)
 		overallStats += fmt.Sprintf("Error Rate: %.2f%%\n", errorRate)
 
-		avgReqPerMin := float64(m.stats.RequestCount) / (time.Since(m.stats.StartTime).Minutes() + 0.01)
+		avgReqPerMin := float64(m.stats.RequestCount) / (m.uptime().Minutes() + 0.01)
 		avgReqPerHour := avgReqPerMin * 60
 		overallStats += fmt.Sprintf("Avg Requests/min: %.1f\n", avgReqPerMin)
 		overallStats += fmt.Sprintf("Avg Requests/hour: %.0f\n", avgReqPerHour)
@@ -249,6 +409,47 @@ func (m *Model) statsView() string {
 
 	sections = append(sections, overallStats)
 
+	// Server process metrics
+	if m.stats.Process != nil {
+		process := "⚙️ Server Process\n\n"
+		process += fmt.Sprintf("Goroutines: %d\n", m.stats.Process.Goroutines)
+		process += fmt.Sprintf("Heap In Use: %.1f MB (%.1f MB reserved)\n",
+			float64(m.stats.Process.HeapAllocBytes)/(1024*1024),
+			float64(m.stats.Process.HeapSysBytes)/(1024*1024))
+		process += fmt.Sprintf("Heap Objects: %d\n", m.stats.Process.HeapObjects)
+		process += fmt.Sprintf("GC Cycles: %d\n", m.stats.Process.NumGC)
+		process += fmt.Sprintf("GC Pause: %.3fms last, %.1fms total\n",
+			m.stats.Process.LastGCPauseMs, m.stats.Process.TotalGCPauseMs)
+		if m.stats.Process.OpenFDs >= 0 {
+			process += fmt.Sprintf("Open File Descriptors: %d\n", m.stats.Process.OpenFDs)
+		}
+		sections = append(sections, process)
+	}
+
+	// User-Agent breakdown
+	if len(m.stats.UserAgents) > 0 {
+		userAgents := "🧭 User Agents\n\n"
+
+		// Sort agents by request count (highest first), then by name
+		agents := make([]string, 0, len(m.stats.UserAgents))
+		for agent := range m.stats.UserAgents {
+			agents = append(agents, agent)
+		}
+		sort.Slice(agents, func(i, j int) bool {
+			ci, cj := m.stats.UserAgents[agents[i]], m.stats.UserAgents[agents[j]]
+			if ci != cj {
+				return ci > cj
+			}
+			return agents[i] < agents[j]
+		})
+
+		for _, agent := range agents {
+			userAgents += fmt.Sprintf("• %s: %d\n", agent, m.stats.UserAgents[agent])
+		}
+
+		sections = append(sections, userAgents)
+	}
+
 	// Per-endpoint statistics
 	endpointStats := "🎯 Per-Endpoint Statistics\n\n"
 	if len(m.stats.Endpoints) == 0 {
@@ -266,9 +467,23 @@ func (m *Model) statsView() string {
 		}
 		sort.Strings(paths)
 
+		// Endpoint lines are offset by the sections above, joined by blank lines
+		offset := len(sections)
+		for _, section := range sections {
+			offset += strings.Count(section, "\n")
+		}
+		m.statsEndpointLines = make(map[string]int, len(paths))
+		selected, _ := m.selectedStatsPath()
+		highlight := m.filterStyle.UnsetPadding()
+
 		for _, path := range paths {
 			stats := m.stats.Endpoints[path]
-			endpointStats += fmt.Sprintf("━━━ %s ━━━\n", path)
+			m.statsEndpointLines[path] = offset + strings.Count(endpointStats, "\n")
+			if path == selected {
+				endpointStats += highlight.Render(fmt.Sprintf("▶ ━━━ %s ━━━", path)) + "\n"
+			} else {
+				endpointStats += fmt.Sprintf("  ━━━ %s ━━━\n", path)
+			}
 			endpointStats += fmt.Sprintf("Requests: %d\n", stats.RequestCount)
 			endpointStats += fmt.Sprintf("Errors: %d\n", stats.ErrorCount)
 			endpointStats += fmt.Sprintf("Success: %d\n", stats.RequestCount-stats.ErrorCount)
@@ -295,6 +510,51 @@ func (m *Model) statsView() string {
 						endpointStats += fmt.Sprintf("Avg Req/min: %.1f\n", reqPerMin)
 					}
 				}
+
+				// Rolling rates over recent windows
+				endpointStats += "Current Rates (req/s | err/s):\n"
+				endpointStats += fmt.Sprintf("  • 1m: %.2f | %.2f\n", stats.Rates.OneMinute.RequestsPerSec, stats.Rates.OneMinute.ErrorsPerSec)
+				endpointStats += fmt.Sprintf("  • 5m: %.2f | %.2f\n", stats.Rates.FiveMinute.RequestsPerSec, stats.Rates.FiveMinute.ErrorsPerSec)
+				endpointStats += fmt.Sprintf("  • 15m: %.2f | %.2f\n", stats.Rates.FifteenMinute.RequestsPerSec, stats.Rates.FifteenMinute.ErrorsPerSec)
+
+				// Recent trend, one column per second
+				if m.timeSeries != nil {
+					if points, ok := m.timeSeries.Endpoints[path]; ok {
+						endpointStats += endpointCharts(points)
+					}
+				}
+
+				// Apdex satisfaction score
+				if stats.Apdex != nil {
+					endpointStats += fmt.Sprintf("Apdex [T=%dms]: %.2f (satisfied %d, tolerating %d, frustrated %d)\n",
+						stats.Apdex.ThresholdMs, stats.Apdex.Score,
+						stats.Apdex.Satisfied, stats.Apdex.Tolerating, stats.Apdex.Frustrated)
+				}
+
+				// SLO compliance and error-budget burn
+				if stats.SLO != nil {
+					endpointStats += fmt.Sprintf("SLO %.2f%%: %.3f%% compliant, %.1f%% error budget remaining\n",
+						stats.SLO.Target, stats.SLO.Compliance, stats.SLO.ErrorBudgetRemaining*100)
+					for _, window := range stats.SLO.Windows {
+						endpointStats += fmt.Sprintf("  • %s: %.3f%% compliant, burn rate %.2fx\n",
+							window.Window, window.Compliance, window.BurnRate)
+					}
+				}
+			}
+
+			// Slow requests
+			if stats.SlowThresholdMs > 0 {
+				endpointStats += fmt.Sprintf("Slow Requests (>%dms): %d\n", stats.SlowThresholdMs, stats.SlowCount)
+			}
+
+			// Contract checks against the real backend
+			if stats.ContractChecks > 0 {
+				endpointStats += fmt.Sprintf("Contract Checks: %d (%d mismatches)\n", stats.ContractChecks, stats.ContractMismatches)
+			}
+
+			// Concurrency
+			if stats.MaxInFlight > 0 {
+				endpointStats += fmt.Sprintf("Concurrency: %d in flight (max %d)\n", stats.InFlight, stats.MaxInFlight)
 			}
 
 			// Status code distribution
@@ -339,15 +599,101 @@ func (m *Model) statsView() string {
 }
 
 // requestLogView renders the request log tab
+// requestLogColumns are the Request Log column widths for the terminal width
+type requestLogColumns struct {
+	path   int  // Path column width, taking the space the other columns leave
+	date   bool // whether the Date column fits
+	remote bool // whether the Remote column fits
+	width  int  // Width of a row
+	// compact puts the path on a second line when even the Path column
+	// alone does not fit next to the others
+	compact bool
+}
+
+// minLogPathWidth is the narrowest the Path column gets before the Remote and
+// then the Date column are dropped to make room, and then the path is moved
+// to a line of its own
+const minLogPathWidth = 24
+
+// requestLogColumns fits the Request Log columns into the content area
+func (m *Model) requestLogColumns() requestLogColumns {
+	// The content border and padding take 6 columns, the header's padding 2
+	available := m.width - 8
+	// Cursor, Time, Method, Status and Duration with their separators
+	fixed := 2 + 11 + 7 + 7 + 9
+
+	columns := requestLogColumns{date: !m.hideDateColumn, remote: !m.hideRemoteColumn}
+	columns.path = available - fixed
+	if columns.date {
+		columns.path -= 9
+	}
+	if columns.remote {
+		columns.path -= 16
+	}
+	if columns.path < minLogPathWidth && columns.remote {
+		columns.remote = false
+		columns.path += 16
+	}
+	if columns.path < minLogPathWidth && columns.date {
+		columns.date = false
+		columns.path += 9
+	}
+	if columns.path < minLogPathWidth {
+		// The path goes under the other columns, indented past the cursor
+		columns.compact = true
+		columns.path = max(available-4, minLogPathWidth)
+		columns.width = max(fixed, available)
+		return columns
+	}
+
+	columns.width = fixed + columns.path
+	if columns.date {
+		columns.width += 9
+	}
+	if columns.remote {
+		columns.width += 16
+	}
+	return columns
+}
+
+// row joins a row's cells, leaving out the columns that do not fit; the
+// path is padded here as highlighting makes its printed width differ
+func (c requestLogColumns) row(time, date, method, path string, pathWidth int, status, duration, remote string) string {
+	if c.compact {
+		return fmt.Sprintf("%-10s %-6s %-6s %-8s\n    %s", time, method, status, duration, path)
+	}
+
+	row := fmt.Sprintf("%-10s ", time)
+	if c.date {
+		row += fmt.Sprintf("%-8s ", date)
+	}
+	row += fmt.Sprintf("%-6s %s%s %-6s %-8s", method, path, strings.Repeat(" ", max(c.path-pathWidth, 0)), status, duration)
+	if c.remote {
+		row += fmt.Sprintf(" %-15s", remote)
+	}
+	return row
+}
+
 func (m *Model) requestLogView() string {
 	if !m.connected {
-		return "❌ Not connected to server\n\nTry pressing 'R' to refresh or check if the server is running."
+		return m.disconnectedView()
 	}
 
 	content := ""
+	if m.replayingID != 0 {
+		content += fmt.Sprintf("⏳ Replaying request #%d...\n\n", m.replayingID)
+	}
+
+	if m.replayResult != nil {
+		return content + m.replayResultView(m.replayResult)
+	}
+	if m.detailEntry != nil {
+		return content + m.requestDetailView(*m.detailEntry)
+	}
 
 	// Get filtered entries
 	filteredEntries := m.filterRequestLog()
+	filter := parseTextFilter(m.filterText)
 
 	if len(m.requestLog) == 0 {
 		content += "No requests logged yet\n\n"
@@ -367,7 +713,7 @@ func (m *Model) requestLogView() string {
 		content += "• F - Enter filter mode (type to search)\n"
 		content += "• S - Toggle hide /stats requests\n"
 		content += "• C - Clear all filters\n"
-	} else if len(filteredEntries) == 0 && (m.filterText != "" || m.hideStatsRequests) {
+	} else if len(filteredEntries) == 0 && (m.filterText != "" || m.hideStatsRequests || len(m.statusClassNames()) > 0) {
 		content += "🔍 No matching requests found\n\n"
 		content += fmt.Sprintf("Total requests: %d\n", len(m.requestLog))
 		if m.filterText != "" {
@@ -376,16 +722,21 @@ func (m *Model) requestLogView() string {
 		if m.hideStatsRequests {
 			content += "Hiding /stats requests\n"
 		}
+		if classes := m.statusClassNames(); len(classes) > 0 {
+			content += fmt.Sprintf("Status: %s only\n", strings.Join(classes, ", "))
+		}
 		content += "\n💡 Tips:\n"
 		content += "• Press 'C' to clear filters\n"
 		content += "• Press 'S' to toggle internal endpoints filter\n"
+		content += "• Press '2'-'5' to toggle the 2xx-5xx status class filters\n"
 		content += "• Press 'F' to change text filter\n"
 		content += "• Press 'A' to toggle auto-refresh on/off\n"
-		content += "• Filters match path, method, or IP address\n"
+		content += "• Filters match path, method, IP address, or test tag (tag:<name> for an exact tag)\n"
+		content += "• re:<pattern> matches a regular expression; a leading ! negates the filter\n"
 		content += "• Scrolling disables auto-refresh automatically\n"
 	} else {
 		// Show filter status
-		if m.filterText != "" || m.hideStatsRequests {
+		if m.filterText != "" || m.hideStatsRequests || len(m.statusClassNames()) > 0 {
 			statusParts := []string{}
 			if m.filterText != "" {
 				statusParts = append(statusParts, fmt.Sprintf("Filter: '%s'", m.filterText))
@@ -393,28 +744,73 @@ func (m *Model) requestLogView() string {
 			if m.hideStatsRequests {
 				statusParts = append(statusParts, "Hiding /stats")
 			}
-			content += fmt.Sprintf("🔍 Filtered: %s | Showing %d/%d requests\n\n",
-				strings.Join(statusParts, ", "), len(filteredEntries), len(m.requestLog))
+			if classes := m.statusClassNames(); len(classes) > 0 {
+				statusParts = append(statusParts, "Status "+strings.Join(classes, "/"))
+			}
+			content += fmt.Sprintf("🔍 Filtered: %s | Showing %d/%d requests (⏰ %s)\n\n",
+				strings.Join(statusParts, ", "), len(filteredEntries), len(m.requestLog), m.logSortDescription())
 		} else {
-			content += fmt.Sprintf("📅 Showing all %d requests (⏰ ordered by request time, newest first)\n\n", len(filteredEntries))
+			content += fmt.Sprintf("📅 Showing all %d requests (⏰ %s)\n\n", len(filteredEntries), m.logSortDescription())
+		}
+
+		if m.groupByRoute {
+			m.requestLogRows = m.requestLogRows[:0]
+			return content + m.requestLogGroupsView(filteredEntries)
+		}
+
+		// Diff of two entries picked with M
+		if m.requestDiff != nil {
+			content += m.requestDiffView(m.requestDiff) + "\n"
+		} else if m.diffBase != nil {
+			content += fmt.Sprintf("🔀 Marked request #%d for diff; scroll to another request and press M\n\n", m.diffBase.ID)
+		}
+
+		// curl command for the entry picked with X
+		if m.curlEntry != nil {
+			content += fmt.Sprintf("📋 curl for request #%d (press X to close):\n", m.curlEntry.ID)
+			content += lipgloss.NewStyle().
+				Foreground(lipgloss.Color(m.theme.Info)).
+				Render(m.curlEntry.CurlCommand("")) + "\n\n"
 		}
 
 		// Header
 		headerStyle := lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#5F5F5F")).
+			Foreground(lipgloss.Color(m.theme.HeaderText)).
+			Background(lipgloss.Color(m.theme.HeaderBackground)).
 			Padding(0, 1)
 
-		header := fmt.Sprintf("%-10s %-8s %-6s %-40s %-6s %-8s %-15s",
-			"Time", "Date", "Method", "Path", "Status", "Duration", "Remote")
+		columns := m.requestLogColumns()
+		header := "  " + columns.row("Time", "Date", "Method", "Path", len("Path"), "Status", "Duration", "Remote")
 		content += headerStyle.Render(header) + "\n"
 
 		// Separator line
-		content += strings.Repeat("─", 95) + "\n"
+		content += strings.Repeat("─", columns.width) + "\n"
+
+		// The cursor is on the selected entry, or the first row in the viewport
+		selectedID := uint64(0)
+		for _, entry := range filteredEntries {
+			if entry.ID == m.selectedLogID {
+				selectedID = entry.ID
+			}
+		}
+		cursorShown := false
 
-		// Log entries (filtered and sorted)
+		// Log entries (filtered and sorted), remembering the line each starts on
+		m.requestLogRows = m.requestLogRows[:0]
 		for i, entry := range filteredEntries {
+			line := strings.Count(content, "\n")
+			m.requestLogRows = append(m.requestLogRows, requestLogRow{
+				line:  line,
+				entry: entry,
+			})
+
+			cursor := "  "
+			if !cursorShown && (entry.ID == selectedID || (selectedID == 0 && line >= m.scrollPositions[3])) {
+				cursor = "▶ "
+				cursorShown = true
+			}
+
 			timestamp := entry.Timestamp.Format("15:04:05")
 			date := entry.Timestamp.Format("01-02")
 
@@ -422,19 +818,25 @@ func (m *Model) requestLogView() string {
 			var statusColor lipgloss.Color
 			switch {
 			case entry.StatusCode >= 500:
-				statusColor = lipgloss.Color("#FF6B6B") // Red
+				statusColor = lipgloss.Color(m.theme.Error)
 			case entry.StatusCode >= 400:
-				statusColor = lipgloss.Color("#FFD93D") // Yellow
+				statusColor = lipgloss.Color(m.theme.Warning)
 			case entry.StatusCode >= 300:
-				statusColor = lipgloss.Color("#6BCF7F") // Green
+				statusColor = lipgloss.Color(m.theme.Success)
 			default:
-				statusColor = lipgloss.Color("#4ECDC4") // Cyan
+				statusColor = lipgloss.Color(m.theme.Info)
 			}
 
 			statusStyle := lipgloss.NewStyle().Foreground(statusColor).Bold(true)
 
+			// The tag follows the path in the Path column, taking at most half of it
+			tag := ""
+			if entry.Tag != "" {
+				tag = " [" + truncateString(entry.Tag, columns.path/2-3) + "]"
+			}
+
 			// Truncate first, THEN highlight to avoid text disappearing
-			truncatedPath := truncateString(entry.Path, 40) // Increased from 25 to 40
+			truncatedPath := truncateString(entry.Path, columns.path-ansi.StringWidth(tag))
 			truncatedRemote := truncateString(entry.RemoteAddr, 15)
 
 			// Now apply highlighting to the truncated text
@@ -443,25 +845,34 @@ func (m *Model) requestLogView() string {
 			displayRemote := truncatedRemote
 
 			if m.filterText != "" {
-				filterLower := strings.ToLower(m.filterText)
-				if strings.Contains(strings.ToLower(entry.Path), filterLower) {
-					displayPath = highlightText(truncatedPath, m.filterText)
-				}
-				if strings.Contains(strings.ToLower(entry.Method), filterLower) {
-					displayMethod = highlightText(entry.Method, m.filterText)
-				}
-				if strings.Contains(strings.ToLower(entry.RemoteAddr), filterLower) {
-					displayRemote = highlightText(truncatedRemote, m.filterText)
-				}
+				displayPath = m.highlightText(truncatedPath, filter)
+				displayMethod = m.highlightText(entry.Method, filter)
+				displayRemote = m.highlightText(truncatedRemote, filter)
+			}
+
+			// Slow requests are highlighted in orange with a marker
+			displayDuration := fmt.Sprintf("%-8s", fmt.Sprintf("%dms", entry.Duration))
+			if entry.Slow {
+				displayDuration = lipgloss.NewStyle().
+					Foreground(lipgloss.Color(m.theme.Slow)).
+					Bold(true).
+					Render(fmt.Sprintf("%-8s", fmt.Sprintf("%dms🐢", entry.Duration)))
+			}
+
+			if tag != "" {
+				displayPath += lipgloss.NewStyle().
+					Foreground(lipgloss.Color(m.theme.Tag)).
+					Render(tag)
 			}
 
-			logLine := fmt.Sprintf("%-10s %-8s %-6s %-40s %-6s %-8s %-15s",
+			logLine := cursor + columns.row(
 				timestamp,
 				date,
 				displayMethod,
 				displayPath,
-				statusStyle.Render(fmt.Sprintf("%d", entry.StatusCode)),
-				fmt.Sprintf("%dms", entry.Duration),
+				ansi.StringWidth(truncatedPath)+ansi.StringWidth(tag),
+				statusStyle.Render(fmt.Sprintf("%-6d", entry.StatusCode)),
+				displayDuration,
 				displayRemote)
 
 			content += logLine + "\n"
@@ -469,13 +880,17 @@ func (m *Model) requestLogView() string {
 			// Add separator every 5 entries for readability
 			if i > 0 && (i+1)%5 == 0 && i < len(filteredEntries)-1 {
 				content += lipgloss.NewStyle().
-					Foreground(lipgloss.Color("#666666")).
-					Render(strings.Repeat("·", 95)) + "\n" // Updated from 80 to 95
+					Foreground(lipgloss.Color(m.theme.Dim)).
+					Render(strings.Repeat("·", columns.width)) + "\n"
 			}
 		}
 
+		if m.follow {
+			m.followNewest()
+		}
+
 		content += "\n📊 Log Summary:\n"
-		if m.filterText != "" || m.hideStatsRequests {
+		if m.filterText != "" || m.hideStatsRequests || len(m.statusClassNames()) > 0 {
 			content += fmt.Sprintf("Filtered Entries: %d (of %d total)\n", len(filteredEntries), len(m.requestLog))
 		} else {
 			content += fmt.Sprintf("Total Entries: %d\n", len(filteredEntries))
@@ -532,35 +947,395 @@ func (m *Model) requestLogView() string {
 	return content
 }
 
-// highlightText highlights matching text in the original string
-func highlightText(original, filter string) string {
-	if filter == "" || original == "" {
-		return original
+// describeAlertRule summarizes the conditions of an alert rule
+func describeAlertRule(rule types.AlertRule) string {
+	var conditions []string
+	if rule.Method != "" {
+		conditions = append(conditions, rule.Method)
+	}
+	if rule.Path != "" {
+		conditions = append(conditions, rule.Path)
+	}
+	if rule.MinStatus > 0 {
+		conditions = append(conditions, fmt.Sprintf("status>=%d", rule.MinStatus))
+	}
+	if rule.MaxStatus > 0 {
+		conditions = append(conditions, fmt.Sprintf("status<=%d", rule.MaxStatus))
+	}
+	if rule.MinDurationMs > 0 {
+		conditions = append(conditions, fmt.Sprintf(">=%dms", rule.MinDurationMs))
+	}
+	if len(conditions) == 0 {
+		return "every request"
+	}
+	return strings.Join(conditions, " ")
+}
+
+// requestDetailView renders every captured detail of a single request log entry
+func (m *Model) requestDetailView(entry types.RequestLogEntry) string {
+	labelStyle := lipgloss.NewStyle().Bold(true)
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Dim))
+
+	content := fmt.Sprintf("🔎 Request #%d (Enter/Esc to close, P to replay)\n\n", entry.ID)
+	field := func(label, value string) {
+		content += fmt.Sprintf("%s %s\n", labelStyle.Render(fmt.Sprintf("%-10s", label+":")), value)
+	}
+	field("Time", entry.Timestamp.Format("2006-01-02 15:04:05.000"))
+	field("Request", fmt.Sprintf("%s %s", entry.Method, entry.Path))
+	if entry.Host != "" {
+		field("Host", entry.Host)
+	}
+	field("Remote", entry.RemoteAddr)
+	field("Status", fmt.Sprintf("%d %s", entry.StatusCode, http.StatusText(entry.StatusCode)))
+	duration := fmt.Sprintf("%dms", entry.Duration)
+	if entry.Slow {
+		duration += " 🐢 slow"
+	}
+	field("Duration", duration)
+	if entry.Tag != "" {
+		field("Tag", entry.Tag)
+	}
+	for i, mismatch := range entry.ContractMismatches {
+		if i == 0 {
+			field("Contract", "✗ "+mismatch)
+		} else {
+			content += fmt.Sprintf("%-10s ✗ %s\n", "", mismatch)
+		}
+	}
+
+	// Query parameters
+	content += "\n" + labelStyle.Render("Query Parameters") + "\n"
+	var query url.Values
+	if u, err := url.Parse(entry.Path); err == nil {
+		query = u.Query()
+	}
+	if len(query) == 0 {
+		content += dim.Render("  (none)") + "\n"
+	}
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range query[name] {
+			content += fmt.Sprintf("  %s = %s\n", name, value)
+		}
+	}
+
+	content += "\n" + m.headersView("Headers", entry.Headers)
+	content += "\n" + m.bodyView("Body", entry.Body, entry.BodyTruncated)
+	return content
+}
+
+// replayResultView renders the response to a replayed request next to the original outcome
+func (m *Model) replayResultView(result *types.ReplayResult) string {
+	labelStyle := lipgloss.NewStyle().Bold(true)
+	original, response := result.Original, result.Response
+
+	content := fmt.Sprintf("🔁 Replay of request #%d (Enter/Esc to close, P to replay again)\n\n", original.ID)
+	field := func(label, value string) {
+		content += fmt.Sprintf("%s %s\n", labelStyle.Render(fmt.Sprintf("%-10s", label+":")), value)
+	}
+	field("Request", fmt.Sprintf("%s %s", original.Method, original.Path))
+	field("Target", result.Target)
+	field("Original", fmt.Sprintf("%d %s in %dms", original.StatusCode, http.StatusText(original.StatusCode), original.Duration))
+
+	replayed := fmt.Sprintf("%d %s in %dms", response.StatusCode, http.StatusText(response.StatusCode), response.Duration)
+	if response.StatusCode != original.StatusCode {
+		replayed = lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Warning)).Bold(true).Render(replayed + " (status changed)")
+	}
+	field("Replayed", replayed)
+
+	content += "\n" + m.headersView("Response Headers", response.Headers)
+	content += "\n" + m.bodyView("Response Body", response.Body, response.BodyTruncated)
+	return content
+}
+
+// headersView renders a titled list of headers sorted by name, one value per line
+func (m *Model) headersView(title string, headers http.Header) string {
+	content := lipgloss.NewStyle().Bold(true).Render(title) + "\n"
+	if len(headers) == 0 {
+		return content + lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Dim)).Render("  (not captured)") + "\n"
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range headers[name] {
+			content += fmt.Sprintf("  %s: %s\n", name, value)
+		}
+	}
+	return content
+}
+
+// bodyView renders a titled captured body, pretty-printed when it is JSON
+func (m *Model) bodyView(title, body string, truncated bool) string {
+	heading := fmt.Sprintf("%s (%d bytes)", title, len(body))
+	if truncated {
+		heading = fmt.Sprintf("%s (first %d bytes, truncated)", title, len(body))
+	}
+	content := lipgloss.NewStyle().Bold(true).Render(heading) + "\n"
+	if body == "" {
+		return content + lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Dim)).Render("  (empty)") + "\n"
+	}
+
+	lines := strings.Split(body, "\n")
+	tree, isJSON := parseJSONTree(body)
+	if isJSON {
+		lines = tree.renderJSON(m.newJSONStyles(), m.bodyFoldDepth)
+	}
+
+	// Long lines scroll sideways instead of wrapping
+	width := max(m.width-8, 20)
+	widest := 0
+	for _, line := range lines {
+		widest = max(widest, ansi.StringWidth(line))
+	}
+	if m.bodyScrollX > widest-width {
+		m.bodyScrollX = max(widest-width, 0)
+	}
+
+	var hints []string
+	if isJSON {
+		hints = append(hints, "z/Z: fold/unfold")
+	}
+	if widest > width {
+		hints = append(hints, fmt.Sprintf("←→: scroll (column %d of %d)", m.bodyScrollX+1, widest))
+	}
+	if len(hints) > 0 {
+		content += lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Dim)).Render("  "+strings.Join(hints, " | ")) + "\n"
+	}
+	for _, line := range lines {
+		content += "  " + ansi.Cut(line, m.bodyScrollX, m.bodyScrollX+width) + "\n"
+	}
+	return content
+}
+
+// requestDiffView renders a structured diff of two request log entries
+func (m *Model) requestDiffView(diff *types.RequestLogDiff) string {
+	content := fmt.Sprintf("🔀 Diff of request #%d → #%d (press M to close):\n", diff.From, diff.To)
+	if diff.Identical {
+		return content + "  No differences in request line, status, headers or body\n"
+	}
+
+	removedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Error))
+	addedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Success))
+
+	sections := []struct {
+		name    string
+		changes []types.ValueChange
+	}{
+		{"Request", diff.Request},
+		{"Headers", diff.Headers},
+		{"Body", diff.Body},
+	}
+	for _, section := range sections {
+		if len(section.changes) == 0 {
+			continue
+		}
+		content += fmt.Sprintf("  %s:\n", section.name)
+		for _, change := range section.changes {
+			switch change.Change {
+			case types.ChangeAdded:
+				content += addedStyle.Render(fmt.Sprintf("    + %s: %v", change.Field, change.To)) + "\n"
+			case types.ChangeRemoved:
+				content += removedStyle.Render(fmt.Sprintf("    - %s: %v", change.Field, change.From)) + "\n"
+			default:
+				content += fmt.Sprintf("    ~ %s: %s → %s\n", change.Field,
+					removedStyle.Render(truncateString(fmt.Sprint(change.From), 35)),
+					addedStyle.Render(truncateString(fmt.Sprint(change.To), 35)))
+			}
+		}
+	}
+	return content
+}
+
+// requestLogGroupsView renders request log entries grouped by normalized route
+func (m *Model) requestLogGroupsView(entries []types.RequestLogEntry) string {
+	groups := types.GroupRequestLog(entries)
+
+	content := fmt.Sprintf("🧩 Grouped by route: %d routes (press V for individual requests)\n\n", len(groups))
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color(m.theme.HeaderText)).
+		Background(lipgloss.Color(m.theme.HeaderBackground)).
+		Padding(0, 1)
+
+	header := fmt.Sprintf("%-40s %-7s %-7s %-9s %-9s %-9s %-10s",
+		"Route", "Count", "Errors", "Avg", "P95", "Max", "Last Seen")
+	content += headerStyle.Render(header) + "\n"
+	content += strings.Repeat("─", 95) + "\n"
+
+	for _, group := range groups {
+		errors := fmt.Sprintf("%d", group.ErrorCount)
+		if group.ErrorCount > 0 {
+			errors = lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Error)).Render(fmt.Sprintf("%-7s", errors))
+		}
+
+		content += fmt.Sprintf("%-40s %-7d %-7s %-9s %-9s %-9s %-10s\n",
+			truncateString(group.Route, 40),
+			group.Count,
+			errors,
+			fmt.Sprintf("%.1fms", group.AvgDurationMs),
+			fmt.Sprintf("%dms", group.P95DurationMs),
+			fmt.Sprintf("%dms", group.MaxDurationMs),
+			group.LastSeen.Format("15:04:05"))
 	}
 
-	// Case-insensitive search
-	filterLower := strings.ToLower(filter)
-	originalLower := strings.ToLower(original)
+	return content
+}
 
-	if !strings.Contains(originalLower, filterLower) {
+// highlightText highlights the filter's matches in the original string
+func (m *Model) highlightText(original string, filter textFilter) string {
+	spans := filter.highlights(original)
+	if len(spans) == 0 {
 		return original
 	}
 
 	highlightStyle := lipgloss.NewStyle().
-		Background(lipgloss.Color("#FFFF00")).
-		Foreground(lipgloss.Color("#000000")).
+		Background(lipgloss.Color(m.theme.MatchBackground)).
+		Foreground(lipgloss.Color(m.theme.MatchText)).
 		Bold(true)
 
-	// Find the actual case-preserved match
-	index := strings.Index(originalLower, filterLower)
-	if index >= 0 && index+len(filter) <= len(original) {
-		before := original[:index]
-		match := original[index : index+len(filter)]
-		after := original[index+len(filter):]
-		return before + highlightStyle.Render(match) + after
+	highlighted := ""
+	last := 0
+	for _, span := range spans {
+		if span[0] == span[1] {
+			continue // Empty regular expression matches
+		}
+		highlighted += original[last:span[0]] + highlightStyle.Render(original[span[0]:span[1]])
+		last = span[1]
+	}
+	return highlighted + original[last:]
+}
+
+// filterError describes why filter text is not a valid filter, for the filter line
+func (m *Model) filterError(text string) string {
+	filter := parseTextFilter(text)
+	if filter.err == nil {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.theme.Error)).
+		Render("  ⚠️ invalid regular expression, ignored")
+}
+
+// endpointEditorView renders the Configuration tab in edit mode: the endpoint
+// list with a cursor, or the form of the endpoint being edited
+func (m *Model) endpointEditorView() string {
+	editor := m.editor
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Dim))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Error))
+	highlight := m.filterStyle.UnsetPadding()
+
+	if form := editor.form; form != nil && form.step != stepForm {
+		return m.endpointWizardView()
+	}
+	if form := editor.form; form != nil {
+		content := "✏️  New Endpoint\n\n"
+		if form.originalPath != "" {
+			content = fmt.Sprintf("✏️  Edit Endpoint %s\n\n", form.originalPath)
+		}
+
+		for field := 0; field < fieldCount; field++ {
+			value := form.values[field]
+			if field == fieldType {
+				value = fmt.Sprintf("◀ %s ▶", value)
+			} else if field == form.focus {
+				value += "|"
+			}
+
+			line := fmt.Sprintf("%-14s %s", endpointFieldLabels[field]+":", value)
+			switch {
+			case field == form.focus:
+				line = highlight.Render(line)
+			case !form.fieldUsed(field):
+				line = dim.Render(line + " (not used by this type)")
+			}
+			content += "  " + line + "\n"
+		}
+
+		content += "\n"
+		if form.submitting {
+			content += "⏳ Saving...\n"
+		} else if form.err != "" {
+			content += errorStyle.Render("❌ "+form.err) + "\n"
+		}
+		return content
+	}
+
+	content := "✏️  Edit Endpoints\n\n"
+	if editor.status != "" {
+		content += editor.status + "\n\n"
+	}
+
+	paths := m.editorPaths()
+	if editor.cursor >= len(paths) && len(paths) > 0 {
+		editor.cursor = len(paths) - 1
+	}
+	if len(paths) == 0 {
+		content += "No endpoints configured. Press 'N' to add one.\n"
+	}
+
+	// Keep the cursor inside the viewport
+	cursorLine := strings.Count(content, "\n") + editor.cursor
+	if cursorLine < m.scrollPositions[1] {
+		m.scrollPositions[1] = cursorLine
+	} else if cursorLine >= m.scrollPositions[1]+m.viewportHeight {
+		m.scrollPositions[1] = cursorLine - m.viewportHeight + 1
+	}
+
+	for i, path := range paths {
+		endpoint := m.config.Endpoints[path]
+		line := fmt.Sprintf("%s (%s)", path, endpoint.Type)
+		if endpoint.Group != "" {
+			line += fmt.Sprintf(" {%s}", endpoint.Group)
+		}
+		if endpoint.Disabled {
+			line += " [disabled]"
+		}
+		if i == editor.cursor {
+			line = highlight.Render("▶ " + line)
+		} else {
+			line = "  " + line
+		}
+		content += line + "\n"
+	}
+
+	if editor.confirmDelete != "" {
+		content += "\n" + errorStyle.Render(fmt.Sprintf("Delete %s? Press 'y' to confirm, any other key to cancel", editor.confirmDelete)) + "\n"
+	}
+	return content
+}
+
+// rawConfigView renders a configuration edited as JSON that is being applied
+// or was rejected, with the error and the offending line marked
+func (m *Model) rawConfigView() string {
+	edit := m.rawConfig
+	if edit.applying {
+		return "⏳ Validating and applying configuration..."
+	}
+
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Error))
+	content := "📝 Edited Configuration\n\n"
+	if edit.err != "" {
+		content += errorStyle.Render("❌ "+edit.err) + "\n"
 	}
+	content += "Press 'O' to fix it in your editor or Esc to discard the changes.\n\n"
 
-	return original
+	for i, line := range strings.Split(strings.TrimRight(edit.buffer, "\n"), "\n") {
+		numbered := fmt.Sprintf("%4d  %s", i+1, line)
+		if i+1 == edit.errLine {
+			numbered = errorStyle.Render(numbered + "  ◀")
+		}
+		content += numbered + "\n"
+	}
+	return content
 }
 
 // helpView renders the help tab
@@ -570,40 +1345,37 @@ func (m *Model) helpView() string {
 	// Keyboard shortcuts
 	content += "⌨️  Keyboard Shortcuts:\n"
 	content += "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"
-	content += "Navigation:\n"
-	content += "• Tab             - Switch to next tab\n"
-	content += "• Shift+Tab       - Switch to previous tab\n"
-	content += "\nScrolling:\n"
-	content += "• ↑ / k           - Scroll up one line\n"
-	content += "• ↓ / j           - Scroll down one line\n"
-	content += "• Page Up / u     - Scroll up half page\n"
-	content += "• Page Down / d   - Scroll down half page\n"
-	content += "• Home / g        - Go to top\n"
-	content += "• End / G         - Go to bottom\n"
-	content += "\nFiltering:\n"
-	content += "• F               - Enter/exit filter mode (Request Log & Configuration tabs)\n"
-	content += "• C               - Clear all filters (Request Log & Configuration tabs)\n"
-	content += "• Enter/Esc       - Exit filter mode (in filter mode)\n"
-	content += "• Backspace       - Delete filter characters (in filter mode)\n"
-	content += "\nRequest Log Specific:\n"
-	content += "• S               - Toggle hide /stats requests\n"
-	content += "• A               - Toggle auto-refresh on/off\n"
-	content += "\nActions:\n"
-	content += "• R               - Refresh data from server\n"
-	content += "• Q / Ctrl+C      - Quit application\n\n"
+	for i, section := range helpSections {
+		if i > 0 {
+			content += "\n"
+		}
+		content += section.title + ":\n"
+		for _, line := range section.lines {
+			content += line + "\n"
+		}
+	}
+	if bindings := m.keys.customBindings(); len(bindings) > 0 {
+		content += "\nCustom key bindings (from the client config, replacing the keys above):\n"
+		for _, binding := range bindings {
+			content += "• " + binding + "\n"
+		}
+	}
+	content += "\n"
 
 	// Tab descriptions
 	content += "📑 Tab Descriptions:\n"
 	content += "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"
 	content += "• Overview        - Server info, quick stats, recent activity\n"
 	content += "                    Shows server configuration, uptime, request counts,\n"
+	content += "                    a live 60-second req/s and error-rate chart,\n"
 	content += "                    and the last few requests made to the server.\n\n"
 	content += "• Configuration   - Server settings and endpoint configurations\n"
 	content += "                    View current server config and all configured\n"
 	content += "                    dynamic endpoints with their settings.\n\n"
 	content += "• Statistics      - Detailed per-endpoint metrics and performance\n"
 	content += "                    Comprehensive statistics including response times,\n"
-	content += "                    error rates, and request frequency per endpoint.\n\n"
+	content += "                    error rates, and request frequency per endpoint,\n"
+	content += "                    with 60-second request and latency sparklines.\n\n"
 	content += "• Request Log     - Real-time request log with advanced filtering\n"
 	content += "                    Shows recent HTTP requests with timestamps,\n"
 	content += "                    methods, paths, status codes, and durations.\n"
@@ -621,10 +1393,13 @@ func (m *Model) helpView() string {
 	content += "• Type to search through relevant fields\n"
 	content += "• Filter applies automatically with 200ms debouncing\n"
 	content += "• Matching text is highlighted in yellow\n"
+	content += "• Type 're:<pattern>' to match a regular expression, e.g. 're:^/api/v[12]/'\n"
+	content += "• Start with '!' to show only what does not match, e.g. '!/health'\n"
 	content += "• Press Enter or Esc to exit filter mode\n"
 	content += "• Press 'C' to clear filters\n\n"
 	content += "Request Log Filtering:\n"
-	content += "• Filters: paths, methods, and IP addresses\n"
+	content += "• Filters: paths, methods, IP addresses, and X-Test-Tag tags\n"
+	content += "• Type 'tag:<name>' to show only requests with that exact tag\n"
 	content += "• Additional 'S' key to hide/show /stats endpoints\n"
 	content += "• Auto-refresh toggle with 'A' key\n"
 	content += "• Status shown: 'Showing X/Y requests'\n\n"
@@ -651,7 +1426,7 @@ func (m *Model) helpView() string {
 	content += "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"
 	content += fmt.Sprintf("• Server URL:     %s\n", m.httpURL)
 	content += fmt.Sprintf("• WebSocket URL:  %s\n", m.serverURL)
-	content += "• Protocol:       HTTP polling (every 1 second)\n"
+	content += fmt.Sprintf("• Protocol:       HTTP polling (every %s%s)\n", m.refreshInterval, m.pollingMode())
 	content += "• Status:         "
 	if m.connected {
 		content += "✅ Connected\n"
@@ -663,10 +1438,11 @@ func (m *Model) helpView() string {
 	// Status indicators
 	content += "🎨 Status Code Colors:\n"
 	content += "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n"
-	content += "• 2xx Success     - " + lipgloss.NewStyle().Foreground(lipgloss.Color("#4ECDC4")).Render("Cyan") + "   (200 OK, 201 Created, etc.)\n"
-	content += "• 3xx Redirect    - " + lipgloss.NewStyle().Foreground(lipgloss.Color("#6BCF7F")).Render("Green") + "  (301 Moved, 302 Found, etc.)\n"
-	content += "• 4xx Client Err  - " + lipgloss.NewStyle().Foreground(lipgloss.Color("#FFD93D")).Render("Yellow") + " (400 Bad Request, 404 Not Found, etc.)\n"
-	content += "• 5xx Server Err  - " + lipgloss.NewStyle().Foreground(lipgloss.Color("#FF6B6B")).Render("Red") + "    (500 Internal Error, 503 Unavailable, etc.)\n\n"
+	content += "• 2xx Success     - " + lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Info)).Render("200 OK, 201 Created, etc.") + "\n"
+	content += "• 3xx Redirect    - " + lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Success)).Render("301 Moved, 302 Found, etc.") + "\n"
+	content += "• 4xx Client Err  - " + lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Warning)).Render("400 Bad Request, 404 Not Found, etc.") + "\n"
+	content += "• 5xx Server Err  - " + lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Error)).Render("500 Internal Error, 503 Unavailable, etc.") + "\n"
+	content += fmt.Sprintf("Theme: %s (press T to cycle %s)\n\n", m.theme.Name, strings.Join(ThemeNames(), ", "))
 
 	// API endpoints
 	content += "🌐 Server API Endpoints:\n"
@@ -718,10 +1494,14 @@ func (m *Model) helpView() string {
 	return content
 }
 
-// Helper function to truncate strings
+// truncateString shortens s to at most maxLen columns, ending it with "..."
+// when cut. It cuts between characters, so UTF-8 paths stay intact.
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if ansi.StringWidth(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	if maxLen <= 3 {
+		return ansi.Truncate(s, maxLen, "")
+	}
+	return ansi.Truncate(s, maxLen, "...")
 }
@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"webserver/pkg/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorilla/websocket"
+)
+
+// liveRetryInterval is how long the TUI polls before trying the push stream again
+const liveRetryInterval = 5 * time.Second
+
+// liveConn is a WebSocket connection to the server's push API. A reader
+// goroutine turns server messages into TUI messages, delivered one at a time
+// by next.
+type liveConn struct {
+	conn   *websocket.Conn
+	events chan tea.Msg
+	done   chan struct{}
+}
+
+// wsEnvelope mirrors types.TUIMessage with the payload left undecoded
+type wsEnvelope struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// connectWebSocket opens the push stream and subscribes to configuration,
+// statistics and the request log
+func (m *Model) connectWebSocket() tea.Msg {
+	dialer := websocket.Dialer{HandshakeTimeout: 5 * time.Second, EnableCompression: true}
+	header := http.Header{}
+	authorize(header, m.token)
+	conn, resp, err := dialer.Dial(m.serverURL, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return UnauthorizedMsg{}
+		}
+		return LiveFailedMsg{Error: err.Error()}
+	}
+
+	subscriptions := []types.WSClientMessage{
+		{Type: types.ClientSubscribe, Topic: types.TopicConfig},
+		{Type: types.ClientSubscribe, Topic: types.TopicStats, IntervalMs: statsIntervalMs(m.refreshInterval)},
+		{Type: types.ClientSubscribe, Topic: types.TopicRequestLog, Backlog: types.DefaultRequestLogSize},
+	}
+	for _, sub := range subscriptions {
+		if err := conn.WriteJSON(sub); err != nil {
+			conn.Close()
+			return LiveFailedMsg{Error: err.Error()}
+		}
+	}
+
+	live := &liveConn{
+		conn:   conn,
+		events: make(chan tea.Msg, 64),
+		done:   make(chan struct{}),
+	}
+	go live.readPump()
+	return LiveConnectedMsg{Conn: live}
+}
+
+// readPump reads server messages until the connection fails or is closed
+func (l *liveConn) readPump() {
+	for {
+		var message wsEnvelope
+		if err := l.conn.ReadJSON(&message); err != nil {
+			l.deliver(LiveDisconnectedMsg{Conn: l, Error: err.Error()})
+			return
+		}
+		if msg := decodeLiveMessage(message); msg != nil {
+			if !l.deliver(LiveEventMsg{Conn: l, Msg: msg}) {
+				return
+			}
+		}
+	}
+}
+
+// deliver hands a message to next, giving up once the connection is closed
+func (l *liveConn) deliver(msg tea.Msg) bool {
+	select {
+	case l.events <- msg:
+		return true
+	case <-l.done:
+		return false
+	}
+}
+
+// next waits for the next message from the push stream
+func (l *liveConn) next() tea.Msg {
+	select {
+	case msg := <-l.events:
+		return msg
+	case <-l.done:
+		return nil
+	}
+}
+
+// subscribeStats subscribes to statistics again, pushed at a new interval
+func (l *liveConn) subscribeStats(interval time.Duration) error {
+	return l.conn.WriteJSON(types.WSClientMessage{Type: types.ClientSubscribe, Topic: types.TopicStats, IntervalMs: statsIntervalMs(interval)})
+}
+
+// close shuts the connection and its reader down
+func (l *liveConn) close() {
+	select {
+	case <-l.done:
+	default:
+		close(l.done)
+		l.conn.Close()
+	}
+}
+
+// decodeLiveMessage converts a server message into the TUI message that
+// updates the same state; messages the TUI does not use yield nil
+func decodeLiveMessage(message wsEnvelope) tea.Msg {
+	switch message.Type {
+	case types.MessageConfig, types.MessageConfigUpdated:
+		var config types.Config
+		if err := json.Unmarshal(message.Data, &config); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to parse pushed config: %v", err)}
+		}
+		return ConfigMsg{Config: &config}
+
+	case types.MessageScenarios:
+		var list types.ScenarioList
+		if err := json.Unmarshal(message.Data, &list); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to parse pushed scenarios: %v", err)}
+		}
+		return ScenariosMsg{List: &list}
+
+	case types.MessageConfigStatus:
+		var status types.ConfigStatus
+		if err := json.Unmarshal(message.Data, &status); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to parse pushed configuration status: %v", err)}
+		}
+		return ConfigStatusMsg{Status: &status}
+
+	case types.MessageStats, types.MessageStatsReset:
+		var stats types.ServerStats
+		if err := json.Unmarshal(message.Data, &stats); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to parse pushed stats: %v", err)}
+		}
+		return StatsMsg{Stats: &stats}
+
+	case types.MessageStatsUpdate:
+		var stats types.ServerStats
+		if err := json.Unmarshal(message.Data, &stats); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to parse pushed stats: %v", err)}
+		}
+		return StatsUpdateMsg{Stats: &stats}
+
+	case types.MessageRequestLog:
+		var entry types.RequestLogEntry
+		if err := json.Unmarshal(message.Data, &entry); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to parse pushed request log entry: %v", err)}
+		}
+		return RequestLogEntryMsg{Entry: entry}
+
+	case types.MessageSubscribed:
+		// The request log backlog follows its subscription ack, replacing
+		// whatever was fetched before
+		var sub types.WSSubscription
+		if err := json.Unmarshal(message.Data, &sub); err == nil && sub.Topic == types.TopicRequestLog {
+			return RequestLogMsg{Entries: []types.RequestLogEntry{}}
+		}
+		return nil
+
+	case types.MessageLogCleared:
+		return RequestLogMsg{Entries: []types.RequestLogEntry{}}
+
+	case types.MessageDropped:
+		// Entries were missed while the TUI was slow; resync over HTTP
+		return LiveDroppedMsg{}
+
+	case types.MessageError:
+		var wsErr types.WSError
+		if err := json.Unmarshal(message.Data, &wsErr); err != nil {
+			return ErrorMsg{Error: fmt.Sprintf("Failed to parse pushed error: %v", err)}
+		}
+		return ErrorMsg{Error: fmt.Sprintf("Server: %s", wsErr.Error)}
+	}
+
+	return nil
+}
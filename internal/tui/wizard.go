@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"webserver/pkg/types"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Steps of the new-endpoint wizard
+const (
+	stepForm    = iota // Not a wizard: the full form, for editing an existing endpoint
+	stepType           // Choose the endpoint type
+	stepFields         // Fill in the fields the type uses
+	stepPreview        // Review the JSON sent to the server, then submit
+)
+
+// endpointTypeDescriptions explain the editable endpoint types to someone who
+// does not know the config schema
+var endpointTypeDescriptions = map[string]string{
+	"error":             "Always fails with a fixed status code and message",
+	"delay":             "Succeeds with an optional JSON body after waiting",
+	"conditional_error": "Fails every Nth request and succeeds otherwise",
+	"fuzz":              "Returns the JSON body broken a different way on every request",
+}
+
+// endpointFieldHints describe what to type in each wizard field
+var endpointFieldHints = [fieldCount]string{
+	fieldPath:        "URL path the endpoint answers, e.g. /api/users",
+	fieldStatusCode:  "HTTP status code of the failures, e.g. 500 or 503",
+	fieldMessage:     "Error message in the JSON response (optional)",
+	fieldDelayMs:     "Milliseconds to wait before responding, e.g. 1000",
+	fieldErrorEveryN: "Fail every Nth request, e.g. 3 fails one in three",
+	fieldBody:        `JSON object returned on success, e.g. {"ok": true} (optional)`,
+}
+
+// newEndpointWizard opens the wizard for creating an endpoint
+func newEndpointWizard() *endpointForm {
+	form := newEndpointForm("", types.EndpointConfig{})
+	form.values[fieldStatusCode] = "" // Defaulted once the type is chosen
+	form.step = stepType
+	return form
+}
+
+// wizardFields returns the fields the chosen type uses, in display order
+func (f *endpointForm) wizardFields() []int {
+	var fields []int
+	for field := 0; field < fieldCount; field++ {
+		if field != fieldType && f.fieldUsed(field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// chooseType fills in sensible defaults for the chosen type's empty fields
+func (f *endpointForm) chooseType() {
+	defaults := map[string]map[int]string{
+		"error":             {fieldStatusCode: "500"},
+		"delay":             {fieldDelayMs: "1000"},
+		"conditional_error": {fieldStatusCode: "503", fieldErrorEveryN: "3"},
+		"fuzz":              {fieldStatusCode: "200"},
+	}
+	for field, value := range defaults[f.values[fieldType]] {
+		if f.values[field] == "" {
+			f.values[field] = value
+		}
+	}
+	f.focus = fieldPath
+}
+
+// moveWizardFocus moves to the next (1) or previous (-1) field the type
+// uses, reporting false when there is none in that direction
+func (f *endpointForm) moveWizardFocus(step int) bool {
+	fields := f.wizardFields()
+	for i, field := range fields {
+		if field == f.focus && i+step >= 0 && i+step < len(fields) {
+			f.focus = fields[i+step]
+			return true
+		}
+	}
+	return false
+}
+
+// handleWizardKey handles key presses in the new-endpoint wizard
+func (m *Model) handleWizardKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	form := m.editor.form
+	if form.submitting {
+		return m, nil
+	}
+
+	switch form.step {
+	case stepType:
+		switch msg.String() {
+		case "esc":
+			m.editor.form = nil
+		case "up", "k", "left":
+			form.cycleType(-1)
+		case "down", "j", "right", "tab":
+			form.cycleType(1)
+		case "enter":
+			form.chooseType()
+			form.step = stepFields
+		}
+
+	case stepFields:
+		switch msg.String() {
+		case "esc":
+			form.err = ""
+			form.step = stepType
+		case "tab", "down":
+			form.moveWizardFocus(1)
+		case "shift+tab", "up":
+			form.moveWizardFocus(-1)
+		case "backspace":
+			if value := form.values[form.focus]; len(value) > 0 {
+				_, size := utf8.DecodeLastRuneInString(value)
+				form.values[form.focus] = value[:len(value)-size]
+			}
+		case "enter":
+			// Enter moves through the fields, then on to the preview
+			if form.moveWizardFocus(1) {
+				return m, nil
+			}
+			if _, _, err := form.endpoint(); err != nil {
+				form.err = err.Error()
+				return m, nil
+			}
+			form.err = ""
+			form.step = stepPreview
+		default:
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				form.values[form.focus] += string(msg.Runes)
+			}
+		}
+
+	case stepPreview:
+		switch msg.String() {
+		case "esc":
+			form.err = ""
+			form.step = stepFields
+		case "enter":
+			path, config, err := form.endpoint()
+			if err != nil {
+				form.err = err.Error()
+				return m, nil
+			}
+			form.err = ""
+			form.submitting = true
+			return m, m.saveEndpoint("", path, config)
+		}
+	}
+	return m, nil
+}
+
+// endpointWizardView renders the current step of the new-endpoint wizard
+func (m *Model) endpointWizardView() string {
+	form := m.editor.form
+	dim := lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Dim))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.theme.Error))
+	highlight := m.filterStyle.UnsetPadding()
+
+	content := "✨ New Endpoint\n\n"
+	switch form.step {
+	case stepType:
+		content += "Step 1 of 3: What should the endpoint do?\n\n"
+		for _, endpointType := range editableEndpointTypes {
+			line := fmt.Sprintf("%-18s %s", endpointType, endpointTypeDescriptions[endpointType])
+			if endpointType == form.values[fieldType] {
+				content += "  " + highlight.Render("▶ "+line) + "\n"
+			} else {
+				content += "    " + line + "\n"
+			}
+		}
+
+	case stepFields:
+		content += fmt.Sprintf("Step 2 of 3: Fill in the %s endpoint\n\n", form.values[fieldType])
+		for _, field := range form.wizardFields() {
+			value := form.values[field]
+			line := fmt.Sprintf("%-14s %s", endpointFieldLabels[field]+":", value)
+			if field == form.focus {
+				line = highlight.Render(line + "|")
+			}
+			content += "  " + line + "\n"
+			content += "  " + dim.Render(fmt.Sprintf("%-14s %s", "", endpointFieldHints[field])) + "\n"
+		}
+
+	case stepPreview:
+		content += "Step 3 of 3: Review the endpoint sent to POST /config\n\n"
+		path, config, err := form.endpoint()
+		if err == nil {
+			request := struct {
+				Path   string               `json:"path"`
+				Config types.EndpointConfig `json:"config"`
+			}{path, config}
+			data, _ := json.MarshalIndent(request, "", "  ")
+			for _, line := range strings.Split(string(data), "\n") {
+				content += "  " + line + "\n"
+			}
+		}
+	}
+
+	content += "\n"
+	if form.submitting {
+		content += "⏳ Saving...\n"
+	} else if form.err != "" {
+		content += errorStyle.Render("❌ "+form.err) + "\n"
+	}
+	return content
+}
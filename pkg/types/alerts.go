@@ -0,0 +1,30 @@
+package types
+
+import "time"
+
+// Webhook payload formats for alert notifications
+const (
+	AlertFormatJSON  = "json"
+	AlertFormatSlack = "slack"
+)
+
+// AlertRule represents a condition on logged requests that triggers a
+// webhook notification, e.g. status >= 500 on /api/payments*
+type AlertRule struct {
+	Name            string `json:"name"`
+	Path            string `json:"path,omitempty"`             // Path pattern as used by request_log_exclude; empty matches all
+	Method          string `json:"method,omitempty"`           // Empty matches all methods
+	MinStatus       int    `json:"min_status,omitempty"`       // Inclusive lower bound on the status code
+	MaxStatus       int    `json:"max_status,omitempty"`       // Inclusive upper bound on the status code; 0 means no bound
+	MinDurationMs   int64  `json:"min_duration_ms,omitempty"`  // Only match requests at least this slow
+	WebhookURL      string `json:"webhook_url"`                // Receives a POST for every match
+	Format          string `json:"format,omitempty"`           // "json" (default) or "slack"
+	CooldownSeconds int    `json:"cooldown_seconds,omitempty"` // Minimum time between notifications; 0 sends every match
+}
+
+// AlertNotification represents the JSON payload posted to an alert webhook
+type AlertNotification struct {
+	Rule      string          `json:"rule"`
+	Timestamp time.Time       `json:"timestamp"`
+	Entry     RequestLogEntry `json:"entry"`
+}
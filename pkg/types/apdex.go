@@ -0,0 +1,35 @@
+package types
+
+// ApdexStats represents Apdex satisfaction counts for an endpoint.
+// Requests at or under the threshold are satisfied, those under four times
+// the threshold are tolerating, and slower or failed requests are frustrated.
+type ApdexStats struct {
+	ThresholdMs int64   `json:"threshold_ms"`
+	Satisfied   int64   `json:"satisfied"`
+	Tolerating  int64   `json:"tolerating"`
+	Frustrated  int64   `json:"frustrated"`
+	Score       float64 `json:"score"`
+}
+
+// record classifies a single request
+func (a *ApdexStats) record(durationMs int64, statusCode int) {
+	switch {
+	case statusCode >= 500:
+		a.Frustrated++
+	case durationMs <= a.ThresholdMs:
+		a.Satisfied++
+	case durationMs <= 4*a.ThresholdMs:
+		a.Tolerating++
+	default:
+		a.Frustrated++
+	}
+}
+
+// score computes (satisfied + tolerating/2) / total, or 1 with no samples
+func (a *ApdexStats) score() float64 {
+	total := a.Satisfied + a.Tolerating + a.Frustrated
+	if total == 0 {
+		return 1
+	}
+	return (float64(a.Satisfied) + float64(a.Tolerating)/2) / float64(total)
+}
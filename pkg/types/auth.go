@@ -0,0 +1,95 @@
+package types
+
+import (
+	"crypto/subtle"
+	"strings"
+)
+
+// Management API permissions granted to an auth token
+const (
+	PermissionRead  = "read"  // View configuration, statistics and the request log
+	PermissionWrite = "write" // Change configuration, take snapshots and replay requests; implies read
+)
+
+// ManagementAuthConfig protects the management API (/config, /stats*,
+// /requestlog*, /ws) with bearer tokens. Auth is enabled once any token is
+// configured; dynamic endpoints and static files stay public.
+type ManagementAuthConfig struct {
+	Tokens []AuthToken `json:"tokens"`
+}
+
+// AuthToken represents a named management API token and its permissions
+type AuthToken struct {
+	Name        string   `json:"name"`
+	Token       string   `json:"token"`
+	Permissions []string `json:"permissions"` // "read" and/or "write"
+}
+
+// Enabled reports whether management auth is configured
+func (c *ManagementAuthConfig) Enabled() bool {
+	return c != nil && len(c.Tokens) > 0
+}
+
+// Authenticate returns the token matching the given secret, or nil
+func (c *ManagementAuthConfig) Authenticate(secret string) *AuthToken {
+	if c == nil || secret == "" {
+		return nil
+	}
+
+	for i := range c.Tokens {
+		if subtle.ConstantTimeCompare([]byte(c.Tokens[i].Token), []byte(secret)) == 1 {
+			return &c.Tokens[i]
+		}
+	}
+	return nil
+}
+
+// Allows reports whether the token grants a permission
+func (t *AuthToken) Allows(permission string) bool {
+	for _, granted := range t.Permissions {
+		if strings.EqualFold(granted, permission) || strings.EqualFold(granted, PermissionWrite) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithoutSecrets returns a copy of the configuration with auth token secrets
+// masked, suitable for returning to API and WebSocket clients
+func (c *Config) WithoutSecrets() *Config {
+	if c == nil || c.Server.ManagementAuth == nil {
+		return c
+	}
+
+	masked := *c
+	auth := *c.Server.ManagementAuth
+	auth.Tokens = make([]AuthToken, len(c.Server.ManagementAuth.Tokens))
+	for i, token := range c.Server.ManagementAuth.Tokens {
+		token.Token = RedactedValue
+		auth.Tokens[i] = token
+	}
+	masked.Server.ManagementAuth = &auth
+	return &masked
+}
+
+// RestoreSecrets replaces masked auth token secrets with the secret of the
+// same-named token in current, so a configuration read from the API can be
+// sent back unchanged
+func (c *Config) RestoreSecrets(current *Config) {
+	if c.Server.ManagementAuth == nil || current == nil || current.Server.ManagementAuth == nil {
+		return
+	}
+
+	for i := range c.Server.ManagementAuth.Tokens {
+		token := &c.Server.ManagementAuth.Tokens[i]
+		if token.Token != RedactedValue {
+			continue
+		}
+		for _, existing := range current.Server.ManagementAuth.Tokens {
+			if existing.Name == token.Name {
+				token.Token = existing.Token
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// NonReplayableHeaders are dropped when a captured request is re-issued
+var NonReplayableHeaders = []string{
+	"Connection", "Content-Length", "Host", "Keep-Alive", "Proxy-Connection",
+	"Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// CredentialHeaders are dropped when a captured request is replayed against
+// a server other than this one, so stored credentials never leave it
+var CredentialHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization"}
+
+// CurlCommand renders the entry as a curl command line. The request is sent
+// to baseURL, or to the host it was originally made against when empty.
+func (e *RequestLogEntry) CurlCommand(baseURL string) string {
+	if baseURL == "" {
+		baseURL = "http://" + e.Host
+	}
+
+	parts := []string{"curl"}
+	if e.Method != "" && e.Method != http.MethodGet {
+		parts = append(parts, "-X "+e.Method)
+	}
+
+	names := make([]string, 0, len(e.Headers))
+	for name := range e.Headers {
+		if !isNonReplayableHeader(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range e.Headers[name] {
+			parts = append(parts, "-H "+shellQuote(name+": "+value))
+		}
+	}
+
+	if e.Body != "" {
+		parts = append(parts, "--data-raw "+shellQuote(e.Body))
+	}
+	parts = append(parts, shellQuote(strings.TrimSuffix(baseURL, "/")+e.Path))
+
+	return strings.Join(parts, " \\\n  ")
+}
+
+// isNonReplayableHeader reports whether the header is dropped on replay
+func isNonReplayableHeader(name string) bool {
+	for _, header := range NonReplayableHeaders {
+		if http.CanonicalHeaderKey(name) == header {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote wraps s in single quotes for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,18 @@
+package types
+
+import "time"
+
+// EndpointOverride changes how matching requests are answered for a number
+// of requests or a while, then expires; set at runtime to inject one-off
+// failures without touching the configuration
+type EndpointOverride struct {
+	ID        string         `json:"id"`
+	Path      string         `json:"path"`             // Request path or path pattern, e.g. /api/orders or /api/*
+	Method    string         `json:"method,omitempty"` // Only requests with this method; any when empty
+	Endpoint  EndpointConfig `json:"endpoint"`         // How matching requests are answered
+	Count     int            `json:"count,omitempty"`  // Requests it applies to; unlimited until the TTL when 0
+	TTL       string         `json:"ttl,omitempty"`    // How long it applies, e.g. 30s; until used up when empty
+	Remaining int            `json:"remaining,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	ExpiresAt *time.Time     `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,53 @@
+package types
+
+import (
+	"os"
+	"runtime"
+	"time"
+)
+
+// ProcessStats represents resource usage of the server process itself
+type ProcessStats struct {
+	Goroutines     int       `json:"goroutines"`
+	HeapAllocBytes uint64    `json:"heap_alloc_bytes"`
+	HeapSysBytes   uint64    `json:"heap_sys_bytes"`
+	HeapObjects    uint64    `json:"heap_objects"`
+	NumGC          uint32    `json:"num_gc"`
+	LastGCPauseMs  float64   `json:"last_gc_pause_ms"`
+	TotalGCPauseMs float64   `json:"total_gc_pause_ms"`
+	LastGC         time.Time `json:"last_gc"`
+	OpenFDs        int       `json:"open_fds"` // -1 when not available on this platform
+}
+
+// CollectProcessStats gathers runtime and OS metrics for the current process
+func CollectProcessStats() *ProcessStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := &ProcessStats{
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapSysBytes:   mem.HeapSys,
+		HeapObjects:    mem.HeapObjects,
+		NumGC:          mem.NumGC,
+		TotalGCPauseMs: float64(mem.PauseTotalNs) / float64(time.Millisecond),
+		OpenFDs:        countOpenFDs(),
+	}
+
+	if mem.NumGC > 0 {
+		// PauseNs is a circular buffer; the most recent pause is at (NumGC+255)%256
+		stats.LastGCPauseMs = float64(mem.PauseNs[(mem.NumGC+255)%256]) / float64(time.Millisecond)
+		stats.LastGC = time.Unix(0, int64(mem.LastGC))
+	}
+
+	return stats
+}
+
+// countOpenFDs counts open file descriptors via /proc, returning -1 if unavailable
+func countOpenFDs() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
@@ -0,0 +1,250 @@
+package types
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// rateWindowSeconds is the longest sliding window tracked (15 minutes)
+	rateWindowSeconds = 15 * 60
+
+	// maxSecondSeriesSeconds is the longest time series window served with
+	// steps of single seconds; longer ones come in whole minutes
+	maxSecondSeriesSeconds = 120
+
+	// rateSecondBuckets is how many seconds are kept one by one: the current
+	// one and enough for the 1 minute rate and per-second time series
+	rateSecondBuckets = maxSecondSeriesSeconds + 1
+
+	// rateMinuteBuckets is how many minutes are kept for the 5 and 15 minute
+	// rates and longer time series: the current one and 15 complete ones
+	rateMinuteBuckets = rateWindowSeconds/60 + 1
+)
+
+// RateWindow represents request and error rates over a single time window
+type RateWindow struct {
+	RequestsPerSec float64 `json:"requests_per_sec"`
+	ErrorsPerSec   float64 `json:"errors_per_sec"`
+	Requests       int64   `json:"requests"`
+	Errors         int64   `json:"errors"`
+}
+
+// RequestRates represents rolling rates over the 1, 5 and 15 minute windows
+type RequestRates struct {
+	OneMinute     RateWindow `json:"1m"`
+	FiveMinute    RateWindow `json:"5m"`
+	FifteenMinute RateWindow `json:"15m"`
+}
+
+// latencyBucketBoundsMs are the upper bounds of the latency histogram kept
+// per second; slower requests go in one more bucket without a bound
+var latencyBucketBoundsMs = [...]int64{10, 25, 50, 100, 250, 500, 1000, 2500}
+
+// latencyBucketCount is the number of latency histogram buckets
+const latencyBucketCount = len(latencyBucketBoundsMs) + 1
+
+// LatencyBucketBoundsMs returns the upper bounds of the latency histogram
+// buckets in milliseconds; the last bucket, one more than the bounds, has none
+func LatencyBucketBoundsMs() []int64 {
+	return append([]int64(nil), latencyBucketBoundsMs[:]...)
+}
+
+// latencyBucket returns the histogram bucket of a latency
+func latencyBucket(durationMs int64) int {
+	for i, bound := range latencyBucketBoundsMs {
+		if durationMs <= bound {
+			return i
+		}
+	}
+	return len(latencyBucketBoundsMs)
+}
+
+// rateBucket holds the counts recorded during a single period (second or minute)
+type rateBucket struct {
+	period    int64
+	requests  int64
+	errors    int64
+	latencyMs int64 // Sum of the sampled latencies
+	samples   int64 // Requests whose latency was sampled
+	maxTimeMs int64
+	latencies [latencyBucketCount]int64 // Sampled requests per latency histogram bucket
+}
+
+// claim resets a bucket left over from a previous pass through its ring
+func (b *rateBucket) claim(period int64) {
+	if current := atomic.LoadInt64(&b.period); current != period {
+		if atomic.CompareAndSwapInt64(&b.period, current, period) {
+			atomic.StoreInt64(&b.requests, 0)
+			atomic.StoreInt64(&b.errors, 0)
+			atomic.StoreInt64(&b.latencyMs, 0)
+			atomic.StoreInt64(&b.samples, 0)
+			atomic.StoreInt64(&b.maxTimeMs, 0)
+			for i := range b.latencies {
+				atomic.StoreInt64(&b.latencies[i], 0)
+			}
+		}
+	}
+}
+
+// add counts a request
+func (b *rateBucket) add(isError bool) {
+	atomic.AddInt64(&b.requests, 1)
+	if isError {
+		atomic.AddInt64(&b.errors, 1)
+	}
+}
+
+// addLatency counts a sampled latency
+func (b *rateBucket) addLatency(durationMs int64) {
+	atomic.AddInt64(&b.latencyMs, durationMs)
+	atomic.AddInt64(&b.samples, 1)
+	atomic.AddInt64(&b.latencies[latencyBucket(durationMs)], 1)
+	for {
+		current := atomic.LoadInt64(&b.maxTimeMs)
+		if durationMs <= current || atomic.CompareAndSwapInt64(&b.maxTimeMs, current, durationMs) {
+			break
+		}
+	}
+}
+
+// rateCounter tracks request counts in two fixed-size rings: per second for
+// the last two minutes and per minute for the last 15 minutes, so that an endpoint
+// costs a few kilobytes however long the windows are. Buckets are updated
+// with atomics; a bucket being recycled for a new period may lose a handful
+// of concurrent increments, which is acceptable for rates.
+type rateCounter struct {
+	seconds [rateSecondBuckets]rateBucket
+	minutes [rateMinuteBuckets]rateBucket
+}
+
+// record adds a request to the buckets for the given time
+func (rc *rateCounter) record(now time.Time, isError bool) {
+	second := now.Unix()
+	minute := second / 60
+
+	bucket := &rc.seconds[second%rateSecondBuckets]
+	bucket.claim(second)
+	bucket.add(isError)
+
+	bucket = &rc.minutes[minute%rateMinuteBuckets]
+	bucket.claim(minute)
+	bucket.add(isError)
+}
+
+// recordLatency adds a sampled latency to the buckets for the given time. It
+// must follow record for the same request, which claims the buckets.
+func (rc *rateCounter) recordLatency(now time.Time, durationMs int64) {
+	second := now.Unix()
+	rc.seconds[second%rateSecondBuckets].addLatency(durationMs)
+	rc.minutes[(second/60)%rateMinuteBuckets].addLatency(durationMs)
+}
+
+// rates computes the rolling rates ending at the given time
+func (rc *rateCounter) rates(now time.Time) RequestRates {
+	return RequestRates{
+		OneMinute:     rc.secondWindow(now, 60),
+		FiveMinute:    rc.minuteWindow(now, 5),
+		FifteenMinute: rc.minuteWindow(now, rateWindowSeconds/60),
+	}
+}
+
+// secondWindow sums the second buckets within the last n seconds
+func (rc *rateCounter) secondWindow(now time.Time, seconds int64) RateWindow {
+	current := now.Unix()
+	var window RateWindow
+
+	for i := range rc.seconds {
+		bucket := &rc.seconds[i]
+		requests := atomic.LoadInt64(&bucket.requests)
+		if requests == 0 {
+			continue
+		}
+		if age := current - atomic.LoadInt64(&bucket.period); age >= 0 && age < seconds {
+			window.Requests += requests
+			window.Errors += atomic.LoadInt64(&bucket.errors)
+		}
+	}
+
+	window.finish(seconds)
+	return window
+}
+
+// minuteWindow sums the minute buckets covering the last n minutes: the
+// current minute so far, the complete minutes before it, and of the oldest
+// minute the share still inside the window
+func (rc *rateCounter) minuteWindow(now time.Time, minutes int64) RateWindow {
+	current := now.Unix() / 60
+	oldestShare := 1 - float64(now.Unix()%60)/60
+	var requests, errors float64
+
+	for i := range rc.minutes {
+		bucket := &rc.minutes[i]
+		bucketRequests := atomic.LoadInt64(&bucket.requests)
+		if bucketRequests == 0 {
+			continue
+		}
+		share := 1.0
+		switch age := current - atomic.LoadInt64(&bucket.period); {
+		case age < 0 || age > minutes:
+			continue
+		case age == minutes:
+			share = oldestShare
+		}
+		requests += share * float64(bucketRequests)
+		errors += share * float64(atomic.LoadInt64(&bucket.errors))
+	}
+
+	window := RateWindow{Requests: int64(math.Round(requests)), Errors: int64(math.Round(errors))}
+	window.finish(minutes * 60)
+	return window
+}
+
+// finish computes the per-second rates of a window lasting seconds
+func (w *RateWindow) finish(seconds int64) {
+	w.RequestsPerSec = float64(w.Requests) / float64(seconds)
+	w.ErrorsPerSec = float64(w.Errors) / float64(seconds)
+}
+
+// series returns one point per step for the points steps ending with the
+// period before now, oldest first: seconds for windows of up to
+// maxSecondSeriesSeconds and whole minutes beyond that. The current period is
+// left out because it is still being recorded.
+func (rc *rateCounter) series(now time.Time, points, step int) []TimeSeriesPoint {
+	buckets, period := rc.seconds[:], int64(1)
+	if points*step > maxSecondSeriesSeconds {
+		buckets, period = rc.minutes[:], 60
+	}
+
+	series := make([]TimeSeriesPoint, points)
+	first := seriesStart(now, points*step)
+	for i := range series {
+		series[i].Time = time.Unix(first+int64(i*step), 0)
+		series[i].LatencyBuckets = make([]int64, latencyBucketCount)
+	}
+
+	for i := range buckets {
+		bucket := &buckets[i]
+		requests := atomic.LoadInt64(&bucket.requests)
+		if requests == 0 {
+			continue
+		}
+		offset := atomic.LoadInt64(&bucket.period)*period - first
+		if offset < 0 || offset >= int64(points*step) {
+			continue
+		}
+		point := &series[offset/int64(step)]
+		point.Requests += requests
+		point.Errors += atomic.LoadInt64(&bucket.errors)
+		point.latencyMs += atomic.LoadInt64(&bucket.latencyMs)
+		point.samples += atomic.LoadInt64(&bucket.samples)
+		if maxTimeMs := atomic.LoadInt64(&bucket.maxTimeMs); maxTimeMs > point.MaxTimeMs {
+			point.MaxTimeMs = maxTimeMs
+		}
+		for j := range bucket.latencies {
+			point.LatencyBuckets[j] += atomic.LoadInt64(&bucket.latencies[j])
+		}
+	}
+	return series
+}
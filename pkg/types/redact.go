@@ -0,0 +1,200 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces sensitive data in request log entries
+const RedactedValue = "[REDACTED]"
+
+// MaskedHeaders always have their values masked in request log entries, as
+// they carry management tokens and other credentials
+var MaskedHeaders = []string{"Authorization", "Proxy-Authorization"}
+
+// MaskedQueryParams always have their values masked in logged paths
+var MaskedQueryParams = []string{"token"}
+
+// RedactionConfig represents the rules applied to request log entries before
+// they are stored or broadcast
+type RedactionConfig struct {
+	Headers    []string `json:"headers,omitempty"`     // Header names whose values are masked, e.g. "Authorization"
+	JSONFields []string `json:"json_fields,omitempty"` // Dot-separated paths into JSON bodies, e.g. "user.password"
+	Patterns   []string `json:"patterns,omitempty"`    // Regular expressions masked in paths, header values and bodies
+}
+
+// Redactor applies compiled redaction rules to request log entries
+type Redactor struct {
+	headers    map[string]bool
+	jsonFields [][]string
+	patterns   []*regexp.Regexp
+}
+
+// NewRedactor compiles the redaction rules; a nil config yields nil, which
+// redacts nothing
+func NewRedactor(config *RedactionConfig) (*Redactor, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	redactor := &Redactor{headers: make(map[string]bool)}
+
+	for _, header := range config.Headers {
+		if strings.TrimSpace(header) == "" {
+			return nil, fmt.Errorf("header name cannot be empty")
+		}
+		redactor.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	for _, field := range config.JSONFields {
+		segments := strings.Split(field, ".")
+		for _, segment := range segments {
+			if segment == "" {
+				return nil, fmt.Errorf("invalid JSON field path %q", field)
+			}
+		}
+		redactor.jsonFields = append(redactor.jsonFields, segments)
+	}
+
+	for _, pattern := range config.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		redactor.patterns = append(redactor.patterns, re)
+	}
+
+	return redactor, nil
+}
+
+// Redact masks sensitive data in the entry in place
+func (r *Redactor) Redact(entry *RequestLogEntry) {
+	if r == nil {
+		return
+	}
+
+	entry.Path = r.redactString(entry.Path)
+
+	if len(entry.Headers) > 0 {
+		headers := make(http.Header, len(entry.Headers))
+		for name, values := range entry.Headers {
+			redacted := make([]string, len(values))
+			for i, value := range values {
+				if r.headers[http.CanonicalHeaderKey(name)] {
+					redacted[i] = RedactedValue
+				} else {
+					redacted[i] = r.redactString(value)
+				}
+			}
+			headers[name] = redacted
+		}
+		entry.Headers = headers
+	}
+
+	if entry.Body != "" {
+		entry.Body = r.redactString(r.redactJSON(entry.Body))
+	}
+}
+
+// MaskCredentials masks the credential headers and query parameters of the
+// entry in place, whatever redaction rules are configured
+func MaskCredentials(entry *RequestLogEntry) {
+	entry.Path = maskQueryParams(entry.Path)
+
+	for _, header := range MaskedHeaders {
+		values := entry.Headers.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+		masked := make([]string, len(values))
+		for i := range masked {
+			masked[i] = RedactedValue
+		}
+		// Replace rather than modify the slice, which may be shared with
+		// the live request
+		entry.Headers[http.CanonicalHeaderKey(header)] = masked
+	}
+}
+
+// maskQueryParams masks the values of MaskedQueryParams in a request URI,
+// leaving the rest of the query as it was sent
+func maskQueryParams(requestURI string) string {
+	path, query, found := strings.Cut(requestURI, "?")
+	if !found {
+		return requestURI
+	}
+
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		for _, param := range MaskedQueryParams {
+			if key == param {
+				pairs[i] = param + "=" + RedactedValue
+			}
+		}
+	}
+	return path + "?" + strings.Join(pairs, "&")
+}
+
+// redactString masks every match of the configured patterns
+func (r *Redactor) redactString(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, RedactedValue)
+	}
+	return s
+}
+
+// redactJSON masks the configured fields when the body is valid JSON
+func (r *Redactor) redactJSON(body string) string {
+	if len(r.jsonFields) == 0 {
+		return body
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return body
+	}
+
+	for _, field := range r.jsonFields {
+		redactJSONField(document, field)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(document); err != nil {
+		return body
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// redactJSONField masks the value at path, descending into every element of
+// arrays along the way
+func redactJSONField(value interface{}, path []string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, exists := v[path[0]]
+		if !exists {
+			return
+		}
+		if len(path) == 1 {
+			v[path[0]] = RedactedValue
+			return
+		}
+		redactJSONField(child, path[1:])
+	case []interface{}:
+		for _, element := range v {
+			redactJSONField(element, path)
+		}
+	}
+}
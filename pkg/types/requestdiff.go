@@ -0,0 +1,183 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kinds of change reported in a request diff
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// ValueChange represents a single difference between two captured requests
+type ValueChange struct {
+	Field  string      `json:"field"`
+	Change string      `json:"change"`
+	From   interface{} `json:"from,omitempty"`
+	To     interface{} `json:"to,omitempty"`
+}
+
+// RequestLogDiff represents the differences between two request log entries.
+// Timestamps and durations are not compared.
+type RequestLogDiff struct {
+	From      uint64        `json:"from"`
+	To        uint64        `json:"to"`
+	Identical bool          `json:"identical"`
+	Request   []ValueChange `json:"request"` // Method, path, status code, tag and host
+	Headers   []ValueChange `json:"headers"`
+	Body      []ValueChange `json:"body"` // Per-field for JSON bodies, otherwise a single "body" change
+}
+
+// DiffRequestLogEntries computes a structured diff of two captured requests
+func DiffRequestLogEntries(from, to RequestLogEntry) *RequestLogDiff {
+	diff := &RequestLogDiff{
+		From:    from.ID,
+		To:      to.ID,
+		Request: make([]ValueChange, 0),
+		Headers: make([]ValueChange, 0),
+		Body:    make([]ValueChange, 0),
+	}
+
+	diffValue(&diff.Request, "method", from.Method, to.Method)
+	diffValue(&diff.Request, "path", from.Path, to.Path)
+	diffValue(&diff.Request, "status_code", from.StatusCode, to.StatusCode)
+	diffValue(&diff.Request, "tag", from.Tag, to.Tag)
+	diffValue(&diff.Request, "host", from.Host, to.Host)
+
+	names := make(map[string]bool)
+	for name := range from.Headers {
+		names[name] = true
+	}
+	for name := range to.Headers {
+		names[name] = true
+	}
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+	for _, name := range sortedNames {
+		diffValue(&diff.Headers, name, headerValue(from.Headers[name]), headerValue(to.Headers[name]))
+	}
+
+	fromJSON, fromOK := parseJSONBody(from.Body)
+	toJSON, toOK := parseJSONBody(to.Body)
+	if fromOK && toOK {
+		diffJSON(&diff.Body, "", fromJSON, toJSON)
+	} else {
+		diffValue(&diff.Body, "body", nilIfEmpty(from.Body), nilIfEmpty(to.Body))
+	}
+
+	diff.Identical = len(diff.Request) == 0 && len(diff.Headers) == 0 && len(diff.Body) == 0
+	return diff
+}
+
+// diffValue appends a change when the values differ; nil or empty strings
+// count as absent
+func diffValue(changes *[]ValueChange, field string, from, to interface{}) {
+	appendChange(changes, field, nilIfEmpty(from), nilIfEmpty(to))
+}
+
+// appendChange appends a change when the values differ, treating nil as absent
+func appendChange(changes *[]ValueChange, field string, from, to interface{}) {
+	switch {
+	case reflect.DeepEqual(from, to):
+	case from == nil:
+		*changes = append(*changes, ValueChange{Field: field, Change: ChangeAdded, To: to})
+	case to == nil:
+		*changes = append(*changes, ValueChange{Field: field, Change: ChangeRemoved, From: from})
+	default:
+		*changes = append(*changes, ValueChange{Field: field, Change: ChangeChanged, From: from, To: to})
+	}
+}
+
+// diffJSON recursively compares decoded JSON values, reporting changes by
+// dot-separated field path
+func diffJSON(changes *[]ValueChange, path string, from, to interface{}) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		keys := make(map[string]bool)
+		for key := range fromMap {
+			keys[key] = true
+		}
+		for key := range toMap {
+			keys[key] = true
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for key := range keys {
+			sortedKeys = append(sortedKeys, key)
+		}
+		sort.Strings(sortedKeys)
+		for _, key := range sortedKeys {
+			diffJSON(changes, joinFieldPath(path, key), fromMap[key], toMap[key])
+		}
+		return
+	}
+
+	fromSlice, fromIsSlice := from.([]interface{})
+	toSlice, toIsSlice := to.([]interface{})
+	if fromIsSlice && toIsSlice {
+		for i := 0; i < len(fromSlice) || i < len(toSlice); i++ {
+			var fromElement, toElement interface{}
+			if i < len(fromSlice) {
+				fromElement = fromSlice[i]
+			}
+			if i < len(toSlice) {
+				toElement = toSlice[i]
+			}
+			diffJSON(changes, joinFieldPath(path, fmt.Sprint(i)), fromElement, toElement)
+		}
+		return
+	}
+
+	if path == "" {
+		path = "body"
+	}
+	appendChange(changes, path, from, to)
+}
+
+// parseJSONBody decodes a JSON body, reporting false for empty or non-JSON bodies
+func parseJSONBody(body string) (interface{}, bool) {
+	if strings.TrimSpace(body) == "" {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// headerValue joins repeated header values for comparison
+func headerValue(values []string) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return strings.Join(values, ", ")
+}
+
+// nilIfEmpty treats empty strings as absent values
+func nilIfEmpty(value interface{}) interface{} {
+	if s, ok := value.(string); ok && s == "" {
+		return nil
+	}
+	return value
+}
+
+// joinFieldPath appends a key to a dot-separated field path
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,178 @@
+package types
+
+import "sync"
+
+// RequestLogBuffer stores the most recent request log entries in a fixed-size
+// circular buffer. Appends are O(1) and never allocate; when the buffer is
+// full, or the optional memory budget is exceeded, the oldest entries are
+// overwritten.
+type RequestLogBuffer struct {
+	mutex    sync.RWMutex
+	entries  []RequestLogEntry
+	sizes    []int // Estimated size of each stored entry
+	head     int   // Index the next entry is written to
+	count    int
+	added    uint64 // Entries added since the buffer was created
+	bytes    int    // Estimated memory used by stored entries
+	maxBytes int    // Memory budget; 0 disables
+}
+
+// NewRequestLogBuffer creates a buffer holding up to capacity entries
+func NewRequestLogBuffer(capacity, maxBytes int) *RequestLogBuffer {
+	if capacity <= 0 {
+		capacity = DefaultRequestLogSize
+	}
+	return &RequestLogBuffer{
+		entries:  make([]RequestLogEntry, capacity),
+		sizes:    make([]int, capacity),
+		maxBytes: maxBytes,
+	}
+}
+
+// Add stores an entry, evicting the oldest entries as needed
+func (b *RequestLogBuffer) Add(entry RequestLogEntry) {
+	size := entry.EstimatedSize()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.count == len(b.entries) {
+		b.evictOldest()
+	}
+
+	b.entries[b.head] = entry
+	b.sizes[b.head] = size
+	b.head = (b.head + 1) % len(b.entries)
+	b.count++
+	b.added++
+	b.bytes += size
+
+	b.trim()
+}
+
+// Clear removes all stored entries
+func (b *RequestLogBuffer) Clear() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for i := range b.entries {
+		b.entries[i] = RequestLogEntry{}
+		b.sizes[i] = 0
+	}
+	b.head = 0
+	b.count = 0
+	b.bytes = 0
+}
+
+// Resize changes the capacity and memory budget, keeping the newest entries
+func (b *RequestLogBuffer) Resize(capacity, maxBytes int) {
+	if capacity <= 0 {
+		capacity = DefaultRequestLogSize
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.maxBytes = maxBytes
+	if capacity != len(b.entries) {
+		keep := b.count
+		if keep > capacity {
+			keep = capacity
+		}
+
+		entries := make([]RequestLogEntry, capacity)
+		sizes := make([]int, capacity)
+		// Copy oldest-to-newest of the entries being kept
+		for i := 0; i < keep; i++ {
+			index := b.index(b.count - keep + i)
+			entries[i] = b.entries[index]
+			sizes[i] = b.sizes[index]
+		}
+
+		b.bytes = 0
+		for _, size := range sizes[:keep] {
+			b.bytes += size
+		}
+		b.entries = entries
+		b.sizes = sizes
+		b.count = keep
+		b.head = keep % capacity
+	}
+
+	b.trim()
+}
+
+// Entries returns a copy of the stored entries, newest first
+func (b *RequestLogBuffer) Entries() []RequestLogEntry {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	entries := make([]RequestLogEntry, b.count)
+	for i := range entries {
+		entries[i] = b.entries[b.index(b.count-1-i)]
+	}
+	return entries
+}
+
+// EntriesSince returns a copy of the stored entries added after position,
+// newest first, and the position to pass on the next call. Positions count
+// every entry ever added, so they keep advancing across Clear and eviction.
+func (b *RequestLogBuffer) EntriesSince(position uint64) ([]RequestLogEntry, uint64) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	// A position past the end comes from before a server restart, and gets
+	// everything stored
+	count := b.count
+	if position <= b.added && b.added-position < uint64(count) {
+		count = int(b.added - position)
+	}
+
+	entries := make([]RequestLogEntry, count)
+	for i := range entries {
+		entries[i] = b.entries[b.index(b.count-1-i)]
+	}
+	return entries, b.added
+}
+
+// Find returns the stored entry with the given ID
+func (b *RequestLogBuffer) Find(id uint64) (RequestLogEntry, bool) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for i := 0; i < b.count; i++ {
+		if entry := b.entries[b.index(i)]; entry.ID == id {
+			return entry, true
+		}
+	}
+	return RequestLogEntry{}, false
+}
+
+// Len returns the number of stored entries
+func (b *RequestLogBuffer) Len() int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.count
+}
+
+// index maps the i-th oldest stored entry to its position in the ring
+func (b *RequestLogBuffer) index(i int) int {
+	return (b.head - b.count + i + 2*len(b.entries)) % len(b.entries)
+}
+
+// evictOldest drops the oldest entry. Callers must hold the mutex.
+func (b *RequestLogBuffer) evictOldest() {
+	oldest := b.index(0)
+	b.bytes -= b.sizes[oldest]
+	b.entries[oldest] = RequestLogEntry{} // Release headers and body for GC
+	b.sizes[oldest] = 0
+	b.count--
+}
+
+// trim evicts the oldest entries until the memory budget is met, always
+// keeping the newest entry. Callers must hold the mutex.
+func (b *RequestLogBuffer) trim() {
+	for b.maxBytes > 0 && b.bytes > b.maxBytes && b.count > 1 {
+		b.evictOldest()
+	}
+}
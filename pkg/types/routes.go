@@ -0,0 +1,115 @@
+package types
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Placeholders substituted for high-cardinality path segments
+const (
+	RouteParamID   = "{id}"
+	RouteParamUUID = "{uuid}"
+	RouteParamHash = "{hash}"
+)
+
+var (
+	numericSegment = regexp.MustCompile(`^\d+$`)
+	uuidSegment    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hashSegment    = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+)
+
+// RequestLogGroup summarizes the request log entries sharing a normalized route
+type RequestLogGroup struct {
+	Route         string         `json:"route"`
+	Count         int            `json:"count"`
+	ErrorCount    int            `json:"error_count"`
+	AvgDurationMs float64        `json:"avg_duration_ms"`
+	MinDurationMs int64          `json:"min_duration_ms"`
+	MaxDurationMs int64          `json:"max_duration_ms"`
+	P95DurationMs int64          `json:"p95_duration_ms"`
+	Methods       map[string]int `json:"methods"`
+	LastSeen      time.Time      `json:"last_seen"`
+}
+
+// NormalizeRoute strips the query string from a request path and collapses
+// numeric IDs, UUIDs and long hex hashes in its segments, so /users/42?x=1
+// and /users/43 both become /users/{id}
+func NormalizeRoute(requestPath string) string {
+	if i := strings.IndexAny(requestPath, "?#"); i >= 0 {
+		requestPath = requestPath[:i]
+	}
+
+	segments := strings.Split(requestPath, "/")
+	for i, segment := range segments {
+		switch {
+		case segment == "":
+		case numericSegment.MatchString(segment):
+			segments[i] = RouteParamID
+		case uuidSegment.MatchString(segment):
+			segments[i] = RouteParamUUID
+		case hashSegment.MatchString(segment):
+			segments[i] = RouteParamHash
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// GroupRequestLog groups entries by normalized route, busiest route first
+func GroupRequestLog(entries []RequestLogEntry) []RequestLogGroup {
+	groups := make(map[string]*RequestLogGroup)
+	durations := make(map[string][]int64)
+
+	for _, entry := range entries {
+		route := NormalizeRoute(entry.Path)
+		group, exists := groups[route]
+		if !exists {
+			group = &RequestLogGroup{
+				Route:         route,
+				MinDurationMs: entry.Duration,
+				Methods:       make(map[string]int),
+			}
+			groups[route] = group
+		}
+
+		group.Count++
+		if entry.StatusCode >= 400 {
+			group.ErrorCount++
+		}
+		if entry.Duration < group.MinDurationMs {
+			group.MinDurationMs = entry.Duration
+		}
+		if entry.Duration > group.MaxDurationMs {
+			group.MaxDurationMs = entry.Duration
+		}
+		group.Methods[entry.Method]++
+		if entry.Timestamp.After(group.LastSeen) {
+			group.LastSeen = entry.Timestamp
+		}
+		durations[route] = append(durations[route], entry.Duration)
+	}
+
+	result := make([]RequestLogGroup, 0, len(groups))
+	for route, group := range groups {
+		routeDurations := durations[route]
+		sort.Slice(routeDurations, func(i, j int) bool { return routeDurations[i] < routeDurations[j] })
+
+		var total int64
+		for _, duration := range routeDurations {
+			total += duration
+		}
+		group.AvgDurationMs = float64(total) / float64(len(routeDurations))
+		group.P95DurationMs = routeDurations[(len(routeDurations)*95+99)/100-1]
+
+		result = append(result, *group)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Route < result[j].Route
+	})
+	return result
+}
@@ -0,0 +1,44 @@
+package types
+
+import "time"
+
+// Scenario is a script of timed endpoint changes, e.g. "after 2 minutes,
+// /api/orders starts returning 500s", loaded from a YAML file listed in the
+// configuration's scenarios. Its changes are kept in memory and never saved
+// to the configuration file.
+type Scenario struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Loop        bool           `json:"loop,omitempty"` // Start over from the configuration after the last step until stopped
+	Steps       []ScenarioStep `json:"steps"`
+}
+
+// ScenarioStep changes endpoints once its delay has passed
+type ScenarioStep struct {
+	Name    string                    `json:"name,omitempty"`
+	After   string                    `json:"after"`             // Delay after the previous step, or the start, e.g. "2m"
+	Set     map[string]EndpointConfig `json:"set,omitempty"`     // Endpoints added or replaced
+	Remove  []string                  `json:"remove,omitempty"`  // Endpoints served as if not configured
+	Restore []string                  `json:"restore,omitempty"` // Endpoints back to their configuration; "*" restores all
+}
+
+// ScenarioStatus describes a loaded scenario, as served by GET /scenarios
+type ScenarioStatus struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	File        string     `json:"file"`
+	Steps       int        `json:"steps"`
+	Loop        bool       `json:"loop,omitempty"`
+	Running     bool       `json:"running"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	Step        int        `json:"step,omitempty"`         // Steps applied in the current pass
+	NextStep    string     `json:"next_step,omitempty"`    // Name, or number, of the step applied next
+	NextStepAt  *time.Time `json:"next_step_at,omitempty"` // When the next step is applied
+}
+
+// ScenarioList is served by GET /scenarios: the loaded scenarios and the
+// endpoints scenarios currently change
+type ScenarioList struct {
+	Scenarios []ScenarioStatus `json:"scenarios"`
+	Changed   []string         `json:"changed,omitempty"`
+}
@@ -0,0 +1,110 @@
+package types
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// statsShardCount is the number of shards used for endpoint and User-Agent maps
+	statsShardCount = 32
+
+	// maxStatusCode bounds the status codes counted without locking
+	maxStatusCode = 600
+
+	// maxTrackedPaths bounds the distinct paths other than configured
+	// endpoints, such as static files and 404s, with statistics of their own
+	maxTrackedPaths = 500
+
+	// EndpointOther collects requests to further paths once maxTrackedPaths
+	// are tracked
+	EndpointOther = "other"
+)
+
+// statsShard holds a slice of the endpoint and User-Agent maps. Lookups of
+// existing keys only take the read lock, so concurrent requests to different
+// or already-known paths do not contend.
+type statsShard struct {
+	mutex      sync.RWMutex
+	endpoints  map[string]*EndpointStats
+	userAgents map[string]*int64
+}
+
+// shardFor returns the shard responsible for a key
+func (ss *ServerStats) shardFor(key string) *statsShard {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return &ss.shards[hash.Sum32()%statsShardCount]
+}
+
+// endpoint returns the stats for a path, creating them on first use
+func (sh *statsShard) endpoint(path string) *EndpointStats {
+	endpointStats, _ := sh.endpointLimited(path, nil)
+	return endpointStats
+}
+
+// endpointLimited returns the stats for a path. New paths are only created
+// while keyCount is below maxTrackedPaths; it returns false when the path
+// could not be tracked. A nil keyCount always allows creation.
+func (sh *statsShard) endpointLimited(path string, keyCount *int64) (*EndpointStats, bool) {
+	sh.mutex.RLock()
+	endpointStats, exists := sh.endpoints[path]
+	sh.mutex.RUnlock()
+	if exists {
+		return endpointStats, true
+	}
+
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	if endpointStats, exists := sh.endpoints[path]; exists {
+		return endpointStats, true
+	}
+	if keyCount != nil {
+		if atomic.AddInt64(keyCount, 1) > maxTrackedPaths {
+			atomic.AddInt64(keyCount, -1)
+			return nil, false
+		}
+	}
+	if sh.endpoints == nil {
+		sh.endpoints = make(map[string]*EndpointStats)
+	}
+	endpointStats = &EndpointStats{Path: path}
+	sh.endpoints[path] = endpointStats
+	return endpointStats, true
+}
+
+// addUserAgent adds weight to a User-Agent counter. New keys are only created
+// while keyCount is below maxTrackedUserAgents; it returns false when the key
+// could not be tracked. A nil keyCount always allows creation.
+func (sh *statsShard) addUserAgent(key string, weight int64, keyCount *int64) bool {
+	sh.mutex.RLock()
+	counter, exists := sh.userAgents[key]
+	sh.mutex.RUnlock()
+	if exists {
+		atomic.AddInt64(counter, weight)
+		return true
+	}
+
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	if counter, exists := sh.userAgents[key]; exists {
+		atomic.AddInt64(counter, weight)
+		return true
+	}
+	if keyCount != nil {
+		if atomic.AddInt64(keyCount, 1) > maxTrackedUserAgents {
+			atomic.AddInt64(keyCount, -1)
+			return false
+		}
+	}
+	if sh.userAgents == nil {
+		sh.userAgents = make(map[string]*int64)
+	}
+	counter = new(int64)
+	*counter = weight
+	sh.userAgents[key] = counter
+	return true
+}
@@ -0,0 +1,165 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// sloWindowMinutes is the longest burn-rate window that can be tracked (24 hours)
+	sloWindowMinutes = 24 * 60
+
+	// MaxSLOWindow is the longest burn-rate window accepted in configuration
+	MaxSLOWindow = sloWindowMinutes * time.Minute
+)
+
+// DefaultSLOWindows are the burn-rate windows used when none are configured
+var DefaultSLOWindows = []string{"5m", "1h"}
+
+// SLOConfig represents an availability objective for an endpoint
+type SLOConfig struct {
+	Target  float64  `json:"target"`            // Availability percentage, e.g. 99.5
+	Windows []string `json:"windows,omitempty"` // Burn-rate windows, e.g. ["5m", "1h"]
+}
+
+// SLOWindowStats represents compliance and burn rate over a single window
+type SLOWindowStats struct {
+	Window     string  `json:"window"`
+	Requests   int64   `json:"requests"`
+	Errors     int64   `json:"errors"`
+	Compliance float64 `json:"compliance"` // Percentage of successful requests in the window
+	BurnRate   float64 `json:"burn_rate"`  // Error rate divided by the allowed error rate
+}
+
+// SLOStats represents SLO compliance and error-budget consumption for an endpoint
+type SLOStats struct {
+	Target               float64          `json:"target"`
+	Requests             int64            `json:"requests"`
+	Errors               int64            `json:"errors"`
+	Compliance           float64          `json:"compliance"`             // Percentage of successful requests since tracking began
+	ErrorBudgetRemaining float64          `json:"error_budget_remaining"` // Fraction of the error budget left, negative when exhausted
+	Windows              []SLOWindowStats `json:"windows"`
+
+	windows []time.Duration
+	minutes *[sloWindowMinutes]rateBucket
+}
+
+// ParseSLOWindows parses and validates burn-rate window durations
+func ParseSLOWindows(windows []string) ([]time.Duration, error) {
+	if len(windows) == 0 {
+		windows = DefaultSLOWindows
+	}
+
+	durations := make([]time.Duration, 0, len(windows))
+	for _, window := range windows {
+		duration, err := time.ParseDuration(window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SLO window %q: %w", window, err)
+		}
+		if duration < time.Minute || duration > MaxSLOWindow {
+			return nil, fmt.Errorf("SLO window %q must be between 1m and %s", window, MaxSLOWindow)
+		}
+		durations = append(durations, duration)
+	}
+	return durations, nil
+}
+
+// newSLOStats creates SLO tracking state for the given configuration
+func newSLOStats(config *SLOConfig) *SLOStats {
+	windows, err := ParseSLOWindows(config.Windows)
+	if err != nil {
+		// Configuration is validated on load; fall back to the defaults
+		windows, _ = ParseSLOWindows(nil)
+	}
+
+	return &SLOStats{
+		Target:  config.Target,
+		windows: windows,
+		minutes: &[sloWindowMinutes]rateBucket{},
+	}
+}
+
+// matches reports whether the tracking state was created for the given configuration
+func (s *SLOStats) matches(config *SLOConfig) bool {
+	windows, err := ParseSLOWindows(config.Windows)
+	if err != nil || s.Target != config.Target || len(windows) != len(s.windows) {
+		return false
+	}
+	for i := range windows {
+		if windows[i] != s.windows[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// record adds a request to the per-minute history
+func (s *SLOStats) record(now time.Time, isError bool) {
+	minute := now.Unix() / 60
+	bucket := &s.minutes[minute%sloWindowMinutes]
+
+	if bucket.period != minute {
+		bucket.period = minute
+		bucket.requests = 0
+		bucket.errors = 0
+	}
+
+	bucket.requests++
+	s.Requests++
+	if isError {
+		bucket.errors++
+		s.Errors++
+	}
+}
+
+// snapshot returns a copy with compliance and burn rates computed at the given time
+func (s *SLOStats) snapshot(now time.Time) *SLOStats {
+	allowedErrorRate := 1 - s.Target/100
+
+	result := &SLOStats{
+		Target:               s.Target,
+		Requests:             s.Requests,
+		Errors:               s.Errors,
+		Compliance:           compliance(s.Requests, s.Errors),
+		ErrorBudgetRemaining: 1,
+		Windows:              make([]SLOWindowStats, 0, len(s.windows)),
+	}
+
+	if s.Requests > 0 && allowedErrorRate > 0 {
+		consumed := (float64(s.Errors) / float64(s.Requests)) / allowedErrorRate
+		result.ErrorBudgetRemaining = 1 - consumed
+	}
+
+	current := now.Unix() / 60
+	for _, window := range s.windows {
+		minutes := int64(window / time.Minute)
+		windowStats := SLOWindowStats{Window: window.String()}
+
+		for i := range s.minutes {
+			bucket := s.minutes[i]
+			if bucket.requests == 0 {
+				continue
+			}
+			if age := current - bucket.period; age >= 0 && age < minutes {
+				windowStats.Requests += bucket.requests
+				windowStats.Errors += bucket.errors
+			}
+		}
+
+		windowStats.Compliance = compliance(windowStats.Requests, windowStats.Errors)
+		if windowStats.Requests > 0 && allowedErrorRate > 0 {
+			windowStats.BurnRate = (float64(windowStats.Errors) / float64(windowStats.Requests)) / allowedErrorRate
+		}
+		result.Windows = append(result.Windows, windowStats)
+	}
+
+	return result
+}
+
+// compliance returns the success percentage, or 100 when there are no requests
+func compliance(requests, errors int64) float64 {
+	if requests == 0 {
+		return 100
+	}
+	return float64(requests-errors) / float64(requests) * 100
+}
@@ -0,0 +1,87 @@
+package types
+
+import "time"
+
+// StatsSnapshot represents a named point-in-time copy of the server statistics
+type StatsSnapshot struct {
+	Name    string       `json:"name"`
+	TakenAt time.Time    `json:"taken_at"`
+	Stats   *ServerStats `json:"stats"`
+}
+
+// EndpointStatsDiff represents the change in a single endpoint's statistics
+type EndpointStatsDiff struct {
+	Path             string        `json:"path"`
+	RequestDelta     int64         `json:"request_delta"`
+	ErrorDelta       int64         `json:"error_delta"`
+	TotalTimeMsDelta int64         `json:"total_time_ms_delta"`
+	AvgTimeMs        float64       `json:"avg_time_ms"`      // Average latency of requests made between the snapshots
+	FromAvgTimeMs    float64       `json:"from_avg_time_ms"` // Lifetime average latency at the first snapshot
+	ToAvgTimeMs      float64       `json:"to_avg_time_ms"`   // Lifetime average latency at the second snapshot
+	StatusCodeDeltas map[int]int64 `json:"status_code_deltas"`
+}
+
+// StatsDiff represents the change in server statistics between two snapshots
+type StatsDiff struct {
+	From         string                        `json:"from"`
+	To           string                        `json:"to"`
+	FromTime     time.Time                     `json:"from_time"`
+	ToTime       time.Time                     `json:"to_time"`
+	DurationMs   int64                         `json:"duration_ms"`
+	RequestDelta int64                         `json:"request_delta"`
+	ErrorDelta   int64                         `json:"error_delta"`
+	Endpoints    map[string]*EndpointStatsDiff `json:"endpoints"`
+}
+
+// DiffSnapshots computes the deltas between two snapshots
+func DiffSnapshots(from, to *StatsSnapshot) *StatsDiff {
+	diff := &StatsDiff{
+		From:         from.Name,
+		To:           to.Name,
+		FromTime:     from.TakenAt,
+		ToTime:       to.TakenAt,
+		DurationMs:   to.TakenAt.Sub(from.TakenAt).Milliseconds(),
+		RequestDelta: to.Stats.RequestCount - from.Stats.RequestCount,
+		ErrorDelta:   to.Stats.ErrorCount - from.Stats.ErrorCount,
+		Endpoints:    make(map[string]*EndpointStatsDiff),
+	}
+
+	for path, toStats := range to.Stats.Endpoints {
+		fromStats, exists := from.Stats.Endpoints[path]
+		if !exists {
+			fromStats = &EndpointStats{Path: path}
+		}
+
+		endpointDiff := &EndpointStatsDiff{
+			Path:             path,
+			RequestDelta:     toStats.RequestCount - fromStats.RequestCount,
+			ErrorDelta:       toStats.ErrorCount - fromStats.ErrorCount,
+			TotalTimeMsDelta: toStats.TotalTimeMs - fromStats.TotalTimeMs,
+			FromAvgTimeMs:    averageMs(fromStats.TotalTimeMs, fromStats.RequestCount),
+			ToAvgTimeMs:      averageMs(toStats.TotalTimeMs, toStats.RequestCount),
+			StatusCodeDeltas: make(map[int]int64),
+		}
+		endpointDiff.AvgTimeMs = averageMs(endpointDiff.TotalTimeMsDelta, endpointDiff.RequestDelta)
+
+		for code, count := range toStats.StatusCodes {
+			if delta := count - fromStats.StatusCodes[code]; delta != 0 {
+				endpointDiff.StatusCodeDeltas[code] = delta
+			}
+		}
+
+		// Only report endpoints that saw traffic between the snapshots
+		if endpointDiff.RequestDelta != 0 {
+			diff.Endpoints[path] = endpointDiff
+		}
+	}
+
+	return diff
+}
+
+// averageMs returns total divided by count, or zero when count is zero
+func averageMs(total, count int64) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
@@ -0,0 +1,126 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultTimeSeriesSeconds is the time series window used when none is requested
+	DefaultTimeSeriesSeconds = 60
+
+	// MaxTimeSeriesSeconds is the longest time series window available
+	MaxTimeSeriesSeconds = rateWindowSeconds
+)
+
+// TimeSeriesPoint holds the traffic recorded during one step of a time series
+type TimeSeriesPoint struct {
+	Time           time.Time `json:"time"` // Start of the step
+	Requests       int64     `json:"requests"`
+	Errors         int64     `json:"errors"`
+	AvgTimeMs      float64   `json:"avg_time_ms"` // Average of the sampled latencies; 0 when none were sampled
+	MaxTimeMs      int64     `json:"max_time_ms"`
+	LatencyBuckets []int64   `json:"latency_buckets"` // Sampled requests per TimeSeries.LatencyBucketsMs bucket
+	latencyMs      int64
+	samples        int64
+}
+
+// TimeSeries represents traffic over a recent window, oldest point first, for each endpoint and for all endpoints combined
+type TimeSeries struct {
+	WindowSeconds    int                          `json:"window_seconds"`
+	StepSeconds      int                          `json:"step_seconds"`
+	LatencyBucketsMs []int64                      `json:"latency_buckets_ms"` // Upper bounds of the latency buckets; the last bucket has none
+	Total            []TimeSeriesPoint            `json:"total"`
+	Endpoints        map[string][]TimeSeriesPoint `json:"endpoints"`
+}
+
+// ValidateTimeSeriesRange checks a time series window and step, both in seconds
+func ValidateTimeSeriesRange(windowSeconds, stepSeconds int) error {
+	if windowSeconds < 1 || windowSeconds > MaxTimeSeriesSeconds {
+		return fmt.Errorf("window must be between 1 and %d seconds", MaxTimeSeriesSeconds)
+	}
+	if stepSeconds < 1 || stepSeconds > windowSeconds {
+		return fmt.Errorf("step must be between 1 and %d seconds", windowSeconds)
+	}
+	if windowSeconds%stepSeconds != 0 {
+		return fmt.Errorf("window (%ds) must be a multiple of step (%ds)", windowSeconds, stepSeconds)
+	}
+	if windowSeconds > maxSecondSeriesSeconds && stepSeconds%60 != 0 {
+		return fmt.Errorf("windows over %d seconds need a step in whole minutes (%ds)", maxSecondSeriesSeconds, stepSeconds)
+	}
+	return nil
+}
+
+// TimeSeries returns the traffic of the last windowSeconds complete seconds,
+// or complete minutes for windows over two minutes, in steps of stepSeconds. When path is set only that endpoint is included.
+// The range must pass ValidateTimeSeriesRange.
+func (ss *ServerStats) TimeSeries(windowSeconds, stepSeconds int, path string) *TimeSeries {
+	now := time.Now()
+	points := windowSeconds / stepSeconds
+
+	series := &TimeSeries{
+		WindowSeconds:    windowSeconds,
+		StepSeconds:      stepSeconds,
+		LatencyBucketsMs: LatencyBucketBoundsMs(),
+		Total:            make([]TimeSeriesPoint, points),
+		Endpoints:        make(map[string][]TimeSeriesPoint),
+	}
+	first := seriesStart(now, windowSeconds)
+	for i := range series.Total {
+		series.Total[i].Time = time.Unix(first+int64(i*stepSeconds), 0)
+		series.Total[i].LatencyBuckets = make([]int64, latencyBucketCount)
+	}
+
+	for i := range ss.shards {
+		shard := &ss.shards[i]
+		shard.mutex.RLock()
+		for endpointPath, endpointStats := range shard.endpoints {
+			if path != "" && endpointPath != path {
+				continue
+			}
+			endpointSeries := endpointStats.rates.series(now, points, stepSeconds)
+			for i := range endpointSeries {
+				series.Total[i].add(endpointSeries[i])
+				endpointSeries[i].finish()
+			}
+			series.Endpoints[endpointPath] = endpointSeries
+		}
+		shard.mutex.RUnlock()
+	}
+
+	for i := range series.Total {
+		series.Total[i].finish()
+	}
+	return series
+}
+
+// seriesStart returns the Unix time a time series window ending before now
+// starts at: the window before the current second, or for windows in whole
+// minutes before the current minute
+func seriesStart(now time.Time, windowSeconds int) int64 {
+	if windowSeconds > maxSecondSeriesSeconds {
+		return now.Unix()/60*60 - int64(windowSeconds)
+	}
+	return now.Unix() - int64(windowSeconds)
+}
+
+// add merges the traffic of another point covering the same step
+func (p *TimeSeriesPoint) add(other TimeSeriesPoint) {
+	p.Requests += other.Requests
+	p.Errors += other.Errors
+	p.latencyMs += other.latencyMs
+	p.samples += other.samples
+	if other.MaxTimeMs > p.MaxTimeMs {
+		p.MaxTimeMs = other.MaxTimeMs
+	}
+	for i, count := range other.LatencyBuckets {
+		p.LatencyBuckets[i] += count
+	}
+}
+
+// finish computes the average latency from the sampled latencies
+func (p *TimeSeriesPoint) finish() {
+	if p.samples > 0 {
+		p.AvgTimeMs = float64(p.latencyMs) / float64(p.samples)
+	}
+}
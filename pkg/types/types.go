@@ -1,56 +1,329 @@
 package types
 
 import (
+	"math"
+	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // ServerConfig represents the main server configuration
 type ServerConfig struct {
-	Port      int    `json:"port"`
-	Host      string `json:"host"`
-	StaticDir string `json:"static_dir"`
+	Port        int    `json:"port"`
+	Host        string `json:"host"`
+	StaticDir   string `json:"static_dir"`
+	SampleEvery int    `json:"sample_every,omitempty"` // Record 1-in-N requests into latency stats and the request log
+
+	RequestLogSize     int `json:"request_log_size,omitempty"`      // Maximum stored request log entries (default 1000)
+	RequestLogMemoryMB int `json:"request_log_memory_mb,omitempty"` // Optional memory budget for the request log; 0 disables
+
+	RequestLogSampleEvery    int `json:"request_log_sample_every,omitempty"`     // Store/broadcast 1-in-N requests; defaults to sample_every
+	RequestLogSampleAboveRPS int `json:"request_log_sample_above_rps,omitempty"` // Only sample while traffic exceeds this rate; 0 always samples
+
+	RequestLogExclude []string         `json:"request_log_exclude,omitempty"` // Path patterns (e.g. "/stats*", "/favicon.ico") never logged or broadcast
+	RequestLogRedact  *RedactionConfig `json:"request_log_redact,omitempty"`  // Masks sensitive data before entries are stored or broadcast
+	ReplayTargets     []string         `json:"replay_targets,omitempty"`      // Base URLs besides this server logged requests may be replayed against
+
+	ManagementAuth *ManagementAuthConfig `json:"management_auth,omitempty"` // Bearer tokens required by the management API and /ws
+
+	WSHeartbeatSeconds int    `json:"ws_heartbeat_seconds,omitempty"`  // WebSocket ping interval (default 30); clients silent for two intervals are dropped
+	WSSendQueueSize    int    `json:"ws_send_queue_size,omitempty"`    // Messages buffered per WebSocket client (default 256)
+	WSSlowClientPolicy string `json:"ws_slow_client_policy,omitempty"` // "drop" (default) or "disconnect" when a client's queue is full
+
+	ShutdownDrainMs int `json:"shutdown_drain_ms,omitempty"` // How long /readyz fails before the listener closes on shutdown
+
+	Contract *ContractConfig `json:"contract,omitempty"` // Proxy to a real backend and check its responses against the endpoints
+
+	Sessions *SessionConfig `json:"sessions,omitempty"` // How callers' sessions are told apart
+}
+
+// SessionConfig names where a request's session comes from: the cookie if
+// configured, then the header, then the caller's IP address
+type SessionConfig struct {
+	Header string `json:"header,omitempty"` // Header naming the session (default X-Session-Id)
+	Cookie string `json:"cookie,omitempty"` // Cookie naming the session, handed out to callers without one
 }
 
+// DefaultRequestLogSize is the request log capacity used when none is configured
+const DefaultRequestLogSize = 1000
+
 // EndpointConfig represents configuration for a single endpoint
 type EndpointConfig struct {
-	Type           string                 `json:"type"`
-	StatusCode     int                    `json:"status_code,omitempty"`
-	Message        string                 `json:"message,omitempty"`
-	DelayMs        int                    `json:"delay_ms,omitempty"`
-	Response       map[string]interface{} `json:"response,omitempty"`
-	ErrorEveryN    int                    `json:"error_every_n,omitempty"`
-	SuccessResponse map[string]interface{} `json:"success_response,omitempty"`
+	Type             string                 `json:"type"`
+	StatusCode       int                    `json:"status_code,omitempty"`
+	Message          string                 `json:"message,omitempty"`
+	DelayMs          int                    `json:"delay_ms,omitempty"`
+	DelayProfile     *DelayProfile          `json:"delay_profile,omitempty"` // Delay changing over time, in place of delay_ms
+	Response         map[string]interface{} `json:"response,omitempty"`
+	ErrorEveryN      int                    `json:"error_every_n,omitempty"`
+	SuccessResponse  map[string]interface{} `json:"success_response,omitempty"`
+	ApdexThresholdMs int                    `json:"apdex_threshold_ms,omitempty"`
+	SlowThresholdMs  int                    `json:"slow_threshold_ms,omitempty"` // Requests taking longer are flagged as slow
+	SLO              *SLOConfig             `json:"slo,omitempty"`
+	Disabled         bool                   `json:"disabled,omitempty"`       // Disabled endpoints are served as if not configured
+	Protobuf         *ProtobufConfig        `json:"protobuf,omitempty"`       // Encode successful responses as protobuf instead of JSON
+	Fuzz             *FuzzConfig            `json:"fuzz,omitempty"`           // How fuzz endpoints break their response
+	RequestSchema    map[string]interface{} `json:"request_schema,omitempty"` // JSON Schema request bodies must match, or get a 400
+	Requires         *RequiresConfig        `json:"requires,omitempty"`       // Endpoint a session must call first
+	PerSession       bool                   `json:"per_session,omitempty"`    // Count requests per session instead of for all callers
+	TargetURL        string                 `json:"target_url,omitempty"`     // Upstream a proxy endpoint forwards to
+	Location         string                 `json:"location,omitempty"`       // Where a redirect endpoint sends clients
+	Group            string                 `json:"group,omitempty"`          // Label for enabling, disabling and breaking endpoints together
+	Methods          []string               `json:"methods,omitempty"`        // Methods answered, every one when empty
+}
+
+// EndpointPath returns the request path an endpoint key serves. Keys name
+// the path, optionally followed by #label so several endpoints can serve the
+// same path for different methods, e.g. /api/users#create.
+func EndpointPath(key string) string {
+	if i := strings.IndexByte(key, '#'); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+// AcceptsMethod reports whether an endpoint answers a request method. HEAD
+// requests are answered by endpoints accepting GET.
+func (e *EndpointConfig) AcceptsMethod(method string) bool {
+	if len(e.Methods) == 0 {
+		return true
+	}
+	for _, accepted := range e.Methods {
+		if accepted == method || (method == http.MethodHead && accepted == http.MethodGet) {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay profile shapes
+const (
+	DelayProfileLinear = "linear" // Ramps from from_ms to to_ms over the duration, then holds
+	DelayProfileSine   = "sine"   // Swings from from_ms to to_ms and back once per duration
+)
+
+// DelayProfile makes a delay endpoint's delay change over time, from when
+// the endpoint was first requested with the profile, to rehearse alerting on
+// gradual degradation
+type DelayProfile struct {
+	Shape    string `json:"shape"`            // linear or sine
+	FromMs   int    `json:"from_ms"`          // Delay at the start
+	ToMs     int    `json:"to_ms"`            // Delay at the end of a ramp, or the peak of a wave
+	Duration string `json:"duration"`         // Length of the ramp, or period of the wave, e.g. 10m
+	Repeat   bool   `json:"repeat,omitempty"` // Start linear ramps over once they end
+}
+
+// DelayAt returns the delay a profile prescribes some time after it started
+func (p *DelayProfile) DelayAt(elapsed time.Duration) time.Duration {
+	from := float64(p.FromMs) * float64(time.Millisecond)
+	to := float64(p.ToMs) * float64(time.Millisecond)
+	duration, err := time.ParseDuration(p.Duration)
+	if err != nil || duration <= 0 {
+		return time.Duration(from)
+	}
+
+	var progress float64 // From 0 at from_ms to 1 at to_ms
+	switch p.Shape {
+	case DelayProfileSine:
+		phase := float64(elapsed%duration) / float64(duration)
+		progress = (1 - math.Cos(2*math.Pi*phase)) / 2
+	default:
+		if p.Repeat {
+			elapsed %= duration
+		}
+		progress = math.Min(float64(elapsed)/float64(duration), 1)
+	}
+	return time.Duration(from + (to-from)*progress)
+}
+
+// SessionHeader is the default header naming a request's session
+const SessionHeader = "X-Session-Id"
+
+// RequiresConfig makes an endpoint refuse sessions that have not yet called
+// another endpoint successfully, e.g. /api/items until /api/login
+type RequiresConfig struct {
+	Path       string `json:"path"`                  // Endpoint to call first
+	StatusCode int    `json:"status_code,omitempty"` // Answer until then (default 401)
+	Message    string `json:"message,omitempty"`     // Error message until then
+}
+
+// SessionState is what the server remembers about a session
+type SessionState struct {
+	Session  string               `json:"session"`
+	LastSeen time.Time            `json:"last_seen"`
+	Calls    map[string]time.Time `json:"calls"`              // Last successful call by endpoint path
+	Counters map[string]int64     `json:"counters,omitempty"` // Requests to per_session endpoints by path
+}
+
+// FuzzConfig controls the malformed payloads of a fuzz endpoint, which breaks
+// its response (or a built-in one) differently on every request
+type FuzzConfig struct {
+	Seed            int64    `json:"seed,omitempty"`              // Same seed, same sequence of payloads
+	Mutations       []string `json:"mutations,omitempty"`         // Mutations to choose from; all by default
+	HugeStringBytes int      `json:"huge_string_bytes,omitempty"` // Length of huge strings (default 1 MiB)
+}
+
+// ProtobufConfig encodes an endpoint's response as a protobuf message. The
+// response's JSON fields give the field values, as in the protobuf JSON
+// mapping.
+type ProtobufConfig struct {
+	DescriptorSet string `json:"descriptor_set"` // File written by protoc --descriptor_set_out --include_imports
+	Message       string `json:"message"`        // Fully qualified message type, e.g. "shop.v1.Order"
+}
+
+// Contract matching modes
+const (
+	ContractMatchShape = "shape" // Fields of the endpoint's response exist upstream with the same JSON types
+	ContractMatchExact = "exact" // Upstream responses equal the endpoint's response
+)
+
+// ContractConfig turns the server into a contract tester: instead of being
+// mocked, requests are proxied to a real backend, and the responses of
+// configured endpoints are compared with the endpoints' responses, which act
+// as golden fixtures
+type ContractConfig struct {
+	Upstream     string   `json:"upstream"`                // Backend base URL, e.g. "https://staging.example.com"
+	Match        string   `json:"match,omitempty"`         // "shape" (default) or "exact"
+	IgnoreFields []string `json:"ignore_fields,omitempty"` // Dotted field paths never compared, e.g. "meta.request_id" or "items.id"
+	TimeoutMs    int      `json:"timeout_ms,omitempty"`    // Upstream timeout (default 10000)
 }
 
 // Config represents the complete server configuration
 type Config struct {
 	Server    ServerConfig              `json:"server"`
 	Endpoints map[string]EndpointConfig `json:"endpoints"`
+	Alerts    []AlertRule               `json:"alerts,omitempty"`    // Webhook notifications for matching logged requests
+	Scenarios []string                  `json:"scenarios,omitempty"` // Scenario YAML files, or glob patterns matching them
+
+	ChaosProfiles map[string]ChaosProfile `json:"chaos_profiles,omitempty"` // Named trouble to apply to endpoint groups
+}
+
+// ChaosProfile slows down and fails requests to every endpoint of a group it
+// is applied to, on top of what the endpoints do
+type ChaosProfile struct {
+	LatencyMs  int     `json:"latency_ms,omitempty"`  // Added before each response
+	ErrorRate  float64 `json:"error_rate,omitempty"`  // Share of requests failed, from 0 to 1
+	StatusCode int     `json:"status_code,omitempty"` // Status of failed requests, 503 by default
+	Message    string  `json:"message,omitempty"`     // Error of failed requests
 }
 
-// EndpointStats represents statistics for a single endpoint
+// EndpointGroup describes the endpoints sharing a group, as listed by
+// GET /groups
+type EndpointGroup struct {
+	Name      string   `json:"name"`
+	Endpoints []string `json:"endpoints"`
+	Disabled  int      `json:"disabled"`        // How many of the endpoints are disabled
+	Chaos     string   `json:"chaos,omitempty"` // Chaos profile applied to the group
+}
+
+// VersionInfo describes a build, as served by GET /version
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // Built from a working tree with uncommitted changes
+	GoVersion string `json:"go_version"`
+	Protocol  int    `json:"protocol"` // Version of the HTTP and WebSocket API
+}
+
+// ShortCommit returns the first 7 characters of the commit, marked when the
+// working tree had changes
+func (v VersionInfo) ShortCommit() string {
+	commit := v.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	if commit != "" && v.Modified {
+		commit += "-dirty"
+	}
+	return commit
+}
+
+// ConfigStatus is the response of GET /config/status: where the
+// configuration comes from and whether the latest reload succeeded
+type ConfigStatus struct {
+	Files    []string   `json:"files,omitempty"`     // Configuration file and layers; none for a configuration from the environment
+	LoadedAt time.Time  `json:"loaded_at"`           // When the configuration in use was loaded
+	Error    string     `json:"error,omitempty"`     // Why the latest reload failed; the configuration loaded at loaded_at stays in use
+	FailedAt *time.Time `json:"failed_at,omitempty"` // When the latest reload failed
+}
+
+// ConfigValidation is the response of POST /config/validate
+type ConfigValidation struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// EndpointStats represents statistics for a single endpoint.
+// Live instances update their counters with atomics; MinTimeMs, StatusCodes,
+// First/LastRequest, Rates, Apdex and SLO are only populated on the snapshots
+// returned by GetStats.
 type EndpointStats struct {
-	Path            string             `json:"path"`
-	RequestCount    int64              `json:"request_count"`
-	ErrorCount      int64              `json:"error_count"`
-	TotalTimeMs     int64              `json:"total_time_ms"`
-	MinTimeMs       int64              `json:"min_time_ms"`
-	MaxTimeMs       int64              `json:"max_time_ms"`
-	StatusCodes     map[int]int64      `json:"status_codes"`
-	FirstRequest    time.Time          `json:"first_request"`
-	LastRequest     time.Time          `json:"last_request"`
-	ConditionalCount int64             `json:"conditional_count"` // For N-request pattern tracking
-	mutex           sync.RWMutex       `json:"-"`
-}
-
-// ServerStats represents overall server statistics
+	Path               string               `json:"path"`
+	RequestCount       int64                `json:"request_count"`
+	ErrorCount         int64                `json:"error_count"`
+	TotalTimeMs        int64                `json:"total_time_ms"`
+	MinTimeMs          int64                `json:"min_time_ms"`
+	MaxTimeMs          int64                `json:"max_time_ms"`
+	StatusCodes        map[int]int64        `json:"status_codes"`
+	FirstRequest       time.Time            `json:"first_request"`
+	LastRequest        time.Time            `json:"last_request"`
+	ConditionalCount   int64                `json:"conditional_count"` // For N-request pattern tracking
+	Rates              RequestRates         `json:"rates"`             // Rolling 1m/5m/15m rates
+	Apdex              *ApdexStats          `json:"apdex,omitempty"`   // Only present when a threshold is configured
+	SLO                *SLOStats            `json:"slo,omitempty"`     // Only present when an SLO is configured
+	InFlight           int64                `json:"in_flight"`         // Requests currently being handled
+	MaxInFlight        int64                `json:"max_in_flight"`     // High-water mark of simultaneous requests
+	SlowCount          int64                `json:"slow_count"`        // Requests exceeding the slow threshold
+	SlowThresholdMs    int64                `json:"slow_threshold_ms,omitempty"`
+	ContractChecks     int64                `json:"contract_checks,omitempty"`     // Upstream responses compared in contract mode
+	ContractMismatches int64                `json:"contract_mismatches,omitempty"` // Compared responses that did not match
+	statusCodes        [maxStatusCode]int64 // Hot-path status code counters, indexed by code
+	minTimeMsPlusOne   int64                // Minimum latency plus one, 0 until the first sample
+	firstRequestNs     int64                // Unix nanoseconds, 0 until the first request
+	lastRequestNs      int64                // Unix nanoseconds
+	rates              rateCounter
+	apdexThreshold     int64                     // Mirrors Apdex.ThresholdMs for lock-free change checks
+	slowThreshold      int64                     // Slow request threshold in milliseconds; 0 disables
+	sloConfig          atomic.Pointer[SLOConfig] // Last SLO configuration applied
+	mutex              sync.Mutex                // Guards Apdex, SLO and out-of-range status codes
+}
+
+// ServerStats represents overall server statistics.
+// Live instances keep endpoints and User-Agents in sharded maps and update
+// counters with atomics; Endpoints and UserAgents are only populated on the
+// snapshots returned by GetAllStats.
 type ServerStats struct {
-	StartTime     time.Time                `json:"start_time"`
-	RequestCount  int64                    `json:"total_requests"`
-	ErrorCount    int64                    `json:"total_errors"`
+	StartTime     time.Time                 `json:"start_time"`
+	RequestCount  int64                     `json:"total_requests"`
+	ErrorCount    int64                     `json:"total_errors"`
 	Endpoints     map[string]*EndpointStats `json:"endpoints"`
-	mutex         sync.RWMutex             `json:"-"`
+	UserAgents    map[string]int64          `json:"user_agents"` // Normalized User-Agent -> request count
+	Process       *ProcessStats             `json:"process,omitempty"`
+	SampleEvery   int                       `json:"sample_every,omitempty"` // Latency details and log sampled 1-in-N when > 1
+	sampleEvery   int64
+	shards        [statsShardCount]statsShard
+	userAgentKeys int64 // Number of distinct User-Agent keys across shards
+	pathKeys      int64 // Number of paths tracked through PathKey across shards
+}
+
+// TopEndpoint represents a single ranked entry in the top endpoints summary
+type TopEndpoint struct {
+	Rank         int     `json:"rank"`
+	Path         string  `json:"path"`
+	RequestCount int64   `json:"request_count"`
+	ErrorCount   int64   `json:"error_count"`
+	AvgTimeMs    float64 `json:"avg_time_ms"`
+	MaxTimeMs    int64   `json:"max_time_ms"`
+}
+
+// TopEndpointsResponse represents the response of the top endpoints summary
+type TopEndpointsResponse struct {
+	By        string        `json:"by"`
+	N         int           `json:"n"`
+	Endpoints []TopEndpoint `json:"endpoints"`
 }
 
 // TUIMessage represents messages sent to the TUI client
@@ -60,14 +333,70 @@ type TUIMessage struct {
 	Data      interface{} `json:"data"`
 }
 
+const (
+	// TestTagHeader is the request header used to tag log entries
+	TestTagHeader = "X-Test-Tag"
+
+	// RequestIDHeader is the response header carrying the request log entry ID
+	RequestIDHeader = "X-Request-ID"
+
+	// RequestLogPositionHeader is the GET /requestlog response header carrying
+	// how many entries have been logged, for use as ?since on the next request
+	RequestLogPositionHeader = "X-Request-Log-Position"
+)
+
+// MaxCapturedBodyBytes bounds the request body stored with a log entry
+const MaxCapturedBodyBytes = 64 * 1024
+
 // RequestLogEntry represents a single request log entry
 type RequestLogEntry struct {
-	Timestamp  time.Time `json:"timestamp"`
-	Method     string    `json:"method"`
-	Path       string    `json:"path"`
-	StatusCode int       `json:"status_code"`
-	Duration   int64     `json:"duration_ms"`
-	RemoteAddr string    `json:"remote_addr"`
+	ID            uint64      `json:"id"`
+	Timestamp     time.Time   `json:"timestamp"`
+	Method        string      `json:"method"`
+	Path          string      `json:"path"`
+	StatusCode    int         `json:"status_code"`
+	Duration      int64       `json:"duration_ms"`
+	RemoteAddr    string      `json:"remote_addr"`
+	Tag           string      `json:"tag,omitempty"`  // From the X-Test-Tag request header
+	Slow          bool        `json:"slow,omitempty"` // Exceeded the endpoint's slow_threshold_ms
+	Host          string      `json:"host,omitempty"`
+	Headers       http.Header `json:"headers,omitempty"`
+	Body          string      `json:"body,omitempty"`
+	BodyTruncated bool        `json:"body_truncated,omitempty"`
+
+	ContractMismatches []string `json:"contract_mismatches,omitempty"` // How the upstream response differed from the endpoint's, in contract mode
+}
+
+// EstimatedSize approximates the memory used by an entry in bytes
+func (e *RequestLogEntry) EstimatedSize() int {
+	// Fixed struct overhead plus string contents
+	size := 128 + len(e.Method) + len(e.Path) + len(e.RemoteAddr) + len(e.Tag) + len(e.Host) + len(e.Body)
+	for _, mismatch := range e.ContractMismatches {
+		size += len(mismatch) + 16
+	}
+	for name, values := range e.Headers {
+		size += len(name)
+		for _, value := range values {
+			size += len(value) + 16
+		}
+	}
+	return size
+}
+
+// ReplayResponse represents the response received when replaying a request
+type ReplayResponse struct {
+	StatusCode    int         `json:"status_code"`
+	Duration      int64       `json:"duration_ms"`
+	Headers       http.Header `json:"headers"`
+	Body          string      `json:"body"`
+	BodyTruncated bool        `json:"body_truncated,omitempty"`
+}
+
+// ReplayResult represents the outcome of replaying a logged request
+type ReplayResult struct {
+	Original RequestLogEntry `json:"original"`
+	Target   string          `json:"target"`
+	Response ReplayResponse  `json:"response"`
 }
 
 // ConfigUpdateRequest represents a request to update configuration
@@ -79,121 +408,325 @@ type ConfigUpdateRequest struct {
 
 // Methods for EndpointStats
 func (es *EndpointStats) RecordRequest(duration time.Duration, statusCode int) {
-	es.mutex.Lock()
-	defer es.mutex.Unlock()
-	
+	es.recordRequest(duration, statusCode, 1)
+}
+
+// recordRequest records a request, keeping counters exact while only
+// sampling 1-in-sampleEvery requests into latency details. Sampled latencies
+// are weighted by sampleEvery so TotalTimeMs stays an estimate of the total.
+func (es *EndpointStats) recordRequest(duration time.Duration, statusCode int, sampleEvery int) {
 	now := time.Now()
 	durationMs := duration.Milliseconds()
-	
-	es.RequestCount++
-	es.TotalTimeMs += durationMs
-	
-	if statusCode >= 400 {
-		es.ErrorCount++
+	isError := statusCode >= 400
+
+	count := atomic.AddInt64(&es.RequestCount, 1)
+	if isError {
+		atomic.AddInt64(&es.ErrorCount, 1)
 	}
-	
-	if es.MinTimeMs == 0 || durationMs < es.MinTimeMs {
-		es.MinTimeMs = durationMs
+
+	if statusCode >= 0 && statusCode < maxStatusCode {
+		atomic.AddInt64(&es.statusCodes[statusCode], 1)
+	} else {
+		es.mutex.Lock()
+		if es.StatusCodes == nil {
+			es.StatusCodes = make(map[int]int64)
+		}
+		es.StatusCodes[statusCode]++
+		es.mutex.Unlock()
 	}
-	
-	if durationMs > es.MaxTimeMs {
-		es.MaxTimeMs = durationMs
+
+	atomic.CompareAndSwapInt64(&es.firstRequestNs, 0, now.UnixNano())
+	atomic.StoreInt64(&es.lastRequestNs, now.UnixNano())
+
+	es.rates.record(now, isError)
+
+	if threshold := atomic.LoadInt64(&es.slowThreshold); threshold > 0 && durationMs > threshold {
+		atomic.AddInt64(&es.SlowCount, 1)
 	}
-	
-	if es.StatusCodes == nil {
-		es.StatusCodes = make(map[int]int64)
+
+	// SLO tracking is optional and guarded by the endpoint mutex
+	if es.sloConfig.Load() != nil {
+		es.mutex.Lock()
+		if es.SLO != nil {
+			es.SLO.record(now, isError)
+		}
+		es.mutex.Unlock()
+	}
+
+	// Latency details are only recorded for sampled requests
+	if sampleEvery > 1 && count%int64(sampleEvery) != 0 {
+		return
+	}
+	if sampleEvery < 1 {
+		sampleEvery = 1
+	}
+
+	atomic.AddInt64(&es.TotalTimeMs, durationMs*int64(sampleEvery))
+	es.rates.recordLatency(now, durationMs)
+
+	// minTimeMsPlusOne stores the minimum offset by one so zero means unset
+	for {
+		current := atomic.LoadInt64(&es.minTimeMsPlusOne)
+		if current != 0 && durationMs+1 >= current {
+			break
+		}
+		if atomic.CompareAndSwapInt64(&es.minTimeMsPlusOne, current, durationMs+1) {
+			break
+		}
+	}
+
+	for {
+		current := atomic.LoadInt64(&es.MaxTimeMs)
+		if durationMs <= current {
+			break
+		}
+		if atomic.CompareAndSwapInt64(&es.MaxTimeMs, current, durationMs) {
+			break
+		}
+	}
+
+	if atomic.LoadInt64(&es.apdexThreshold) > 0 {
+		es.mutex.Lock()
+		if es.Apdex != nil {
+			es.Apdex.record(durationMs, statusCode)
+		}
+		es.mutex.Unlock()
 	}
-	es.StatusCodes[statusCode]++
-	
-	if es.FirstRequest.IsZero() {
-		es.FirstRequest = now
+}
+
+// SetSLO sets the availability objective tracked for this endpoint. A changed
+// objective restarts SLO tracking; nil disables it.
+func (es *EndpointStats) SetSLO(config *SLOConfig) {
+	// Fast path: the same configuration is applied on every request
+	if es.sloConfig.Load() == config {
+		return
+	}
+
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+
+	if config == nil {
+		es.SLO = nil
+	} else if es.SLO == nil || !es.SLO.matches(config) {
+		es.SLO = newSLOStats(config)
 	}
-	es.LastRequest = now
+	es.sloConfig.Store(config)
 }
 
-func (es *EndpointStats) IncrementConditionalCount() {
+// SetApdexThreshold sets the target latency used for the Apdex score. A
+// changed threshold restarts the Apdex counts; zero disables tracking.
+func (es *EndpointStats) SetApdexThreshold(thresholdMs int) {
+	if thresholdMs < 0 {
+		thresholdMs = 0
+	}
+
+	// Fast path: the same threshold is applied on every request
+	if atomic.LoadInt64(&es.apdexThreshold) == int64(thresholdMs) {
+		return
+	}
+
 	es.mutex.Lock()
 	defer es.mutex.Unlock()
-	es.ConditionalCount++
+
+	if thresholdMs == 0 {
+		es.Apdex = nil
+	} else {
+		es.Apdex = &ApdexStats{ThresholdMs: int64(thresholdMs)}
+	}
+	atomic.StoreInt64(&es.apdexThreshold, int64(thresholdMs))
+}
+
+// SetSlowThreshold sets the latency above which requests count as slow;
+// zero disables slow request counting
+func (es *EndpointStats) SetSlowThreshold(thresholdMs int) {
+	if thresholdMs < 0 {
+		thresholdMs = 0
+	}
+	atomic.StoreInt64(&es.slowThreshold, int64(thresholdMs))
+}
+
+// BeginRequest marks a request as in flight and updates the high-water mark
+func (es *EndpointStats) BeginRequest() {
+	inFlight := atomic.AddInt64(&es.InFlight, 1)
+	for {
+		current := atomic.LoadInt64(&es.MaxInFlight)
+		if inFlight <= current || atomic.CompareAndSwapInt64(&es.MaxInFlight, current, inFlight) {
+			return
+		}
+	}
+}
+
+// EndRequest marks an in-flight request as finished
+func (es *EndpointStats) EndRequest() {
+	atomic.AddInt64(&es.InFlight, -1)
+}
+
+// IncrementConditionalCount increments the conditional counter and returns the new value
+func (es *EndpointStats) IncrementConditionalCount() int64 {
+	return atomic.AddInt64(&es.ConditionalCount, 1)
 }
 
 func (es *EndpointStats) GetConditionalCount() int64 {
-	es.mutex.RLock()
-	defer es.mutex.RUnlock()
-	return es.ConditionalCount
-}
-
-func (es *EndpointStats) GetStats() EndpointStats {
-	es.mutex.RLock()
-	defer es.mutex.RUnlock()
-	
-	// Create a copy to avoid race conditions
-	stats := EndpointStats{
-		Path:             es.Path,
-		RequestCount:     es.RequestCount,
-		ErrorCount:       es.ErrorCount,
-		TotalTimeMs:      es.TotalTimeMs,
-		MinTimeMs:        es.MinTimeMs,
-		MaxTimeMs:        es.MaxTimeMs,
-		StatusCodes:      make(map[int]int64),
-		FirstRequest:     es.FirstRequest,
-		LastRequest:      es.LastRequest,
-		ConditionalCount: es.ConditionalCount,
-	}
-	
+	return atomic.LoadInt64(&es.ConditionalCount)
+}
+
+// RecordContractCheck counts an upstream response compared in contract mode
+func (es *EndpointStats) RecordContractCheck(matched bool) {
+	atomic.AddInt64(&es.ContractChecks, 1)
+	if !matched {
+		atomic.AddInt64(&es.ContractMismatches, 1)
+	}
+}
+
+// GetStats returns a point-in-time snapshot of the endpoint statistics
+func (es *EndpointStats) GetStats() *EndpointStats {
+	now := time.Now()
+
+	stats := &EndpointStats{
+		Path:               es.Path,
+		RequestCount:       atomic.LoadInt64(&es.RequestCount),
+		ErrorCount:         atomic.LoadInt64(&es.ErrorCount),
+		TotalTimeMs:        atomic.LoadInt64(&es.TotalTimeMs),
+		MaxTimeMs:          atomic.LoadInt64(&es.MaxTimeMs),
+		StatusCodes:        make(map[int]int64),
+		ConditionalCount:   atomic.LoadInt64(&es.ConditionalCount),
+		InFlight:           atomic.LoadInt64(&es.InFlight),
+		MaxInFlight:        atomic.LoadInt64(&es.MaxInFlight),
+		SlowCount:          atomic.LoadInt64(&es.SlowCount),
+		SlowThresholdMs:    atomic.LoadInt64(&es.slowThreshold),
+		ContractChecks:     atomic.LoadInt64(&es.ContractChecks),
+		ContractMismatches: atomic.LoadInt64(&es.ContractMismatches),
+		Rates:              es.rates.rates(now),
+	}
+
+	if minPlusOne := atomic.LoadInt64(&es.minTimeMsPlusOne); minPlusOne != 0 {
+		stats.MinTimeMs = minPlusOne - 1
+	}
+	if ns := atomic.LoadInt64(&es.firstRequestNs); ns != 0 {
+		stats.FirstRequest = time.Unix(0, ns)
+	}
+	if ns := atomic.LoadInt64(&es.lastRequestNs); ns != 0 {
+		stats.LastRequest = time.Unix(0, ns)
+	}
+
+	for code := range es.statusCodes {
+		if count := atomic.LoadInt64(&es.statusCodes[code]); count > 0 {
+			stats.StatusCodes[code] = count
+		}
+	}
+
+	es.mutex.Lock()
+	defer es.mutex.Unlock()
+
 	for code, count := range es.StatusCodes {
 		stats.StatusCodes[code] = count
 	}
-	
+
+	if es.Apdex != nil {
+		apdex := *es.Apdex
+		apdex.Score = apdex.score()
+		stats.Apdex = &apdex
+	}
+
+	if es.SLO != nil {
+		stats.SLO = es.SLO.snapshot(now)
+	}
+
 	return stats
 }
 
 // Methods for ServerStats
 func (ss *ServerStats) GetEndpointStats(path string) *EndpointStats {
-	ss.mutex.Lock()
-	defer ss.mutex.Unlock()
-	
-	if ss.Endpoints == nil {
-		ss.Endpoints = make(map[string]*EndpointStats)
-	}
-	
-	if _, exists := ss.Endpoints[path]; !exists {
-		ss.Endpoints[path] = &EndpointStats{
-			Path:        path,
-			StatusCodes: make(map[int]int64),
-		}
+	return ss.shardFor(path).endpoint(path)
+}
+
+// PathKey returns the key requests to a path other than a configured
+// endpoint, e.g. a static file or a 404, are counted under: the path itself
+// while fewer than maxTrackedPaths such paths are tracked, and EndpointOther
+// after that, so stray paths cannot grow the statistics without bound
+func (ss *ServerStats) PathKey(path string) string {
+	if _, tracked := ss.shardFor(path).endpointLimited(path, &ss.pathKeys); tracked {
+		return path
 	}
-	
-	return ss.Endpoints[path]
+	return EndpointOther
 }
 
 func (ss *ServerStats) RecordRequest(path string, duration time.Duration, statusCode int) {
-	ss.mutex.Lock()
-	ss.RequestCount++
+	atomic.AddInt64(&ss.RequestCount, 1)
 	if statusCode >= 400 {
-		ss.ErrorCount++
+		atomic.AddInt64(&ss.ErrorCount, 1)
 	}
-	ss.mutex.Unlock()
-	
+
 	endpointStats := ss.GetEndpointStats(path)
-	endpointStats.RecordRequest(duration, statusCode)
+	endpointStats.recordRequest(duration, statusCode, ss.GetSampleEvery())
+}
+
+// SetSampleEvery sets the detailed-stats sampling rate (1-in-N requests)
+func (ss *ServerStats) SetSampleEvery(n int) {
+	atomic.StoreInt64(&ss.sampleEvery, int64(n))
+}
+
+// GetSampleEvery returns the detailed-stats sampling rate
+func (ss *ServerStats) GetSampleEvery() int {
+	return int(atomic.LoadInt64(&ss.sampleEvery))
+}
+
+// RecordUserAgent counts a request under its normalized User-Agent. Once
+// maxTrackedUserAgents keys are tracked, new agents are counted as "other".
+func (ss *ServerStats) RecordUserAgent(userAgent string) {
+	ss.RecordUserAgentWeighted(userAgent, 1)
+}
+
+// RecordUserAgentWeighted counts a sampled request as weight requests
+func (ss *ServerStats) RecordUserAgentWeighted(userAgent string, weight int64) {
+	key := NormalizeUserAgent(userAgent)
+
+	if ss.shardFor(key).addUserAgent(key, weight, &ss.userAgentKeys) {
+		return
+	}
+	ss.shardFor(UserAgentOther).addUserAgent(UserAgentOther, weight, nil)
+}
+
+// Reset clears the request counters, endpoint statistics and User-Agent
+// counts. The start time and sampling rate are kept.
+func (ss *ServerStats) Reset() {
+	for i := range ss.shards {
+		shard := &ss.shards[i]
+		shard.mutex.Lock()
+		shard.endpoints = nil
+		shard.userAgents = nil
+		shard.mutex.Unlock()
+	}
+
+	atomic.StoreInt64(&ss.userAgentKeys, 0)
+	atomic.StoreInt64(&ss.pathKeys, 0)
+	atomic.StoreInt64(&ss.RequestCount, 0)
+	atomic.StoreInt64(&ss.ErrorCount, 0)
 }
 
-func (ss *ServerStats) GetAllStats() ServerStats {
-	ss.mutex.RLock()
-	defer ss.mutex.RUnlock()
-	
-	stats := ServerStats{
+// GetAllStats returns a point-in-time snapshot of all statistics
+func (ss *ServerStats) GetAllStats() *ServerStats {
+	stats := &ServerStats{
 		StartTime:    ss.StartTime,
-		RequestCount: ss.RequestCount,
-		ErrorCount:   ss.ErrorCount,
+		RequestCount: atomic.LoadInt64(&ss.RequestCount),
+		ErrorCount:   atomic.LoadInt64(&ss.ErrorCount),
 		Endpoints:    make(map[string]*EndpointStats),
+		UserAgents:   make(map[string]int64),
+		Process:      CollectProcessStats(),
+		SampleEvery:  ss.GetSampleEvery(),
 	}
-	
-	for path, endpointStats := range ss.Endpoints {
-		endpointStatsCopy := endpointStats.GetStats()
-		stats.Endpoints[path] = &endpointStatsCopy
+
+	for i := range ss.shards {
+		shard := &ss.shards[i]
+		shard.mutex.RLock()
+		for path, endpointStats := range shard.endpoints {
+			stats.Endpoints[path] = endpointStats.GetStats()
+		}
+		for agent, count := range shard.userAgents {
+			stats.UserAgents[agent] = atomic.LoadInt64(count)
+		}
+		shard.mutex.RUnlock()
 	}
-	
+
 	return stats
-} 
\ No newline at end of file
+}
@@ -0,0 +1,65 @@
+package types
+
+import "strings"
+
+const (
+	// maxTrackedUserAgents bounds the number of distinct User-Agent keys kept
+	maxTrackedUserAgents = 50
+
+	// maxUserAgentLength bounds the length of a normalized User-Agent key
+	maxUserAgentLength = 64
+
+	// UserAgentOther collects requests once the User-Agent map is full
+	UserAgentOther = "other"
+
+	// UserAgentUnknown is used for requests without a User-Agent header
+	UserAgentUnknown = "unknown"
+)
+
+// browserTokens lists browser product tokens in detection order; more
+// specific browsers come first since their UA strings also mention Chrome/Safari
+var browserTokens = []struct {
+	token string
+	name  string
+}{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"Version/", "Safari"},
+}
+
+// NormalizeUserAgent reduces a User-Agent header to a short, low-cardinality key.
+// Browser strings become "Name/major" (e.g. "Chrome/120"), while other clients
+// keep their leading product token (e.g. "curl/8.4.0", "okhttp/4.12.0") so SDK
+// versions remain visible.
+func NormalizeUserAgent(userAgent string) string {
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return UserAgentUnknown
+	}
+
+	if strings.HasPrefix(userAgent, "Mozilla/") {
+		for _, browser := range browserTokens {
+			idx := strings.Index(userAgent, browser.token)
+			if idx < 0 {
+				continue
+			}
+			version := userAgent[idx+len(browser.token):]
+			if end := strings.IndexAny(version, ". ;)"); end >= 0 {
+				version = version[:end]
+			}
+			return browser.name + "/" + version
+		}
+	}
+
+	// Keep the leading product token
+	key := userAgent
+	if end := strings.IndexAny(key, " ("); end > 0 {
+		key = key[:end]
+	}
+	if len(key) > maxUserAgentLength {
+		key = key[:maxUserAgentLength]
+	}
+	return key
+}
@@ -0,0 +1,158 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WebSocket topics clients can subscribe to
+const (
+	TopicStats      = "stats"
+	TopicConfig     = "config"
+	TopicRequestLog = "request_log"
+)
+
+// Message types sent by WebSocket clients
+const (
+	ClientSubscribe   = "subscribe"
+	ClientUnsubscribe = "unsubscribe"
+	ClientGetConfig   = "get_config"
+	ClientGetStats    = "get_stats"
+	ClientAuth        = "auth"
+	ClientResetStats  = "reset_stats"       // Requires write permission
+	ClientClearLog    = "clear_request_log" // Requires write permission
+)
+
+// Message types sent by the server, as TUIMessage.Type
+const (
+	MessageConfig        = "config"              // Data: Config
+	MessageConfigUpdated = "config_updated"      // Data: Config
+	MessageStats         = "stats"               // Data: ServerStats with every endpoint
+	MessageStatsUpdate   = "stats_update"        // Data: ServerStats with only endpoints changed since the last push
+	MessageRequestLog    = "request_log"         // Data: RequestLogEntry
+	MessageSubscribed    = "subscribed"          // Data: WSSubscription
+	MessageUnsubscribed  = "unsubscribed"        // Data: WSSubscription
+	MessageAuthenticated = "authenticated"       // Data: WSAuthResult
+	MessageDropped       = "dropped"             // Data: WSDropped
+	MessageStatsReset    = "stats_reset"         // Data: ServerStats after the reset; sent to every client
+	MessageLogCleared    = "request_log_cleared" // No data; sent to every client
+	MessageError         = "error"               // Data: WSError
+	MessageScenarios     = "scenarios"           // Data: ScenarioList; sent on the config topic as scenarios change endpoints
+	MessageConfigStatus  = "config_status"       // Data: ConfigStatus; sent on the config topic after every reload of the configuration files
+)
+
+const (
+	// DefaultStatsIntervalMs is the stats push interval used when none is requested
+	DefaultStatsIntervalMs = 1000
+
+	// MinStatsIntervalMs bounds how often stats can be pushed to a client
+	MinStatsIntervalMs = 250
+
+	// DefaultWSSendQueueSize is the per-connection send queue length used when none is configured
+	DefaultWSSendQueueSize = 256
+
+	// DefaultWSHeartbeatSeconds is the WebSocket ping interval used when none is configured
+	DefaultWSHeartbeatSeconds = 30
+)
+
+// WebSocket message encodings. Clients choose msgpack with the "msgpack"
+// subprotocol or ?encoding=msgpack; messages are then sent as binary frames.
+const (
+	WSEncodingJSON    = "json"
+	WSEncodingMsgpack = "msgpack"
+)
+
+// Policies for clients whose send queue is full
+const (
+	SlowClientDrop       = "drop"       // Drop request_log and stats_update messages (default)
+	SlowClientDisconnect = "disconnect" // Disconnect the client
+)
+
+// WSClientMessage represents a message sent by a WebSocket client
+type WSClientMessage struct {
+	Type       string            `json:"type"`
+	Topic      string            `json:"topic,omitempty"`
+	Filter     *RequestLogFilter `json:"filter,omitempty"`      // request_log: only push matching entries
+	Backlog    int               `json:"backlog,omitempty"`     // request_log: recent matching entries sent on subscribe
+	IntervalMs int               `json:"interval_ms,omitempty"` // stats: push interval
+	Token      string            `json:"token,omitempty"`       // auth: management API token
+}
+
+// RequestLogFilter selects the request log entries pushed to a subscriber.
+// All set fields must match.
+type RequestLogFilter struct {
+	Path          string   `json:"path,omitempty"`        // Path pattern, as in request_log_exclude
+	PathPrefix    string   `json:"path_prefix,omitempty"` // Literal path prefix, e.g. "/api/v2/"
+	Method        string   `json:"method,omitempty"`
+	MinStatus     int      `json:"min_status,omitempty"`
+	StatusClasses []string `json:"status_classes,omitempty"` // e.g. ["4xx", "5xx"]
+	Tag           string   `json:"tag,omitempty"`
+}
+
+// StatusClass returns the class of an HTTP status code, e.g. "5xx" for 503
+func StatusClass(statusCode int) string {
+	return fmt.Sprintf("%dxx", statusCode/100)
+}
+
+// ValidStatusClass reports whether class names an HTTP status class ("1xx" to "5xx")
+func ValidStatusClass(class string) bool {
+	return len(class) == 3 && class[0] >= '1' && class[0] <= '5' && strings.EqualFold(class[1:], "xx")
+}
+
+// WSSubscription acknowledges a subscribe or unsubscribe request
+type WSSubscription struct {
+	Topic      string            `json:"topic"`
+	Filter     *RequestLogFilter `json:"filter,omitempty"`
+	IntervalMs int               `json:"interval_ms,omitempty"`
+}
+
+// WSClientInfo describes a connected WebSocket client, as listed by GET /ws/clients
+type WSClientInfo struct {
+	RemoteAddr    string           `json:"remote_addr"`
+	ConnectedAt   time.Time        `json:"connected_at"`
+	Encoding      string           `json:"encoding"`
+	TokenName     string           `json:"token_name,omitempty"` // Management token the client authenticated with
+	Subscriptions []WSSubscription `json:"subscriptions"`
+}
+
+// WSClientList is the response of GET /ws/clients
+type WSClientList struct {
+	Count   int            `json:"count"`
+	Clients []WSClientInfo `json:"clients"`
+}
+
+// WSAuthResult acknowledges an auth message with the permissions granted to the connection
+type WSAuthResult struct {
+	Name        string   `json:"name,omitempty"` // Token name; empty when management auth is disabled
+	Permissions []string `json:"permissions"`
+}
+
+// WSDropped tells a slow client how many pushed messages it missed
+type WSDropped struct {
+	Count int64 `json:"count"`
+}
+
+// WSError reports a rejected client message
+type WSError struct {
+	Error string `json:"error"`
+}
+
+// MergeStatsUpdate applies the data of a stats_update message to the last full
+// statistics: totals come from the update, and endpoints it leaves out keep
+// their previous values. The update is modified and returned.
+func MergeStatsUpdate(current, update *ServerStats) *ServerStats {
+	if current == nil {
+		return update
+	}
+
+	endpoints := make(map[string]*EndpointStats, len(current.Endpoints)+len(update.Endpoints))
+	for path, endpoint := range current.Endpoints {
+		endpoints[path] = endpoint
+	}
+	for path, endpoint := range update.Endpoints {
+		endpoints[path] = endpoint
+	}
+	update.Endpoints = endpoints
+	return update
+}
@@ -0,0 +1,222 @@
+// Package webservertest runs the configurable web server inside Go tests, as
+// a replacement for hand-rolled httptest servers that also injects errors and
+// delays and records the requests it received:
+//
+//	func TestClientRetries(t *testing.T) {
+//		ts := webservertest.NewTestServer(t, nil)
+//		ts.AddError("/api/orders", http.StatusServiceUnavailable, "maintenance")
+//
+//		client := NewClient(ts.URL, WithRetries(3))
+//		if _, err := client.Orders(); err == nil {
+//			t.Fatal("want an error")
+//		}
+//		ts.AssertRequested(http.MethodGet, "/api/orders", 3)
+//	}
+package webservertest
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"webserver/internal/server"
+	"webserver/pkg/types"
+)
+
+// requestWait is how long assertions wait for a request still being
+// logged when its response reached the client first
+const requestWait = time.Second
+
+// Server is a web server listening on an ephemeral local port for the
+// duration of a test
+type Server struct {
+	URL string // Base URL of the server, e.g. http://127.0.0.1:54321
+
+	t   testing.TB
+	srv *server.Server
+}
+
+// NewTestServer starts a server with a configuration, or without endpoints
+// when it is nil. The configured host and port are ignored in favour of an
+// ephemeral port on 127.0.0.1, and a temporary static directory is used when
+// none is set. The server is stopped when the test ends; the test fails at
+// once if the configuration is invalid.
+func NewTestServer(t testing.TB, cfg *types.Config) *Server {
+	t.Helper()
+
+	dir := t.TempDir()
+	fileConfig := types.Config{}
+	if cfg != nil {
+		fileConfig = *cfg
+	}
+	if fileConfig.Server.Host == "" {
+		fileConfig.Server.Host = "127.0.0.1"
+	}
+	if fileConfig.Server.Port == 0 {
+		fileConfig.Server.Port = 8080
+	}
+	if fileConfig.Server.StaticDir == "" {
+		fileConfig.Server.StaticDir = filepath.Join(dir, "static")
+	}
+	if fileConfig.Endpoints == nil {
+		fileConfig.Endpoints = make(map[string]types.EndpointConfig)
+	}
+
+	// The server reads its configuration from a file, which also receives
+	// endpoints added during the test
+	data, err := json.MarshalIndent(fileConfig, "", "  ")
+	if err != nil {
+		t.Fatalf("webservertest: failed to encode configuration: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatalf("webservertest: failed to write configuration: %v", err)
+	}
+
+	srv, err := server.NewServer(configPath)
+	if err != nil {
+		t.Fatalf("webservertest: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("webservertest: failed to listen: %v", err)
+	}
+	if err := srv.StartListener(listener); err != nil {
+		listener.Close()
+		t.Fatalf("webservertest: failed to start server: %v", err)
+	}
+	t.Cleanup(func() { srv.Stop() })
+
+	return &Server{URL: "http://" + listener.Addr().String(), t: t, srv: srv}
+}
+
+// Config returns a copy of the current configuration
+func (s *Server) Config() *types.Config {
+	return s.srv.GetConfig()
+}
+
+// Stats returns the statistics of the requests served so far
+func (s *Server) Stats() *types.ServerStats {
+	return s.srv.GetStats()
+}
+
+// AddEndpoint adds or replaces an endpoint, failing the test if it is invalid
+func (s *Server) AddEndpoint(path string, endpoint types.EndpointConfig) {
+	s.t.Helper()
+	if err := s.srv.UpdateEndpoint(path, endpoint); err != nil {
+		s.t.Fatalf("webservertest: failed to add %s: %v", path, err)
+	}
+}
+
+// AddError adds an endpoint that always fails with a status code and
+// {"error": message}
+func (s *Server) AddError(path string, statusCode int, message string) {
+	s.t.Helper()
+	s.AddEndpoint(path, types.EndpointConfig{Type: "error", StatusCode: statusCode, Message: message})
+}
+
+// AddDelay adds an endpoint that answers 200 with a response after a delay
+func (s *Server) AddDelay(path string, delay time.Duration, response map[string]interface{}) {
+	s.t.Helper()
+	s.AddEndpoint(path, types.EndpointConfig{Type: "delay", DelayMs: int(delay.Milliseconds()), Response: response})
+}
+
+// AddFlaky adds an endpoint that fails with a status code on every
+// errorEveryN-th request and answers 200 with a response otherwise
+func (s *Server) AddFlaky(path string, statusCode, errorEveryN int, response map[string]interface{}) {
+	s.t.Helper()
+	s.AddEndpoint(path, types.EndpointConfig{
+		Type:            "conditional_error",
+		StatusCode:      statusCode,
+		ErrorEveryN:     errorEveryN,
+		SuccessResponse: response,
+	})
+}
+
+// Override answers the next count requests to a path, or path pattern, with
+// an endpoint instead of the configured one, failing the test if it is invalid
+func (s *Server) Override(path string, endpoint types.EndpointConfig, count int) {
+	s.t.Helper()
+	if _, err := s.srv.AddOverride(types.EndpointOverride{Path: path, Endpoint: endpoint, Count: count}); err != nil {
+		s.t.Fatalf("webservertest: failed to override %s: %v", path, err)
+	}
+}
+
+// FailNext makes the next count requests to a path fail with a status code,
+// after which the path is served as configured again
+func (s *Server) FailNext(path string, statusCode, count int) {
+	s.t.Helper()
+	s.Override(path, types.EndpointConfig{Type: "error", StatusCode: statusCode, Message: "injected failure"}, count)
+}
+
+// RemoveEndpoint removes an endpoint, failing the test if it does not exist
+func (s *Server) RemoveEndpoint(path string) {
+	s.t.Helper()
+	if _, exists := s.srv.GetConfig().Endpoints[path]; !exists {
+		s.t.Fatalf("webservertest: no endpoint %s to remove", path)
+	}
+	if err := s.srv.RemoveEndpoint(path); err != nil {
+		s.t.Fatalf("webservertest: failed to remove %s: %v", path, err)
+	}
+}
+
+// Reset forgets the requests received and the statistics, including the
+// counts conditional errors fail by
+func (s *Server) Reset() {
+	s.srv.ClearRequestLog()
+	s.srv.ResetStats()
+}
+
+// Requests returns the requests received so far, oldest first
+func (s *Server) Requests() []types.RequestLogEntry {
+	entries := s.srv.GetRequestLog()
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries
+}
+
+// RequestsTo returns the requests received for a path, ignoring the query,
+// oldest first; an empty method matches every method
+func (s *Server) RequestsTo(method, path string) []types.RequestLogEntry {
+	var matched []types.RequestLogEntry
+	for _, entry := range s.Requests() {
+		requestPath, _, _ := strings.Cut(entry.Path, "?")
+		if requestPath == path && (method == "" || strings.EqualFold(method, entry.Method)) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+// AssertRequested fails the test unless a path was requested exactly times
+// times with a method, or with any method when it is empty
+func (s *Server) AssertRequested(method, path string, times int) {
+	s.t.Helper()
+
+	// A response can reach the client just before its request is logged
+	deadline := time.Now().Add(requestWait)
+	got := len(s.RequestsTo(method, path))
+	for got < times && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		got = len(s.RequestsTo(method, path))
+	}
+
+	if got != times {
+		if method == "" {
+			method = "any method"
+		}
+		s.t.Errorf("webservertest: %s %s requested %d times, want %d", method, path, got, times)
+	}
+}
+
+// AssertNotRequested fails the test if a path was requested with a method,
+// or with any method when it is empty
+func (s *Server) AssertNotRequested(method, path string) {
+	s.t.Helper()
+	s.AssertRequested(method, path, 0)
+}
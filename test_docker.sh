@@ -20,7 +20,7 @@ echo "📦 Build Process:"
 echo "  1. Build stage: golang:1.24-alpine (with dependencies)"
 echo "  2. Runtime stage: alpine:latest (minimal footprint)"
 echo "  3. Non-root user: webserveruser (security best practice)"
-echo "  4. Health check: /stats endpoint monitoring"
+echo "  4. Health check: webserver healthcheck (GET /healthz)"
 echo ""
 
 echo "🚀 Available Commands:"
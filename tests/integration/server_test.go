@@ -3,13 +3,20 @@ package integration
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"webserver/internal/buildinfo"
 	"webserver/internal/server"
 	"webserver/pkg/types"
 
@@ -53,6 +60,32 @@ func TestServerIntegration(t *testing.T) {
 		assert.Greater(t, len(config.Endpoints), 0)
 	})
 
+	t.Run("GET /version", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/version")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var info types.VersionInfo
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+		assert.NotEmpty(t, info.Version)
+		assert.NotEmpty(t, info.GoVersion)
+		assert.Equal(t, buildinfo.ProtocolVersion, info.Protocol)
+	})
+
+	t.Run("GET /healthz", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/healthz")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var health map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&health))
+		assert.Equal(t, "ok", health["status"])
+	})
+
 	// Test statistics endpoint
 	t.Run("GET /stats", func(t *testing.T) {
 		resp, err := http.Get(baseURL + "/stats")
@@ -67,6 +100,9 @@ func TestServerIntegration(t *testing.T) {
 		require.NoError(t, err)
 
 		assert.False(t, stats.StartTime.IsZero())
+		require.NotNil(t, stats.Process)
+		assert.Greater(t, stats.Process.Goroutines, 0)
+		assert.Greater(t, stats.Process.HeapAllocBytes, uint64(0))
 	})
 
 	// Test dynamic endpoints
@@ -175,6 +211,76 @@ func TestServerIntegration(t *testing.T) {
 
 		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 	})
+
+	t.Run("Disabled endpoint", func(t *testing.T) {
+		setEndpoint := func(disabled bool) {
+			body, err := json.Marshal(map[string]interface{}{
+				"path":   "/api/toggled",
+				"config": map[string]interface{}{"type": "delay", "disabled": disabled},
+			})
+			require.NoError(t, err)
+			resp, err := http.Post(baseURL+"/config", "application/json", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		setEndpoint(false)
+		resp, err := http.Get(baseURL + "/api/toggled")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// Disabled endpoints fall through to static file serving
+		setEndpoint(true)
+		resp, err = http.Get(baseURL + "/api/toggled")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Configuration validation", func(t *testing.T) {
+		validate := func(body []byte) (int, types.ConfigValidation) {
+			resp, err := http.Post(baseURL+"/config/validate", "application/json", bytes.NewReader(body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			var result types.ConfigValidation
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+			return resp.StatusCode, result
+		}
+
+		resp, err := http.Get(baseURL + "/config")
+		require.NoError(t, err)
+		var config types.Config
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&config))
+		resp.Body.Close()
+
+		body, err := json.Marshal(config)
+		require.NoError(t, err)
+		status, result := validate(body)
+		assert.Equal(t, http.StatusOK, status)
+		assert.True(t, result.Valid)
+
+		config.Endpoints["/api/broken"] = types.EndpointConfig{Type: "error", StatusCode: 200}
+		body, err = json.Marshal(config)
+		require.NoError(t, err)
+		status, result = validate(body)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.False(t, result.Valid)
+		assert.Contains(t, result.Error, "invalid error status code")
+
+		status, result = validate([]byte(`{"server": `))
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, result.Error, "Invalid JSON")
+
+		// Validation never applies the configuration
+		resp, err = http.Get(baseURL + "/api/broken")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+	})
 }
 
 func TestServerConfigurationPersistence(t *testing.T) {
@@ -271,6 +377,429 @@ func TestServerConfigurationPersistence(t *testing.T) {
 	})
 }
 
+func TestServerStartListener(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+
+	// An ephemeral port, whatever the configuration says
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, srv.StartListener(listener))
+	defer srv.Stop()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/api/error")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	assert.Error(t, srv.Start(), "a running server cannot be started again")
+}
+
+func TestServerProbes(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+
+	cfg := srv.GetConfig()
+	cfg.Server.ShutdownDrainMs = 300
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+	require.NoError(t, srv.ReloadConfig())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, srv.StartListener(listener))
+	defer srv.Stop()
+	baseURL := "http://" + listener.Addr().String()
+
+	probe := func(path string) (int, string) {
+		resp, err := http.Get(baseURL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.StatusCode, body["reason"]
+	}
+
+	for _, path := range []string{"/livez", "/readyz", "/startupz", "/healthz"} {
+		status, _ := probe(path)
+		assert.Equal(t, http.StatusOK, status, path)
+	}
+
+	// A broken configuration keeps the previous one serving, but not ready
+	require.NoError(t, os.WriteFile(configPath, []byte("{"), 0644))
+	require.Error(t, srv.ReloadConfig())
+	status, reason := probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Contains(t, reason, "configuration reload failed")
+	status, _ = probe("/livez")
+	assert.Equal(t, http.StatusOK, status)
+
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+	require.NoError(t, srv.ReloadConfig())
+	status, _ = probe("/readyz")
+	assert.Equal(t, http.StatusOK, status)
+
+	// Readiness fails while the server drains, before the listener closes
+	stopped := make(chan error, 1)
+	go func() { stopped <- srv.Stop() }()
+	time.Sleep(100 * time.Millisecond)
+	status, reason = probe("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "shutting down", reason)
+	status, _ = probe("/livez")
+	assert.Equal(t, http.StatusOK, status)
+	require.NoError(t, <-stopped)
+}
+
+func TestServerProtobufEndpoint(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// A descriptor set for: syntax = "proto3"; package t; message Greeting { string text = 1; }
+	field := []byte{0x0a, 0x04, 't', 'e', 'x', 't', 0x18, 0x01, 0x20, 0x01, 0x28, 0x09}
+	message := append([]byte{0x0a, 0x08, 'G', 'r', 'e', 'e', 't', 'i', 'n', 'g', 0x12, byte(len(field))}, field...)
+	file := append([]byte{0x12, 0x01, 't', 0x22, byte(len(message))}, message...)
+	file = append(file, 0x62, 0x06, 'p', 'r', 'o', 't', 'o', '3')
+	descriptorPath := filepath.Join(tempDir, "greeting.desc")
+	require.NoError(t, os.WriteFile(descriptorPath, append([]byte{0x0a, byte(len(file))}, file...), 0644))
+
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/greeting": {
+				Type:     "delay",
+				Response: map[string]interface{}{"text": "hi"},
+				Protobuf: &types.ProtobufConfig{DescriptorSet: descriptorPath, Message: "t.Greeting"},
+			},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/greeting")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-protobuf", resp.Header.Get("Content-Type"))
+	assert.Equal(t, []byte{0x0a, 0x02, 'h', 'i'}, body)
+
+	// Clients asking for JSON get the same response as JSON
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/greeting", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/json")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var greeting map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&greeting))
+	assert.Equal(t, "hi", greeting["text"])
+}
+
+func TestServerContentNegotiation(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/user": {
+				Type:     "delay",
+				Response: map[string]interface{}{"name": "Ada & Co", "count": 3, "tags": []string{"a", "b"}},
+			},
+			"/down": {Type: "error", StatusCode: 503, Message: "maintenance"},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	get := func(path, accept string) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		require.NoError(t, err)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	for accept, want := range map[string]string{
+		"":                    "application/json",
+		"*/*":                 "application/json",
+		"text/html":           "application/json", // Nothing acceptable falls back to JSON
+		"application/xml":     "application/xml",
+		"text/*":              "text/xml",
+		"application/msgpack": "application/msgpack",
+		"text/html, application/xml;q=0.9, application/json;q=0.5": "application/xml",
+		"application/*;q=0.5, application/x-msgpack":               "application/x-msgpack",
+	} {
+		resp, _ := get("/user", accept)
+		assert.Equal(t, want, resp.Header.Get("Content-Type"), "Accept: %s", accept)
+		assert.Equal(t, "Accept", resp.Header.Get("Vary"))
+	}
+
+	_, body := get("/user", "application/xml")
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		"<response><count>3</count><name>Ada &amp; Co</name><tags>a</tags><tags>b</tags></response>\n", string(body))
+
+	_, body = get("/user", "application/msgpack")
+	decoded, err := types.UnmarshalMsgpack(body)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "Ada & Co", "count": int64(3), "tags": []interface{}{"a", "b"}}, decoded)
+
+	resp, body := get("/down", "application/xml")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Contains(t, string(body), "<response><error>maintenance</error></response>")
+}
+
+func TestServerHandler(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+
+	// Mounted under a prefix of another mux, without starting the server
+	mux := http.NewServeMux()
+	mux.Handle("/mock/", http.StripPrefix("/mock", srv.Handler()))
+	mux.HandleFunc("/own", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/mock/api/error")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get(types.RequestIDHeader), "requests go through the logging middleware")
+
+	resp, err = http.Get(ts.URL + "/own")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+
+	assert.Equal(t, int64(1), srv.GetStats().RequestCount)
+	require.Len(t, srv.GetRequestLog(), 1)
+	assert.Equal(t, "/api/error", srv.GetRequestLog()[0].Path)
+	assert.False(t, srv.IsRunning())
+}
+
+func TestServerScenarios(t *testing.T) {
+	tempDir := t.TempDir()
+	scenarioPath := filepath.Join(tempDir, "outage.yaml")
+	require.NoError(t, os.WriteFile(scenarioPath, []byte(`
+name: outage
+steps:
+  - name: orders down
+    after: 50ms
+    set:
+      /api/orders: {type: error, status_code: 500, message: down}
+    remove: [/api/users]
+  - after: 10s
+    restore: ["*"]
+`), 0644))
+
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/orders": {Type: "delay", Response: map[string]interface{}{"orders": []interface{}{}}},
+			"/api/users":  {Type: "delay"},
+		},
+		Scenarios: []string{scenarioPath},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	defer srv.StopScenario()
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	status := func(path string) int {
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	request := func(method, query string) *http.Response {
+		req, err := http.NewRequest(method, ts.URL+"/scenarios"+query, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := request(http.MethodPost, "?name=missing")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = request(http.MethodPost, "?name=outage")
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = request(http.MethodPost, "?name=outage")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	// The first step applies after its delay, without touching the file
+	assert.Equal(t, http.StatusOK, status("/api/orders"))
+	assert.Eventually(t, func() bool { return status("/api/orders") == http.StatusInternalServerError },
+		2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusNotFound, status("/api/users"))
+	assert.Equal(t, "delay", srv.GetConfig().Endpoints["/api/orders"].Type)
+
+	resp = request(http.MethodGet, "")
+	var list types.ScenarioList
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	resp.Body.Close()
+	require.Len(t, list.Scenarios, 1)
+	assert.True(t, list.Scenarios[0].Running)
+	assert.Equal(t, 1, list.Scenarios[0].Step)
+	assert.Equal(t, "step 2", list.Scenarios[0].NextStep)
+	assert.Equal(t, []string{"/api/orders", "/api/users"}, list.Changed)
+
+	// Stopping restores the configured endpoints
+	resp = request(http.MethodDelete, "")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusOK, status("/api/orders"))
+	assert.Equal(t, http.StatusOK, status("/api/users"))
+	assert.False(t, srv.Scenarios().Scenarios[0].Running)
+	assert.Empty(t, srv.Scenarios().Changed)
+}
+
+func TestServerContractMode(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/users":
+			w.Write([]byte(`{"users": [{"id": 1, "name": "ada"}, {"id": 2, "name": "bob"}], "next": null}`))
+		case "/api/orders":
+			w.Write([]byte(`{"orders": [{"id": "A1", "total": "12.50"}]}`))
+		case "/api/down":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+	defer upstream.Close()
+
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{
+			Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static"),
+			Contract: &types.ContractConfig{Upstream: upstream.URL},
+		},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/users": {Type: "delay", Response: map[string]interface{}{
+				"users": []interface{}{map[string]interface{}{"id": 7, "name": "x"}},
+				"next":  nil,
+			}},
+			"/api/orders": {Type: "delay", Response: map[string]interface{}{
+				"orders": []interface{}{map[string]interface{}{"id": "A1", "total": 12.5}},
+			}},
+			"/api/down": {Type: "error", StatusCode: 503},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+	// Entries are logged once the response is written, so wait for them
+	mismatches := func(path string, times int) []string {
+		var entries []types.RequestLogEntry
+		require.Eventually(t, func() bool {
+			entries = entries[:0]
+			for _, entry := range srv.GetRequestLog() {
+				if entry.Path == path {
+					entries = append(entries, entry)
+				}
+			}
+			return len(entries) == times
+		}, time.Second, 5*time.Millisecond, "%s logged %d times", path, times)
+		return entries[0].ContractMismatches
+	}
+	apply := func() {
+		data, err := json.Marshal(cfg)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(configPath, data, 0644))
+		require.NoError(t, srv.ReloadConfig())
+	}
+
+	// Clients get the real backend's responses
+	status, body := get("/api/users")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `"bob"`)
+	assert.Empty(t, mismatches("/api/users", 1))
+
+	status, _ = get("/api/orders")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"orders[0].total: got string, want number"}, mismatches("/api/orders", 1))
+
+	status, _ = get("/api/down")
+	assert.Equal(t, http.StatusBadGateway, status)
+	assert.Equal(t, []string{"status 502, want 503"}, mismatches("/api/down", 1))
+
+	// Paths without an endpoint are proxied without a check
+	status, _ = get("/unknown")
+	assert.Equal(t, http.StatusTeapot, status)
+	assert.Empty(t, mismatches("/unknown", 1))
+
+	stats := srv.GetStats()
+	assert.Equal(t, int64(1), stats.Endpoints["/api/users"].ContractChecks)
+	assert.Equal(t, int64(0), stats.Endpoints["/api/users"].ContractMismatches)
+	assert.Equal(t, int64(1), stats.Endpoints["/api/orders"].ContractMismatches)
+	assert.Equal(t, int64(0), stats.Endpoints["/unknown"].ContractChecks)
+
+	// Exact matching compares values and reports extra fields
+	cfg.Server.Contract.Match = types.ContractMatchExact
+	cfg.Server.Contract.IgnoreFields = []string{"users.name"}
+	apply()
+	get("/api/users")
+	assert.Equal(t, []string{"users: 2 elements, want 1", "users[0].id: got 1, want 7"}, mismatches("/api/users", 2))
+
+	// Without contract settings the endpoints are mocked again
+	cfg.Server.Contract = nil
+	apply()
+	status, _ = get("/api/down")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+}
+
 func TestServerStatisticsTracking(t *testing.T) {
 	tempDir := t.TempDir()
 	configPath := filepath.Join(tempDir, "config.json")
@@ -316,4 +845,1328 @@ func TestServerStatisticsTracking(t *testing.T) {
 		assert.Greater(t, errorStats.ErrorCount, int64(0))
 		assert.Contains(t, errorStats.StatusCodes, 500)
 	})
+	t.Run("Top endpoints", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/stats/top?by=errors&n=1")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var top types.TopEndpointsResponse
+		err = json.NewDecoder(resp.Body).Decode(&top)
+		require.NoError(t, err)
+
+		assert.Equal(t, "errors", top.By)
+		require.Len(t, top.Endpoints, 1)
+		assert.Equal(t, "/api/error", top.Endpoints[0].Path)
+		assert.Equal(t, 1, top.Endpoints[0].Rank)
+
+		// Unknown ranking metric is rejected
+		resp, err = http.Get(baseURL + "/stats/top?by=bogus")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("Statistics time series", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/api/error")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		// Only complete seconds are reported
+		time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+
+		resp, err = http.Get(baseURL + "/stats/timeseries?window=10&step=2&path=/api/error")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var series types.TimeSeries
+		err = json.NewDecoder(resp.Body).Decode(&series)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, series.StepSeconds)
+		require.Len(t, series.Total, 5)
+		require.Contains(t, series.Endpoints, "/api/error")
+		assert.Len(t, series.Endpoints, 1)
+		last := series.Total[len(series.Total)-1]
+		assert.GreaterOrEqual(t, last.Requests, int64(1))
+		assert.Equal(t, last.Requests, last.Errors)
+
+		// Step must divide the window
+		resp, err = http.Get(baseURL + "/stats/timeseries?window=10&step=3")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("Statistics export", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/stats/export?format=csv")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/csv", resp.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(body), "path,request_count,error_count")
+		assert.Contains(t, string(body), "/api/error,")
+		assert.Contains(t, string(body), "500:")
+	})
+	t.Run("Request log NDJSON export", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/requestlog/export?format=ndjson")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+		decoder := json.NewDecoder(resp.Body)
+		var entries []types.RequestLogEntry
+		for decoder.More() {
+			var entry types.RequestLogEntry
+			require.NoError(t, decoder.Decode(&entry))
+			entries = append(entries, entry)
+		}
+
+		require.NotEmpty(t, entries)
+		assert.Equal(t, "/api/error", entries[0].Path)
+		for i := 1; i < len(entries); i++ {
+			assert.False(t, entries[i].Timestamp.Before(entries[i-1].Timestamp), "entries should be oldest first")
+		}
+	})
+	t.Run("Request log tag filter", func(t *testing.T) {
+		req, err := http.NewRequest("GET", baseURL+"/api/error", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Test-Tag", "suite-a")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		resp, err = http.Get(baseURL + "/requestlog?tag=suite-a")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var entries []types.RequestLogEntry
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&entries))
+		require.Len(t, entries, 1)
+		assert.Equal(t, "suite-a", entries[0].Tag)
+		assert.Equal(t, "/api/error", entries[0].Path)
+	})
+	t.Run("Request log and stats changes", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/requestlog")
+		require.NoError(t, err)
+		resp.Body.Close()
+		position := resp.Header.Get(types.RequestLogPositionHeader)
+		require.NotEmpty(t, position)
+
+		before := time.Now()
+		resp, err = http.Get(baseURL + "/api/error")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		resp, err = http.Get(baseURL + "/requestlog?since=" + position)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var entries []types.RequestLogEntry
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&entries))
+		// The first poll is logged once it has responded, so it comes next
+		require.Len(t, entries, 2)
+		assert.Equal(t, "/api/error", entries[0].Path)
+		assert.Equal(t, "/requestlog", entries[1].Path)
+
+		resp, err = http.Get(baseURL + "/stats?changed_since=" + before.Format(time.RFC3339Nano))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var stats types.ServerStats
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+		assert.Contains(t, stats.Endpoints, "/api/error")
+		assert.NotContains(t, stats.Endpoints, "/stats/top")
+
+		resp, err = http.Get(baseURL + "/stats?changed_since=yesterday")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("Dump state", func(t *testing.T) {
+		dir := t.TempDir()
+		paths, err := srv.DumpState(dir)
+		require.NoError(t, err)
+		require.Len(t, paths, 2)
+
+		data, err := os.ReadFile(paths[0])
+		require.NoError(t, err)
+		var stats types.ServerStats
+		require.NoError(t, json.Unmarshal(data, &stats))
+		assert.Greater(t, stats.RequestCount, int64(0))
+
+		data, err = os.ReadFile(paths[1])
+		require.NoError(t, err)
+		var entries []types.RequestLogEntry
+		require.NoError(t, json.Unmarshal(data, &entries))
+		assert.NotEmpty(t, entries)
+	})
+
+	t.Run("Reload configuration", func(t *testing.T) {
+		data, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		var cfg types.Config
+		require.NoError(t, json.Unmarshal(data, &cfg))
+		cfg.Endpoints["/api/reloaded"] = types.EndpointConfig{Type: "error", StatusCode: 418, Message: "reloaded"}
+		data, err = json.Marshal(cfg)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(configPath, data, 0644))
+		require.NoError(t, srv.ReloadConfig())
+
+		resp, err := http.Get(baseURL + "/api/reloaded")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+	})
+
+	t.Run("Request log entry by ID", func(t *testing.T) {
+		resp, err := http.Get(baseURL + "/api/error")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		id := resp.Header.Get("X-Request-ID")
+		require.NotEmpty(t, id)
+
+		resp, err = http.Get(baseURL + "/requestlog/" + id)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var entry types.RequestLogEntry
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&entry))
+		assert.Equal(t, id, fmt.Sprint(entry.ID))
+		assert.Equal(t, "/api/error", entry.Path)
+		assert.Equal(t, http.StatusInternalServerError, entry.StatusCode)
+	})
+	t.Run("Slow request flagging", func(t *testing.T) {
+		endpoint := map[string]interface{}{
+			"path":   "/api/slow",
+			"config": types.EndpointConfig{Type: "delay", DelayMs: 30, SlowThresholdMs: 10},
+		}
+		body, err := json.Marshal(endpoint)
+		require.NoError(t, err)
+
+		resp, err := http.Post(baseURL+"/config", "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp, err = http.Get(baseURL + "/api/slow")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		entry, found := srv.GetRequestLogEntry(parseRequestID(t, resp))
+		require.True(t, found)
+		assert.True(t, entry.Slow)
+
+		stats := srv.GetStats()
+		require.Contains(t, stats.Endpoints, "/api/slow")
+		assert.Equal(t, int64(1), stats.Endpoints["/api/slow"].SlowCount)
+	})
+	t.Run("Request log replay", func(t *testing.T) {
+		requestLog := srv.GetRequestLog()
+		var original types.RequestLogEntry
+		for _, entry := range requestLog {
+			if entry.Path == "/api/error" {
+				original = entry
+				break
+			}
+		}
+		require.NotZero(t, original.ID)
+
+		resp, err := http.Post(fmt.Sprintf("%s/requestlog/%d/replay", baseURL, original.ID), "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var result types.ReplayResult
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, original.ID, result.Original.ID)
+		assert.Equal(t, http.StatusInternalServerError, result.Response.StatusCode)
+
+		resp, err = http.Post(baseURL+"/requestlog/999999999/replay", "application/json", nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestServerRequestLogReplayTargets(t *testing.T) {
+	received := make(chan http.Header, 1)
+	staging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Clone()
+	}))
+	defer staging.Close()
+
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{
+			Host: "127.0.0.1", Port: 8086, StaticDir: filepath.Join(tempDir, "static"),
+			ReplayTargets: []string{staging.URL},
+		},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/items": {Type: "delay", Response: map[string]interface{}{"items": []interface{}{}}},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+	time.Sleep(100 * time.Millisecond)
+
+	baseURL := "http://127.0.0.1:8086"
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/api/items", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer user-secret")
+	req.Header.Set("Cookie", "session=abc")
+	req.Header.Set("X-Trace", "1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	id := parseRequestID(t, resp)
+
+	replay := func(target string) *http.Response {
+		resp, err := http.Post(fmt.Sprintf("%s/requestlog/%d/replay?target=%s", baseURL, id, url.QueryEscape(target)), "application/json", nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	t.Run("Targets outside the allowlist", func(t *testing.T) {
+		for _, target := range []string{"http://127.0.0.1:1", "https://" + strings.TrimPrefix(staging.URL, "http://"), "http://user@127.0.0.1:8086"} {
+			assert.Equal(t, http.StatusForbidden, replay(target).StatusCode, target)
+		}
+	})
+
+	t.Run("Cross-origin replay drops credentials", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, replay(staging.URL).StatusCode)
+		headers := <-received
+		assert.Empty(t, headers.Get("Authorization"))
+		assert.Empty(t, headers.Get("Cookie"))
+		assert.Equal(t, "1", headers.Get("X-Trace"))
+		assert.Equal(t, strconv.FormatUint(id, 10), headers.Get("X-Replayed-From"))
+	})
+
+	t.Run("Same-origin replay keeps credentials", func(t *testing.T) {
+		// The explicit and default forms of the server's own URL are the same origin
+		assert.Equal(t, http.StatusOK, replay("HTTP://127.0.0.1:8086/").StatusCode)
+
+		var replayed types.RequestLogEntry
+		for _, entry := range srv.GetRequestLog() {
+			if entry.Headers.Get("X-Replayed-From") == strconv.FormatUint(id, 10) {
+				replayed = entry
+				break
+			}
+		}
+		require.NotZero(t, replayed.ID)
+		assert.Equal(t, "session=abc", replayed.Headers.Get("Cookie"))
+		// Authorization was masked when first logged, and is replayed as stored
+		assert.Equal(t, types.RedactedValue, replayed.Headers.Get("Authorization"))
+	})
+}
+
+func TestServerAlerts(t *testing.T) {
+	notifications := make(chan types.AlertNotification, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification types.AlertNotification
+		if err := json.NewDecoder(r.Body).Decode(&notification); err == nil {
+			notifications <- notification
+		}
+	}))
+	defer webhook.Close()
+
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.json")
+
+	alertConfig := types.Config{
+		Server: types.ServerConfig{
+			Port:      8082,
+			Host:      "127.0.0.1",
+			StaticDir: "./static",
+		},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/payments": {Type: "error", StatusCode: 502, Message: "Upstream down"},
+			"/api/ok":       {Type: "delay", DelayMs: 0},
+		},
+		Alerts: []types.AlertRule{
+			{Name: "payments-5xx", Path: "/api/payments*", MinStatus: 500, WebhookURL: webhook.URL},
+		},
+	}
+
+	configData, err := json.MarshalIndent(alertConfig, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, configData, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	baseURL := "http://127.0.0.1:8082"
+
+	for _, path := range []string{"/api/ok", "/api/payments?order=1"} {
+		resp, err := http.Get(baseURL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	select {
+	case notification := <-notifications:
+		assert.Equal(t, "payments-5xx", notification.Rule)
+		assert.Equal(t, "/api/payments?order=1", notification.Entry.Path)
+		assert.Equal(t, http.StatusBadGateway, notification.Entry.StatusCode)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected an alert notification")
+	}
+
+	select {
+	case notification := <-notifications:
+		t.Fatalf("unexpected alert for %s", notification.Entry.Path)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// parseRequestID returns the request log ID from a response's X-Request-ID header
+func parseRequestID(t *testing.T, resp *http.Response) uint64 {
+	id, err := strconv.ParseUint(resp.Header.Get("X-Request-ID"), 10, 64)
+	require.NoError(t, err)
+	return id
+}
+
+func TestServerFuzzEndpoint(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/fuzz": {
+				Type:     "fuzz",
+				Response: map[string]interface{}{"id": 1, "name": "ada"},
+				Fuzz:     &types.FuzzConfig{Seed: 42, HugeStringBytes: 128},
+			},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	get := func(replay string) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/fuzz", nil)
+		require.NoError(t, err)
+		if replay != "" {
+			req.Header.Set("X-Fuzz-Case", replay)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	// Each request is the next case, and the headers say how to replay it
+	bodies := make(map[string][]byte)
+	for i := 1; i <= 5; i++ {
+		resp, body := get("")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "42", resp.Header.Get("X-Fuzz-Seed"))
+		assert.Equal(t, fmt.Sprint(i), resp.Header.Get("X-Fuzz-Case"))
+		assert.NotEmpty(t, resp.Header.Get("X-Fuzz-Mutation"))
+		bodies[resp.Header.Get("X-Fuzz-Case")] = body
+	}
+
+	resp, body := get("3")
+	assert.Equal(t, "3", resp.Header.Get("X-Fuzz-Case"))
+	assert.Equal(t, bodies["3"], body)
+
+	// Replays leave the sequence alone
+	resp, _ = get("")
+	assert.Equal(t, "6", resp.Header.Get("X-Fuzz-Case"))
+}
+
+func TestServerRequestDeadline(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/slow":    {Type: "delay", DelayMs: 500, Response: map[string]interface{}{"ok": true}},
+			"/api/slowest": {Type: "delay", DelayMs: 5000, Response: map[string]interface{}{"ok": true}},
+			"/api/error":   {Type: "error", StatusCode: 503, Message: "down"},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	request := func(path, header, value string) (int, time.Duration) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		require.NoError(t, err)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		start := time.Now()
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode, time.Since(start)
+	}
+
+	// A deadline shorter than the delay cuts it short with a 504
+	for _, deadline := range [][2]string{{"X-Request-Timeout", "50"}, {"X-Request-Timeout", "50ms"}, {"grpc-timeout", "50m"}} {
+		status, elapsed := request("/api/slow", deadline[0], deadline[1])
+		assert.Equal(t, http.StatusGatewayTimeout, status, deadline)
+		assert.Less(t, elapsed, 400*time.Millisecond, deadline)
+	}
+
+	// A long enough deadline, or none, waits for the delay
+	status, elapsed := request("/api/slow", "X-Request-Timeout", "2s")
+	assert.Equal(t, http.StatusOK, status)
+	assert.GreaterOrEqual(t, elapsed, 500*time.Millisecond)
+
+	status, _ = request("/api/error", "X-Request-Timeout", "0")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+
+	// A delay longer than the time left fails without waiting for the deadline
+	status, elapsed = request("/api/slowest", "X-Request-Timeout", "3s")
+	assert.Equal(t, http.StatusGatewayTimeout, status)
+	assert.Less(t, elapsed, time.Second)
+
+	// Malformed deadlines are refused, and counted like other answers
+	status, _ = request("/api/slow", "X-Request-Timeout", "soon")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = request("/api/slow", "grpc-timeout", "50x")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = request("/missing.txt", "X-Request-Timeout", "soon")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	resp, err := http.Get(ts.URL + "/stats")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var stats types.ServerStats
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+	require.NotNil(t, stats.Endpoints["/api/slow"])
+	assert.Equal(t, int64(2), stats.Endpoints["/api/slow"].StatusCodes[http.StatusBadRequest])
+	require.NotNil(t, stats.Endpoints["/missing.txt"])
+	assert.Equal(t, int64(1), stats.Endpoints["/missing.txt"].StatusCodes[http.StatusBadRequest])
+}
+
+func TestServerContractModeForwardsDeadline(t *testing.T) {
+	forwarded := make(chan string, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded <- r.Header.Get("X-Request-Timeout")
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer upstream.Close()
+
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{
+			Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static"),
+			Contract: &types.ContractConfig{Upstream: upstream.URL},
+		},
+		Endpoints: map[string]types.EndpointConfig{},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/anything", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Request-Timeout", "100")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// The upstream gets the budget left, and running out of it is a 504
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	remaining, err := strconv.Atoi(<-forwarded)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, remaining, 100)
+	assert.Greater(t, remaining, 0)
+}
+
+func TestServerRequestSchema(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/users": {
+				Type:     "delay",
+				Response: map[string]interface{}{"created": true},
+				RequestSchema: map[string]interface{}{
+					"type":     "object",
+					"required": []interface{}{"name"},
+					"properties": map[string]interface{}{
+						"name": map[string]interface{}{"type": "string"},
+						"age":  map[string]interface{}{"type": "integer", "minimum": 0},
+					},
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	send := func(method, body string) (int, map[string]interface{}) {
+		req, err := http.NewRequest(method, ts.URL+"/api/users", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var decoded map[string]interface{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+		return resp.StatusCode, decoded
+	}
+
+	status, body := send(http.MethodPost, `{"name": "ada", "age": 36}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, true, body["created"])
+
+	status, body = send(http.MethodPost, `{"name": 7, "age": -1}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "Request body does not match the schema", body["error"])
+	assert.Equal(t, []interface{}{"age: -1 is less than the minimum 0", "name: got integer, want string"}, body["violations"])
+
+	status, body = send(http.MethodPost, `{"name": `)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body["violations"].([]interface{})[0], "body: not JSON")
+
+	status, body = send(http.MethodPut, ``)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []interface{}{"body: missing"}, body["violations"])
+
+	// Requests that do not send bodies are served
+	status, _ = send(http.MethodGet, ``)
+	assert.Equal(t, http.StatusOK, status)
+
+	stats := srv.GetStats().Endpoints["/api/users"]
+	require.NotNil(t, stats)
+	assert.Equal(t, int64(3), stats.StatusCodes[http.StatusBadRequest])
+
+	// A changed schema applies at once instead of the one compiled before
+	endpoint := srv.GetConfig().Endpoints["/api/users"]
+	endpoint.RequestSchema = map[string]interface{}{"type": "object", "required": []interface{}{"email"}}
+	require.NoError(t, srv.UpdateEndpoint("/api/users", endpoint))
+	status, body = send(http.MethodPost, `{"name": "ada"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []interface{}{"email: required property is missing"}, body["violations"])
+}
+
+func TestServerRequiresPriorCall(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/login":  {Type: "delay", Response: map[string]interface{}{"token": "abc"}},
+			"/api/broken": {Type: "error", StatusCode: 500},
+			"/api/items": {
+				Type:     "delay",
+				Response: map[string]interface{}{"items": []interface{}{}},
+				Requires: &types.RequiresConfig{Path: "/api/login"},
+			},
+			"/api/orders": {
+				Type:     "delay",
+				Response: map[string]interface{}{"orders": []interface{}{}},
+				Requires: &types.RequiresConfig{Path: "/api/broken", StatusCode: 428, Message: "Not yet"},
+			},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	call := func(method, path, session string) (int, map[string]interface{}) {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.NoError(t, err)
+		if session != "" {
+			req.Header.Set(types.SessionHeader, session)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var decoded map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&decoded)
+		return resp.StatusCode, decoded
+	}
+
+	status, body := call(http.MethodGet, "/api/items", "alice")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "Call /api/login first", body["error"])
+
+	status, _ = call(http.MethodPost, "/api/login", "alice")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = call(http.MethodGet, "/api/items", "alice")
+	assert.Equal(t, http.StatusOK, status)
+
+	// Sessions are independent, and callers without one are told apart by IP
+	status, _ = call(http.MethodGet, "/api/items", "bob")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = call(http.MethodGet, "/api/items", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// Failed calls do not count
+	status, _ = call(http.MethodGet, "/api/broken", "alice")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	status, body = call(http.MethodGet, "/api/orders", "alice")
+	assert.Equal(t, 428, status)
+	assert.Equal(t, "Not yet", body["error"])
+
+	resp, err := http.Get(ts.URL + "/sessions?session=alice")
+	require.NoError(t, err)
+	var sessions []types.SessionState
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&sessions))
+	resp.Body.Close()
+	require.Len(t, sessions, 1)
+	assert.Contains(t, sessions[0].Calls, "/api/login")
+	assert.NotContains(t, sessions[0].Calls, "/api/broken")
+
+	// Only calls that some endpoint requires are remembered
+	assert.NotContains(t, sessions[0].Calls, "/api/items")
+
+	// Forgetting a session makes it start over
+	req, err := http.NewRequest(http.MethodDelete, ts.URL+"/sessions?session=alice", nil)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	status, _ = call(http.MethodGet, "/api/items", "alice")
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestServerSessionScopedCounters(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{
+			Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static"),
+			Sessions: &types.SessionConfig{Cookie: "mock_session", Header: "X-Test-Session"},
+		},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/flaky":  {Type: "conditional_error", ErrorEveryN: 2, StatusCode: 503, PerSession: true},
+			"/api/shared": {Type: "conditional_error", ErrorEveryN: 2, StatusCode: 503},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	call := func(path, session string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		require.NoError(t, err)
+		if session != "" {
+			req.Header.Set("X-Test-Session", session)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	// Each session fails its own second request
+	assert.Equal(t, http.StatusOK, call("/api/flaky", "alice").StatusCode)
+	assert.Equal(t, http.StatusOK, call("/api/flaky", "bob").StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, call("/api/flaky", "alice").StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, call("/api/flaky", "bob").StatusCode)
+
+	// Endpoints without per_session count all callers together
+	assert.Equal(t, http.StatusOK, call("/api/shared", "alice").StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, call("/api/shared", "bob").StatusCode)
+
+	// Callers without a session are handed a cookie, and keep their count with it
+	resp := call("/api/flaky", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var cookie *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == "mock_session" {
+			cookie = c
+		}
+	}
+	require.NotNil(t, cookie)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/flaky", nil)
+	require.NoError(t, err)
+	req.AddCookie(cookie)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Empty(t, resp.Cookies())
+
+	resp, err = http.Get(ts.URL + "/sessions?session=alice")
+	require.NoError(t, err)
+	var sessions []types.SessionState
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&sessions))
+	resp.Body.Close()
+	require.Len(t, sessions, 1)
+	assert.Equal(t, int64(2), sessions[0].Counters["/api/flaky"])
+	assert.NotContains(t, sessions[0].Counters, "/api/shared")
+}
+
+func TestServerProxyEndpoint(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/api/users":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Upstream", "yes")
+			w.WriteHeader(http.StatusCreated)
+			body, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, `{"method": %q, "query": %q, "body": %q, "forwarded_for": %q}`, r.Method, r.URL.RawQuery, body, r.Header.Get("X-Forwarded-For"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer upstream.Close()
+
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/users": {Type: "proxy", TargetURL: upstream.URL + "/v2"},
+			"/api/down":  {Type: "proxy", TargetURL: "http://127.0.0.1:1"},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/api/users?page=2", "application/json", bytes.NewBufferString(`{"name": "ada"}`))
+	require.NoError(t, err)
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "yes", resp.Header.Get("X-Upstream"))
+	assert.Equal(t, map[string]string{"method": "POST", "query": "page=2", "body": `{"name": "ada"}`, "forwarded_for": "127.0.0.1"}, body)
+
+	resp, err = http.Get(ts.URL + "/api/down")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+
+	// Proxied traffic shows up in the statistics and the request log
+	stats := srv.GetStats().Endpoints
+	require.NotNil(t, stats["/api/users"])
+	assert.Equal(t, int64(1), stats["/api/users"].StatusCodes[http.StatusCreated])
+	require.NotNil(t, stats["/api/down"])
+	assert.Equal(t, int64(1), stats["/api/down"].StatusCodes[http.StatusBadGateway])
+
+	require.Eventually(t, func() bool {
+		for _, entry := range srv.GetRequestLog() {
+			if entry.Path == "/api/users?page=2" && entry.StatusCode == http.StatusCreated && entry.Body == `{"name": "ada"}` {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestServerEndpointOverrides(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/orders": {Type: "delay", Response: map[string]interface{}{"orders": []interface{}{}}},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	addOverride := func(body string) (int, types.EndpointOverride) {
+		resp, err := http.Post(ts.URL+"/config/overrides", "application/json", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var override types.EndpointOverride
+		json.NewDecoder(resp.Body).Decode(&override)
+		return resp.StatusCode, override
+	}
+	status := func(method, path string) int {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// The next two POSTs fail, then the configured endpoint answers again
+	code, override := addOverride(`{"path": "/api/orders", "method": "post", "count": 2, "endpoint": {"type": "error", "status_code": 503}}`)
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "POST", override.Method)
+	assert.Equal(t, 2, override.Remaining)
+
+	assert.Equal(t, http.StatusOK, status(http.MethodGet, "/api/orders"))
+	assert.Equal(t, http.StatusServiceUnavailable, status(http.MethodPost, "/api/orders"))
+	assert.Len(t, srv.Overrides(), 1)
+	assert.Equal(t, 1, srv.Overrides()[0].Remaining)
+	assert.Equal(t, http.StatusServiceUnavailable, status(http.MethodPost, "/api/orders"))
+	assert.Empty(t, srv.Overrides())
+	assert.Equal(t, http.StatusOK, status(http.MethodPost, "/api/orders"))
+
+	// Overrides with a TTL apply to any number of requests, even to paths
+	// that are not configured, until they expire
+	code, _ = addOverride(`{"path": "/api/*", "ttl": "150ms", "endpoint": {"type": "error", "status_code": 429}}`)
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, http.StatusTooManyRequests, status(http.MethodGet, "/api/orders"))
+	assert.Equal(t, http.StatusTooManyRequests, status(http.MethodGet, "/api/anything"))
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, status(http.MethodGet, "/api/orders"))
+	assert.Empty(t, srv.Overrides())
+
+	// Overrides that would never expire, or are invalid, are rejected
+	for _, body := range []string{
+		`{"path": "/api/orders", "endpoint": {"type": "error", "status_code": 500}}`,
+		`{"path": "/api/orders", "count": 1, "endpoint": {"type": "error", "status_code": 42}}`,
+		`{"path": "/api/orders", "ttl": "soon", "endpoint": {"type": "error", "status_code": 500}}`,
+		`{"path": "[", "count": 1, "endpoint": {"type": "error", "status_code": 500}}`,
+	} {
+		code, _ := addOverride(body)
+		assert.Equal(t, http.StatusBadRequest, code, body)
+	}
+
+	// Overrides can be removed before they expire
+	_, override = addOverride(`{"path": "/api/orders", "count": 5, "endpoint": {"type": "error", "status_code": 500}}`)
+	req, err := http.NewRequest(http.MethodDelete, ts.URL+"/config/overrides?id="+override.ID, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusOK, status(http.MethodGet, "/api/orders"))
+}
+
+func TestServerDelayProfile(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/degrading": {
+				Type:         "delay",
+				DelayProfile: &types.DelayProfile{Shape: types.DelayProfileLinear, FromMs: 0, ToMs: 300, Duration: "300ms"},
+			},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	timed := func() time.Duration {
+		start := time.Now()
+		resp, err := http.Get(ts.URL + "/api/degrading")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		return time.Since(start)
+	}
+
+	// The ramp starts with the first request and holds at its end
+	assert.Less(t, timed(), 150*time.Millisecond)
+	time.Sleep(300 * time.Millisecond)
+	assert.GreaterOrEqual(t, timed(), 300*time.Millisecond)
+
+	t.Run("Endpoints sharing a path", func(t *testing.T) {
+		require.NoError(t, srv.UpdateEndpoint("/api/shared", types.EndpointConfig{
+			Type:         "delay",
+			Methods:      []string{"GET"},
+			DelayProfile: &types.DelayProfile{Shape: types.DelayProfileLinear, FromMs: 0, ToMs: 300, Duration: "300ms"},
+		}))
+		require.NoError(t, srv.UpdateEndpoint("/api/shared#create", types.EndpointConfig{
+			Type:         "delay",
+			Methods:      []string{"POST"},
+			DelayProfile: &types.DelayProfile{Shape: types.DelayProfileLinear, FromMs: 0, ToMs: 10, Duration: "1h"},
+		}))
+
+		timedMethod := func(method string) time.Duration {
+			req, err := http.NewRequest(method, ts.URL+"/api/shared", nil)
+			require.NoError(t, err)
+			start := time.Now()
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			return time.Since(start)
+		}
+
+		// Each endpoint follows its own ramp, whatever the other is doing
+		assert.Less(t, timedMethod(http.MethodGet), 150*time.Millisecond)
+		time.Sleep(300 * time.Millisecond)
+		timedMethod(http.MethodPost)
+		assert.GreaterOrEqual(t, timedMethod(http.MethodGet), 300*time.Millisecond)
+	})
+}
+
+func TestServerHTTPMethods(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/users":        {Type: "delay", Methods: []string{"GET"}, Response: map[string]interface{}{"users": []interface{}{}}},
+			"/api/users#create": {Type: "delay", Methods: []string{"POST", "PUT"}, Response: map[string]interface{}{"id": 42}},
+			"/api/items":        {Type: "error", StatusCode: 503, Message: "Down"},
+			"/api/items#create": {Type: "error", Methods: []string{"POST"}, StatusCode: 409, Message: "Exists"},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	request := func(method, path string) (*http.Response, string) {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp, string(body)
+	}
+
+	resp, body := request(http.MethodGet, "/api/users")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"users": []}`, body)
+
+	resp, _ = request(http.MethodHead, "/api/users")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		resp, body = request(method, "/api/users")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, method)
+		assert.JSONEq(t, `{"id": 42}`, body, method)
+	}
+
+	// Methods no endpoint of the path accepts are refused
+	resp, body = request(http.MethodDelete, "/api/users")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, "GET, POST, PUT", resp.Header.Get("Allow"))
+	assert.JSONEq(t, `{"error": "Method not allowed"}`, body)
+
+	// An endpoint without methods answers the ones others do not list
+	resp, _ = request(http.MethodPost, "/api/items")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	resp, _ = request(http.MethodDelete, "/api/items")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	// Requests are counted against the endpoint that answered, and refused
+	// ones against the path
+	stats := srv.GetStats().Endpoints
+	require.NotNil(t, stats["/api/users"])
+	require.NotNil(t, stats["/api/users#create"])
+	assert.Equal(t, int64(3), stats["/api/users"].RequestCount)
+	assert.Equal(t, int64(2), stats["/api/users#create"].RequestCount)
+}
+
+func TestServerRedirectEndpoint(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/old":       {Type: "redirect", Location: "/moved", StatusCode: 301},
+			"/moved":     {Type: "redirect", Location: "/api/users"},
+			"/api/users": {Type: "delay", Response: map[string]interface{}{"users": []interface{}{}}},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	noFollow := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noFollow.Get(ts.URL + "/old")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+	assert.Equal(t, "/moved", resp.Header.Get("Location"))
+	assert.JSONEq(t, `{"location": "/moved"}`, string(body))
+
+	resp, err = noFollow.Get(ts.URL + "/moved")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+
+	// Clients following the chain end up at the final endpoint
+	resp, err = http.Get(ts.URL + "/old")
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"users": []}`, string(body))
+
+	stats := srv.GetStats().Endpoints
+	require.NotNil(t, stats["/old"])
+	assert.Equal(t, int64(2), stats["/old"].RequestCount)
+	assert.Equal(t, int64(2), stats["/moved"].RequestCount)
+}
+
+func TestServerEndpointGroups(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/payments": {Type: "delay", Group: "payments", Response: map[string]interface{}{"status": "paid"}},
+			"/api/refunds":  {Type: "delay", Group: "payments", Response: map[string]interface{}{"status": "refunded"}},
+			"/api/users":    {Type: "delay", Response: map[string]interface{}{"users": []interface{}{}}},
+		},
+		ChaosProfiles: map[string]types.ChaosProfile{
+			"down": {ErrorRate: 1, StatusCode: 502, Message: "Payment service unavailable"},
+			"slow": {LatencyMs: 100},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(tempDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	send := func(method, path string) (*http.Response, string) {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp, string(body)
+	}
+	status := func(path string) int {
+		resp, _ := send(http.MethodGet, path)
+		return resp.StatusCode
+	}
+
+	// Disabling a group disables all of its endpoints and nothing else
+	resp, _ := send(http.MethodPost, "/groups?group=payments&action=disable")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusNotFound, status("/api/payments"))
+	assert.Equal(t, http.StatusNotFound, status("/api/refunds"))
+	assert.Equal(t, http.StatusOK, status("/api/users"))
+
+	resp, body := send(http.MethodGet, "/groups")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var groups []types.EndpointGroup
+	require.NoError(t, json.Unmarshal([]byte(body), &groups))
+	assert.Equal(t, []types.EndpointGroup{{Name: "payments", Endpoints: []string{"/api/payments", "/api/refunds"}, Disabled: 2}}, groups)
+
+	resp, _ = send(http.MethodPost, "/groups?group=payments&action=enable")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusOK, status("/api/payments"))
+
+	// A chaos profile breaks every endpoint of the group until cleared
+	resp, _ = send(http.MethodPost, "/groups?group=payments&action=chaos&profile=down")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, body = send(http.MethodGet, "/api/refunds")
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.JSONEq(t, `{"error": "Payment service unavailable"}`, body)
+	assert.Equal(t, http.StatusBadGateway, status("/api/payments"))
+	assert.Equal(t, http.StatusOK, status("/api/users"))
+
+	resp, _ = send(http.MethodPost, "/groups?group=payments&action=chaos&profile=slow")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	start := time.Now()
+	assert.Equal(t, http.StatusOK, status("/api/payments"))
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	resp, _ = send(http.MethodDelete, "/groups?group=payments")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, srv.Groups()[0].Chaos)
+
+	// Unknown groups, profiles and actions are refused
+	resp, _ = send(http.MethodPost, "/groups?group=shipping&action=disable")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = send(http.MethodPost, "/groups?group=shipping&action=chaos&profile=down")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = send(http.MethodPost, "/groups?group=payments&action=chaos&profile=missing")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = send(http.MethodPost, "/groups?group=payments&action=explode")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Enabling a group whose endpoints clash with enabled ones is refused
+	// with the reason, and leaves the group disabled
+	require.NoError(t, srv.UpdateEndpoint("/api/users#legacy", types.EndpointConfig{Type: "error", StatusCode: 410, Group: "legacy", Disabled: true}))
+	resp, body = send(http.MethodPost, "/groups?group=legacy&action=enable")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, body, "both answer every method")
+	assert.True(t, srv.GetConfig().Endpoints["/api/users#legacy"].Disabled)
+	assert.Equal(t, http.StatusOK, status("/api/users"))
+}
+
+func TestServerHotReloadRollback(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.json")
+	cfg := types.Config{
+		Server: types.ServerConfig{Host: "127.0.0.1", Port: 8080, StaticDir: filepath.Join(tempDir, "static")},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/users": {Type: "error", StatusCode: 500, Message: "original"},
+		},
+	}
+	write := func(cfg types.Config) {
+		data, err := json.Marshal(cfg)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(configPath, data, 0644))
+	}
+	write(cfg)
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, srv.StartListener(listener))
+	defer srv.Stop()
+	baseURL := "http://" + listener.Addr().String()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(baseURL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+	configStatus := func() types.ConfigStatus {
+		status, body := get("/config/status")
+		require.Equal(t, http.StatusOK, status)
+		var configStatus types.ConfigStatus
+		require.NoError(t, json.Unmarshal([]byte(body), &configStatus))
+		return configStatus
+	}
+
+	initial := configStatus()
+	assert.Empty(t, initial.Error)
+	assert.Equal(t, []string{configPath}, initial.Files)
+
+	// A valid edit is applied to the endpoints and to the server's own
+	// settings, here the request log exclusions
+	cfg.Server.RequestLogExclude = []string{"/api/hidden"}
+	cfg.Endpoints["/api/users"] = types.EndpointConfig{Type: "error", StatusCode: 503, Message: "edited"}
+	write(cfg)
+	require.Eventually(t, func() bool {
+		status, _ := get("/api/users")
+		return status == http.StatusServiceUnavailable
+	}, 5*time.Second, 50*time.Millisecond)
+	get("/api/hidden")
+	get("/api/users?after=hidden")
+	require.Eventually(t, func() bool {
+		for _, entry := range srv.GetRequestLog() {
+			if entry.Path == "/api/users?after=hidden" {
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+	for _, entry := range srv.GetRequestLog() {
+		assert.NotEqual(t, "/api/hidden", entry.Path)
+	}
+
+	// An invalid edit is rejected and the previous configuration keeps serving
+	time.Sleep(600 * time.Millisecond) // The watcher ignores changes right after a reload
+	broken := cfg
+	broken.Endpoints = map[string]types.EndpointConfig{"/api/users": {Type: "teapot"}}
+	write(broken)
+	require.Eventually(t, func() bool { return configStatus().Error != "" }, 5*time.Second, 50*time.Millisecond)
+
+	failed := configStatus()
+	assert.Contains(t, failed.Error, "teapot")
+	require.NotNil(t, failed.FailedAt)
+	status, body := get("/api/users")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.JSONEq(t, `{"error": "edited"}`, body)
+	assert.Equal(t, "error", srv.GetConfig().Endpoints["/api/users"].Type)
+
+	// Fixing the file reloads it and clears the error
+	time.Sleep(600 * time.Millisecond)
+	cfg.Endpoints["/api/users"] = types.EndpointConfig{Type: "error", StatusCode: 418, Message: "fixed"}
+	write(cfg)
+	require.Eventually(t, func() bool { return configStatus().Error == "" }, 5*time.Second, 50*time.Millisecond)
+	status, _ = get("/api/users")
+	assert.Equal(t, http.StatusTeapot, status)
 }
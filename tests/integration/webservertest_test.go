@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"webserver/pkg/types"
+	"webserver/pkg/webservertest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebServerTest(t *testing.T) {
+	ts := webservertest.NewTestServer(t, &types.Config{
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/users": {Type: "delay", Response: map[string]interface{}{"users": []interface{}{"ada"}}},
+		},
+	})
+
+	get := func(path string) (int, map[string]interface{}) {
+		resp, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	status, body := get("/api/users?page=2")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []interface{}{"ada"}, body["users"])
+
+	ts.AddError("/api/orders", http.StatusServiceUnavailable, "maintenance")
+	status, body = get("/api/orders")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "maintenance", body["error"])
+
+	ts.AddFlaky("/api/flaky", http.StatusInternalServerError, 2, map[string]interface{}{"ok": true})
+	first, _ := get("/api/flaky")
+	second, _ := get("/api/flaky")
+	assert.Equal(t, []int{http.StatusOK, http.StatusInternalServerError}, []int{first, second})
+
+	ts.AddDelay("/api/slow", 50*time.Millisecond, nil)
+	start := time.Now()
+	status, _ = get("/api/slow")
+	assert.Equal(t, http.StatusOK, status)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	ts.AssertRequested(http.MethodGet, "/api/users", 1)
+	ts.AssertRequested("", "/api/flaky", 2)
+	ts.AssertNotRequested(http.MethodPost, "/api/orders")
+	require.Len(t, ts.RequestsTo("", "/api/users"), 1)
+	assert.Equal(t, "/api/users?page=2", ts.RequestsTo("", "/api/users")[0].Path)
+	assert.Equal(t, "/api/users?page=2", ts.Requests()[0].Path)
+
+	ts.RemoveEndpoint("/api/orders")
+	status, _ = get("/api/orders")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// Reset starts conditional errors over
+	ts.Reset()
+	assert.Empty(t, ts.Requests())
+	first, _ = get("/api/flaky")
+	assert.Equal(t, http.StatusOK, first)
+}
+
+func TestWebServerTestFailNext(t *testing.T) {
+	ts := webservertest.NewTestServer(t, &types.Config{
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/users": {Type: "delay", Response: map[string]interface{}{"users": []interface{}{}}},
+		},
+	})
+
+	ts.FailNext("/api/users", http.StatusServiceUnavailable, 2)
+	for _, want := range []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK} {
+		resp, err := http.Get(ts.URL + "/api/users")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, want, resp.StatusCode)
+	}
+}
+
+func TestWebServerTestReplay(t *testing.T) {
+	// The configuration says port 8080, but the server listens on an
+	// ephemeral port, which replays without a target must reach
+	ts := webservertest.NewTestServer(t, &types.Config{
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/users": {Type: "delay", Response: map[string]interface{}{"users": []interface{}{"ada"}}},
+		},
+	})
+
+	resp, err := http.Get(ts.URL + "/api/users")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Len(t, ts.RequestsTo(http.MethodGet, "/api/users"), 1)
+	id := ts.RequestsTo(http.MethodGet, "/api/users")[0].ID
+
+	resp, err = http.Post(fmt.Sprintf("%s/requestlog/%d/replay", ts.URL, id), "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result types.ReplayResult
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, ts.URL, result.Target)
+	assert.Equal(t, http.StatusOK, result.Response.StatusCode)
+	assert.Contains(t, result.Response.Body, "ada")
+	ts.AssertRequested(http.MethodGet, "/api/users", 2)
+}
@@ -0,0 +1,538 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"webserver/internal/server"
+	"webserver/pkg/types"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wsMessage mirrors types.TUIMessage with the payload left undecoded
+type wsMessage struct {
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// readUntil reads WebSocket messages until one of the given type arrives
+func readUntil(t *testing.T, conn *websocket.Conn, messageType string) wsMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	for {
+		var message wsMessage
+		require.NoError(t, conn.ReadJSON(&message))
+		if message.Type == messageType {
+			return message
+		}
+	}
+}
+
+func TestWebSocketSubscriptions(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.json")
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	baseURL := "http://localhost:8080"
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://localhost:8080/ws", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	t.Run("Initial data", func(t *testing.T) {
+		readUntil(t, conn, types.MessageConfig)
+		readUntil(t, conn, types.MessageStats)
+	})
+
+	t.Run("Request log subscription with filter", func(t *testing.T) {
+		require.NoError(t, conn.WriteJSON(types.WSClientMessage{
+			Type:   types.ClientSubscribe,
+			Topic:  types.TopicRequestLog,
+			Filter: &types.RequestLogFilter{Path: "/api/error", MinStatus: 500},
+		}))
+
+		var ack types.WSSubscription
+		require.NoError(t, json.Unmarshal(readUntil(t, conn, types.MessageSubscribed).Data, &ack))
+		assert.Equal(t, types.TopicRequestLog, ack.Topic)
+
+		for _, path := range []string{"/stats", "/api/error"} {
+			resp, err := http.Get(baseURL + path)
+			require.NoError(t, err)
+			resp.Body.Close()
+		}
+
+		var entry types.RequestLogEntry
+		require.NoError(t, json.Unmarshal(readUntil(t, conn, types.MessageRequestLog).Data, &entry))
+		assert.Equal(t, "/api/error", entry.Path)
+		assert.Equal(t, http.StatusInternalServerError, entry.StatusCode)
+	})
+
+	t.Run("Path prefix and status class filter", func(t *testing.T) {
+		require.NoError(t, conn.WriteJSON(types.WSClientMessage{
+			Type:   types.ClientSubscribe,
+			Topic:  types.TopicRequestLog,
+			Filter: &types.RequestLogFilter{Path: "/nowhere", StatusClasses: []string{"6xx"}},
+		}))
+		var wsErr types.WSError
+		require.NoError(t, json.Unmarshal(readUntil(t, conn, types.MessageError).Data, &wsErr))
+		assert.Contains(t, wsErr.Error, "status class")
+
+		require.NoError(t, conn.WriteJSON(types.WSClientMessage{
+			Type:   types.ClientSubscribe,
+			Topic:  types.TopicRequestLog,
+			Filter: &types.RequestLogFilter{PathPrefix: "/api/", StatusClasses: []string{"4xx"}},
+		}))
+		readUntil(t, conn, types.MessageSubscribed)
+
+		for _, path := range []string{"/api/error", "/missing", "/api/missing"} {
+			resp, err := http.Get(baseURL + path)
+			require.NoError(t, err)
+			resp.Body.Close()
+		}
+
+		var entry types.RequestLogEntry
+		require.NoError(t, json.Unmarshal(readUntil(t, conn, types.MessageRequestLog).Data, &entry))
+		assert.Equal(t, "/api/missing", entry.Path)
+		assert.Equal(t, http.StatusNotFound, entry.StatusCode)
+	})
+
+	t.Run("Stats subscription", func(t *testing.T) {
+		require.NoError(t, conn.WriteJSON(types.WSClientMessage{
+			Type:       types.ClientSubscribe,
+			Topic:      types.TopicStats,
+			IntervalMs: types.MinStatsIntervalMs,
+		}))
+		readUntil(t, conn, types.MessageSubscribed)
+		readUntil(t, conn, types.MessageStats)
+
+		var update types.ServerStats
+		require.NoError(t, json.Unmarshal(readUntil(t, conn, types.MessageStatsUpdate).Data, &update))
+		assert.Greater(t, update.RequestCount, int64(0))
+	})
+
+	t.Run("Unsubscribe and errors", func(t *testing.T) {
+		require.NoError(t, conn.WriteJSON(types.WSClientMessage{Type: types.ClientUnsubscribe, Topic: types.TopicStats}))
+		readUntil(t, conn, types.MessageUnsubscribed)
+
+		require.NoError(t, conn.WriteJSON(types.WSClientMessage{Type: types.ClientSubscribe, Topic: "bogus"}))
+		var wsErr types.WSError
+		require.NoError(t, json.Unmarshal(readUntil(t, conn, types.MessageError).Data, &wsErr))
+		assert.Contains(t, wsErr.Error, "unknown topic")
+	})
+
+	t.Run("Reset commands", func(t *testing.T) {
+		other, _, err := websocket.DefaultDialer.Dial("ws://localhost:8080/ws", nil)
+		require.NoError(t, err)
+		defer other.Close()
+		readUntil(t, other, types.MessageStats)
+
+		require.NoError(t, conn.WriteJSON(types.WSClientMessage{Type: types.ClientResetStats}))
+		var stats types.ServerStats
+		require.NoError(t, json.Unmarshal(readUntil(t, other, types.MessageStatsReset).Data, &stats))
+		assert.Equal(t, int64(0), stats.RequestCount)
+		assert.Empty(t, stats.Endpoints)
+		readUntil(t, conn, types.MessageStatsReset)
+
+		require.NoError(t, conn.WriteJSON(types.WSClientMessage{Type: types.ClientClearLog}))
+		readUntil(t, other, types.MessageLogCleared)
+		assert.Empty(t, srv.GetRequestLog())
+
+		// The HTTP API does the same
+		resp, err := http.Get(baseURL + "/api/error")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		req, err := http.NewRequest(http.MethodDelete, baseURL+"/requestlog", nil)
+		require.NoError(t, err)
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		readUntil(t, other, types.MessageLogCleared)
+
+		// Only the clearing request itself remains
+		entries := srv.GetRequestLog()
+		require.Len(t, entries, 1)
+		assert.Equal(t, http.MethodDelete, entries[0].Method)
+	})
+
+	t.Run("MessagePack encoding", func(t *testing.T) {
+		dialer := websocket.Dialer{Subprotocols: []string{types.WSEncodingMsgpack}}
+		packed, _, err := dialer.Dial("ws://localhost:8080/ws", nil)
+		require.NoError(t, err)
+		defer packed.Close()
+		assert.Equal(t, types.WSEncodingMsgpack, packed.Subprotocol())
+
+		readPacked := func() map[string]interface{} {
+			packed.SetReadDeadline(time.Now().Add(3 * time.Second))
+			frameType, data, err := packed.ReadMessage()
+			require.NoError(t, err)
+			require.Equal(t, websocket.BinaryMessage, frameType)
+			decoded, err := types.UnmarshalMsgpack(data)
+			require.NoError(t, err)
+			return decoded.(map[string]interface{})
+		}
+		assert.Equal(t, types.MessageConfig, readPacked()["type"])
+		assert.Equal(t, types.MessageStats, readPacked()["type"])
+
+		// Clients may send MessagePack too
+		command, err := types.MarshalMsgpack(types.WSClientMessage{Type: types.ClientSubscribe, Topic: types.TopicConfig})
+		require.NoError(t, err)
+		require.NoError(t, packed.WriteMessage(websocket.BinaryMessage, command))
+		message := readPacked()
+		assert.Equal(t, types.MessageSubscribed, message["type"])
+		assert.Equal(t, types.TopicConfig, message["data"].(map[string]interface{})["topic"])
+	})
+
+	t.Run("Compression negotiated", func(t *testing.T) {
+		dialer := websocket.Dialer{EnableCompression: true}
+		compressed, resp, err := dialer.Dial("ws://localhost:8080/ws", nil)
+		require.NoError(t, err)
+		defer compressed.Close()
+
+		assert.Contains(t, resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+
+		var cfg types.Config
+		require.NoError(t, json.Unmarshal(readUntil(t, compressed, types.MessageConfig).Data, &cfg))
+		assert.Contains(t, cfg.Endpoints, "/api/error")
+	})
+
+	t.Run("Connected clients listing", func(t *testing.T) {
+		watcher, _, err := websocket.DefaultDialer.Dial("ws://localhost:8080/ws", nil)
+		require.NoError(t, err)
+		defer watcher.Close()
+
+		require.NoError(t, watcher.WriteJSON(types.WSClientMessage{Type: types.ClientSubscribe, Topic: types.TopicConfig}))
+		readUntil(t, watcher, types.MessageSubscribed)
+
+		resp, err := http.Get(baseURL + "/ws/clients")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var list types.WSClientList
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		assert.Equal(t, len(list.Clients), list.Count)
+		assert.GreaterOrEqual(t, list.Count, 2)
+
+		var found *types.WSClientInfo
+		for i := range list.Clients {
+			if list.Clients[i].RemoteAddr == watcher.LocalAddr().String() {
+				found = &list.Clients[i]
+			}
+		}
+		require.NotNil(t, found)
+		assert.Equal(t, types.WSEncodingJSON, found.Encoding)
+		assert.WithinDuration(t, time.Now(), found.ConnectedAt, 5*time.Second)
+		require.Len(t, found.Subscriptions, 1)
+		assert.Equal(t, types.TopicConfig, found.Subscriptions[0].Topic)
+
+		req, err := http.NewRequest(http.MethodPost, baseURL+"/ws/clients", nil)
+		require.NoError(t, err)
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
+
+func TestManagementAuth(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.json")
+
+	authConfig := types.Config{
+		Server: types.ServerConfig{
+			Port:      8083,
+			Host:      "127.0.0.1",
+			StaticDir: "./static",
+			ManagementAuth: &types.ManagementAuthConfig{
+				Tokens: []types.AuthToken{
+					{Name: "admin", Token: "admin-secret", Permissions: []string{types.PermissionWrite}},
+					{Name: "viewer", Token: "viewer-secret", Permissions: []string{types.PermissionRead}},
+				},
+			},
+		},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/error": {Type: "error", StatusCode: 500, Message: "boom"},
+		},
+	}
+
+	configData, err := json.MarshalIndent(authConfig, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, configData, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	baseURL := "http://127.0.0.1:8083"
+	wsURL := "ws://127.0.0.1:8083/ws"
+
+	get := func(path, token string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, baseURL+path, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("HTTP management API", func(t *testing.T) {
+		resp := get("/stats", "")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp = get("/stats", "viewer-secret")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// Dynamic endpoints stay public
+		resp = get("/api/error", "")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+		// Read-only tokens cannot change configuration
+		req, err := http.NewRequest(http.MethodDelete, baseURL+"/config?path=/api/error", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer viewer-secret")
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		// Token secrets are masked when reading configuration
+		resp = get("/config", "admin-secret")
+		defer resp.Body.Close()
+		var cfg types.Config
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&cfg))
+		require.NotNil(t, cfg.Server.ManagementAuth)
+		assert.Equal(t, types.RedactedValue, cfg.Server.ManagementAuth.Tokens[0].Token)
+	})
+
+	t.Run("Request log hides tokens", func(t *testing.T) {
+		resp := get("/stats", "admin-secret")
+		resp.Body.Close()
+		resp = get("/stats?token=admin-secret", "")
+		resp.Body.Close()
+		id := parseRequestID(t, resp)
+
+		// A read-only token must not find the write token anywhere in the log
+		resp = get("/requestlog", "viewer-secret")
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotContains(t, string(body), "admin-secret")
+		assert.Contains(t, string(body), types.RedactedValue)
+
+		resp = get(fmt.Sprintf("/requestlog/%d/curl", id), "viewer-secret")
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		assert.NotContains(t, string(body), "admin-secret")
+		assert.Contains(t, string(body), "token="+types.RedactedValue)
+	})
+
+	t.Run("WebSocket query token", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL+"?token=wrong", nil)
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?token=viewer-secret", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var result types.WSAuthResult
+		require.NoError(t, json.Unmarshal(readUntil(t, conn, types.MessageAuthenticated).Data, &result))
+		assert.Equal(t, "viewer", result.Name)
+		assert.Equal(t, []string{types.PermissionRead}, result.Permissions)
+		readUntil(t, conn, types.MessageConfig)
+
+		// Read-only connections cannot reset statistics
+		require.NoError(t, conn.WriteJSON(types.WSClientMessage{Type: types.ClientResetStats}))
+		var wsErr types.WSError
+		require.NoError(t, json.Unmarshal(readUntil(t, conn, types.MessageError).Data, &wsErr))
+		assert.Contains(t, wsErr.Error, "permission denied")
+	})
+
+	t.Run("WebSocket auth message", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, conn.WriteJSON(types.WSClientMessage{Type: types.ClientAuth, Token: "admin-secret"}))
+		readUntil(t, conn, types.MessageAuthenticated)
+		readUntil(t, conn, types.MessageStats)
+	})
+
+	t.Run("WebSocket without token", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, conn.WriteJSON(types.WSClientMessage{Type: types.ClientGetStats}))
+		var wsErr types.WSError
+		require.NoError(t, json.Unmarshal(readUntil(t, conn, types.MessageError).Data, &wsErr))
+		assert.Contains(t, wsErr.Error, "authentication required")
+
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, _, err = conn.ReadMessage()
+		assert.Error(t, err, "connection should be closed")
+	})
+}
+
+func TestWebSocketHeartbeat(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.json")
+
+	heartbeatConfig := types.Config{
+		Server: types.ServerConfig{
+			Port:               8084,
+			Host:               "127.0.0.1",
+			StaticDir:          "./static",
+			WSHeartbeatSeconds: 1,
+		},
+		Endpoints: map[string]types.EndpointConfig{},
+	}
+
+	configData, err := json.MarshalIndent(heartbeatConfig, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, configData, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	wsURL := "ws://127.0.0.1:8084/ws"
+
+	// A responsive client keeps reading, which answers pings automatically
+	alive, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer alive.Close()
+
+	messages := make(chan wsMessage, 100)
+	go func() {
+		defer close(messages)
+		for {
+			var message wsMessage
+			if err := alive.ReadJSON(&message); err != nil {
+				return
+			}
+			messages <- message
+		}
+	}()
+
+	// An unresponsive client never reads, so it never answers pings
+	dead, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer dead.Close()
+
+	time.Sleep(3500 * time.Millisecond)
+
+	t.Run("Unresponsive client is dropped", func(t *testing.T) {
+		dead.SetReadDeadline(time.Now().Add(2 * time.Second))
+		for {
+			if _, _, err := dead.ReadMessage(); err != nil {
+				var netErr interface{ Timeout() bool }
+				if errors.As(err, &netErr) {
+					assert.False(t, netErr.Timeout(), "connection should have been closed by the server")
+				}
+				break
+			}
+		}
+	})
+
+	t.Run("Responsive client survives", func(t *testing.T) {
+		require.NoError(t, alive.WriteJSON(types.WSClientMessage{Type: types.ClientGetStats}))
+
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case message, ok := <-messages:
+				require.True(t, ok, "responsive connection was closed")
+				if message.Type == types.MessageStats {
+					return
+				}
+			case <-timeout:
+				t.Fatal("no stats reply on responsive connection")
+			}
+		}
+	})
+}
+
+func TestWebSocketSlowConsumer(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.json")
+
+	queueConfig := types.Config{
+		Server: types.ServerConfig{
+			Port:            8085,
+			Host:            "127.0.0.1",
+			StaticDir:       "./static",
+			WSSendQueueSize: 4,
+		},
+		Endpoints: map[string]types.EndpointConfig{
+			"/api/upload": {Type: "delay", DelayMs: 0},
+		},
+	}
+
+	configData, err := json.MarshalIndent(queueConfig, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, configData, 0644))
+
+	srv, err := server.NewServer(configPath)
+	require.NoError(t, err)
+	require.NoError(t, srv.Start())
+	defer srv.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	slow, _, err := websocket.DefaultDialer.Dial("ws://127.0.0.1:8085/ws", nil)
+	require.NoError(t, err)
+	defer slow.Close()
+
+	require.NoError(t, slow.WriteJSON(types.WSClientMessage{Type: types.ClientSubscribe, Topic: types.TopicRequestLog}))
+	readUntil(t, slow, types.MessageSubscribed)
+
+	// Stop reading while large request log entries are broadcast; the
+	// requests must not be held up by the stalled client
+	body := bytes.Repeat([]byte("x"), types.MaxCapturedBodyBytes)
+	start := time.Now()
+	for i := 0; i < 400; i++ {
+		resp, err := http.Post("http://127.0.0.1:8085/api/upload", "text/plain", bytes.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	var dropped types.WSDropped
+	require.NoError(t, json.Unmarshal(readUntil(t, slow, types.MessageDropped).Data, &dropped))
+	assert.Greater(t, dropped.Count, int64(0))
+}
@@ -0,0 +1,36 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"webserver/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelayProfile_Linear(t *testing.T) {
+	profile := &types.DelayProfile{Shape: types.DelayProfileLinear, FromMs: 50, ToMs: 2050, Duration: "10m"}
+
+	assert.Equal(t, 50*time.Millisecond, profile.DelayAt(0))
+	assert.Equal(t, 1050*time.Millisecond, profile.DelayAt(5*time.Minute))
+	assert.Equal(t, 2050*time.Millisecond, profile.DelayAt(10*time.Minute))
+	assert.Equal(t, 2050*time.Millisecond, profile.DelayAt(time.Hour), "holds at the end")
+
+	profile.Repeat = true
+	assert.Equal(t, 1050*time.Millisecond, profile.DelayAt(15*time.Minute), "starts over")
+
+	// Ramps may go down as well
+	recovery := &types.DelayProfile{Shape: types.DelayProfileLinear, FromMs: 1000, ToMs: 0, Duration: "1s"}
+	assert.Equal(t, 250*time.Millisecond, recovery.DelayAt(750*time.Millisecond))
+}
+
+func TestDelayProfile_Sine(t *testing.T) {
+	profile := &types.DelayProfile{Shape: types.DelayProfileSine, FromMs: 100, ToMs: 900, Duration: "4m"}
+
+	assert.Equal(t, 100*time.Millisecond, profile.DelayAt(0))
+	assert.InDelta(t, float64(500*time.Millisecond), float64(profile.DelayAt(time.Minute)), float64(time.Millisecond))
+	assert.Equal(t, 900*time.Millisecond, profile.DelayAt(2*time.Minute))
+	assert.InDelta(t, float64(500*time.Millisecond), float64(profile.DelayAt(3*time.Minute)), float64(time.Millisecond))
+	assert.Equal(t, 100*time.Millisecond, profile.DelayAt(4*time.Minute))
+}
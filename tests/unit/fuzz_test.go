@@ -0,0 +1,82 @@
+package unit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"webserver/internal/fuzz"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzGenerate_Reproducible(t *testing.T) {
+	template := map[string]interface{}{"id": 1, "user": map[string]interface{}{"name": "ada", "roles": []interface{}{"admin"}}}
+
+	for caseNumber := int64(1); caseNumber <= 20; caseNumber++ {
+		first := fuzz.Generate(template, 42, caseNumber, nil, 64)
+		second := fuzz.Generate(template, 42, caseNumber, nil, 64)
+		assert.Equal(t, first, second, "case %d", caseNumber)
+	}
+
+	// The template is left untouched
+	assert.Equal(t, "ada", template["user"].(map[string]interface{})["name"])
+
+	// Other seeds give other sequences
+	differs := false
+	for caseNumber := int64(1); caseNumber <= 20; caseNumber++ {
+		differs = differs || string(fuzz.Generate(template, 42, caseNumber, nil, 64).Body) != string(fuzz.Generate(template, 43, caseNumber, nil, 64).Body)
+	}
+	assert.True(t, differs)
+}
+
+func TestFuzzGenerate_Mutations(t *testing.T) {
+	template := map[string]interface{}{"name": "ada", "age": 36}
+
+	for _, mutation := range fuzz.Mutations {
+		payload := fuzz.Generate(template, 1, 1, []string{mutation}, 64)
+		require.Equal(t, mutation, payload.Mutation)
+
+		var decoded map[string]interface{}
+		err := json.Unmarshal(payload.Body, &decoded)
+
+		switch mutation {
+		case fuzz.Truncated, fuzz.EmptyBody, fuzz.DeepNesting:
+			assert.Error(t, err, mutation)
+		case fuzz.InvalidUTF8:
+			assert.False(t, utf8.Valid(payload.Body), mutation)
+			assert.NotEmpty(t, payload.Field, mutation)
+		case fuzz.HugeNumber:
+			// Valid JSON, but out of range for 64-bit numbers
+			assert.True(t, json.Valid(payload.Body), mutation)
+			assert.NotEmpty(t, payload.Field, mutation)
+		default:
+			require.NoError(t, err, mutation)
+			require.NotEmpty(t, payload.Field, mutation)
+			value, exists := decoded[payload.Field]
+			switch mutation {
+			case fuzz.WrongType:
+				assert.True(t, exists)
+				assert.NotEqual(t, template[payload.Field], value)
+			case fuzz.MissingField:
+				assert.False(t, exists)
+			case fuzz.NullField:
+				assert.True(t, exists)
+				assert.Nil(t, value)
+			case fuzz.HugeString:
+				assert.Equal(t, strings.Repeat("x", 64), value)
+			}
+		}
+	}
+}
+
+func TestFuzzGenerate_DefaultTemplate(t *testing.T) {
+	payload := fuzz.Generate(nil, 0, 1, []string{fuzz.NullField}, 0)
+	assert.Equal(t, fuzz.NullField, payload.Mutation)
+	assert.NotEmpty(t, payload.Field)
+	assert.True(t, json.Valid(payload.Body))
+	assert.True(t, fuzz.IsMutation("huge_string"))
+	assert.False(t, fuzz.IsMutation("scramble"))
+}
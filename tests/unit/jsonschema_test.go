@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"webserver/internal/jsonschema"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compileSchema(t *testing.T, schema string) *jsonschema.Schema {
+	t.Helper()
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(schema), &decoded))
+	compiled, err := jsonschema.Compile(decoded)
+	require.NoError(t, err)
+	return compiled
+}
+
+func validateJSON(t *testing.T, schema *jsonschema.Schema, body string) []string {
+	t.Helper()
+	var decoded interface{}
+	require.NoError(t, json.Unmarshal([]byte(body), &decoded))
+	return schema.Validate(decoded)
+}
+
+func TestJSONSchema_Validate(t *testing.T) {
+	schema := compileSchema(t, `{
+		"type": "object",
+		"required": ["name", "age"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+			"age": {"type": "integer", "minimum": 0, "maximum": 150},
+			"email": {"type": ["string", "null"]},
+			"role": {"enum": ["admin", "user"]},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2, "uniqueItems": true},
+			"address": {"$ref": "#/$defs/address"}
+		},
+		"$defs": {
+			"address": {"type": "object", "required": ["city"], "properties": {"city": {"type": "string"}}}
+		}
+	}`)
+
+	assert.Empty(t, validateJSON(t, schema, `{"name": "ada", "age": 36, "email": null, "role": "admin", "tags": ["a"], "address": {"city": "London"}}`))
+
+	violations := validateJSON(t, schema, `{"name": "A", "age": 36.5, "role": "root", "tags": ["a", "a", 3], "address": {}, "extra": 1}`)
+	assert.ElementsMatch(t, []string{
+		`name: 1 characters, want at least 2`,
+		`name: "A" does not match ^[a-z]+$`,
+		`age: got number, want integer`,
+		`role: "root" is not one of ["admin","user"]`,
+		`tags: 3 items, want at most 2`,
+		`tags: items 0 and 1 are equal`,
+		`tags[2]: got integer, want string`,
+		`address.city: required property is missing`,
+		`extra: unexpected property`,
+	}, violations)
+
+	assert.Equal(t, []string{"name: required property is missing", "age: required property is missing"}, validateJSON(t, schema, `{}`))
+	assert.Equal(t, []string{"body: got array, want object"}, validateJSON(t, schema, `[]`))
+}
+
+func TestJSONSchema_Combinators(t *testing.T) {
+	schema := compileSchema(t, `{
+		"oneOf": [
+			{"type": "object", "required": ["card"]},
+			{"type": "object", "required": ["iban"]}
+		],
+		"not": {"required": ["debug"]},
+		"properties": {"amount": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01}}
+	}`)
+
+	assert.Empty(t, validateJSON(t, schema, `{"card": "4242", "amount": 12.5}`))
+	assert.Equal(t, []string{"body: matches 2 of the oneOf schemas, want exactly 1"}, validateJSON(t, schema, `{"card": "4242", "iban": "DE89"}`))
+	assert.Equal(t, []string{"body: matches 0 of the oneOf schemas, want exactly 1"}, validateJSON(t, schema, `{}`))
+	assert.Equal(t, []string{"body: matches the schema it must not"}, validateJSON(t, schema, `{"card": "4242", "debug": true}`))
+	assert.Equal(t, []string{"amount: 0 must be greater than 0"}, validateJSON(t, schema, `{"card": "4242", "amount": 0}`))
+	assert.Equal(t, []string{"amount: 0.001 is not a multiple of 0.01"}, validateJSON(t, schema, `{"card": "4242", "amount": 0.001}`))
+}
+
+func TestJSONSchema_CompileErrors(t *testing.T) {
+	invalid := []string{
+		`{"type": "text"}`,
+		`{"type": []}`,
+		`{"minimum": "1"}`,
+		`{"multipleOf": 0}`,
+		`{"pattern": "("}`,
+		`{"required": "name"}`,
+		`{"properties": {"name": 1}}`,
+		`{"anyOf": []}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "https://example.com/schema.json"}`,
+	}
+	for _, schema := range invalid {
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(schema), &decoded))
+		_, err := jsonschema.Compile(decoded)
+		assert.Error(t, err, schema)
+	}
+
+	// Recursive references are fine as long as the value ends
+	schema := compileSchema(t, `{"type": "object", "properties": {"child": {"$ref": "#"}}}`)
+	assert.Empty(t, validateJSON(t, schema, `{"child": {"child": {}}}`))
+	assert.Equal(t, []string{"child.child: got integer, want object"}, validateJSON(t, schema, `{"child": {"child": 1}}`))
+}
@@ -0,0 +1,51 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"testing"
+
+	"webserver/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogging_Levels(t *testing.T) {
+	level, err := logging.ParseLevel("WARN")
+	require.NoError(t, err)
+	assert.Equal(t, logging.LevelWarn, level)
+	_, err = logging.ParseLevel("loud")
+	assert.Error(t, err)
+
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	previous := logging.GetLevel()
+	defer func() {
+		log.SetOutput(os.Stderr)
+		logging.SetLevel(previous)
+	}()
+
+	logging.SetLevel(logging.LevelWarn)
+	logging.Infof("GET /api/hello")
+	logging.Warnf("slow client")
+	assert.NotContains(t, out.String(), "GET /api/hello")
+	assert.Contains(t, out.String(), "slow client")
+}
+
+func TestLogging_JSON(t *testing.T) {
+	var out bytes.Buffer
+	logging.SetJSON(&out)
+	defer logging.SetJSON(nil)
+
+	logging.Infof("GET %s -> %d", "/api/hello", 200)
+	logging.Debugf("dropped at the default level")
+
+	var line map[string]string
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "info", line["level"])
+	assert.Equal(t, "GET /api/hello -> 200", line["msg"])
+	assert.NotEmpty(t, line["time"])
+}
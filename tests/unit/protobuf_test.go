@@ -0,0 +1,160 @@
+package unit
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"webserver/internal/config"
+	"webserver/internal/protobuf"
+	"webserver/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Helpers building descriptor sets by hand, as protoc would serialize them
+
+func pbVarint(number int, value uint64) []byte {
+	buf := binary.AppendUvarint(nil, uint64(number)<<3)
+	return binary.AppendUvarint(buf, value)
+}
+
+func pbBytes(number int, parts ...[]byte) []byte {
+	var data []byte
+	for _, part := range parts {
+		data = append(data, part...)
+	}
+	buf := binary.AppendUvarint(nil, uint64(number)<<3|2)
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+func pbString(number int, value string) []byte {
+	return pbBytes(number, []byte(value))
+}
+
+// pbField builds a FieldDescriptorProto inside a DescriptorProto
+func pbField(name string, number, label, kind int, typeName string) []byte {
+	parts := [][]byte{pbString(1, name), pbVarint(3, uint64(number)), pbVarint(4, uint64(label)), pbVarint(5, uint64(kind))}
+	if typeName != "" {
+		parts = append(parts, pbString(6, typeName))
+	}
+	return pbBytes(2, parts...)
+}
+
+// shopDescriptorSet describes, in proto3:
+//
+//	package shop.v1;
+//	message Order {
+//	  enum Status { UNKNOWN = 0; PAID = 1; }
+//	  message Customer { string name = 1; }
+//	  string id = 1;
+//	  int64 total_cents = 2;
+//	  repeated int32 quantities = 3;
+//	  Status status = 4;
+//	  Customer customer = 5;
+//	  map<string, int32> tags = 6;
+//	  bytes payload = 7;
+//	  sint32 delta = 8;
+//	  double ratio = 9;
+//	}
+func shopDescriptorSet() []byte {
+	const optional, repeated = 1, 3
+	order := pbBytes(4,
+		pbString(1, "Order"),
+		pbField("id", 1, optional, 9, ""),
+		pbField("total_cents", 2, optional, 3, ""),
+		pbField("quantities", 3, repeated, 5, ""),
+		pbField("status", 4, optional, 14, ".shop.v1.Order.Status"),
+		pbField("customer", 5, optional, 11, ".shop.v1.Order.Customer"),
+		pbField("tags", 6, repeated, 11, ".shop.v1.Order.TagsEntry"),
+		pbField("payload", 7, optional, 12, ""),
+		pbField("delta", 8, optional, 17, ""),
+		pbField("ratio", 9, optional, 1, ""),
+		pbBytes(3, pbString(1, "Customer"), pbField("name", 1, optional, 9, "")),
+		pbBytes(3, pbString(1, "TagsEntry"),
+			pbField("key", 1, optional, 9, ""),
+			pbField("value", 2, optional, 5, ""),
+			pbBytes(7, pbVarint(7, 1))),
+		pbBytes(4, pbString(1, "Status"),
+			pbBytes(2, pbString(1, "UNKNOWN"), pbVarint(2, 0)),
+			pbBytes(2, pbString(1, "PAID"), pbVarint(2, 1))),
+	)
+	file := pbBytes(1, pbString(1, "shop.proto"), pbString(2, "shop.v1"), order, pbString(12, "proto3"))
+	return file
+}
+
+func TestProtobuf_Marshal(t *testing.T) {
+	set, err := protobuf.Parse(shopDescriptorSet())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop.v1.Order", "shop.v1.Order.Customer"}, set.Messages())
+
+	// Field names and JSON names are both accepted
+	data, err := set.Marshal("shop.v1.Order", map[string]interface{}{
+		"id":         "A1",
+		"totalCents": "1250",
+		"quantities": []interface{}{1.0, 2.0},
+		"status":     "PAID",
+		"customer":   map[string]interface{}{"name": "Ada"},
+		"tags":       map[string]interface{}{"x": 3.0},
+		"payload":    "AQI=",
+		"delta":      -1.0,
+		"ratio":      0.5,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{
+		0x0a, 0x02, 'A', '1', // id
+		0x10, 0xe2, 0x09, // total_cents
+		0x1a, 0x02, 0x01, 0x02, // quantities, packed
+		0x20, 0x01, // status
+		0x2a, 0x05, 0x0a, 0x03, 'A', 'd', 'a', // customer
+		0x32, 0x05, 0x0a, 0x01, 'x', 0x10, 0x03, // tags entry
+		0x3a, 0x02, 0x01, 0x02, // payload
+		0x40, 0x01, // delta, zigzag
+		0x49, 0, 0, 0, 0, 0, 0, 0xe0, 0x3f, // ratio
+	}, data)
+
+	for name, values := range map[string]map[string]interface{}{
+		"unknown field": {"discount": 1.0},
+		"wrong type":    {"id": 1.0},
+		"unknown enum":  {"status": "REFUNDED"},
+		"overflow":      {"quantities": []interface{}{1e10}},
+		"fraction":      {"total_cents": 1.5},
+		"nested":        {"customer": map[string]interface{}{"age": 3.0}},
+	} {
+		_, err := set.Marshal("shop.v1.Order", values)
+		assert.Error(t, err, name)
+	}
+	_, err = set.Marshal("shop.v1.Invoice", nil)
+	assert.Error(t, err)
+}
+
+func TestProtobuf_EndpointValidation(t *testing.T) {
+	descriptorPath := filepath.Join(t.TempDir(), "shop.desc")
+	require.NoError(t, os.WriteFile(descriptorPath, shopDescriptorSet(), 0644))
+	manager := config.NewManager(filepath.Join(t.TempDir(), "config.json"))
+
+	endpoint := func(message string, response map[string]interface{}) *types.Config {
+		return &types.Config{
+			Server: types.ServerConfig{Host: "localhost", Port: 8080, StaticDir: "./static"},
+			Endpoints: map[string]types.EndpointConfig{"/orders/1": {
+				Type:     "delay",
+				Response: response,
+				Protobuf: &types.ProtobufConfig{DescriptorSet: descriptorPath, Message: message},
+			}},
+		}
+	}
+
+	assert.NoError(t, manager.ValidateConfig(endpoint("shop.v1.Order", map[string]interface{}{"id": "A1"})))
+	assert.ErrorContains(t, manager.ValidateConfig(endpoint("shop.v1.Invoice", nil)), "has no message")
+	assert.ErrorContains(t, manager.ValidateConfig(endpoint("shop.v1.Order", map[string]interface{}{"discount": 1.0})), "does not encode")
+
+	errorEndpoint := endpoint("shop.v1.Order", nil)
+	errorEndpoint.Endpoints["/orders/1"] = types.EndpointConfig{
+		Type: "error", StatusCode: 500,
+		Protobuf: &types.ProtobufConfig{DescriptorSet: descriptorPath, Message: "shop.v1.Order"},
+	}
+	assert.Error(t, manager.ValidateConfig(errorEndpoint))
+}
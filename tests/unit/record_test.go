@@ -0,0 +1,69 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"webserver/internal/config"
+	"webserver/internal/record"
+	"webserver/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_Config(t *testing.T) {
+	flaky := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users":
+			w.Write([]byte(`{"users": ["ada"]}`))
+		case "/flaky":
+			flaky++
+			if flaky%3 == 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"error": "try again"}`))
+				return
+			}
+			w.Write([]byte(`{"status": "ok"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "no such thing"}`))
+		}
+	}))
+	defer upstream.Close()
+
+	upstreamURL, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+	recorder := record.New(upstreamURL)
+	proxy := httptest.NewServer(recorder)
+	defer proxy.Close()
+
+	for _, path := range []string{"/users?page=2", "/flaky", "/flaky", "/flaky", "/missing"} {
+		resp, err := http.Get(proxy.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.Equal(t, 3, recorder.Routes())
+
+	cfg, notes := recorder.Config(types.ServerConfig{Host: "localhost", Port: 8080, StaticDir: "./static"}, false)
+	assert.Empty(t, notes)
+	assert.Equal(t, types.EndpointConfig{
+		Type:     "delay",
+		Response: map[string]interface{}{"users": []interface{}{"ada"}},
+	}, cfg.Endpoints["/users"])
+	assert.Equal(t, types.EndpointConfig{
+		Type:            "conditional_error",
+		StatusCode:      503,
+		ErrorEveryN:     3,
+		SuccessResponse: map[string]interface{}{"status": "ok"},
+	}, cfg.Endpoints["/flaky"])
+	assert.Equal(t, types.EndpointConfig{Type: "error", StatusCode: 404, Message: "no such thing"}, cfg.Endpoints["/missing"])
+
+	manager := config.NewManager(filepath.Join(t.TempDir(), "recorded.json"))
+	assert.NoError(t, manager.ValidateConfig(cfg))
+}
@@ -0,0 +1,675 @@
+package unit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"webserver/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointStats_SlidingWindowRates(t *testing.T) {
+	stats := &types.EndpointStats{Path: "/api/test"}
+
+	for i := 0; i < 6; i++ {
+		stats.RecordRequest(10*time.Millisecond, 200)
+	}
+	for i := 0; i < 3; i++ {
+		stats.RecordRequest(10*time.Millisecond, 500)
+	}
+
+	snapshot := stats.GetStats()
+
+	assert.Equal(t, int64(9), snapshot.Rates.OneMinute.Requests)
+	assert.Equal(t, int64(3), snapshot.Rates.OneMinute.Errors)
+	assert.InDelta(t, 9.0/60.0, snapshot.Rates.OneMinute.RequestsPerSec, 0.0001)
+	assert.InDelta(t, 3.0/60.0, snapshot.Rates.OneMinute.ErrorsPerSec, 0.0001)
+
+	assert.Equal(t, int64(9), snapshot.Rates.FiveMinute.Requests)
+	assert.Equal(t, int64(9), snapshot.Rates.FifteenMinute.Requests)
+	assert.InDelta(t, 9.0/900.0, snapshot.Rates.FifteenMinute.RequestsPerSec, 0.0001)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	serverStats := &types.ServerStats{StartTime: time.Now()}
+
+	serverStats.RecordRequest("/api/test", 10*time.Millisecond, 200)
+	before := serverStats.GetAllStats()
+
+	serverStats.RecordRequest("/api/test", 30*time.Millisecond, 500)
+	serverStats.RecordRequest("/api/test", 50*time.Millisecond, 200)
+	serverStats.RecordRequest("/api/other", 5*time.Millisecond, 200)
+	after := serverStats.GetAllStats()
+
+	diff := types.DiffSnapshots(
+		&types.StatsSnapshot{Name: "before", Stats: before},
+		&types.StatsSnapshot{Name: "after", Stats: after},
+	)
+
+	assert.Equal(t, "before", diff.From)
+	assert.Equal(t, "after", diff.To)
+	assert.Equal(t, int64(3), diff.RequestDelta)
+	assert.Equal(t, int64(1), diff.ErrorDelta)
+
+	testDiff := diff.Endpoints["/api/test"]
+	if assert.NotNil(t, testDiff) {
+		assert.Equal(t, int64(2), testDiff.RequestDelta)
+		assert.Equal(t, int64(1), testDiff.ErrorDelta)
+		assert.InDelta(t, 40.0, testDiff.AvgTimeMs, 0.0001)
+		assert.Equal(t, int64(1), testDiff.StatusCodeDeltas[500])
+		assert.Equal(t, int64(1), testDiff.StatusCodeDeltas[200])
+	}
+
+	otherDiff := diff.Endpoints["/api/other"]
+	if assert.NotNil(t, otherDiff) {
+		assert.Equal(t, int64(1), otherDiff.RequestDelta)
+	}
+}
+
+func TestNormalizeUserAgent(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{"", types.UserAgentUnknown},
+		{"curl/8.4.0", "curl/8.4.0"},
+		{"Go-http-client/1.1", "Go-http-client/1.1"},
+		{"python-requests/2.31.0 CPython/3.12", "python-requests/2.31.0"},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "Chrome/120"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91", "Edge/120"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", "Firefox/121"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_2) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15", "Safari/17"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, types.NormalizeUserAgent(tt.userAgent), tt.userAgent)
+	}
+}
+
+func TestServerStats_RecordUserAgentBounded(t *testing.T) {
+	serverStats := &types.ServerStats{}
+
+	for i := 0; i < 200; i++ {
+		serverStats.RecordUserAgent(fmt.Sprintf("client-%d/1.0", i))
+	}
+	serverStats.RecordUserAgent("client-0/1.0")
+
+	stats := serverStats.GetAllStats()
+	assert.LessOrEqual(t, len(stats.UserAgents), 51)
+	assert.Equal(t, int64(2), stats.UserAgents["client-0/1.0"])
+	assert.Greater(t, stats.UserAgents[types.UserAgentOther], int64(0))
+}
+
+func TestEndpointStats_Apdex(t *testing.T) {
+	stats := &types.EndpointStats{Path: "/api/test"}
+
+	// No threshold configured, no Apdex reported
+	stats.RecordRequest(10*time.Millisecond, 200)
+	assert.Nil(t, stats.GetStats().Apdex)
+
+	stats.SetApdexThreshold(100)
+	stats.RecordRequest(50*time.Millisecond, 200)  // satisfied
+	stats.RecordRequest(100*time.Millisecond, 200) // satisfied
+	stats.RecordRequest(300*time.Millisecond, 200) // tolerating
+	stats.RecordRequest(500*time.Millisecond, 200) // frustrated
+	stats.RecordRequest(10*time.Millisecond, 503)  // frustrated (server error)
+
+	apdex := stats.GetStats().Apdex
+	if assert.NotNil(t, apdex) {
+		assert.Equal(t, int64(100), apdex.ThresholdMs)
+		assert.Equal(t, int64(2), apdex.Satisfied)
+		assert.Equal(t, int64(1), apdex.Tolerating)
+		assert.Equal(t, int64(2), apdex.Frustrated)
+		assert.InDelta(t, 0.5, apdex.Score, 0.0001)
+	}
+
+	// Changing the threshold restarts the counts
+	stats.SetApdexThreshold(200)
+	apdex = stats.GetStats().Apdex
+	if assert.NotNil(t, apdex) {
+		assert.Equal(t, int64(0), apdex.Satisfied+apdex.Tolerating+apdex.Frustrated)
+		assert.InDelta(t, 1.0, apdex.Score, 0.0001)
+	}
+}
+
+func TestEndpointStats_SLO(t *testing.T) {
+	stats := &types.EndpointStats{Path: "/api/test"}
+	stats.SetSLO(&types.SLOConfig{Target: 99, Windows: []string{"5m", "1h"}})
+
+	for i := 0; i < 98; i++ {
+		stats.RecordRequest(time.Millisecond, 200)
+	}
+	stats.RecordRequest(time.Millisecond, 503)
+	stats.RecordRequest(time.Millisecond, 503)
+
+	slo := stats.GetStats().SLO
+	if assert.NotNil(t, slo) {
+		assert.Equal(t, 99.0, slo.Target)
+		assert.InDelta(t, 98.0, slo.Compliance, 0.0001)
+		assert.InDelta(t, -1.0, slo.ErrorBudgetRemaining, 0.0001)
+		if assert.Len(t, slo.Windows, 2) {
+			assert.Equal(t, "5m0s", slo.Windows[0].Window)
+			assert.Equal(t, int64(100), slo.Windows[0].Requests)
+			assert.InDelta(t, 2.0, slo.Windows[0].BurnRate, 0.0001)
+		}
+	}
+
+	stats.SetSLO(nil)
+	assert.Nil(t, stats.GetStats().SLO)
+}
+
+func TestServerStats_Sampling(t *testing.T) {
+	serverStats := &types.ServerStats{}
+	serverStats.SetSampleEvery(10)
+
+	for i := 0; i < 100; i++ {
+		serverStats.RecordRequest("/api/test", 5*time.Millisecond, 200)
+	}
+
+	stats := serverStats.GetAllStats()
+	assert.Equal(t, 10, stats.SampleEvery)
+	assert.Equal(t, int64(100), stats.RequestCount)
+
+	endpoint := stats.Endpoints["/api/test"]
+	if assert.NotNil(t, endpoint) {
+		// Counters stay exact while sampled latencies are weighted
+		assert.Equal(t, int64(100), endpoint.RequestCount)
+		assert.Equal(t, int64(100), endpoint.StatusCodes[200])
+		assert.Equal(t, int64(500), endpoint.TotalTimeMs)
+		assert.Equal(t, int64(5), endpoint.MinTimeMs)
+	}
+}
+
+func TestServerStats_ConcurrentRecording(t *testing.T) {
+	serverStats := &types.ServerStats{StartTime: time.Now()}
+
+	const workers = 16
+	const perWorker = 500
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			path := fmt.Sprintf("/api/%d", w%4)
+			for i := 0; i < perWorker; i++ {
+				statusCode := 200
+				if i%10 == 0 {
+					statusCode = 500
+				}
+				serverStats.RecordRequest(path, time.Duration(i%7)*time.Millisecond, statusCode)
+				serverStats.RecordUserAgent("load-tester/1.0")
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	stats := serverStats.GetAllStats()
+	assert.Equal(t, int64(workers*perWorker), stats.RequestCount)
+	assert.Equal(t, int64(workers*perWorker/10), stats.ErrorCount)
+	assert.Equal(t, int64(workers*perWorker), stats.UserAgents["load-tester/1.0"])
+	assert.Len(t, stats.Endpoints, 4)
+
+	var total int64
+	for _, endpoint := range stats.Endpoints {
+		total += endpoint.RequestCount
+		assert.Equal(t, endpoint.RequestCount, endpoint.StatusCodes[200]+endpoint.StatusCodes[500])
+		assert.Equal(t, int64(6), endpoint.MaxTimeMs)
+	}
+	assert.Equal(t, int64(workers*perWorker), total)
+}
+
+func TestEndpointStats_ConcurrencyHighWaterMark(t *testing.T) {
+	stats := &types.EndpointStats{Path: "/api/test"}
+
+	stats.BeginRequest()
+	stats.BeginRequest()
+	stats.BeginRequest()
+	stats.EndRequest()
+	stats.BeginRequest()
+	stats.EndRequest()
+	stats.EndRequest()
+
+	snapshot := stats.GetStats()
+	assert.Equal(t, int64(1), snapshot.InFlight)
+	assert.Equal(t, int64(3), snapshot.MaxInFlight)
+}
+
+func TestRequestLogEntry_CurlCommand(t *testing.T) {
+	entry := types.RequestLogEntry{
+		Method: "POST",
+		Path:   "/api/users?debug=1",
+		Host:   "localhost:8080",
+		Headers: map[string][]string{
+			"Content-Type":   {"application/json"},
+			"Content-Length": {"17"},
+			"X-Note":         {"it's"},
+		},
+		Body: `{"name":"alice"}`,
+	}
+
+	expected := "curl \\\n" +
+		"  -X POST \\\n" +
+		"  -H 'Content-Type: application/json' \\\n" +
+		"  -H 'X-Note: it'\\''s' \\\n" +
+		"  --data-raw '{\"name\":\"alice\"}' \\\n" +
+		"  'http://localhost:8080/api/users?debug=1'"
+	assert.Equal(t, expected, entry.CurlCommand(""))
+
+	get := types.RequestLogEntry{Method: "GET", Path: "/health", Host: "localhost:8080"}
+	assert.Equal(t, "curl \\\n  'https://example.com/health'", get.CurlCommand("https://example.com/"))
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	redactor, err := types.NewRedactor(&types.RedactionConfig{
+		Headers:    []string{"authorization"},
+		JSONFields: []string{"password", "cards.number"},
+		Patterns:   []string{`token=[^&]+`},
+	})
+	assert.NoError(t, err)
+
+	entry := types.RequestLogEntry{
+		Path: "/api/login?token=secret&page=1",
+		Headers: map[string][]string{
+			"Authorization": {"Bearer abc"},
+			"Accept":        {"application/json"},
+		},
+		Body: `{"user":"alice","password":"hunter2","cards":[{"number":"4111"},{"number":"5500"}]}`,
+	}
+	redactor.Redact(&entry)
+
+	assert.Equal(t, "/api/login?[REDACTED]&page=1", entry.Path)
+	assert.Equal(t, []string{"[REDACTED]"}, entry.Headers["Authorization"])
+	assert.Equal(t, []string{"application/json"}, entry.Headers["Accept"])
+	assert.Equal(t, `{"cards":[{"number":"[REDACTED]"},{"number":"[REDACTED]"}],"password":"[REDACTED]","user":"alice"}`, entry.Body)
+
+	// Non-JSON bodies are still subject to patterns
+	entry.Body = "token=xyz&keep=1"
+	redactor.Redact(&entry)
+	assert.Equal(t, "[REDACTED]&keep=1", entry.Body)
+
+	// A nil redactor leaves entries untouched
+	var none *types.Redactor
+	none.Redact(&entry)
+	assert.Equal(t, "[REDACTED]&keep=1", entry.Body)
+}
+
+func TestMaskCredentials(t *testing.T) {
+	entry := types.RequestLogEntry{
+		Path: "/requestlog?tag=a&token=admin-secret&to%6Ben=other",
+		Headers: map[string][]string{
+			"Authorization":       {"Bearer admin-secret"},
+			"Proxy-Authorization": {"Basic dXNlcjpwYXNz"},
+			"Accept":              {"application/json"},
+		},
+	}
+	types.MaskCredentials(&entry)
+
+	assert.Equal(t, "/requestlog?tag=a&token=[REDACTED]&token=[REDACTED]", entry.Path)
+	assert.Equal(t, []string{"[REDACTED]"}, entry.Headers["Authorization"])
+	assert.Equal(t, []string{"[REDACTED]"}, entry.Headers["Proxy-Authorization"])
+	assert.Equal(t, []string{"application/json"}, entry.Headers["Accept"])
+
+	// Entries without credentials are left alone
+	plain := types.RequestLogEntry{Path: "/api/users?page=1"}
+	types.MaskCredentials(&plain)
+	assert.Equal(t, "/api/users?page=1", plain.Path)
+	assert.Nil(t, plain.Headers)
+}
+
+func TestRequestLogBuffer_Wraparound(t *testing.T) {
+	buffer := types.NewRequestLogBuffer(3, 0)
+	for id := uint64(1); id <= 5; id++ {
+		buffer.Add(types.RequestLogEntry{ID: id})
+	}
+
+	entries := buffer.Entries()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, []uint64{5, 4, 3}, []uint64{entries[0].ID, entries[1].ID, entries[2].ID})
+
+	_, found := buffer.Find(2)
+	assert.False(t, found)
+	entry, found := buffer.Find(4)
+	assert.True(t, found)
+	assert.Equal(t, uint64(4), entry.ID)
+}
+
+func TestRequestLogBuffer_Resize(t *testing.T) {
+	buffer := types.NewRequestLogBuffer(5, 0)
+	for id := uint64(1); id <= 7; id++ {
+		buffer.Add(types.RequestLogEntry{ID: id})
+	}
+
+	// Shrinking keeps the newest entries
+	buffer.Resize(2, 0)
+	entries := buffer.Entries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, uint64(7), entries[0].ID)
+	assert.Equal(t, uint64(6), entries[1].ID)
+
+	// Growing keeps everything and continues appending in order
+	buffer.Resize(4, 0)
+	buffer.Add(types.RequestLogEntry{ID: 8})
+	entries = buffer.Entries()
+	assert.Len(t, entries, 3)
+	assert.Equal(t, uint64(8), entries[0].ID)
+	assert.Equal(t, uint64(6), entries[2].ID)
+}
+
+func TestRequestLogBuffer_MemoryBudget(t *testing.T) {
+	entry := types.RequestLogEntry{Path: "/api/test"}
+	budget := entry.EstimatedSize()*2 + 1
+
+	buffer := types.NewRequestLogBuffer(10, budget)
+	for id := uint64(1); id <= 5; id++ {
+		entry.ID = id
+		buffer.Add(entry)
+	}
+
+	assert.Equal(t, 2, buffer.Len())
+	assert.Equal(t, uint64(5), buffer.Entries()[0].ID)
+}
+
+func TestNormalizeRoute(t *testing.T) {
+	tests := map[string]string{
+		"/users/42":        "/users/{id}",
+		"/users/42/orders": "/users/{id}/orders",
+		"/orders/3f2504e0-4f89-11d3-9a0c-0305e82c3301?x=1": "/orders/{uuid}",
+		"/blobs/9f86d081884c7d659a2feaa0c55ad015":          "/blobs/{hash}",
+		"/api/v2/health": "/api/v2/health",
+		"/":              "/",
+	}
+
+	for path, want := range tests {
+		assert.Equal(t, want, types.NormalizeRoute(path), path)
+	}
+}
+
+func TestGroupRequestLog(t *testing.T) {
+	now := time.Now()
+	entries := []types.RequestLogEntry{
+		{Method: "GET", Path: "/users/1", StatusCode: 200, Duration: 10, Timestamp: now},
+		{Method: "GET", Path: "/users/2", StatusCode: 404, Duration: 30, Timestamp: now.Add(time.Second)},
+		{Method: "PUT", Path: "/users/3", StatusCode: 200, Duration: 20, Timestamp: now},
+		{Method: "GET", Path: "/health", StatusCode: 200, Duration: 1, Timestamp: now},
+	}
+
+	groups := types.GroupRequestLog(entries)
+	assert.Len(t, groups, 2)
+
+	users := groups[0]
+	assert.Equal(t, "/users/{id}", users.Route)
+	assert.Equal(t, 3, users.Count)
+	assert.Equal(t, 1, users.ErrorCount)
+	assert.Equal(t, 20.0, users.AvgDurationMs)
+	assert.Equal(t, int64(10), users.MinDurationMs)
+	assert.Equal(t, int64(30), users.MaxDurationMs)
+	assert.Equal(t, int64(30), users.P95DurationMs)
+	assert.Equal(t, map[string]int{"GET": 2, "PUT": 1}, users.Methods)
+	assert.Equal(t, now.Add(time.Second), users.LastSeen)
+
+	assert.Equal(t, "/health", groups[1].Route)
+}
+
+func TestDiffRequestLogEntries(t *testing.T) {
+	from := types.RequestLogEntry{
+		ID:         1,
+		Method:     "POST",
+		Path:       "/api/payments",
+		StatusCode: 200,
+		Headers:    map[string][]string{"Content-Type": {"application/json"}, "X-Trace": {"a"}},
+		Body:       `{"amount":10,"currency":"EUR","items":[1,2]}`,
+	}
+	to := from
+	to.ID = 2
+	to.StatusCode = 500
+	to.Headers = map[string][]string{"Content-Type": {"application/json"}, "Authorization": {"Bearer x"}}
+	to.Body = `{"amount":10,"currency":"USD","items":[1]}`
+
+	diff := types.DiffRequestLogEntries(from, to)
+	assert.False(t, diff.Identical)
+	assert.Equal(t, []types.ValueChange{
+		{Field: "status_code", Change: types.ChangeChanged, From: 200, To: 500},
+	}, diff.Request)
+	assert.Equal(t, []types.ValueChange{
+		{Field: "Authorization", Change: types.ChangeAdded, To: "Bearer x"},
+		{Field: "X-Trace", Change: types.ChangeRemoved, From: "a"},
+	}, diff.Headers)
+	assert.Len(t, diff.Body, 2)
+	assert.Equal(t, "currency", diff.Body[0].Field)
+	assert.Equal(t, "items.1", diff.Body[1].Field)
+	assert.Equal(t, types.ChangeRemoved, diff.Body[1].Change)
+
+	// Non-JSON bodies are compared as a whole
+	from.Body, to.Body = "a=1", "a=2"
+	diff = types.DiffRequestLogEntries(from, to)
+	assert.Equal(t, []types.ValueChange{{Field: "body", Change: types.ChangeChanged, From: "a=1", To: "a=2"}}, diff.Body)
+
+	assert.True(t, types.DiffRequestLogEntries(from, from).Identical)
+}
+
+func TestEndpointStats_SlowRequests(t *testing.T) {
+	stats := &types.EndpointStats{Path: "/api/test"}
+
+	// Nothing is counted until a threshold is set
+	stats.RecordRequest(500*time.Millisecond, 200)
+	assert.Equal(t, int64(0), stats.GetStats().SlowCount)
+
+	stats.SetSlowThreshold(100)
+	stats.RecordRequest(50*time.Millisecond, 200)
+	stats.RecordRequest(100*time.Millisecond, 200)
+	stats.RecordRequest(150*time.Millisecond, 200)
+	stats.RecordRequest(300*time.Millisecond, 500)
+
+	snapshot := stats.GetStats()
+	assert.Equal(t, int64(2), snapshot.SlowCount)
+	assert.Equal(t, int64(100), snapshot.SlowThresholdMs)
+}
+
+func TestStatusClass(t *testing.T) {
+	assert.Equal(t, "2xx", types.StatusClass(204))
+	assert.Equal(t, "5xx", types.StatusClass(503))
+
+	for _, class := range []string{"1xx", "4xx", "5XX"} {
+		assert.True(t, types.ValidStatusClass(class), class)
+	}
+	for _, class := range []string{"", "6xx", "0xx", "50x", "5xxx"} {
+		assert.False(t, types.ValidStatusClass(class), class)
+	}
+}
+
+func TestServerStats_Reset(t *testing.T) {
+	stats := &types.ServerStats{StartTime: time.Now()}
+	stats.RecordRequest("/api/test", 10*time.Millisecond, 500)
+	stats.RecordUserAgent("curl/8.4.0")
+
+	stats.Reset()
+
+	snapshot := stats.GetAllStats()
+	assert.Equal(t, int64(0), snapshot.RequestCount)
+	assert.Equal(t, int64(0), snapshot.ErrorCount)
+	assert.Empty(t, snapshot.Endpoints)
+	assert.Empty(t, snapshot.UserAgents)
+
+	stats.RecordRequest("/api/test", 10*time.Millisecond, 200)
+	assert.Equal(t, int64(1), stats.GetAllStats().Endpoints["/api/test"].RequestCount)
+}
+
+func TestRequestLogBuffer_Clear(t *testing.T) {
+	buffer := types.NewRequestLogBuffer(3, 0)
+	for id := uint64(1); id <= 5; id++ {
+		buffer.Add(types.RequestLogEntry{ID: id})
+	}
+
+	buffer.Clear()
+	assert.Equal(t, 0, buffer.Len())
+	assert.Empty(t, buffer.Entries())
+
+	buffer.Add(types.RequestLogEntry{ID: 6})
+	assert.Equal(t, uint64(6), buffer.Entries()[0].ID)
+}
+
+func TestMsgpack_MatchesJSON(t *testing.T) {
+	entry := types.RequestLogEntry{
+		ID:         42,
+		Timestamp:  time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC),
+		Method:     "POST",
+		Path:       "/api/users/" + strings.Repeat("x", 300),
+		StatusCode: 503,
+		Duration:   -70000,
+		Headers:    http.Header{"Content-Type": {"application/json"}, "X-Many": make([]string, 20)},
+		Body:       `{"name":"test"}`,
+	}
+	message := types.TUIMessage{Type: types.MessageRequestLog, Timestamp: entry.Timestamp, Data: entry}
+
+	packed, err := types.MarshalMsgpack(message)
+	require.NoError(t, err)
+	decoded, err := types.UnmarshalMsgpack(packed)
+	require.NoError(t, err)
+
+	// Both encodings must describe the same document
+	normalize := func(v interface{}) interface{} {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		var generic interface{}
+		require.NoError(t, json.Unmarshal(data, &generic))
+		return generic
+	}
+	assert.Equal(t, normalize(message), normalize(decoded))
+
+	jsonData, err := json.Marshal(message)
+	require.NoError(t, err)
+	assert.Less(t, len(packed), len(jsonData))
+}
+
+func TestMsgpack_Numbers(t *testing.T) {
+	for _, n := range []int64{0, 1, 127, 128, 255, 65535, 1 << 40, -1, -32, -33, -128, -129, -40000, -1 << 40} {
+		packed, err := types.MarshalMsgpack(n)
+		require.NoError(t, err)
+		decoded, err := types.UnmarshalMsgpack(packed)
+		require.NoError(t, err)
+		assert.EqualValues(t, n, decoded, "%d", n)
+	}
+
+	packed, err := types.MarshalMsgpack(2.5)
+	require.NoError(t, err)
+	decoded, err := types.UnmarshalMsgpack(packed)
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, decoded)
+
+	_, err = types.UnmarshalMsgpack([]byte{0xda, 0xff})
+	assert.Error(t, err)
+}
+
+func TestMergeStatsUpdate(t *testing.T) {
+	current := &types.ServerStats{
+		RequestCount: 3,
+		Endpoints: map[string]*types.EndpointStats{
+			"/a": {Path: "/a", RequestCount: 2},
+			"/b": {Path: "/b", RequestCount: 1},
+		},
+	}
+	update := &types.ServerStats{
+		RequestCount: 5,
+		Endpoints: map[string]*types.EndpointStats{
+			"/b": {Path: "/b", RequestCount: 2},
+			"/c": {Path: "/c", RequestCount: 1},
+		},
+	}
+
+	merged := types.MergeStatsUpdate(current, update)
+	assert.Equal(t, int64(5), merged.RequestCount)
+	require.Len(t, merged.Endpoints, 3)
+	assert.Equal(t, int64(2), merged.Endpoints["/a"].RequestCount)
+	assert.Equal(t, int64(2), merged.Endpoints["/b"].RequestCount)
+	assert.Equal(t, int64(1), merged.Endpoints["/c"].RequestCount)
+
+	first := &types.ServerStats{RequestCount: 1}
+	assert.Same(t, first, types.MergeStatsUpdate(nil, first))
+}
+
+func TestServerStats_TimeSeries(t *testing.T) {
+	stats := &types.ServerStats{StartTime: time.Now()}
+	stats.RecordRequest("/a", 10*time.Millisecond, 200)
+	stats.RecordRequest("/a", 30*time.Millisecond, 500)
+	stats.RecordRequest("/b", 50*time.Millisecond, 200)
+
+	// The current second is still being recorded and is left out
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+
+	series := stats.TimeSeries(10, 5, "")
+	assert.Equal(t, 10, series.WindowSeconds)
+	require.Len(t, series.Total, 2)
+	require.Len(t, series.Endpoints, 2)
+	assert.Equal(t, series.Total[0].Time.Add(5*time.Second), series.Total[1].Time)
+
+	last := series.Total[1]
+	assert.Equal(t, int64(3), last.Requests)
+	assert.Equal(t, int64(1), last.Errors)
+	assert.Equal(t, int64(50), last.MaxTimeMs)
+	assert.InDelta(t, 30.0, last.AvgTimeMs, 0.01)
+	assert.Equal(t, types.LatencyBucketBoundsMs(), series.LatencyBucketsMs)
+	require.Len(t, last.LatencyBuckets, len(series.LatencyBucketsMs)+1)
+	assert.Equal(t, int64(1), last.LatencyBuckets[0]) // 10ms: ≤10ms
+	assert.Equal(t, int64(2), last.LatencyBuckets[2]) // 30ms and 50ms: ≤50ms
+
+	endpoint := series.Endpoints["/a"][1]
+	assert.Equal(t, int64(2), endpoint.Requests)
+	assert.InDelta(t, 20.0, endpoint.AvgTimeMs, 0.01)
+
+	filtered := stats.TimeSeries(10, 1, "/b")
+	require.Len(t, filtered.Endpoints, 1)
+	require.Len(t, filtered.Total, 10)
+	assert.Equal(t, int64(1), filtered.Total[9].Requests)
+
+	// Windows over two minutes come in whole minutes, the current one left out
+	minutes := stats.TimeSeries(900, 300, "")
+	require.Len(t, minutes.Total, 3)
+	assert.Zero(t, minutes.Total[2].Time.Unix()%60)
+	assert.True(t, minutes.Total[2].Time.Add(5*time.Minute).After(time.Now().Add(-time.Minute)))
+
+	assert.NoError(t, types.ValidateTimeSeriesRange(60, 1))
+	assert.NoError(t, types.ValidateTimeSeriesRange(120, 1))
+	assert.NoError(t, types.ValidateTimeSeriesRange(types.MaxTimeSeriesSeconds, 60))
+	assert.Error(t, types.ValidateTimeSeriesRange(0, 1))
+	assert.Error(t, types.ValidateTimeSeriesRange(types.MaxTimeSeriesSeconds+1, 1))
+	assert.Error(t, types.ValidateTimeSeriesRange(60, 7))
+	assert.Error(t, types.ValidateTimeSeriesRange(300, 10))
+}
+
+func TestServerStats_PathKey(t *testing.T) {
+	stats := &types.ServerStats{StartTime: time.Now()}
+
+	// Stray paths get their own statistics up to a limit, then share one entry
+	var folded int
+	for i := 0; i < 1000; i++ {
+		path := fmt.Sprintf("/missing/%d", i)
+		key := stats.PathKey(path)
+		if key == types.EndpointOther {
+			folded++
+		} else {
+			assert.Equal(t, path, key)
+		}
+		stats.RecordRequest(key, time.Millisecond, 404)
+	}
+	assert.Equal(t, 500, folded)
+	assert.Equal(t, "/missing/1", stats.PathKey("/missing/1"), "tracked paths keep their entry")
+
+	all := stats.GetAllStats()
+	assert.Len(t, all.Endpoints, 501)
+	assert.Equal(t, int64(500), all.Endpoints[types.EndpointOther].RequestCount)
+
+	// Configured endpoints are always tracked
+	stats.RecordRequest("/api/users", time.Millisecond, 200)
+	assert.Contains(t, stats.GetAllStats().Endpoints, "/api/users")
+
+	stats.Reset()
+	assert.Equal(t, "/missing/999", stats.PathKey("/missing/999"))
+}